@@ -15,6 +15,7 @@ import (
 	"github.com/openshift/ci-tools/pkg/load"
 	"github.com/openshift/ci-tools/pkg/load/agents"
 	"github.com/openshift/ci-tools/pkg/registry"
+	"github.com/openshift/ci-tools/pkg/registry/lint"
 	"github.com/openshift/ci-tools/pkg/steps/release"
 	"github.com/openshift/ci-tools/pkg/util"
 	"github.com/openshift/ci-tools/pkg/validation"
@@ -30,32 +31,65 @@ type promotedTag struct {
 type options struct {
 	config.Options
 
-	resolver           registry.Resolver
+	// resolverFor creates a resolver that warns about the deprecated
+	// registry components used by a configuration
+	resolverFor        func(metadata api.Metadata) registry.Resolver
 	ciOPConfigAgent    agents.ConfigAgent
 	clusterProfiles    api.ClusterProfilesMap
 	clusterClaimOwners api.ClusterClaimOwnersMap
+
+	// linter checks the registry components when --lint-registry is set
+	linter       *lint.Linter
+	lintRegistry *lint.Registry
 }
 
 func (o *options) parse() error {
 	var registryDir string
+	var federatedRegistries load.FederatedRegistriesFlag
 	var profilesConfigPath string
 	var clusterClaimConfigPath string
+	var lintRegistry bool
+	var lintConfigPath string
 
 	fs := flag.NewFlagSet("", flag.ExitOnError)
 
 	fs.StringVar(&registryDir, "registry", "", "Path to the step registry directory")
+	fs.Var(&federatedRegistries, "federated-registry", load.FederatedRegistriesUsage)
 	fs.StringVar(&profilesConfigPath, "cluster-profiles-config", "", "Path to the cluster profile config file")
 	fs.StringVar(&clusterClaimConfigPath, "cluster-claim-owners-config", "", "Path to the cluster claim owners config file")
+	fs.BoolVar(&lintRegistry, "lint-registry", false, "Check the step registry with the lint rules")
+	fs.StringVar(&lintConfigPath, "registry-lint-config", "", "Path to the registry lint configuration setting rule severities and suppressions. Implies --lint-registry.")
 	o.Options.Bind(fs)
 
 	if err := fs.Parse(os.Args[1:]); err != nil {
 		return fmt.Errorf("failed to parse flags: %w", err)
 	}
 
-	if err := o.loadResolver(registryDir); err != nil {
+	if registryDir == "" && len(federatedRegistries) != 0 {
+		return errors.New("--federated-registry requires --registry")
+	}
+	if err := o.loadResolver(registryDir, federatedRegistries); err != nil {
 		return fmt.Errorf("failed to load registry: %w", err)
 	}
 
+	if lintRegistry || lintConfigPath != "" {
+		if registryDir == "" {
+			return errors.New("--registry is required to lint the registry")
+		}
+		var lintConfig lint.Config
+		if lintConfigPath != "" {
+			var err error
+			if lintConfig, err = lint.LoadConfig(lintConfigPath); err != nil {
+				return err
+			}
+		}
+		linter, err := lint.NewLinter(lint.DefaultRules(), lintConfig)
+		if err != nil {
+			return err
+		}
+		o.linter = linter
+	}
+
 	profiles, err := load.ClusterProfilesConfig(profilesConfigPath)
 	if err != nil {
 		return fmt.Errorf("failed to load cluster profile config: %w", err)
@@ -118,18 +152,49 @@ func (o *options) validate() (ret []error) {
 	if err := util.ProduceMapReduce(0, produce, map_, reduce, done, errCh); err != nil {
 		ret = append(ret, err)
 	}
-	return append(ret, validateTags(seen)...)
+	ret = append(ret, validateTags(seen)...)
+	return append(ret, o.lint()...)
 }
 
-func (o *options) loadResolver(path string) error {
+// lint checks the registry, logging warnings and returning a failure for each
+// finding with error severity
+func (o *options) lint() []error {
+	if o.linter == nil {
+		return nil
+	}
+	// unused components can only be detected when all configurations are loaded
+	if o.Org == "" && o.Repo == "" {
+		o.lintRegistry.Configs = []api.ReleaseBuildConfiguration{}
+		for _, repos := range o.ciOPConfigAgent.GetAll() {
+			for _, configs := range repos {
+				o.lintRegistry.Configs = append(o.lintRegistry.Configs, configs...)
+			}
+		}
+	}
+	findings := o.linter.Lint(o.lintRegistry)
+	for _, f := range findings {
+		if f.Severity == lint.SeverityWarning {
+			logrus.WithField("rule", f.Rule).Warn(f.String())
+		}
+	}
+	return lint.Errors(findings)
+}
+
+func (o *options) loadResolver(path string, federated []load.FederatedRegistry) error {
 	if path == "" {
 		return nil
 	}
-	refs, chains, workflows, _, _, _, observers, err := load.Registry(path, load.RegistryFlag(0))
+	refs, chains, workflows, _, _, metadata, observers, err := load.FederatedRegistries(path, load.RegistryMetadata|load.RegistryAnalyzeCommands, federated)
 	if err != nil {
 		return err
 	}
-	o.resolver = registry.NewResolver(refs, chains, workflows, observers)
+	o.lintRegistry = &lint.Registry{References: refs, Chains: chains, Workflows: workflows, Observers: observers}
+	deprecations := load.Deprecations(metadata)
+	o.resolverFor = func(metadata api.Metadata) registry.Resolver {
+		return registry.NewResolver(refs, chains, workflows, observers, registry.WithDeprecations(deprecations, func(w registry.DeprecationWarning) {
+			logrus.WithField("config", metadata.RelativePath()).Warn(w.String())
+		}))
+	}
 	return nil
 }
 
@@ -138,8 +203,8 @@ func (o *options) validateConfiguration(
 	seenCh chan<- promotedTag,
 	configuration api.ReleaseBuildConfiguration,
 ) error {
-	if o.resolver != nil {
-		if c, err := registry.ResolveConfig(o.resolver, configuration); err != nil {
+	if o.resolverFor != nil {
+		if c, err := registry.ResolveConfig(o.resolverFor(configuration.Metadata), configuration); err != nil {
 			return err
 		} else if err := validator.IsValidResolvedConfiguration(&c); err != nil {
 			return err
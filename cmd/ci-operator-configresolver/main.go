@@ -33,7 +33,9 @@ import (
 	"github.com/openshift/ci-tools/pkg/api/configresolver"
 	"github.com/openshift/ci-tools/pkg/config"
 	"github.com/openshift/ci-tools/pkg/html"
+	"github.com/openshift/ci-tools/pkg/load"
 	"github.com/openshift/ci-tools/pkg/load/agents"
+	"github.com/openshift/ci-tools/pkg/registry/bundle"
 	registryserver "github.com/openshift/ci-tools/pkg/registry/server"
 	"github.com/openshift/ci-tools/pkg/util"
 	"github.com/openshift/ci-tools/pkg/webreg"
@@ -51,6 +53,12 @@ type options struct {
 	gracePeriod            time.Duration
 	validateOnly           bool
 	flatRegistry           bool
+	registrySnapshots      int
+	registrySnapshotDir    string
+	registryBundle         string
+	registryBundleKey      string
+	allowRegistryUploads   bool
+	federatedRegistries    load.FederatedRegistriesFlag
 	instrumentationOptions flagutil.InstrumentationOptions
 }
 
@@ -73,6 +81,12 @@ func gatherOptions() (options, error) {
 	_ = fs.Duration("cycle", time.Minute*2, "Legacy flag kept for compatibility. Does nothing")
 	fs.BoolVar(&o.validateOnly, "validate-only", false, "Load the config and registry, validate them and exit.")
 	fs.BoolVar(&o.flatRegistry, "flat-registry", false, "Disable directory structure based registry validation")
+	fs.IntVar(&o.registrySnapshots, "registry-snapshots", 50, "Number of registry versions to retain in memory for resolving configurations that pin a registry_version")
+	fs.StringVar(&o.registrySnapshotDir, "registry-snapshot-dir", "", "Directory to persist every loaded registry version in, so configurations pinning a registry_version no longer retained in memory can still be resolved. Should be backed by persistent storage.")
+	fs.StringVar(&o.registryBundle, "registry-bundle", "", "Path to a signed registry bundle to serve instead of --config and --registry")
+	fs.StringVar(&o.registryBundleKey, "registry-bundle-public-key", "", "Path to the PEM-encoded ed25519 public key the registry bundle is verified with")
+	fs.Var(&o.federatedRegistries, "federated-registry", load.FederatedRegistriesUsage)
+	fs.BoolVar(&o.allowRegistryUploads, "allow-registry-uploads", false, "Allow comparing resolved tests with registry archives uploaded to /resolvedDiff and the UI. Uploads are processed by the server, so only enable this on instances that are not publicly reachable.")
 	o.instrumentationOptions.AddFlags(fs)
 	if err := fs.Parse(os.Args[1:]); err != nil {
 		return o, fmt.Errorf("failed to parse flags: %w", err)
@@ -90,7 +104,14 @@ func validateOptions(o *options) error {
 		return fmt.Errorf("--release-repo-path is mutually exclusive with --config and --registry")
 	}
 
-	if o.releaseRepoGitSyncPath == "" {
+	if o.registryBundle != "" {
+		if o.releaseRepoGitSyncPath != "" || o.configPath != "" || o.registryPath != "" {
+			return errors.New("--registry-bundle is mutually exclusive with --release-repo-path, --config and --registry")
+		}
+		if o.registryBundleKey == "" {
+			return errors.New("--registry-bundle-public-key is required with --registry-bundle")
+		}
+	} else if o.releaseRepoGitSyncPath == "" {
 		if o.configPath == "" {
 			return fmt.Errorf("--config is required")
 		}
@@ -124,6 +145,27 @@ func validateOptions(o *options) error {
 		o.registryPath = filepath.Join(o.releaseRepoGitSyncPath, config.RegistryPath)
 	}
 
+	if o.registrySnapshots < 1 {
+		return errors.New("--registry-snapshots must be at least 1")
+	}
+	if o.registrySnapshotDir != "" {
+		if o.registryBundle != "" {
+			return errors.New("--registry-snapshot-dir cannot be used with --registry-bundle")
+		}
+		if _, err := os.Stat(o.registrySnapshotDir); err != nil {
+			return fmt.Errorf("error getting stat info for --registry-snapshot-dir directory: %w", err)
+		}
+	}
+
+	if o.registryBundle != "" && len(o.federatedRegistries) != 0 {
+		return errors.New("--federated-registry cannot be used with --registry-bundle, pass it to registry-bundle to include federated registries in the bundle")
+	}
+	for _, federated := range o.federatedRegistries {
+		if _, err := os.Stat(federated.Path); err != nil {
+			return fmt.Errorf("--federated-registry %s: %w", federated.Namespace, err)
+		}
+	}
+
 	if o.validateOnly && o.flatRegistry {
 		return errors.New("--validate-only and --flat-registry flags cannot be set simultaneously")
 	}
@@ -144,6 +186,13 @@ func getRegistryGeneration(agent agents.RegistryAgent) http.HandlerFunc {
 	}
 }
 
+func getRegistryVersion(agent agents.RegistryAgent) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		fmt.Fprint(w, agent.GetRegistryVersion())
+	}
+}
+
 type memoryCache struct {
 	Client                 ctrlruntimeclient.Client
 	IntegratedStreamsMutex sync.Mutex
@@ -284,19 +333,37 @@ func main() {
 		interrupts.Run(watcher)
 	}
 
-	configErrCh := make(chan error)
-	configAgent, err := agents.NewConfigAgent(o.configPath, configErrCh, agents.WithConfigMetrics(configresolverMetrics.ErrorRate), configAgentOption)
-	if err != nil {
-		logrus.Fatalf("Failed to get config agent: %v", err)
-	}
-	go func() { logrus.Fatal(<-configErrCh) }()
+	var configAgent agents.ConfigAgent
+	var registryAgent agents.RegistryAgent
+	if o.registryBundle != "" {
+		key, err := bundle.LoadPublicKey(o.registryBundleKey)
+		if err != nil {
+			logrus.WithError(err).Fatal("Failed to load registry bundle public key")
+		}
+		b, err := bundle.ReadFile(o.registryBundle, key)
+		if err != nil {
+			logrus.WithError(err).Fatal("Failed to load registry bundle")
+		}
+		configAgent = agents.NewStaticConfigAgent(b.Configs)
+		registryAgent, err = agents.NewStaticRegistryAgent(b.Loader(), agents.WithRegistryMetrics(configresolverMetrics.ErrorRate))
+		if err != nil {
+			logrus.Fatalf("Failed to get registry agent: %v", err)
+		}
+	} else {
+		configErrCh := make(chan error)
+		configAgent, err = agents.NewConfigAgent(o.configPath, configErrCh, agents.WithConfigMetrics(configresolverMetrics.ErrorRate), configAgentOption)
+		if err != nil {
+			logrus.Fatalf("Failed to get config agent: %v", err)
+		}
+		go func() { logrus.Fatal(<-configErrCh) }()
 
-	registryErrCh := make(chan error)
-	registryAgent, err := agents.NewRegistryAgent(o.registryPath, registryErrCh, agents.WithRegistryMetrics(configresolverMetrics.ErrorRate), agents.WithRegistryFlat(o.flatRegistry), registryAgentOption)
-	if err != nil {
-		logrus.Fatalf("Failed to get registry agent: %v", err)
+		registryErrCh := make(chan error)
+		registryAgent, err = agents.NewRegistryAgent(o.registryPath, registryErrCh, agents.WithRegistryMetrics(configresolverMetrics.ErrorRate), agents.WithRegistryFlat(o.flatRegistry), agents.WithRegistrySnapshots(o.registrySnapshots), agents.WithRegistrySnapshotDir(o.registrySnapshotDir), agents.WithFederatedRegistries(o.federatedRegistries...), registryAgentOption)
+		if err != nil {
+			logrus.Fatalf("Failed to get registry agent: %v", err)
+		}
+		go func() { logrus.Fatal(<-registryErrCh) }()
 	}
-	go func() { logrus.Fatal(<-registryErrCh) }()
 
 	inClusterConfig, err := util.LoadClusterConfig()
 	if err != nil {
@@ -322,7 +389,12 @@ func main() {
 		l("clusterProfile"),
 		l("configGeneration"),
 		l("registryGeneration"),
+		l("registryVersion"),
 		l("integratedStream"),
+		l("impact"),
+		l("deprecations"),
+		l("registrySearch"),
+		l("resolvedDiff"),
 	))
 
 	uisimplifier := simplifypath.NewSimplifier(l("", // shadow element mimicing the root
@@ -332,6 +404,10 @@ func main() {
 		l("reference"),
 		l("chain"),
 		l("workflow"),
+		l("impact"),
+		l("deprecations"),
+		l("registry-search"),
+		l("resolved-diff"),
 	))
 	handler := metrics.TraceHandler(simplifier, configresolverMetrics.HTTPRequestDuration, configresolverMetrics.HTTPResponseSize)
 	uihandler := metrics.TraceHandler(uisimplifier, configresolverMetrics.HTTPRequestDuration, configresolverMetrics.HTTPResponseSize)
@@ -340,16 +416,22 @@ func main() {
 	http.HandleFunc("/config", handler(registryserver.ResolveConfig(configAgent, registryAgent, configresolverMetrics)).ServeHTTP)
 	http.HandleFunc("/mergeConfigsWithInjectedTest", handler(registryserver.ResolveAndMergeConfigsAndInjectTest(configAgent, registryAgent, configresolverMetrics)).ServeHTTP)
 	http.HandleFunc("/resolve", handler(registryserver.ResolveLiteralConfig(registryAgent, configresolverMetrics)).ServeHTTP)
+	http.HandleFunc("/impact", handler(registryserver.ResolveImpact(registryAgent, configAgent, configresolverMetrics)).ServeHTTP)
+	http.HandleFunc("/deprecations", handler(registryserver.ResolveDeprecations(registryAgent, configAgent, configresolverMetrics)).ServeHTTP)
+	searcher := registryserver.NewSearcher(registryAgent)
+	http.HandleFunc("/registrySearch", handler(registryserver.ResolveSearch(searcher, configresolverMetrics)).ServeHTTP)
+	http.HandleFunc("/resolvedDiff", handler(registryserver.ResolveDiff(registryAgent, configAgent, o.allowRegistryUploads, configresolverMetrics)).ServeHTTP)
 	http.HandleFunc("/clusterProfile", handler(registryserver.ResolveClusterProfile(registryAgent, configresolverMetrics)).ServeHTTP)
 	http.HandleFunc("/configGeneration", handler(getConfigGeneration(configAgent)).ServeHTTP)
 	http.HandleFunc("/registryGeneration", handler(getRegistryGeneration(registryAgent)).ServeHTTP)
+	http.HandleFunc("/registryVersion", handler(getRegistryVersion(registryAgent)).ServeHTTP)
 	cache := memoryCache{Client: ocClient, CacheDuration: time.Minute}
 	http.HandleFunc("/integratedStream", handler(getIntegratedStream(context.Background(), &cache)).ServeHTTP)
 	http.HandleFunc("/readyz", func(_ http.ResponseWriter, _ *http.Request) {})
 	interrupts.ListenAndServe(&http.Server{Addr: ":" + strconv.Itoa(o.port)}, o.gracePeriod)
 	uiMux := http.NewServeMux()
 	uiMux.HandleFunc(html.StaticURL, handler(http.StripPrefix(html.StaticURL, http.FileServer(http.FS(static)))).ServeHTTP)
-	uiMux.Handle("/", uihandler(webreg.WebRegHandler(registryAgent, configAgent)))
+	uiMux.Handle("/", uihandler(webreg.WebRegHandler(registryAgent, configAgent, searcher, o.allowRegistryUploads)))
 	uiServer := &http.Server{
 		Addr:    ":" + strconv.Itoa(o.uiPort),
 		Handler: uiMux,
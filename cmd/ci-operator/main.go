@@ -29,6 +29,7 @@ import (
 	"github.com/go-logr/logr"
 	egressfirewallv1 "github.com/ovn-org/ovn-kubernetes/go-controller/pkg/crd/egressfirewall/v1"
 	"github.com/sirupsen/logrus"
+	"go.opentelemetry.io/otel/attribute"
 
 	appsv1 "k8s.io/api/apps/v1"
 	authapi "k8s.io/api/authorization/v1"
@@ -85,10 +86,12 @@ import (
 	"github.com/openshift/ci-tools/pkg/lease"
 	"github.com/openshift/ci-tools/pkg/load"
 	"github.com/openshift/ci-tools/pkg/registry"
+	"github.com/openshift/ci-tools/pkg/registry/bundle"
 	"github.com/openshift/ci-tools/pkg/registry/server"
 	"github.com/openshift/ci-tools/pkg/results"
 	"github.com/openshift/ci-tools/pkg/secrets"
 	"github.com/openshift/ci-tools/pkg/steps"
+	"github.com/openshift/ci-tools/pkg/tracing"
 	"github.com/openshift/ci-tools/pkg/util"
 	"github.com/openshift/ci-tools/pkg/util/gzip"
 	"github.com/openshift/ci-tools/pkg/validation"
@@ -114,6 +117,19 @@ a description of JOB_SPEC.
 The inputs of the build (source code, tagged images, configuration) are combined to form
 a consistent name for the target namespace that will change if any of the inputs change.
 This allows multiple test jobs to share common artifacts and still perform retries.
+With --resume, every step that completes is recorded in a checkpoint in the target
+namespace, and an interrupted execution started with --resume can be continued by
+running it again with --resume, which only schedules the steps that have not yet
+completed.
+
+The --plan flag resolves the configuration and the execution graph for the targets
+and prints what an execution would do without contacting a cluster: which images are
+built or imported, which leases and cluster claims are taken, which secrets are mounted
+and the resource requests of every Pod. Pass --plan-pod-scaler-data with a copy of the
+pod-scaler cache to also print the historical usage of every Pod at the default
+quantiles of the pod-scaler. This is raw, unweighted usage: the requests set by the
+pod-scaler admission may differ, as it weighs usage by recency and applies policies,
+caps and headroom that are only configured on the server.
 
 The standard build steps are designed for simple command-line actions (like invoking
 "make test") but can be extended by passing one or more templates via the --template flag.
@@ -258,6 +274,13 @@ func main() {
 		os.Exit(1)
 	}
 
+	if opt.plan {
+		if err := opt.Plan(os.Stdout); err != nil {
+			logrus.WithError(err).Fatal("Failed to print the execution plan.")
+		}
+		return
+	}
+
 	if errs := opt.Run(); len(errs) > 0 {
 		var defaulted []error
 		for _, err := range errs {
@@ -363,6 +386,13 @@ type options struct {
 	verbose    bool
 	help       bool
 	printGraph bool
+	resume     bool
+
+	plan              bool
+	planPodScalerData string
+
+	concurrency     api.ConcurrencyConfiguration
+	executionPolicy string
 
 	writeParams string
 	artifactDir string
@@ -399,11 +429,14 @@ type options struct {
 	resolverAddress string
 	resolverClient  server.ResolverClient
 
-	registryPath string
-	org          string
-	repo         string
-	branch       string
-	variant      string
+	registryPath            string
+	federatedRegistries     load.FederatedRegistriesFlag
+	registryBundlePath      string
+	registryBundlePublicKey string
+	org                     string
+	repo                    string
+	branch                  string
+	variant                 string
 
 	injectTest string
 
@@ -421,6 +454,7 @@ type options struct {
 	cloneAuthConfig *steps.CloneAuthConfig
 
 	resultsOptions results.Options
+	tracingOptions tracing.Options
 
 	censor *secrets.DynamicCensor
 
@@ -456,10 +490,21 @@ func bindOptions(flag *flag.FlagSet) *options {
 	flag.StringVar(&opt.leaseServerCredentialsFile, "lease-server-credentials-file", "", "The path to credentials file used to access the lease server. The content is of the form <username>:<password>.")
 	flag.DurationVar(&opt.leaseAcquireTimeout, "lease-acquire-timeout", leaseAcquireTimeout, "Maximum amount of time to wait for lease acquisition")
 	flag.StringVar(&opt.registryPath, "registry", "", "Path to the step registry directory")
+	flag.Var(&opt.federatedRegistries, "federated-registry", load.FederatedRegistriesUsage)
+	flag.StringVar(&opt.registryBundlePath, "registry-bundle", "", "Path to a signed registry bundle. When set, configurations and cluster profiles are resolved from the bundle instead of the configresolver.")
+	flag.StringVar(&opt.registryBundlePublicKey, "registry-bundle-public-key", "", "Path to the PEM-encoded ed25519 public key the registry bundle is verified with")
 	flag.StringVar(&opt.configSpecPath, "config", "", "The configuration file. If not specified the CONFIG_SPEC environment variable or the configresolver will be used.")
 	flag.StringVar(&opt.unresolvedConfigPath, "unresolved-config", "", "The configuration file, before resolution. If not specified the UNRESOLVED_CONFIG environment variable will be used, if set.")
 	flag.Var(&opt.targets, "target", "One or more targets in the configuration to build. Only steps that are required for this target will be run.")
 	flag.BoolVar(&opt.printGraph, "print-graph", opt.printGraph, "Print a directed graph of the build steps and exit. Intended for use with the golang digraph utility.")
+	flag.BoolVar(&opt.plan, "plan", opt.plan, "Print the execution plan for the targets and exit, without contacting a cluster.")
+	flag.StringVar(&opt.planPodScalerData, "plan-pod-scaler-data", "", "Directory holding the usage data cached by the pod-scaler, used to add the unweighted historical usage of every Pod to the --plan output.")
+	flag.IntVar(&opt.concurrency.MaxParallelism, "max-parallelism", 0, "Maximum number of steps to run at the same time. Overrides the configuration, zero means no limit.")
+	flag.IntVar(&opt.concurrency.Builds, "max-parallel-builds", 0, "Maximum number of image builds to run at the same time. Overrides the configuration, zero means no limit.")
+	flag.IntVar(&opt.concurrency.Tests, "max-parallel-tests", 0, "Maximum number of tests to run at the same time. Overrides the configuration, zero means no limit.")
+	flag.IntVar(&opt.concurrency.Imports, "max-parallel-imports", 0, "Maximum number of image imports to run at the same time. Overrides the configuration, zero means no limit.")
+	flag.StringVar(&opt.executionPolicy, "execution-policy", "", fmt.Sprintf("How to continue after a step fails: %q cancels all running steps on the first failure of a non-optional step, %q runs every independent branch and reports all failures. Overrides the policy of the targeted tests.", api.ExecutionPolicyFailFast, api.ExecutionPolicyKeepGoing))
+	flag.BoolVar(&opt.resume, "resume", false, "Record completed steps in a checkpoint in the namespace and resume a previous execution that used --resume, skipping the steps recorded as completed.")
 
 	// add to the graph of things we run or create
 	flag.Var(&opt.templatePaths, "template", "A set of paths to optional templates to add as stages to this job. Each template is expected to contain at least one restart=Never pod. Parameters are filled from environment or from the automatic parameters generated by the operator.")
@@ -512,10 +557,17 @@ func bindOptions(flag *flag.FlagSet) *options {
 	flag.StringVar(&opt.localRegistryDNS, "local-registry-dns", "image-registry.openshift-image-registry.svc:5000", "Defines the target image registry.")
 
 	opt.resultsOptions.Bind(flag)
+	opt.tracingOptions.Bind(flag)
 	return opt
 }
 
 func (o *options) Complete() error {
+	switch api.ExecutionPolicy(o.executionPolicy) {
+	case "", api.ExecutionPolicyKeepGoing, api.ExecutionPolicyFailFast:
+	default:
+		return fmt.Errorf("--execution-policy must be one of %q or %q", api.ExecutionPolicyFailFast, api.ExecutionPolicyKeepGoing)
+	}
+
 	jobSpec, err := api.ResolveSpecFromEnv()
 	if err != nil {
 		if len(o.gitRef) == 0 {
@@ -545,12 +597,36 @@ func (o *options) Complete() error {
 
 	info := o.getResolverInfo(jobSpec)
 	o.resolverClient = server.NewResolverClient(o.resolverAddress)
+	if len(o.federatedRegistries) != 0 && o.registryPath == "" {
+		return errors.New("--federated-registry requires --registry")
+	}
+	if o.registryBundlePath != "" {
+		if o.registryPath != "" {
+			return errors.New("cannot set --registry and --registry-bundle at the same time")
+		}
+		if o.registryBundlePublicKey == "" {
+			return errors.New("--registry-bundle-public-key is required with --registry-bundle")
+		}
+		key, err := bundle.LoadPublicKey(o.registryBundlePublicKey)
+		if err != nil {
+			return fmt.Errorf("failed to load registry bundle public key: %w", err)
+		}
+		b, err := bundle.ReadFile(o.registryBundlePath, key)
+		if err != nil {
+			return fmt.Errorf("failed to load registry bundle: %w", err)
+		}
+		logrus.Infof("Resolving configuration from registry bundle at version %s", b.RegistryVersion)
+		o.resolverClient = bundle.NewResolverClient(b)
+	}
 
 	if o.unresolvedConfigPath != "" && o.configSpecPath != "" {
 		return errors.New("cannot set --config and --unresolved-config at the same time")
 	}
-	if o.unresolvedConfigPath != "" && o.resolverAddress == "" {
-		return errors.New("cannot request resolved config with --unresolved-config unless providing --resolver-address")
+	if o.unresolvedConfigPath != "" && o.resolverAddress == "" && o.registryBundlePath == "" {
+		return errors.New("cannot request resolved config with --unresolved-config unless providing --resolver-address or --registry-bundle")
+	}
+	if o.planPodScalerData != "" && !o.plan {
+		return errors.New("--plan-pod-scaler-data can only be used with --plan")
 	}
 
 	injectTest, err := o.getInjectTest()
@@ -684,21 +760,24 @@ func (o *options) Complete() error {
 		o.templates = append(o.templates, template)
 	}
 
-	clusterConfig, err := util.LoadClusterConfig()
-	if err != nil {
-		return fmt.Errorf("failed to load cluster config: %w", err)
-	}
+	// the plan is determined without contacting a cluster
+	if !o.plan {
+		clusterConfig, err := util.LoadClusterConfig()
+		if err != nil {
+			return fmt.Errorf("failed to load cluster config: %w", err)
+		}
 
-	if len(o.impersonateUser) > 0 {
-		clusterConfig.Impersonate = rest.ImpersonationConfig{UserName: o.impersonateUser}
-	}
+		if len(o.impersonateUser) > 0 {
+			clusterConfig.Impersonate = rest.ImpersonationConfig{UserName: o.impersonateUser}
+		}
 
-	if o.verbose {
-		clusterConfig.ContentType = "application/json"
-		clusterConfig.AcceptContentTypes = "application/json"
-	}
+		if o.verbose {
+			clusterConfig.ContentType = "application/json"
+			clusterConfig.AcceptContentTypes = "application/json"
+		}
 
-	o.clusterConfig = clusterConfig
+		o.clusterConfig = clusterConfig
+	}
 
 	if o.pullSecretPath != "" {
 		if o.pullSecret, err = getDockerConfigSecret(api.RegistryPullCredentialsSecret, o.pullSecretPath); err != nil {
@@ -913,6 +992,21 @@ func (o *options) Run() []error {
 		logrus.Infof("error: Process interrupted with signal %s, cancelling execution...", s)
 		cancel()
 	}
+	shutdownTracing, err := o.tracingOptions.Setup(ctx,
+		attribute.String("ci.job", o.jobSpec.Job),
+		attribute.String("ci.build_id", o.jobSpec.BuildID),
+		attribute.String("ci.namespace", o.namespace),
+	)
+	if err != nil {
+		return []error{fmt.Errorf("could not set up tracing: %w", err)}
+	}
+	defer func() {
+		if err := shutdownTracing(context.Background()); err != nil {
+			logrus.WithError(err).Warn("Unable to export spans.")
+		}
+	}()
+	ctx, span := tracing.Start(ctx, "ci-operator")
+	defer span.End()
 	var leaseClient *lease.Client
 	if o.leaseServer != "" && o.leaseServerCredentialsFile != "" {
 		leaseClient = &o.leaseClient
@@ -936,9 +1030,10 @@ func (o *options) Run() []error {
 	}
 
 	injectedTest := o.injectTest != ""
+	params := api.NewDeferredParameters(nil)
 	// load the graph from the configuration
 	buildSteps, promotionSteps, err := defaults.FromConfig(ctx, o.configSpec, &o.graphConfig, o.jobSpec, o.templates, o.writeParams, o.promote, o.clusterConfig,
-		o.podPendingTimeout, leaseClient, o.targets.values, o.cloneAuthConfig, o.pullSecret, o.pushSecret, o.censor, o.hiveKubeconfig,
+		o.podPendingTimeout, leaseClient, o.targets.values, o.cloneAuthConfig, o.pullSecret, o.pushSecret, params, o.censor, o.hiveKubeconfig,
 		o.nodeName, nodeArchitectures, o.targetAdditionalSuffix, o.manifestToolDockerCfg, o.localRegistryDNS, streams, injectedTest, o.enableSecretsStoreCSIDriver)
 	if err != nil {
 		return []error{results.ForReason("defaulting_config").WithError(err).Errorf("failed to generate steps from config: %v", err)}
@@ -1011,8 +1106,16 @@ func (o *options) Run() []error {
 		}
 		runtimeObject := &coreapi.ObjectReference{Namespace: o.namespace}
 		eventRecorder.Event(runtimeObject, coreapi.EventTypeNormal, "CiJobStarted", eventJobDescription(o.jobSpec, o.namespace))
+		runOpts := []steps.RunOption{steps.WithConcurrency(o.resolveConcurrency()), steps.WithExecutionPolicy(o.resolveExecutionPolicy(), o.optionalTests()...)}
+		if o.resume {
+			ctrlClient, err := ctrlruntimeclient.New(o.clusterConfig, ctrlruntimeclient.Options{})
+			if err != nil {
+				return []error{fmt.Errorf("could not get client for cluster config: %w", err)}
+			}
+			runOpts = append(runOpts, steps.WithCheckpoint(steps.NewConfigMapCheckpointStore(ctrlClient, o.namespace), params))
+		}
 		// execute the graph
-		suites, graphDetails, errs := steps.Run(ctx, nodes)
+		suites, graphDetails, errs := steps.Run(ctx, nodes, runOpts...)
 		if err := o.writeJUnit(suites, "operator"); err != nil {
 			logrus.WithError(err).Warn("Unable to write JUnit result.")
 		}
@@ -1053,6 +1156,62 @@ func (o *options) Run() []error {
 	})
 }
 
+// resolveConcurrency merges the concurrency limits from the configuration
+// with the ones set by flags, which take precedence.
+func (o *options) resolveConcurrency() api.ConcurrencyConfiguration {
+	var resolved api.ConcurrencyConfiguration
+	if o.configSpec != nil && o.configSpec.Concurrency != nil {
+		resolved = *o.configSpec.Concurrency
+	}
+	for _, limit := range []struct {
+		flag int
+		into *int
+	}{
+		{flag: o.concurrency.MaxParallelism, into: &resolved.MaxParallelism},
+		{flag: o.concurrency.Builds, into: &resolved.Builds},
+		{flag: o.concurrency.Tests, into: &resolved.Tests},
+		{flag: o.concurrency.Imports, into: &resolved.Imports},
+	} {
+		if limit.flag > 0 {
+			*limit.into = limit.flag
+		}
+	}
+	return resolved
+}
+
+// resolveExecutionPolicy determines the execution policy from the flag or,
+// if it is not set, from the targeted tests. Any targeted test that asks to
+// fail fast makes the whole execution fail fast.
+func (o *options) resolveExecutionPolicy() api.ExecutionPolicy {
+	if o.executionPolicy != "" {
+		return api.ExecutionPolicy(o.executionPolicy)
+	}
+	if o.configSpec == nil {
+		return api.ExecutionPolicyKeepGoing
+	}
+	targets := sets.New[string](o.targets.values...)
+	for _, test := range o.configSpec.Tests {
+		if targets.Has(test.As) && test.ExecutionPolicy == api.ExecutionPolicyFailFast {
+			return api.ExecutionPolicyFailFast
+		}
+	}
+	return api.ExecutionPolicyKeepGoing
+}
+
+// optionalTests lists the tests whose failure does not fail the job.
+func (o *options) optionalTests() []string {
+	if o.configSpec == nil {
+		return nil
+	}
+	var optional []string
+	for _, test := range o.configSpec.Tests {
+		if test.Optional {
+			optional = append(optional, test.As)
+		}
+	}
+	return optional
+}
+
 func runPromotionStep(ctx context.Context, step api.Step, detailsChan chan<- api.CIOperatorStepDetails, errChan chan<- error) {
 	details, err := runStep(ctx, step)
 	if err != nil {
@@ -1108,7 +1267,9 @@ func integratedStreams(config *api.ReleaseBuildConfiguration, client server.Reso
 // so we can not re-use it.
 func runStep(ctx context.Context, step api.Step) (api.CIOperatorStepDetails, error) {
 	start := time.Now()
+	ctx, span := tracing.Start(ctx, step.Name())
 	err := step.Run(ctx)
+	tracing.End(span, err)
 	duration := time.Since(start)
 	failed := err != nil
 
@@ -2335,11 +2496,19 @@ func (o *options) loadConfig(info *api.Metadata, gcsReader gcsFileReader) (*api.
 		return nil, fmt.Errorf("invalid configuration: %w\nvalue:\n%s", err, raw)
 	}
 	if o.registryPath != "" {
-		refs, chains, workflows, _, _, _, observers, err := load.Registry(o.registryPath, load.RegistryFlag(0))
+		refs, chains, workflows, _, _, metadata, observers, err := load.FederatedRegistries(o.registryPath, load.RegistryMetadata, o.federatedRegistries)
 		if err != nil {
 			return nil, fmt.Errorf("failed to load registry: %w", err)
 		}
-		configSpec, err = registry.ResolveConfig(registry.NewResolver(refs, chains, workflows, observers), configSpec)
+		version, err := registry.Version(refs, chains, workflows, observers)
+		if err != nil {
+			return nil, fmt.Errorf("failed to determine registry version: %w", err)
+		}
+		if configSpec.RegistryVersion != "" && configSpec.RegistryVersion != version {
+			return nil, fmt.Errorf("configuration pins registry version %s, but the registry in %s is at version %s", configSpec.RegistryVersion, o.registryPath, version)
+		}
+		configSpec.RegistryVersion = version
+		configSpec, err = registry.ResolveConfig(registry.NewResolver(refs, chains, workflows, observers, registry.WithDeprecations(load.Deprecations(metadata), nil)), configSpec)
 		if err != nil {
 			return nil, fmt.Errorf("failed to resolve configuration: %w", err)
 		}
@@ -1832,3 +1832,88 @@ func TestGetClusterProfileNamesFromTargets(t *testing.T) {
 		})
 	}
 }
+
+func TestResolveConcurrency(t *testing.T) {
+	var testCases = []struct {
+		name     string
+		config   *api.ConcurrencyConfiguration
+		flags    api.ConcurrencyConfiguration
+		expected api.ConcurrencyConfiguration
+	}{
+		{
+			name: "nothing configured",
+		},
+		{
+			name:     "configuration only",
+			config:   &api.ConcurrencyConfiguration{MaxParallelism: 10, Builds: 3},
+			expected: api.ConcurrencyConfiguration{MaxParallelism: 10, Builds: 3},
+		},
+		{
+			name:     "flags override the configuration",
+			config:   &api.ConcurrencyConfiguration{MaxParallelism: 10, Builds: 3},
+			flags:    api.ConcurrencyConfiguration{Builds: 1, Imports: 2},
+			expected: api.ConcurrencyConfiguration{MaxParallelism: 10, Builds: 1, Imports: 2},
+		},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			o := &options{
+				configSpec:  &api.ReleaseBuildConfiguration{Concurrency: testCase.config},
+				concurrency: testCase.flags,
+			}
+			if diff := cmp.Diff(testCase.expected, o.resolveConcurrency()); diff != "" {
+				t.Errorf("incorrect concurrency: %s", diff)
+			}
+		})
+	}
+}
+
+func TestResolveExecutionPolicy(t *testing.T) {
+	config := &api.ReleaseBuildConfiguration{
+		Tests: []api.TestStepConfiguration{
+			{As: "unit"},
+			{As: "e2e", ExecutionPolicy: api.ExecutionPolicyFailFast},
+			{As: "e2e-upgrade", ExecutionPolicy: api.ExecutionPolicyKeepGoing},
+		},
+	}
+	var testCases = []struct {
+		name     string
+		flag     string
+		targets  []string
+		expected api.ExecutionPolicy
+	}{
+		{
+			name:     "defaults to keep-going",
+			targets:  []string{"unit"},
+			expected: api.ExecutionPolicyKeepGoing,
+		},
+		{
+			name:     "targeted test asks to fail fast",
+			targets:  []string{"unit", "e2e"},
+			expected: api.ExecutionPolicyFailFast,
+		},
+		{
+			name:     "targeted test asks to keep going",
+			targets:  []string{"e2e-upgrade"},
+			expected: api.ExecutionPolicyKeepGoing,
+		},
+		{
+			name:     "flag overrides the targeted tests",
+			flag:     string(api.ExecutionPolicyKeepGoing),
+			targets:  []string{"e2e"},
+			expected: api.ExecutionPolicyKeepGoing,
+		},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			o := &options{
+				configSpec:      config,
+				executionPolicy: testCase.flag,
+				targets:         stringSlice{values: testCase.targets},
+			}
+			if diff := cmp.Diff(testCase.expected, o.resolveExecutionPolicy()); diff != "" {
+				t.Errorf("incorrect execution policy: %s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,303 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/api/resource"
+
+	"github.com/openshift/ci-tools/pkg/api"
+	"github.com/openshift/ci-tools/pkg/defaults"
+	podscaler "github.com/openshift/ci-tools/pkg/pod-scaler"
+	"github.com/openshift/ci-tools/pkg/steps"
+)
+
+// executionPlan describes what an execution for the targets would do.
+type executionPlan struct {
+	Targets   []string
+	Namespace string
+	Steps     []plannedStep
+	Promotion []string
+}
+
+// plannedStep describes one step of the execution graph.
+type plannedStep struct {
+	Name        string
+	Description string
+	// Action is what the step does: build, import, reuse or run
+	Action string
+
+	Leases         []api.StepLease
+	ClusterClaim   *api.ClusterClaim
+	ClusterProfile api.ClusterProfile
+	Secrets        []string
+	Pods           []plannedPod
+}
+
+// plannedPod describes one Pod the step would create.
+type plannedPod struct {
+	Name      string
+	Container string
+	// Requests are the requests set by the configuration
+	Requests api.ResourceList
+	// Usage is the historical usage of the container at the default quantiles
+	// of the pod-scaler, if it has data for the Pod. This is not what the
+	// pod-scaler admission sets: it does not weigh the data by recency or apply
+	// policies, caps or headroom, as those are only configured on the server.
+	Usage api.ResourceList
+}
+
+const (
+	planActionBuild  = "build"
+	planActionImport = "import"
+	planActionReuse  = "reuse"
+	planActionRun    = "run"
+)
+
+// Plan resolves the execution graph for the targets and prints a description
+// of what an execution would do, without contacting a cluster.
+func (o *options) Plan(out io.Writer) error {
+	buildSteps, promotionSteps, err := defaults.FromConfigOffline(context.Background(), o.configSpec, &o.graphConfig, o.jobSpec, o.templates, o.promote, o.targets.values,
+		o.cloneAuthConfig, o.pullSecret, o.pushSecret, o.censor, o.targetAdditionalSuffix, o.injectTest != "", o.enableSecretsStoreCSIDriver)
+	if err != nil {
+		return fmt.Errorf("failed to generate steps from config: %w", err)
+	}
+	nodes, err := api.BuildPartialGraph(buildSteps, o.targets.values)
+	if err != nil {
+		return fmt.Errorf("could not build execution graph: %w", err)
+	}
+	api.ResolveMultiArch(nodes)
+	stepList, errs := nodes.TopologicalSort()
+	if errs != nil {
+		return fmt.Errorf("could not sort nodes: %w", errors.Join(errs...))
+	}
+	usage, err := loadPodScalerUsage(o.planPodScalerData)
+	if err != nil {
+		return err
+	}
+	namespace := o.namespace
+	if namespace == "" {
+		namespace = "ci-op-{id}"
+	}
+	plan := planFor(o.configSpec, stepList, promotionSteps, usage)
+	plan.Targets = o.targets.values
+	plan.Namespace = namespace
+	return plan.print(out)
+}
+
+// podScalerUsage holds the usage data cached by the pod-scaler.
+type podScalerUsage struct {
+	cpu, memory, ephemeralStorage *podscaler.CachedQuery
+}
+
+func loadPodScalerUsage(dir string) (*podScalerUsage, error) {
+	if dir == "" {
+		return nil, nil
+	}
+	usage := &podScalerUsage{}
+	for file, into := range map[string]**podscaler.CachedQuery{
+		podscaler.CachedQueryFile(podscaler.MetricNameCPUUsage):         &usage.cpu,
+		podscaler.CachedQueryFile(podscaler.MetricNameMemoryWorkingSet): &usage.memory,
+		podscaler.CachedQueryFile(podscaler.MetricNameFSUsage):          &usage.ephemeralStorage,
+	} {
+		raw, err := os.ReadFile(filepath.Join(dir, file))
+		if err != nil {
+			return nil, fmt.Errorf("could not read pod-scaler data: %w", err)
+		}
+		var data podscaler.CachedQuery
+		if err := json.Unmarshal(raw, &data); err != nil {
+			return nil, fmt.Errorf("could not parse pod-scaler data in %s: %w", file, err)
+		}
+		*into = &data
+	}
+	return usage, nil
+}
+
+// usageFor determines the unweighted usage of the container at the default
+// quantiles of the pod-scaler, if it has recorded usage for it.
+func (u *podScalerUsage) usageFor(meta podscaler.FullMetadata) api.ResourceList {
+	if u == nil {
+		return nil
+	}
+	ret := api.ResourceList{}
+	if cores, ok := u.cpu.ValueAtQuantile(meta, podscaler.CPURequestQuantile); ok {
+		ret["cpu"] = resource.NewMilliQuantity(int64(cores*1000), resource.DecimalSI).String()
+	}
+	if bytes, ok := u.memory.ValueAtQuantile(meta, podscaler.MemoryRequestQuantile); ok {
+		ret["memory"] = resource.NewQuantity(int64(bytes), resource.BinarySI).String()
+	}
+	if bytes, ok := u.ephemeralStorage.ValueAtQuantile(meta, podscaler.EphemeralStorageRequestQuantile); ok {
+		ret["ephemeral-storage"] = resource.NewQuantity(int64(bytes), resource.BinarySI).String()
+	}
+	if len(ret) == 0 {
+		return nil
+	}
+	return ret
+}
+
+func planFor(config *api.ReleaseBuildConfiguration, stepList api.OrderedStepList, promotionSteps []api.Step, usage *podScalerUsage) *executionPlan {
+	tests := map[string]*api.TestStepConfiguration{}
+	for i := range config.Tests {
+		tests[config.Tests[i].As] = &config.Tests[i]
+	}
+	plan := &executionPlan{}
+	for _, node := range stepList {
+		step := node.Step
+		planned := plannedStep{Name: step.Name(), Description: step.Description()}
+		switch kind, _ := step.(steps.KindReporter); {
+		case steps.IsInputEnvironmentStep(step):
+			planned.Action = planActionReuse
+		case kind != nil && kind.Kind() == steps.StepKindBuild:
+			planned.Action = planActionBuild
+			planned.Pods = []plannedPod{podFor(config, usage, step.Name()+"-build", "docker", config.Resources.RequirementsForStep(step.Name()).Requests, podscaler.FullMetadata{})}
+		case kind != nil && kind.Kind() == steps.StepKindImport:
+			planned.Action = planActionImport
+		default:
+			planned.Action = planActionRun
+		}
+		if test, ok := tests[step.Name()]; ok {
+			describeTest(config, test, &planned, usage)
+		}
+		plan.Steps = append(plan.Steps, planned)
+	}
+	for _, step := range promotionSteps {
+		plan.Promotion = append(plan.Promotion, step.Name())
+	}
+	return plan
+}
+
+// describeTest records the resources a test would consume.
+func describeTest(config *api.ReleaseBuildConfiguration, test *api.TestStepConfiguration, planned *plannedStep, usage *podScalerUsage) {
+	planned.ClusterClaim = test.ClusterClaim
+	if literal := test.MultiStageTestConfigurationLiteral; literal != nil {
+		planned.Leases = api.LeasesForTest(literal)
+		if lease := api.IPPoolLeaseForTest(literal, config.Metadata); lease.ResourceType != "" {
+			planned.Leases = append(planned.Leases, lease)
+		}
+		planned.ClusterProfile = literal.ClusterProfile
+		for _, phase := range [][]api.LiteralTestStep{literal.Pre, literal.Test, literal.Post} {
+			for _, step := range phase {
+				for _, credential := range step.Credentials {
+					planned.Secrets = append(planned.Secrets, fmt.Sprintf("%s/%s at %s (%s)", credential.Namespace, credential.Name, credential.MountPath, step.As))
+				}
+				planned.Pods = append(planned.Pods, podFor(config, usage, fmt.Sprintf("%s-%s", test.As, step.As), "test", step.Resources.Requests, podscaler.FullMetadata{Target: test.As, Step: step.As}))
+			}
+		}
+		return
+	}
+	secrets := test.Secrets
+	if test.Secret != nil {
+		secrets = append(secrets, test.Secret)
+	}
+	for _, secret := range secrets {
+		planned.Secrets = append(planned.Secrets, fmt.Sprintf("%s at %s", secret.Name, secret.MountPath))
+	}
+	if test.ContainerTestConfiguration != nil {
+		planned.Pods = append(planned.Pods, podFor(config, usage, test.As, "test", config.Resources.RequirementsForStep(test.As).Requests, podscaler.FullMetadata{Target: test.As}))
+	}
+}
+
+func podFor(config *api.ReleaseBuildConfiguration, usage *podScalerUsage, name, container string, requests api.ResourceList, meta podscaler.FullMetadata) plannedPod {
+	meta.Metadata = config.Metadata
+	meta.Pod = name
+	meta.Container = container
+	return plannedPod{
+		Name:      name,
+		Container: container,
+		Requests:  requests,
+		Usage:     usage.usageFor(meta),
+	}
+}
+
+func (p *executionPlan) print(out io.Writer) error {
+	var lines []string
+	add := func(format string, args ...interface{}) {
+		lines = append(lines, fmt.Sprintf(format, args...))
+	}
+	targets := "all"
+	if len(p.Targets) > 0 {
+		targets = strings.Join(p.Targets, ", ")
+	}
+	add("Execution plan for %s in namespace %s", targets, p.Namespace)
+	add("")
+
+	var built, imported, reused []string
+	for _, step := range p.Steps {
+		switch step.Action {
+		case planActionBuild:
+			built = append(built, step.Name)
+		case planActionImport:
+			imported = append(imported, step.Name)
+		case planActionReuse:
+			reused = append(reused, step.Name)
+		}
+	}
+	add("Images:")
+	for _, images := range []struct {
+		what  string
+		names []string
+	}{
+		{what: "built", names: built},
+		{what: "imported", names: imported},
+		{what: "provided by the environment", names: reused},
+	} {
+		if len(images.names) == 0 {
+			continue
+		}
+		add("  %s: %s", images.what, strings.Join(images.names, ", "))
+	}
+	add("")
+
+	add("Steps:")
+	for i, step := range p.Steps {
+		add("  %d. %s [%s]: %s", i+1, step.Name, step.Action, step.Description)
+		for _, lease := range step.Leases {
+			count := lease.Count
+			if count == 0 {
+				count = 1
+			}
+			add("     lease: %d %s (exposed as $%s)", count, lease.ResourceType, lease.Env)
+		}
+		if claim := step.ClusterClaim; claim != nil {
+			add("     cluster claim: %s %s on %s owned by %s", claim.Product, claim.Version, claim.Cloud, claim.Owner)
+		}
+		if step.ClusterProfile != "" {
+			add("     cluster profile: %s", step.ClusterProfile)
+		}
+		for _, secret := range step.Secrets {
+			add("     secret: %s", secret)
+		}
+		for _, pod := range step.Pods {
+			line := fmt.Sprintf("     pod %s[%s]: requests %s", pod.Name, pod.Container, describeResources(pod.Requests))
+			if pod.Usage != nil {
+				line += fmt.Sprintf(", unweighted usage at pod-scaler quantiles %s", describeResources(pod.Usage))
+			}
+			lines = append(lines, line)
+		}
+	}
+	if len(p.Promotion) > 0 {
+		add("")
+		add("Promotion: %s", strings.Join(p.Promotion, ", "))
+	}
+	_, err := fmt.Fprintln(out, strings.Join(lines, "\n"))
+	return err
+}
+
+func describeResources(resources api.ResourceList) string {
+	if len(resources) == 0 {
+		return "none"
+	}
+	var ret []string
+	for name, value := range resources {
+		ret = append(ret, fmt.Sprintf("%s=%s", name, value))
+	}
+	sort.Strings(ret)
+	return strings.Join(ret, " ")
+}
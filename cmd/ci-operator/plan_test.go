@@ -0,0 +1,149 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/openhistogram/circonusllhist"
+	"github.com/prometheus/common/model"
+
+	prowapi "sigs.k8s.io/prow/pkg/apis/prowjobs/v1"
+
+	"github.com/openshift/ci-tools/pkg/api"
+	"github.com/openshift/ci-tools/pkg/defaults"
+	podscaler "github.com/openshift/ci-tools/pkg/pod-scaler"
+	"github.com/openshift/ci-tools/pkg/secrets"
+)
+
+func TestPlan(t *testing.T) {
+	config := &api.ReleaseBuildConfiguration{
+		Metadata: api.Metadata{Org: "org", Repo: "repo", Branch: "main"},
+		InputConfiguration: api.InputConfiguration{
+			BuildRootImage: &api.BuildRootImageConfiguration{
+				ImageStreamTagReference: &api.ImageStreamTagReference{Namespace: "ci", Name: "golang", Tag: "1.23"},
+			},
+		},
+		BinaryBuildCommands: "make",
+		Resources: api.ResourceConfiguration{
+			"*":    {Requests: api.ResourceList{"cpu": "100m"}},
+			"unit": {Requests: api.ResourceList{"cpu": "2", "memory": "1Gi"}},
+		},
+		Tests: []api.TestStepConfiguration{
+			{
+				As:                         "unit",
+				Commands:                   "make test",
+				ContainerTestConfiguration: &api.ContainerTestConfiguration{From: api.PipelineImageStreamTagReferenceBinaries},
+				Secrets:                    []*api.Secret{{Name: "token", MountPath: "/token"}},
+			},
+			{
+				As: "e2e",
+				MultiStageTestConfigurationLiteral: &api.MultiStageTestConfigurationLiteral{
+					Test: []api.LiteralTestStep{{
+						As:          "run",
+						From:        "src",
+						Commands:    "make e2e",
+						Resources:   api.ResourceRequirements{Requests: api.ResourceList{"cpu": "1"}},
+						Leases:      []api.StepLease{{ResourceType: "quota-slice", Env: "LEASED", Count: 2}},
+						Credentials: []api.CredentialReference{{Namespace: "test-credentials", Name: "creds", MountPath: "/creds"}},
+					}},
+				},
+			},
+		},
+	}
+	e2eRun := podscaler.FullMetadata{Metadata: config.Metadata, Target: "e2e", Step: "run", Pod: "e2e-run", Container: "test"}
+	jobSpec := &api.JobSpec{}
+	jobSpec.Refs = &prowapi.Refs{Org: "org", Repo: "repo", BaseRef: "main", BaseSHA: "sha"}
+
+	var testCases = []struct {
+		name     string
+		targets  []string
+		usage    *podScalerUsage
+		expected string
+	}{
+		{
+			name:    "container test",
+			targets: []string{"unit"},
+			expected: `Execution plan for unit in namespace ci-op-{id}
+
+Images:
+  built: src, bin
+  imported: [input:root]
+
+Steps:
+  1. [input:root] [import]: Find the input image root and tag it into the pipeline
+  2. src [build]: Clone the correct source code into an image and tag it as src
+     pod src-build[docker]: requests cpu=100m
+  3. bin [build]: Store build results into a layer on top of src and save as bin
+     pod bin-build[docker]: requests cpu=100m
+  4. unit [run]: Run test unit
+     secret: token at /token
+     pod unit[test]: requests cpu=2 memory=1Gi
+`,
+		},
+		{
+			name:    "multi-stage test with pod-scaler data",
+			targets: []string{"e2e"},
+			usage: &podScalerUsage{
+				cpu:              usageFor(t, e2eRun, 1.5),
+				memory:           usageFor(t, e2eRun, 2*1024*1024*1024),
+				ephemeralStorage: usageFor(t, e2eRun, 10*1024*1024*1024),
+			},
+			expected: `Execution plan for e2e in namespace ci-op-{id}
+
+Images:
+  built: src
+  imported: [input:root]
+
+Steps:
+  1. [input:root] [import]: Find the input image root and tag it into the pipeline
+  2. src [build]: Clone the correct source code into an image and tag it as src
+     pod src-build[docker]: requests cpu=100m
+  3. e2e [run]: Run multi-stage test e2e
+     lease: 2 quota-slice (exposed as $LEASED)
+     secret: test-credentials/creds at /creds (run)
+     pod e2e-run[test]: requests cpu=1, unweighted usage at pod-scaler quantiles cpu=1580m ephemeral-storage=10546875Ki memory=2180000000
+`,
+		},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			graphConfig := defaults.FromConfigStatic(config)
+			censor := secrets.NewDynamicCensor()
+			buildSteps, promotionSteps, err := defaults.FromConfigOffline(context.Background(), config, &graphConfig, jobSpec, nil, false, testCase.targets, nil, nil, nil, &censor, "", false, false)
+			if err != nil {
+				t.Fatalf("failed to generate steps: %v", err)
+			}
+			nodes, err := api.BuildPartialGraph(buildSteps, testCase.targets)
+			if err != nil {
+				t.Fatalf("failed to build graph: %v", err)
+			}
+			stepList, errs := nodes.TopologicalSort()
+			if errs != nil {
+				t.Fatalf("failed to sort graph: %v", errs)
+			}
+			plan := planFor(config, stepList, promotionSteps, testCase.usage)
+			plan.Targets = testCase.targets
+			plan.Namespace = "ci-op-{id}"
+			var out bytes.Buffer
+			if err := plan.print(&out); err != nil {
+				t.Fatalf("failed to print plan: %v", err)
+			}
+			if diff := cmp.Diff(testCase.expected, out.String()); diff != "" {
+				t.Errorf("incorrect plan: %s", diff)
+			}
+		})
+	}
+}
+
+func usageFor(t *testing.T, meta podscaler.FullMetadata, value float64) *podscaler.CachedQuery {
+	histogram := circonusllhist.New()
+	if err := histogram.RecordValue(value); err != nil {
+		t.Fatalf("failed to record value: %v", err)
+	}
+	return &podscaler.CachedQuery{
+		Data:           map[model.Fingerprint]*circonusllhist.HistogramWithoutLookups{1: circonusllhist.NewHistogramWithoutLookups(histogram)},
+		DataByMetaData: map[podscaler.FullMetadata][]podscaler.FingerprintTime{meta: {{Fingerprint: 1}}},
+	}
+}
@@ -82,6 +82,7 @@ type options struct {
 	rwKubeconfig     bool
 	uploadKubeconfig bool
 	updateSharedDir  bool
+	sharedDirSecret  string
 	cmd              []string
 	client           coreclientset.SecretInterface
 }
@@ -91,6 +92,7 @@ func bindOptions(flag *flag.FlagSet) *options {
 	flag.BoolVar(&opt.dry, "dry-run", false, "Print the secret instead of creating it")
 	flag.StringVar(&opt.waitPath, "wait-for-file", "", "Wait for a file to appear at this path before starting the program")
 	flag.StringVar(&opt.waitTimeoutStr, "wait-timeout", "", "Used with --wait-for-file, maximum wait time before starting the program")
+	flag.StringVar(&opt.sharedDirSecret, "shared-dir-secret", "", "Name of the secret to update with the contents of the shared directory, defaults to $JOB_NAME_SAFE")
 	flag.StringVar(&opt.mode, "mode", manageKubeconfigMode, fmt.Sprintf("Set how kubeconfig should be managed. Allowed values are: %s, %s or %s", manageKubeconfigMode, skipKubeconfigMode, observerMode))
 	return opt
 }
@@ -118,8 +120,10 @@ func (o *options) complete() error {
 	if ns = os.Getenv("NAMESPACE"); ns == "" {
 		return fmt.Errorf("environment variable NAMESPACE is empty")
 	}
-	if o.name = os.Getenv("JOB_NAME_SAFE"); o.name == "" {
-		return fmt.Errorf("environment variable JOB_NAME_SAFE is empty")
+	if o.name = o.sharedDirSecret; o.name == "" {
+		if o.name = os.Getenv("JOB_NAME_SAFE"); o.name == "" {
+			return fmt.Errorf("environment variable JOB_NAME_SAFE is empty")
+		}
 	}
 
 	if err := o.validateMode(); err != nil {
@@ -9,6 +9,7 @@ import (
 	"sort"
 	"strconv"
 
+	"github.com/prometheus/client_golang/prometheus"
 	"github.com/sirupsen/logrus"
 
 	corev1 "k8s.io/api/core/v1"
@@ -33,18 +34,21 @@ import (
 	"github.com/openshift/ci-tools/pkg/steps"
 )
 
-func admit(port, healthPort int, certDir string, client buildclientv1.BuildV1Interface, loaders map[string][]*cacheReloader, mutateResourceLimits bool, cpuCap int64, memoryCap string, cpuPriorityScheduling int64, reporter results.PodScalerReporter) {
+func admit(port, healthPort int, certDir string, client buildclientv1.BuildV1Interface, loaders map[string][]*cacheReloader, mutateResourceLimits bool, cpuCap int64, memoryCap, ephemeralStorageCap string, cpuPriorityScheduling int64, weighting podscaler.Weighting, policies *policyReloader, shadow bool, reporter results.PodScalerReporter) {
 	logger := logrus.WithField("component", "pod-scaler admission")
 	logger.Infof("Initializing admission webhook server with %d loaders.", len(loaders))
 	health := pjutil.NewHealthOnPort(healthPort)
-	resources := newResourceServer(loaders, health)
+	resources := newResourceServer(loaders, health, weighting, policies)
 	decoder := admission.NewDecoder(scheme.Scheme)
 
 	server := webhook.NewServer(webhook.Options{
 		Port:    port,
 		CertDir: certDir,
 	})
-	server.Register("/pods", &webhook.Admission{Handler: &podMutator{logger: logger, client: client, decoder: decoder, resources: resources, mutateResourceLimits: mutateResourceLimits, cpuCap: cpuCap, memoryCap: memoryCap, cpuPriorityScheduling: cpuPriorityScheduling, reporter: reporter}})
+	server.Register("/pods", &webhook.Admission{Handler: &podMutator{logger: logger, client: client, decoder: decoder, resources: resources, policies: policies, mutateResourceLimits: mutateResourceLimits, cpuCap: cpuCap, memoryCap: memoryCap, ephemeralStorageCap: ephemeralStorageCap, cpuPriorityScheduling: cpuPriorityScheduling, shadow: shadow, reporter: reporter}})
+	if shadow {
+		logger.Info("Running in shadow mode, Pods will not be mutated.")
+	}
 	logger.Info("Serving admission webhooks.")
 	if err := server.Start(interrupts.Context()); err != nil {
 		logrus.WithError(err).Fatal("Failed to serve webhooks.")
@@ -55,12 +59,16 @@ type podMutator struct {
 	logger                *logrus.Entry
 	client                buildclientv1.BuildV1Interface
 	resources             *resourceServer
+	policies              *policyReloader
 	mutateResourceLimits  bool
 	decoder               admission.Decoder
 	cpuCap                int64
 	memoryCap             string
+	ephemeralStorageCap   string
 	cpuPriorityScheduling int64
-	reporter              results.PodScalerReporter
+	// shadow determines that mutations are only recorded, not applied
+	shadow   bool
+	reporter results.PodScalerReporter
 }
 
 func (m *podMutator) Handle(ctx context.Context, req admission.Request) admission.Response {
@@ -82,6 +90,7 @@ func (m *podMutator) Handle(ctx context.Context, req admission.Request) admissio
 	if !scalePod {
 		return admission.Allowed("ignoring pod due to presence of annotation")
 	}
+	original := pod.DeepCopy()
 
 	if isBuildPod {
 		logger = logger.WithField("build", buildName)
@@ -97,9 +106,14 @@ func (m *podMutator) Handle(ctx context.Context, req admission.Request) admissio
 		logger.WithError(err).Error("Failed to handle rehearsal Pod.")
 		return admission.Allowed("Failed to handle rehearsal Pod, ignoring.")
 	}
-	mutatePodResources(pod, m.resources, m.mutateResourceLimits, m.cpuCap, m.memoryCap, m.reporter, logger)
+	mutatePodResources(pod, m.resources, m.policies, m.mutateResourceLimits, requestCaps(m.cpuCap, m.memoryCap, m.ephemeralStorageCap), m.reporter, logger)
 	m.addPriorityClass(pod)
 
+	if m.shadow {
+		recordShadowMutations(original, pod, m.reporter)
+		return admission.Allowed("running in shadow mode, not mutating Pod")
+	}
+
 	marshaledPod, err := json.Marshal(pod)
 	if err != nil {
 		logger.WithError(err).Error("Could not marshal mutated Pod.")
@@ -213,7 +227,7 @@ func useOursIfLarger(allOfOurs, allOfTheirs *corev1.ResourceRequirements, worklo
 		{ours: &allOfOurs.Requests, theirs: &allOfTheirs.Requests, resource: "request"},
 		{ours: &allOfOurs.Limits, theirs: &allOfTheirs.Limits, resource: "limit"},
 	} {
-		for _, field := range []corev1.ResourceName{corev1.ResourceCPU, corev1.ResourceMemory} {
+		for _, field := range []corev1.ResourceName{corev1.ResourceCPU, corev1.ResourceMemory, corev1.ResourceEphemeralStorage} {
 			our := (*pair.ours)[field]
 			//TODO(sgoeddel): this is a temporary experiment to see what effect setting values that are 120% of what has
 			// been determined has on the rate of OOMKilled and similar termination of workloads
@@ -246,67 +260,132 @@ func useOursIfLarger(allOfOurs, allOfTheirs *corev1.ResourceRequirements, worklo
 }
 
 // reconcileLimits ensures that container resource limits do not set anything for CPU (as we
-// are fairly certain this is never a useful thing to do) and that any limits that have been configured
-// are >=200% of requests (which they may not be any longer if we've changed requests)
+// are fairly certain this is never a useful thing to do) and that any memory or ephemeral storage
+// limits that have been configured are >=200% of requests (which they may not be any longer if
+// we've changed requests)
 func reconcileLimits(resources *corev1.ResourceRequirements) {
 	if resources.Limits == nil {
 		return
 	}
 	delete(resources.Limits, corev1.ResourceCPU)
-	if !resources.Limits.Memory().IsZero() { // Never set a limit where there isn't one defined
+	for _, field := range []corev1.ResourceName{corev1.ResourceMemory, corev1.ResourceEphemeralStorage} {
+		currentLimit, limited := resources.Limits[field]
+		if !limited || currentLimit.IsZero() { // Never set a limit where there isn't one defined
+			continue
+		}
 		// Note: doing math on Quantities is not easy, since they may contain values that overflow
 		// normal integers. Doing math on inf.Dec is possible, but there does not exist any way to
 		// convert back from an inf.Dec to a resource.Quantity. So, while we would want to have a
 		// limit threshold like 120% or similar, we use 200% as that's what is trivially easy to
 		// accomplish with the math we can do on resource.Quantity.
-		minimumLimit := resources.Requests[corev1.ResourceMemory]
+		minimumLimit := resources.Requests[field]
 		minimumLimit.Add(minimumLimit)
-		currentLimit := resources.Limits[corev1.ResourceMemory]
 		if currentLimit.Cmp(minimumLimit) == -1 {
-			resources.Limits[corev1.ResourceMemory] = minimumLimit
+			resources.Limits[field] = minimumLimit
+		}
+	}
+}
+
+// requestCaps determines the largest requests we set from the configured caps.
+func requestCaps(cpuCap int64, memoryCap, ephemeralStorageCap string) corev1.ResourceList {
+	// TODO(DPTP-2525): Make cluster-specific?
+	return corev1.ResourceList{
+		corev1.ResourceCPU:              *resource.NewQuantity(cpuCap, resource.DecimalSI),
+		corev1.ResourceMemory:           resource.MustParse(memoryCap),
+		corev1.ResourceEphemeralStorage: resource.MustParse(ephemeralStorageCap),
+	}
+}
+
+// capsFor overrides the caps with those set by the policy.
+func capsFor(caps corev1.ResourceList, policy podscaler.AppliedPolicy) corev1.ResourceList {
+	overridden := caps.DeepCopy()
+	for _, field := range []corev1.ResourceName{corev1.ResourceCPU, corev1.ResourceMemory, corev1.ResourceEphemeralStorage} {
+		if resourcePolicy := policy.Resource(field); resourcePolicy.Cap != nil {
+			overridden[field] = *resourcePolicy.Cap
 		}
 	}
+	return overridden
 }
 
-func preventUnschedulable(resources *corev1.ResourceRequirements, cpuCap int64, memoryCap string, logger *logrus.Entry) {
+func preventUnschedulable(resources *corev1.ResourceRequirements, caps corev1.ResourceList, logger *logrus.Entry) {
 	if resources.Requests == nil {
 		logger.Debug("no requests, skipping")
 		return
 	}
 
-	if _, ok := resources.Requests[corev1.ResourceCPU]; ok {
-		// TODO(DPTP-2525): Make cluster-specific?
-		cpuRequestCap := *resource.NewQuantity(cpuCap, resource.DecimalSI)
-		if resources.Requests.Cpu().Cmp(cpuRequestCap) == 1 {
-			logger.Debugf("setting original CPU request of: %s to cap", resources.Requests.Cpu())
-			resources.Requests[corev1.ResourceCPU] = cpuRequestCap
+	for _, field := range []corev1.ResourceName{corev1.ResourceCPU, corev1.ResourceMemory, corev1.ResourceEphemeralStorage} {
+		request, requested := resources.Requests[field]
+		requestCap, capped := caps[field]
+		if !requested || !capped {
+			continue
+		}
+		if request.Cmp(requestCap) == 1 {
+			logger.Debugf("setting original %s request of: %s to cap", field, request.String())
+			resources.Requests[field] = requestCap
+		}
+	}
+}
+
+// applyFloors raises requests to the floors set by the policy. Limits must never
+// be lower than requests, so any limit that is lower than a raised request is
+// raised along with it.
+func applyFloors(resources *corev1.ResourceRequirements, policy podscaler.AppliedPolicy, logger *logrus.Entry) {
+	for _, field := range []corev1.ResourceName{corev1.ResourceCPU, corev1.ResourceMemory, corev1.ResourceEphemeralStorage} {
+		floor := policy.Resource(field).Floor
+		if floor == nil {
+			continue
+		}
+		if request := resources.Requests[field]; request.Cmp(*floor) >= 0 {
+			continue
+		}
+		if resources.Requests == nil {
+			resources.Requests = corev1.ResourceList{}
+		}
+		logger.Debugf("setting original %s request of: %s to floor", field, resources.Requests.Name(field, resource.DecimalSI))
+		resources.Requests[field] = *floor
+		if limit, limited := resources.Limits[field]; limited && limit.Cmp(*floor) == -1 {
+			resources.Limits[field] = *floor
 		}
 	}
+}
 
-	if _, ok := resources.Requests[corev1.ResourceMemory]; ok {
-		memoryRequestCap := resource.MustParse(memoryCap)
-		if resources.Requests.Memory().Cmp(memoryRequestCap) == 1 {
-			logger.Debugf("setting original memory request of: %s to cap", resources.Requests.Memory())
-			resources.Requests[corev1.ResourceMemory] = memoryRequestCap
+// handleLimits handles the limits of a container as the policy determines,
+// falling back to reconciling them when we determined requests and limits
+// are to be mutated.
+func handleLimits(resources *corev1.ResourceRequirements, policy podscaler.AppliedPolicy, mutateResourceLimits, recommendationExists bool) {
+	switch policy.Limits {
+	case podscaler.LimitBehaviorReconcile:
+		reconcileLimits(resources)
+	case podscaler.LimitBehaviorKeep:
+	case podscaler.LimitBehaviorRemove:
+		resources.Limits = nil
+	default:
+		if mutateResourceLimits && recommendationExists {
+			reconcileLimits(resources)
 		}
 	}
 }
 
-func mutatePodResources(pod *corev1.Pod, server *resourceServer, mutateResourceLimits bool, cpuCap int64, memoryCap string, reporter results.PodScalerReporter, logger *logrus.Entry) {
+func mutatePodResources(pod *corev1.Pod, server *resourceServer, policies *policyReloader, mutateResourceLimits bool, caps corev1.ResourceList, reporter results.PodScalerReporter, logger *logrus.Entry) {
 	mutateResources := func(containers []corev1.Container) {
 		for i := range containers {
 			meta := podscaler.MetadataFor(pod.ObjectMeta.Labels, pod.ObjectMeta.Name, containers[i].Name)
+			policy := policies.For(meta)
+			containerLogger := logger
+			if len(policy.Policies) > 0 {
+				containerLogger = logger.WithField("policies", policy.Policies)
+				containerLogger.Debugf("policies apply to: %s", containers[i].Name)
+			}
 			resources, recommendationExists := server.recommendedRequestFor(meta)
 			if recommendationExists {
-				logger.Debugf("recommendation exists for: %s", containers[i].Name)
+				containerLogger.Debugf("recommendation exists for: %s", containers[i].Name)
 				workloadType := determineWorkloadType(pod.Annotations, pod.Labels)
 				workloadName := determineWorkloadName(pod.Name, containers[i].Name, workloadType, pod.Labels)
-				useOursIfLarger(&resources, &containers[i].Resources, workloadName, workloadType, reporter, logger)
-				if mutateResourceLimits {
-					reconcileLimits(&containers[i].Resources)
-				}
+				useOursIfLarger(&resources, &containers[i].Resources, workloadName, workloadType, reporter, containerLogger)
 			}
-			preventUnschedulable(&containers[i].Resources, cpuCap, memoryCap, logger)
+			applyFloors(&containers[i].Resources, policy, containerLogger)
+			handleLimits(&containers[i].Resources, policy, mutateResourceLimits, recommendationExists)
+			preventUnschedulable(&containers[i].Resources, capsFor(caps, policy), containerLogger)
 		}
 	}
 	mutateResources(pod.Spec.InitContainers)
@@ -342,6 +421,76 @@ func determineWorkloadName(podName, containerName, workloadType string, labels m
 	return fmt.Sprintf("%s-%s", podName, containerName)
 }
 
+var shadowMutations = prometheus.NewCounterVec(
+	prometheus.CounterOpts{
+		Name: "pod_scaler_admission_shadow_mutations_total",
+		Help: "number of resource changes pod-scaler would have made in shadow mode, by workload type, resource, requirement and direction",
+	},
+	[]string{"workload_type", "resource_type", "requirement", "direction"},
+)
+
+func init() {
+	prometheus.MustRegister(shadowMutations)
+}
+
+// recordShadowMutations records the differences between the resources of the
+// containers in the original and the mutated Pod, so that the effects of the
+// admission webhook can be observed without applying them. The differences are
+// counted in-process and reported for the whole Pod at once, asynchronously.
+func recordShadowMutations(original, mutated *corev1.Pod, reporter results.PodScalerReporter) {
+	var mutations []results.PodScalerShadowRequest
+	workloadType := determineWorkloadType(mutated.Annotations, mutated.Labels)
+	record := func(before, after []corev1.Container) {
+		for i := range after {
+			workloadName := determineWorkloadName(mutated.Name, after[i].Name, workloadType, mutated.Labels)
+			for _, requirement := range []struct {
+				name          string
+				before, after corev1.ResourceList
+			}{
+				{name: "request", before: before[i].Resources.Requests, after: after[i].Resources.Requests},
+				{name: "limit", before: before[i].Resources.Limits, after: after[i].Resources.Limits},
+			} {
+				for _, field := range []corev1.ResourceName{corev1.ResourceCPU, corev1.ResourceMemory, corev1.ResourceEphemeralStorage} {
+					configured, wasConfigured := requirement.before[field]
+					determined, isDetermined := requirement.after[field]
+					var direction, configuredAmount, determinedAmount string
+					switch {
+					case !wasConfigured && !isDetermined:
+						continue
+					case !wasConfigured:
+						direction, determinedAmount = "added", determined.String()
+					case !isDetermined:
+						direction, configuredAmount = "removed", configured.String()
+					default:
+						switch configured.Cmp(determined) {
+						case 0:
+							continue
+						case -1:
+							direction = "increased"
+						case 1:
+							direction = "decreased"
+						}
+						configuredAmount, determinedAmount = configured.String(), determined.String()
+					}
+					shadowMutations.WithLabelValues(workloadType, field.String(), requirement.name, direction).Inc()
+					mutations = append(mutations, results.PodScalerShadowRequest{
+						WorkloadName:     workloadName,
+						WorkloadType:     workloadType,
+						ResourceType:     field.String(),
+						Requirement:      requirement.name,
+						Direction:        direction,
+						ConfiguredAmount: configuredAmount,
+						DeterminedAmount: determinedAmount,
+					})
+				}
+			}
+		}
+	}
+	record(original.Spec.InitContainers, mutated.Spec.InitContainers)
+	record(original.Spec.Containers, mutated.Spec.Containers)
+	reporter.ReportShadowMutations(mutations)
+}
+
 const priorityClassName = "high-priority-nonpreempting"
 
 func (m *podMutator) addPriorityClass(pod *corev1.Pod) {
@@ -26,19 +26,25 @@ import (
 	"github.com/openshift/ci-tools/pkg/api"
 	podscaler "github.com/openshift/ci-tools/pkg/pod-scaler"
 	"github.com/openshift/ci-tools/pkg/rehearse"
+	"github.com/openshift/ci-tools/pkg/results"
 	"github.com/openshift/ci-tools/pkg/steps"
 	"github.com/openshift/ci-tools/pkg/testhelper"
 )
 
 type mockReporter struct {
-	client *http.Client
-	called bool
+	client          *http.Client
+	called          bool
+	shadowMutations []results.PodScalerShadowRequest
 }
 
 func (r *mockReporter) ReportResourceConfigurationWarning(string, string, string, string, string) {
 	r.called = true
 }
 
+func (r *mockReporter) ReportShadowMutations(mutations []results.PodScalerShadowRequest) {
+	r.shadowMutations = append(r.shadowMutations, mutations...)
+}
+
 var defaultReporter = mockReporter{client: &http.Client{}}
 
 func TestMutatePods(t *testing.T) {
@@ -104,6 +110,7 @@ func TestMutatePods(t *testing.T) {
 		decoder:               decoder,
 		cpuCap:                10,
 		memoryCap:             "20Gi",
+		ephemeralStorageCap:   "100Gi",
 		cpuPriorityScheduling: 8,
 		reporter:              &defaultReporter,
 	}
@@ -177,6 +184,83 @@ func TestMutatePods(t *testing.T) {
 	}
 }
 
+func TestMutatePods_Shadow(t *testing.T) {
+	client := fakebuildv1client.NewSimpleClientset(
+		&buildv1.Build{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: "namespace",
+				Name:      "withlabels",
+				Labels: map[string]string{
+					"ci.openshift.io/metadata.org":     "org",
+					"ci.openshift.io/metadata.repo":    "repo",
+					"ci.openshift.io/metadata.branch":  "branch",
+					"ci.openshift.io/metadata.variant": "variant",
+					"ci.openshift.io/metadata.target":  "target",
+				},
+			},
+		},
+	)
+	logger := logrus.WithField("test", t.Name())
+	resources := &resourceServer{
+		logger: logger,
+		lock:   sync.RWMutex{},
+		byMetaData: map[podscaler.FullMetadata]corev1.ResourceRequirements{
+			{
+				Metadata: api.Metadata{
+					Org:     "org",
+					Repo:    "repo",
+					Branch:  "branch",
+					Variant: "variant",
+				},
+				Pod:       "withlabels-build",
+				Container: "test",
+			}: {
+				Requests: corev1.ResourceList{
+					corev1.ResourceCPU:    *resource.NewQuantity(2, resource.DecimalSI),
+					corev1.ResourceMemory: *resource.NewQuantity(2e4, resource.BinarySI),
+				},
+			},
+		},
+	}
+	reporter := &mockReporter{client: &http.Client{}}
+	mutator := podMutator{
+		logger:                logger,
+		client:                client.BuildV1(),
+		resources:             resources,
+		mutateResourceLimits:  true,
+		decoder:               admission.NewDecoder(scheme.Scheme),
+		cpuCap:                10,
+		memoryCap:             "20Gi",
+		ephemeralStorageCap:   "100Gi",
+		cpuPriorityScheduling: 8,
+		shadow:                true,
+		reporter:              reporter,
+	}
+	request := admission.Request{
+		AdmissionRequest: admissionv1.AdmissionRequest{
+			UID:      "705ab4f5-6393-11e8-b7cc-42010a800002",
+			Kind:     metav1.GroupVersionKind{Group: "", Version: "v1", Kind: "Pod"},
+			Resource: metav1.GroupVersionResource{Group: "", Version: "v1", Resource: "pods"},
+			Object:   runtime.RawExtension{Raw: []byte(`{"apiVersion": "v1","kind": "Pod","metadata": {"creationTimestamp": null, "labels": {"openshift.io/build.name": "withlabels"}, "annotations": {"openshift.io/build.name": "withlabels"}, "name": "withlabels-build","namespace": "namespace"}, "spec":{"containers":[{"name":"test","resources":{"requests":{"cpu":"4","memory":"100"},"limits":{"memory":"200"}}},{"name":"other"}]}, "status":{}}`)},
+		},
+	}
+
+	response := mutator.Handle(context.Background(), request)
+	if !response.Allowed {
+		t.Errorf("expected Pod to be allowed, got %v", response.Result)
+	}
+	if len(response.Patches) != 0 {
+		t.Errorf("expected no patches in shadow mode, got %v", response.Patches)
+	}
+	expected := []results.PodScalerShadowRequest{
+		{WorkloadName: "withlabels-build-test", WorkloadType: "build", ResourceType: "memory", Requirement: "request", Direction: "increased", ConfiguredAmount: "100", DeterminedAmount: "24000"},
+		{WorkloadName: "withlabels-build-test", WorkloadType: "build", ResourceType: "memory", Requirement: "limit", Direction: "increased", ConfiguredAmount: "200", DeterminedAmount: "48000"},
+	}
+	if diff := cmp.Diff(expected, reporter.shadowMutations); diff != "" {
+		t.Errorf("unexpected shadow mutations reported: %v", diff)
+	}
+}
+
 func TestMutatePodMetadata(t *testing.T) {
 	var testCases = []struct {
 		name          string
@@ -554,7 +638,7 @@ func TestMutatePodResources(t *testing.T) {
 	for _, testCase := range testCases {
 		t.Run(testCase.name, func(t *testing.T) {
 			original := testCase.pod.DeepCopy()
-			mutatePodResources(testCase.pod, testCase.server, testCase.mutateResourceLimits, 10, "20Gi", &defaultReporter, logrus.WithField("test", testCase.name))
+			mutatePodResources(testCase.pod, testCase.server, nil, testCase.mutateResourceLimits, requestCaps(10, "20Gi", "100Gi"), &defaultReporter, logrus.WithField("test", testCase.name))
 			diff := cmp.Diff(original, testCase.pod)
 			// In some cases, cmp.Diff decides to use non-breaking spaces, and it's not
 			// particularly deterministic about this. We don't care.
@@ -569,6 +653,102 @@ func TestMutatePodResources(t *testing.T) {
 	}
 }
 
+func TestMutatePodResources_Policies(t *testing.T) {
+	quantity := func(q string) *resource.Quantity {
+		parsed := resource.MustParse(q)
+		return &parsed
+	}
+	labels := map[string]string{
+		"ci.openshift.io/metadata.org":    "org",
+		"ci.openshift.io/metadata.repo":   "repo",
+		"ci.openshift.io/metadata.branch": "branch",
+	}
+	server := &resourceServer{
+		logger: logrus.WithField("test", t.Name()),
+		byMetaData: map[podscaler.FullMetadata]corev1.ResourceRequirements{
+			podscaler.MetadataFor(labels, "pod", "test"): {
+				Requests: corev1.ResourceList{
+					corev1.ResourceCPU:    *resource.NewQuantity(15, resource.DecimalSI),
+					corev1.ResourceMemory: *resource.NewQuantity(1e9, resource.BinarySI),
+				},
+			},
+		},
+	}
+	policies := &policyReloader{policies: &podscaler.Policies{Policies: []podscaler.Policy{
+		{
+			Name:   "org",
+			Match:  podscaler.PolicyMatch{Org: "org"},
+			CPU:    &podscaler.ResourcePolicy{Cap: quantity("20")},
+			Memory: &podscaler.ResourcePolicy{Floor: quantity("1Gi")},
+			Limits: podscaler.LimitBehaviorRemove,
+		},
+		{
+			Name:   "container",
+			Match:  podscaler.PolicyMatch{Org: "org", Repo: "repo", Container: "other"},
+			CPU:    &podscaler.ResourcePolicy{Floor: quantity("500m")},
+			Limits: podscaler.LimitBehaviorKeep,
+		},
+	}}}
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "pod", Labels: labels},
+		Spec: corev1.PodSpec{
+			Containers: []corev1.Container{
+				{
+					Name: "test",
+					Resources: corev1.ResourceRequirements{
+						Requests: corev1.ResourceList{
+							corev1.ResourceCPU:    *resource.NewQuantity(1, resource.DecimalSI),
+							corev1.ResourceMemory: resource.MustParse("100Mi"),
+						},
+						Limits: corev1.ResourceList{
+							corev1.ResourceCPU:    *resource.NewQuantity(4, resource.DecimalSI),
+							corev1.ResourceMemory: resource.MustParse("200Mi"),
+						},
+					},
+				},
+				{
+					Name: "other",
+					Resources: corev1.ResourceRequirements{
+						Limits: corev1.ResourceList{
+							corev1.ResourceMemory: resource.MustParse("512Mi"),
+						},
+					},
+				},
+			},
+		},
+	}
+	expected := []corev1.Container{
+		{
+			// the policy raises the CPU cap and removes all limits
+			Name: "test",
+			Resources: corev1.ResourceRequirements{
+				Requests: corev1.ResourceList{
+					corev1.ResourceCPU:    *resource.NewQuantity(18, resource.DecimalSI),
+					corev1.ResourceMemory: *resource.NewQuantity(1.2e9, resource.BinarySI),
+				},
+			},
+		},
+		{
+			// without a recommendation, floors still apply and raise limits that are too low
+			Name: "other",
+			Resources: corev1.ResourceRequirements{
+				Requests: corev1.ResourceList{
+					corev1.ResourceCPU:    resource.MustParse("500m"),
+					corev1.ResourceMemory: resource.MustParse("1Gi"),
+				},
+				Limits: corev1.ResourceList{
+					corev1.ResourceMemory: resource.MustParse("1Gi"),
+				},
+			},
+		},
+	}
+
+	mutatePodResources(pod, server, policies, true, requestCaps(10, "20Gi", "100Gi"), &defaultReporter, logrus.WithField("test", t.Name()))
+	if diff := cmp.Diff(expected, pod.Spec.Containers); diff != "" {
+		t.Errorf("unexpected containers after mutation: %v", diff)
+	}
+}
+
 func TestUseOursIfLarger(t *testing.T) {
 	var testCases = []struct {
 		name                   string
@@ -880,6 +1060,25 @@ func TestReconcileLimits(t *testing.T) {
 				},
 			},
 		},
+		{
+			name: "increase low ephemeral storage limits",
+			input: corev1.ResourceRequirements{
+				Limits: corev1.ResourceList{
+					corev1.ResourceEphemeralStorage: *resource.NewQuantity(3e10, resource.BinarySI),
+				},
+				Requests: corev1.ResourceList{
+					corev1.ResourceEphemeralStorage: *resource.NewQuantity(2e10, resource.BinarySI),
+				},
+			},
+			expected: corev1.ResourceRequirements{
+				Limits: corev1.ResourceList{
+					corev1.ResourceEphemeralStorage: *resource.NewQuantity(4e10, resource.BinarySI),
+				},
+				Requests: corev1.ResourceList{
+					corev1.ResourceEphemeralStorage: *resource.NewQuantity(2e10, resource.BinarySI),
+				},
+			},
+		},
 		{
 			name: "do nothing when no memory limits have been configured",
 			input: corev1.ResourceRequirements{
@@ -942,6 +1141,7 @@ func TestRehearsalMetadata(t *testing.T) {
 func TestPreventUnschedulable(t *testing.T) {
 	cpuCap := int64(10)
 	memoryCap := "20Gi"
+	ephemeralStorageCap := "100Gi"
 	testCases := []struct {
 		name      string
 		resources *corev1.ResourceRequirements
@@ -1003,6 +1203,19 @@ func TestPreventUnschedulable(t *testing.T) {
 				},
 			},
 		},
+		{
+			name: "too much ephemeral storage",
+			resources: &corev1.ResourceRequirements{
+				Requests: corev1.ResourceList{
+					corev1.ResourceEphemeralStorage: resource.MustParse("150Gi"),
+				},
+			},
+			expected: &corev1.ResourceRequirements{
+				Requests: corev1.ResourceList{
+					corev1.ResourceEphemeralStorage: resource.MustParse(ephemeralStorageCap),
+				},
+			},
+		},
 		{
 			name:      "no requests",
 			resources: &corev1.ResourceRequirements{},
@@ -1011,7 +1224,7 @@ func TestPreventUnschedulable(t *testing.T) {
 	}
 	for _, tc := range testCases {
 		t.Run(tc.name, func(t *testing.T) {
-			preventUnschedulable(tc.resources, cpuCap, memoryCap, logrus.WithField("test", tc.name))
+			preventUnschedulable(tc.resources, requestCaps(cpuCap, memoryCap, ephemeralStorageCap), logrus.WithField("test", tc.name))
 			if diff := cmp.Diff(tc.expected, tc.resources); diff != "" {
 				t.Fatalf("result doesn't match expected, diff: %s", diff)
 			}
@@ -35,6 +35,14 @@ type cacheReloader struct {
 	lock        *sync.RWMutex
 	lastUpdated time.Time
 	subscribers []chan<- *podscaler.CachedQuery
+
+	// data is the latest data we published, which deltas are applied to. Digesters
+	// recompute recommendations from the full dataset, so this is the full dataset
+	// for the metric and holding it costs as much memory as the data itself, on top
+	// of what the digesters keep. We only hold it when the producer appends deltas.
+	data *podscaler.CachedQuery
+	// lastDelta is the time at which the latest delta in the data was stored
+	lastDelta time.Time
 }
 
 func (c *cacheReloader) subscribe(out chan<- *podscaler.CachedQuery) {
@@ -61,19 +69,86 @@ func (c *cacheReloader) reload() {
 	})
 
 	if lastUpdated == lastSeen {
-		logger.Debug("Last updated time on cloud artifacts matches our last load, won't reload this tick.")
+		c.reloadDeltas(lastUpdated, logger)
 		return
 	}
 	logger.Debug("Newer update available in cloud storage, reloading data.")
+	c.reloadAll(lastUpdated, logger)
+}
+
+// refresh loads and publishes the data even when it did not change, so that
+// subscribers digest it again, e.g. when the quantiles they use changed.
+func (c *cacheReloader) refresh() {
+	lastUpdated, err := LastUpdated(c.cache, c.name)
+	if err != nil {
+		c.logger.WithError(err).Warn("Failed to query for last cache update time, won't refresh.")
+		return
+	}
+	c.reloadAll(lastUpdated, c.logger.WithField("last_update", lastUpdated.Format(time.RFC3339)))
+}
 
-	data, err := LoadCache(c.cache, c.name, c.logger)
+func (c *cacheReloader) reloadAll(lastUpdated time.Time, logger *logrus.Entry) {
+	data, err := loadCache(c.cache, c.name, c.logger)
 	if err != nil {
 		logger.WithError(err).Warn("Failed to read cached data, won't reload this tick.")
 		return
 	}
+	var lastDelta time.Time
+	if deltas, err := listDeltas(c.cache, c.name); err != nil {
+		logger.WithError(err).Warn("Failed to list deltas, loading cached data without them.")
+	} else {
+		data, lastDelta = applyDeltas(c.cache, data, deltas, logger)
+	}
+	c.publish(data, lastUpdated, lastDelta, logger)
+}
+
+// reloadDeltas applies the deltas stored since our last load to the data we hold,
+// so we do not need to load the full dataset when only a delta was appended
+func (c *cacheReloader) reloadDeltas(lastUpdated time.Time, logger *logrus.Entry) {
+	c.lock.RLock()
+	data, lastDelta := c.data, c.lastDelta
+	c.lock.RUnlock()
+	deltas, err := listDeltas(c.cache, c.name)
+	if err != nil {
+		logger.WithError(err).Warn("Failed to list deltas, won't reload this tick.")
+		return
+	}
+	var newer []delta
+	for _, d := range deltas {
+		if d.stored.After(lastDelta) {
+			newer = append(newer, d)
+		}
+	}
+	if len(newer) == 0 {
+		logger.Debug("Last updated time on cloud artifacts matches our last load and no deltas were appended, won't reload this tick.")
+		return
+	}
+	if data == nil {
+		// we did not hold on to the data as no deltas were used when we loaded it
+		logger.Debug("Deltas were appended to cloud artifacts, reloading data.")
+		c.reloadAll(lastUpdated, logger)
+		return
+	}
+	logger.Debugf("Applying %d new deltas.", len(newer))
+	updated, latest := applyDeltas(c.cache, data, newer, logger)
+	if latest.IsZero() {
+		return
+	}
+	c.publish(updated, lastUpdated, latest, logger)
+}
+
+func (c *cacheReloader) publish(data *podscaler.CachedQuery, lastUpdated, lastDelta time.Time, logger *logrus.Entry) {
 	c.lock.Lock()
 	if len(c.subscribers) > 0 {
 		c.lastUpdated = lastUpdated
+		c.lastDelta = lastDelta
+		// holding on to the data is only necessary to apply deltas to it later, so
+		// we don't when the producer does not append deltas and the memory can be
+		// freed once subscribers digested it
+		c.data = nil
+		if data.DeltasUntil != nil || !lastDelta.IsZero() {
+			c.data = data
+		}
 		for _, subscriber := range c.subscribers {
 			subscriber <- data
 		}
@@ -201,19 +201,22 @@ var (
 	static embed.FS
 )
 
-func serveUI(port, healthPort int, dataDir string, loaders map[string][]*cacheReloader) {
+func serveUI(port, healthPort int, dataDir string, loaders map[string][]*cacheReloader, weighting podscaler.Weighting, policies *policyReloader) {
 	logger := logrus.WithField("component", "pod-scaler frontend")
 	server := &frontendServer{
-		logger:   logger,
-		lock:     sync.RWMutex{},
-		mappings: endpoints(),
-		indices:  map[string][]*IndexNode{},
-		dataDir:  dataDir,
+		logger:    logger,
+		lock:      sync.RWMutex{},
+		mappings:  endpoints(),
+		indices:   map[string][]*IndexNode{},
+		dataDir:   dataDir,
+		weighting: weighting,
+		policies:  policies,
 	}
 	health := pjutil.NewHealthOnPort(healthPort)
 	digestAll(loaders, map[string]digester{
-		MetricNameCPUUsage:         server.digestCPU,
-		MetricNameMemoryWorkingSet: server.digestMemory,
+		podscaler.MetricNameCPUUsage:         server.digestCPU,
+		podscaler.MetricNameMemoryWorkingSet: server.digestMemory,
+		podscaler.MetricNameFSUsage:          server.digestEphemeralStorage,
 	}, health, logger)
 
 	var nodes []simplifypath.Node
@@ -230,6 +233,10 @@ func serveUI(port, healthPort int, dataDir string, loaders map[string][]*cacheRe
 			l("indicies",
 				nodes...,
 			),
+			l("policies"),
+			l("policy",
+				nodes...,
+			),
 		),
 	))
 	handler := metrics.TraceHandler(simplifier, uiMetrics.HTTPRequestDuration, uiMetrics.HTTPResponseSize)
@@ -258,7 +265,9 @@ func serveUI(port, healthPort int, dataDir string, loaders map[string][]*cacheRe
 	for name := range server.mappings {
 		mux.HandleFunc(fmt.Sprintf("/api/data/%s", name), handler(server.getData(name)).ServeHTTP)
 		mux.HandleFunc(fmt.Sprintf("/api/indices/%s", name), handler(server.getIndex(name)).ServeHTTP)
+		mux.HandleFunc(fmt.Sprintf("/api/policy/%s", name), handler(server.getPolicy(name)).ServeHTTP)
 	}
+	mux.HandleFunc("/api/policies", handler(server.getPolicies()).ServeHTTP)
 	httpServer := &http.Server{Addr: ":" + strconv.Itoa(port), Handler: mux}
 	interrupts.ListenAndServe(httpServer, 5*time.Second)
 	logger.Debug("Ready to serve HTTP requests.")
@@ -323,6 +332,12 @@ type frontendServer struct {
 
 	// dataDir is where we hold sharded data by metadata identifier
 	dataDir string
+
+	// weighting determines how historical data is weighted by recency
+	weighting podscaler.Weighting
+
+	// policies override how resources are determined for some workloads
+	policies *policyReloader
 }
 
 // dataForDisplay caches precomputed values for displaying data
@@ -331,6 +346,12 @@ type dataForDisplay struct {
 	LowerBound float64                     `json:"lower_bound"`
 	Merged     *circonusllhist.Histogram   `json:"merged"`
 	Histograms []*circonusllhist.Histogram `json:"histograms"`
+	// HalfLife is the half-life with which the histograms were weighted, if any
+	HalfLife string `json:"half_life,omitempty"`
+	// Weights holds the weight of each of the histograms, in the same order
+	Weights []podscaler.WeightedFingerprintTime `json:"weights,omitempty"`
+	// Trend is set when the cutoff follows an upward trend in usage
+	Trend *podscaler.Trend `json:"trend,omitempty"`
 }
 
 func (s *frontendServer) getIndex(index string) http.HandlerFunc {
@@ -398,6 +419,49 @@ func (s *frontendServer) getData(index string) http.HandlerFunc {
 	}
 }
 
+// getPolicies serves all policies that are currently loaded.
+func (s *frontendServer) getPolicies() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		policies := s.policies.current()
+		if policies == nil {
+			policies = &podscaler.Policies{}
+		}
+		s.writeJSON(w, policies, "policies")
+	}
+}
+
+// getPolicy serves the policy that applies to the workload in the query.
+func (s *frontendServer) getPolicy(index string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "GET" {
+			w.WriteHeader(http.StatusNotImplemented)
+			_, _ = w.Write([]byte(http.StatusText(http.StatusNotImplemented)))
+			return
+		}
+		mapping := s.mappings[index]
+		meta, err := mapping.metadataFromQuery(w, r)
+		if err != nil {
+			metrics.RecordError("invalid query", uiMetrics.ErrorRate)
+			return
+		}
+		s.writeJSON(w, s.policies.For(meta), "policy")
+	}
+}
+
+func (s *frontendServer) writeJSON(w http.ResponseWriter, data interface{}, kind string) {
+	raw, err := json.Marshal(data)
+	if err != nil {
+		metrics.RecordError(fmt.Sprintf("failed to marshal %s", kind), uiMetrics.ErrorRate)
+		w.WriteHeader(http.StatusInternalServerError)
+		fmt.Fprintf(w, "failed to marshal %s to JSON: %v", kind, err)
+		s.logger.WithError(err).Errorf("Failed to marshal %s to JSON.", kind)
+		return
+	}
+	if _, err := w.Write(raw); err != nil {
+		s.logger.WithError(err).Errorf("failed to write %s response", kind)
+	}
+}
+
 func hashed(meta podscaler.FullMetadata) (string, error) {
 	raw, err := json.Marshal(meta)
 	if err != nil {
@@ -463,16 +527,22 @@ func (s *frontendServer) setDatum(meta podscaler.FullMetadata, resource corev1.R
 
 func (s *frontendServer) digestCPU(data *podscaler.CachedQuery) {
 	s.logger.Debugf("Digesting new CPU consumption metrics.")
-	s.digestData(data, corev1.ResourceCPU, cpuRequestQuantile)
+	s.digestData(data, corev1.ResourceCPU, podscaler.CPURequestQuantile)
 }
 
 func (s *frontendServer) digestMemory(data *podscaler.CachedQuery) {
 	s.logger.Debugf("Digesting new Memory consumption metrics.")
-	s.digestData(data, corev1.ResourceMemory, memRequestQuantile)
+	s.digestData(data, corev1.ResourceMemory, podscaler.MemoryRequestQuantile)
+}
+
+func (s *frontendServer) digestEphemeralStorage(data *podscaler.CachedQuery) {
+	s.logger.Debugf("Digesting new ephemeral storage consumption metrics.")
+	s.digestData(data, corev1.ResourceEphemeralStorage, podscaler.EphemeralStorageRequestQuantile)
 }
 
 func (s *frontendServer) digestData(data *podscaler.CachedQuery, metric corev1.ResourceName, quantile float64) {
 	s.logger.Debugf("Digesting %d identifiers.", len(data.DataByMetaData))
+	now := time.Now()
 	for meta, fingerprintTimes := range data.DataByMetaData {
 		s.lock.Lock()
 		for name, mapping := range s.mappings {
@@ -489,19 +559,23 @@ func (s *frontendServer) digestData(data *podscaler.CachedQuery, metric corev1.R
 			}
 		}
 
-		overall := circonusllhist.New()
+		recommendation := data.Recommend(fingerprintTimes, s.policies.For(meta).Resource(metric).QuantileOr(quantile), s.weighting, now)
 		var members []*circonusllhist.Histogram
 		for _, fingerprintTime := range fingerprintTimes {
-			fingerprint := fingerprintTime.Fingerprint
-			overall.Merge(data.Data[fingerprint].Histogram())
-			members = append(members, data.Data[fingerprint].Histogram())
+			members = append(members, data.Data[fingerprintTime.Fingerprint].Histogram())
 		}
-		if err := s.setDatum(meta, metric, dataForDisplay{
-			Cutoff:     overall.ValueAtQuantile(quantile),
-			LowerBound: overall.ValueAtQuantile(.001),
-			Merged:     overall,
+		datum := dataForDisplay{
+			Cutoff:     recommendation.Value,
+			LowerBound: recommendation.Merged.ValueAtQuantile(.001),
+			Merged:     recommendation.Merged,
 			Histograms: members,
-		}); err != nil {
+		}
+		if s.weighting.Enabled() {
+			datum.HalfLife = s.weighting.HalfLife.String()
+			datum.Weights = recommendation.Weighted
+			datum.Trend = recommendation.Trend
+		}
+		if err := s.setDatum(meta, metric, datum); err != nil {
 			s.logger.WithError(err).Error("Could not record data.")
 		}
 		s.lock.Unlock()
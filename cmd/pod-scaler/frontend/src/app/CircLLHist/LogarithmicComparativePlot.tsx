@@ -12,10 +12,30 @@ export interface LogarithmicComparativePlotProps {
   merged: Histogram;
   /** the data to plot */
   histograms: Histogram[];
+  /** the half-life with which the data was weighted, if any */
+  half_life?: string;
+  /** the weight of each of the histograms, if the data was weighted */
+  weights?: Weight[];
+  /** the upward trend the cutoff follows, if any */
+  trend?: Trend;
   /** options for the canvas layout */
   canvasProps: CanvasProps & OptionalCanvasProps;
 }
 
+export interface Weight {
+  /** the time at which the data was recorded */
+  added: string;
+  /** the relative weight of the data, between 0 and 1 */
+  weight: number;
+}
+
+export interface Trend {
+  /** the change in usage per day */
+  slope: number;
+  /** the projected usage of the next execution */
+  projected: number;
+}
+
 export interface CanvasProps {
   /** title of the plot */
   title: string;
@@ -358,10 +378,23 @@ export const LogarithmicComparativePlot: React.FunctionComponent<LogarithmicComp
 
   const request: string = canvasMeta.yAxisFormatter(props.cutoff);
   let samples = 0;
-  for (const bin of props.merged.bins) {
-    samples += bin.count;
+  for (const histogram of props.histograms) {
+    for (const bin of histogram.bins) {
+      samples += bin.count;
+    }
+  }
+  let description: string = "Analyzing " + samples + " samples over " + props.histograms.length + " traces, a request of " + request + canvasMeta.yAxisUnit + " is recommended.";
+  if (props.half_life) {
+    description += " Recent traces weigh more, with a half-life of " + props.half_life;
+    if (props.weights && props.weights.length > 0) {
+      const lightest = Math.min(...props.weights.map((w: Weight) => w.weight));
+      description += ", so the oldest trace counts for " + (100 * lightest).toFixed(1) + "% of a fresh one";
+    }
+    description += ".";
+  }
+  if (props.trend) {
+    description += " Usage is trending upward by " + canvasMeta.yAxisFormatter(props.trend.slope) + canvasMeta.yAxisUnit + " per day, so the projected usage is recommended.";
   }
-  const description: string = "Analyzing " + samples + " samples over " + props.histograms.length + " traces, a request of " + request + canvasMeta.yAxisUnit + " is recommended.";
 
   let sliderMax: number = props.histograms.length - canvasMeta.numCols + 1;
   if (sliderMax <= 0) {
@@ -2,7 +2,7 @@ import {Buffer} from 'buffer';
 import * as React from 'react';
 import {Alert, Flex, Spinner} from '@patternfly/react-core';
 import {DeserializeHistogram, Histogram} from "@app/CircLLHist/CircLLHist";
-import {LogarithmicComparativePlot} from "@app/CircLLHist/LogarithmicComparativePlot";
+import {LogarithmicComparativePlot, Trend, Weight} from "@app/CircLLHist/LogarithmicComparativePlot";
 
 export interface HistogramsProps {
     /** URL to fetch raw data from */
@@ -16,6 +16,9 @@ export interface rawData {
     lower_bound: string;
     merged: string;
     histograms: string[];
+    half_life?: string;
+    weights?: Weight[];
+    trend?: Trend;
 }
 
 export interface Data {
@@ -23,6 +26,9 @@ export interface Data {
     lower_bound: number;
     merged: Histogram;
     histograms: Histogram[];
+    half_life?: string;
+    weights?: Weight[];
+    trend?: Trend;
 }
 
 export type HistogramData = Record<string, Data>;
@@ -35,6 +41,9 @@ const process = (raw: Record<string, rawData>): HistogramData => {
                 lower_bound: parseFloat(raw[resource].lower_bound),
                 merged: DeserializeHistogram(Buffer.from(raw[resource].merged, 'base64')),
                 histograms: [],
+                half_life: raw[resource].half_life,
+                weights: raw[resource].weights,
+                trend: raw[resource].trend,
             };
             for (const histogram of raw[resource].histograms) {
                 datum.histograms.push(DeserializeHistogram(Buffer.from(histogram, 'base64')))
@@ -117,6 +126,21 @@ export const Histograms: React.FunctionComponent<HistogramsProps> = (
                 yAxisTitle: "Memory Used",
                 yAxisUnit: "MiB",
             }}/>}
+        {data["ephemeral-storage"] && <LogarithmicComparativePlot
+            {...data["ephemeral-storage"]}
+            canvasProps={{
+                title: "Ephemeral Storage Usage",
+                yAxisFormatter(value: number): string {
+                    const n: number = value / Math.pow(2, 20);
+                    if (value > 10) {
+                        Math.round(n).toString();
+                    }
+                    return n.toFixed(2);
+                },
+                yAxisMin: Math.pow(2, 20),
+                yAxisTitle: "Ephemeral Storage Used",
+                yAxisUnit: "MiB",
+            }}/>}
     </Flex>;
 };
 
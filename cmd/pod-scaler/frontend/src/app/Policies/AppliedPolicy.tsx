@@ -0,0 +1,121 @@
+import * as React from 'react';
+import {
+    Alert,
+    DescriptionList,
+    DescriptionListDescription,
+    DescriptionListGroup,
+    DescriptionListTerm,
+    Spinner,
+    Text,
+    TextContent,
+} from '@patternfly/react-core';
+
+export interface ResourcePolicy {
+    quantile?: number;
+    cap?: string;
+    floor?: string;
+}
+
+export interface Policy {
+    policies?: string[];
+    cpu: ResourcePolicy;
+    memory: ResourcePolicy;
+    ephemeral_storage: ResourcePolicy;
+    limits?: string;
+}
+
+export interface AppliedPolicyProps {
+    /** URL to fetch the applied policy from */
+    dataUrl: string;
+    /** Query parameters for the fetch */
+    parameters: string;
+}
+
+export const describeResourcePolicy = (policy?: ResourcePolicy): string => {
+    if (!policy) {
+        return "defaults";
+    }
+    const settings: string[] = [];
+    if (policy.quantile !== undefined) {
+        settings.push("quantile " + policy.quantile);
+    }
+    if (policy.floor) {
+        settings.push("floor " + policy.floor);
+    }
+    if (policy.cap) {
+        settings.push("cap " + policy.cap);
+    }
+    if (settings.length === 0) {
+        return "defaults";
+    }
+    return settings.join(", ");
+}
+
+export const AppliedPolicy: React.FunctionComponent<AppliedPolicyProps> = (
+    {
+        dataUrl,
+        parameters,
+    }: AppliedPolicyProps) => {
+    const [policy, setPolicy] = React.useState<Policy>();
+    const [fetchError, setFetchError] = React.useState<string>("");
+
+    React.useEffect(() => {
+        let mounted = true;
+        fetch(dataUrl + "?" + new URLSearchParams(parameters), {headers: {"Accept": "application/json"}}).then(async (res) => {
+            if (!res.ok) {
+                const raw = await res.text();
+                throw new Error(res.status + ": " + raw);
+            }
+            const raw = await res.json();
+            if (mounted) {
+                setPolicy(raw);
+            }
+        }).catch((error) => {
+            if (mounted) {
+                setFetchError(String(error));
+            }
+        })
+        return () => {
+            mounted = false
+        };
+    }, [dataUrl, parameters]);
+
+    if (fetchError) {
+        return <div><Alert variant="danger" title={fetchError}/></div>
+    }
+
+    if (!policy) {
+        return <div><Spinner isSVG size="md"/>Loading policy...</div>
+    }
+
+    if (!policy.policies || policy.policies.length === 0) {
+        return <TextContent>
+            <Text component="p">No policies apply to this workload, so the defaults are used.</Text>
+        </TextContent>
+    }
+
+    return <DescriptionList isHorizontal isCompact>
+        <DescriptionListGroup>
+            <DescriptionListTerm>Applied Policies</DescriptionListTerm>
+            <DescriptionListDescription>{policy.policies.join(", ")}</DescriptionListDescription>
+        </DescriptionListGroup>
+        <DescriptionListGroup>
+            <DescriptionListTerm>CPU</DescriptionListTerm>
+            <DescriptionListDescription>{describeResourcePolicy(policy.cpu)}</DescriptionListDescription>
+        </DescriptionListGroup>
+        <DescriptionListGroup>
+            <DescriptionListTerm>Memory</DescriptionListTerm>
+            <DescriptionListDescription>{describeResourcePolicy(policy.memory)}</DescriptionListDescription>
+        </DescriptionListGroup>
+        <DescriptionListGroup>
+            <DescriptionListTerm>Ephemeral Storage</DescriptionListTerm>
+            <DescriptionListDescription>{describeResourcePolicy(policy.ephemeral_storage)}</DescriptionListDescription>
+        </DescriptionListGroup>
+        <DescriptionListGroup>
+            <DescriptionListTerm>Limits</DescriptionListTerm>
+            <DescriptionListDescription>{policy.limits || "defaults"}</DescriptionListDescription>
+        </DescriptionListGroup>
+    </DescriptionList>;
+};
+
+AppliedPolicy.displayName = 'AppliedPolicy';
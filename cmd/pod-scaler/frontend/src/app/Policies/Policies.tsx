@@ -0,0 +1,141 @@
+import * as React from 'react';
+import {
+    Alert,
+    Card,
+    CardBody,
+    CardTitle,
+    DescriptionList,
+    DescriptionListDescription,
+    DescriptionListGroup,
+    DescriptionListTerm,
+    Divider,
+    Gallery,
+    PageSection,
+    PageSectionVariants,
+    Spinner,
+    Text,
+    TextContent,
+} from '@patternfly/react-core';
+import {describeResourcePolicy, ResourcePolicy} from "@app/Policies/AppliedPolicy";
+
+interface PolicyMatch {
+    org?: string;
+    repo?: string;
+    branch?: string;
+    target?: string;
+    container?: string;
+}
+
+interface ConfiguredPolicy {
+    name: string;
+    match: PolicyMatch;
+    cpu?: ResourcePolicy;
+    memory?: ResourcePolicy;
+    ephemeral_storage?: ResourcePolicy;
+    limits?: string;
+}
+
+const describeMatch = (match: PolicyMatch): string => {
+    const fields: string[] = [];
+    const values: [string, string | undefined][] = [
+        ["org", match.org],
+        ["repo", match.repo],
+        ["branch", match.branch],
+        ["target", match.target],
+        ["container", match.container],
+    ];
+    for (const [field, value] of values) {
+        if (value) {
+            fields.push(field + "=" + value);
+        }
+    }
+    if (fields.length === 0) {
+        return "all workloads";
+    }
+    return fields.join(", ");
+}
+
+const Policies: React.FunctionComponent = () => {
+    const [policies, setPolicies] = React.useState<ConfiguredPolicy[]>();
+    const [fetchError, setFetchError] = React.useState<string>("");
+
+    React.useEffect(() => {
+        let mounted = true;
+        fetch("/api/policies", {headers: {"Accept": "application/json"}}).then(async (res) => {
+            if (!res.ok) {
+                const raw = await res.text();
+                throw new Error(res.status + ": " + raw);
+            }
+            const raw = await res.json();
+            if (mounted) {
+                setPolicies(raw.policies || []);
+            }
+        }).catch((error) => {
+            if (mounted) {
+                setFetchError(String(error));
+            }
+        })
+        return () => {
+            mounted = false
+        };
+    }, []);
+
+    let body: JSX.Element;
+    if (fetchError) {
+        body = <Alert variant="danger" title={fetchError}/>;
+    } else if (!policies) {
+        body = <div><Spinner isSVG size="xl"/>Loading policies...</div>;
+    } else if (policies.length === 0) {
+        body = <div>No policies are configured.</div>;
+    } else {
+        body = <Gallery hasGutter>
+            {policies.map((policy) => <Card key={policy.name}>
+                <CardTitle>{policy.name}</CardTitle>
+                <CardBody>
+                    <DescriptionList isCompact>
+                        <DescriptionListGroup>
+                            <DescriptionListTerm>Matches</DescriptionListTerm>
+                            <DescriptionListDescription>{describeMatch(policy.match)}</DescriptionListDescription>
+                        </DescriptionListGroup>
+                        <DescriptionListGroup>
+                            <DescriptionListTerm>CPU</DescriptionListTerm>
+                            <DescriptionListDescription>{describeResourcePolicy(policy.cpu)}</DescriptionListDescription>
+                        </DescriptionListGroup>
+                        <DescriptionListGroup>
+                            <DescriptionListTerm>Memory</DescriptionListTerm>
+                            <DescriptionListDescription>{describeResourcePolicy(policy.memory)}</DescriptionListDescription>
+                        </DescriptionListGroup>
+                        <DescriptionListGroup>
+                            <DescriptionListTerm>Ephemeral Storage</DescriptionListTerm>
+                            <DescriptionListDescription>{describeResourcePolicy(policy.ephemeral_storage)}</DescriptionListDescription>
+                        </DescriptionListGroup>
+                        <DescriptionListGroup>
+                            <DescriptionListTerm>Limits</DescriptionListTerm>
+                            <DescriptionListDescription>{policy.limits || "defaults"}</DescriptionListDescription>
+                        </DescriptionListGroup>
+                    </DescriptionList>
+                </CardBody>
+            </Card>)}
+        </Gallery>;
+    }
+
+    return <React.Fragment>
+        <PageSection variant={PageSectionVariants.light}>
+            <TextContent>
+                <Text component="h1">Resource Policies</Text>
+                <Text component="p">
+                    Policies override the quantile of usage that is recommended and the floors and caps of requests
+                    for the workloads they match, as well as how their limits are handled. When many policies match a
+                    workload, the settings of the more specific policies take precedence. The policies applied to a
+                    workload are shown alongside its resource usage.
+                </Text>
+            </TextContent>
+        </PageSection>
+        <Divider component="div"/>
+        <PageSection>
+            {body}
+        </PageSection>
+    </React.Fragment>;
+}
+
+export {Policies};
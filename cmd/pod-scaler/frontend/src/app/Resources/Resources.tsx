@@ -20,6 +20,7 @@ import {
 import {Link, useHistory, useLocation} from "react-router-dom";
 import {SearchTreeView, toId} from "@app/SearchTreeView/SearchTreeView";
 import {Histograms} from "@app/Histograms/Histograms";
+import {AppliedPolicy} from "@app/Policies/AppliedPolicy";
 import {selectNames, selectParents} from "@app/store";
 import {useAppSelector} from "@app/hook";
 import {Location} from "history";
@@ -101,7 +102,10 @@ export const Resources: React.FunctionComponent<ResourcesProps> = (props: Resour
   const breadcrumbs: React.ReactNode[] = [<BreadcrumbItem key={"root"} isActive={false}
                                                           onClick={onOpenClick}>Select {props.workload}</BreadcrumbItem>];
   if (activeItem && activeItem.id) {
-    body = <Histograms dataUrl={"/api/data/" + props.urlFragment} parameters={activeItem.id}/>;
+    body = <React.Fragment>
+      <AppliedPolicy dataUrl={"/api/policy/" + props.urlFragment} parameters={activeItem.id}/>
+      <Histograms dataUrl={"/api/data/" + props.urlFragment} parameters={activeItem.id}/>
+    </React.Fragment>;
     breadcrumbs.push(...breadCrumbFor(activeItem.id))
   }
   const breadcrumb: React.ReactNode = <Breadcrumb>
@@ -115,7 +119,7 @@ export const Resources: React.FunctionComponent<ResourcesProps> = (props: Resour
           <TextContent>
             <Text component="h1">Resource Usage for {props.workload} Workloads</Text>
             <Text component="p">
-              Choose a workload to view the CPU and memory usage for recent executions.
+              Choose a workload to view the CPU, memory and ephemeral storage usage for recent executions.
             </Text>
           </TextContent>
         </PageSection>
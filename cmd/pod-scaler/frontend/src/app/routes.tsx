@@ -10,6 +10,7 @@ import { ProwJobs } from '@app/ResourceUsage/ProwJobs/ProwJobs';
 import { Pods } from '@app/ResourceUsage/Pods/Pods';
 import { RPMs } from '@app/ResourceUsage/RPMRepos/RPMRepos';
 import { Landing } from '@app/Landing/Landing';
+import { Policies } from '@app/Policies/Policies';
 
 let routeFocusTimer: number;
 export interface IAppRoute {
@@ -79,6 +80,13 @@ const routes: AppRouteConfig[] = [
       },
     ],
   },
+  {
+    component: Policies,
+    exact: true,
+    label: 'Policies',
+    path: '/policies',
+    title: 'Resource Usage | Policies',
+  },
 ];
 
 // a custom hook for sending focus to the primary content container
@@ -8,6 +8,9 @@ import (
 	"time"
 
 	"cloud.google.com/go/storage"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	awsconfig "github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
 	"github.com/bombsimon/logrusr/v3"
 	prometheusclient "github.com/prometheus/client_golang/api"
 	prometheusapi "github.com/prometheus/client_golang/api/prometheus/v1"
@@ -29,6 +32,7 @@ import (
 	buildclientset "github.com/openshift/client-go/build/clientset/versioned/typed/build/v1"
 	routeclientset "github.com/openshift/client-go/route/clientset/versioned/typed/route/v1"
 
+	podscaler "github.com/openshift/ci-tools/pkg/pod-scaler"
 	"github.com/openshift/ci-tools/pkg/prowconfigutils"
 	"github.com/openshift/ci-tools/pkg/results"
 	"github.com/openshift/ci-tools/pkg/util"
@@ -47,6 +51,10 @@ type options struct {
 	cacheDir           string
 	cacheBucket        string
 	gcsCredentialsFile string
+	cacheS3Bucket      string
+	cacheS3Endpoint    string
+	cacheS3Region      string
+	cacheBoltDB        string
 
 	resultsOptions results.Options
 }
@@ -55,6 +63,7 @@ type producerOptions struct {
 	kubernetesOptions prowflagutil.KubernetesOptions
 	once              bool
 	ignoreLatest      time.Duration
+	compactAfter      int
 }
 
 type consumerOptions struct {
@@ -66,7 +75,14 @@ type consumerOptions struct {
 	mutateResourceLimits  bool
 	cpuCap                int64
 	memoryCap             string
+	ephemeralStorageCap   string
 	cpuPriorityScheduling int64
+	// policyFile holds policies that override how resources are determined for some workloads
+	policyFile string
+	// shadow only records the mutations the admission webhook would make, without applying them
+	shadow bool
+	// recommendationHalfLife is the half-life with which historical data is weighted by recency
+	recommendationHalfLife time.Duration
 }
 
 func bindOptions(fs *flag.FlagSet) *options {
@@ -76,19 +92,28 @@ func bindOptions(fs *flag.FlagSet) *options {
 	o.producerOptions.kubernetesOptions.AddFlags(fs)
 	fs.DurationVar(&o.ignoreLatest, "ignore-latest", 0, "Duration of latest time series to ignore when querying Prometheus. For instance, 1h will ignore the latest hour of data.")
 	fs.BoolVar(&o.once, "produce-once", false, "Query Prometheus and refresh cached data only once before exiting.")
+	fs.IntVar(&o.compactAfter, "compact-after", 0, "Append the data queried in each cycle to the cached data as a delta and only rewrite the cached data once this many deltas exist. Cached data is rewritten every cycle when unset.")
 	fs.IntVar(&o.port, "port", 0, "Port to serve admission webhooks on.")
 	fs.IntVar(&o.uiPort, "ui-port", 0, "Port to serve frontend on.")
 	fs.StringVar(&o.certDir, "serving-cert-dir", "", "Path to directory with serving certificate and key for the admission webhook server.")
 	fs.BoolVar(&o.mutateResourceLimits, "mutate-resource-limits", false, "Enable resource limit mutation in the admission webhook.")
+	fs.StringVar(&o.policyFile, "policy-file", "", "File holding policies that override the quantiles, caps, floors and limit handling for workloads by org, repo, branch, target and container. The file is reloaded when it changes.")
+	fs.BoolVar(&o.shadow, "shadow", false, "Run the admission webhook in shadow mode: compute recommendations without mutating Pods and only record what would have changed.")
 	fs.StringVar(&o.loglevel, "loglevel", "debug", "Logging level.")
 	fs.StringVar(&o.logStyle, "log-style", "json", "Logging style: json or text.")
 	fs.StringVar(&o.cacheDir, "cache-dir", "", "Local directory holding cache data (for development mode).")
 	fs.StringVar(&o.dataDir, "data-dir", "", "Local directory to cache UI data into.")
 	fs.StringVar(&o.cacheBucket, "cache-bucket", "", "GCS bucket name holding cached Prometheus data.")
 	fs.StringVar(&o.gcsCredentialsFile, "gcs-credentials-file", "", "File where GCS credentials are stored.")
+	fs.StringVar(&o.cacheS3Bucket, "cache-s3-bucket", "", "S3 bucket name holding cached Prometheus data. Credentials are read from the environment, as for the AWS CLI.")
+	fs.StringVar(&o.cacheS3Endpoint, "cache-s3-endpoint", "", "Endpoint of an S3-compatible object storage service holding the --cache-s3-bucket, ex: 'https://minio.example.com'.")
+	fs.StringVar(&o.cacheS3Region, "cache-s3-region", "", "Region of the --cache-s3-bucket, if not set in the environment.")
+	fs.StringVar(&o.cacheBoltDB, "cache-bolt-db", "", "Path to an embedded bbolt database file holding cached Prometheus data, ex: on a volume shared by the producer and consumers.")
 	fs.Int64Var(&o.cpuCap, "cpu-cap", 10, "The maximum CPU request value, ex: 10")
 	fs.StringVar(&o.memoryCap, "memory-cap", "20Gi", "The maximum memory request value, ex: '20Gi'")
+	fs.StringVar(&o.ephemeralStorageCap, "ephemeral-storage-cap", "100Gi", "The maximum ephemeral storage request value, ex: '100Gi'")
 	fs.Int64Var(&o.cpuPriorityScheduling, "cpu-priority-scheduling", 8, "Pods with CPU requests at, or above, this value will be admitted with priority scheduling")
+	fs.DurationVar(&o.recommendationHalfLife, "recommendation-half-life", 0, "Weigh historical data by recency with this half-life and follow upward trends in usage when recommending resources, ex: '336h'. All data is weighted equally when unset.")
 	o.resultsOptions.Bind(fs)
 	return &o
 }
@@ -101,6 +126,9 @@ const (
 func (o *options) validate() error {
 	switch o.mode {
 	case "producer":
+		if o.compactAfter < 0 {
+			return errors.New("--compact-after must not be negative")
+		}
 		return o.kubernetesOptions.Validate(false)
 	case "consumer.ui":
 		if o.uiPort == 0 {
@@ -109,6 +137,12 @@ func (o *options) validate() error {
 		if o.dataDir == "" {
 			return errors.New("--data-dir is required")
 		}
+		if o.recommendationHalfLife < 0 {
+			return errors.New("--recommendation-half-life must not be negative")
+		}
+		if err := o.validatePolicies(); err != nil {
+			return err
+		}
 	case "consumer.admission":
 		if o.port == 0 {
 			return errors.New("--port is required")
@@ -116,12 +150,23 @@ func (o *options) validate() error {
 		if o.certDir == "" {
 			return errors.New("--serving-cert-dir is required")
 		}
+		if o.recommendationHalfLife < 0 {
+			return errors.New("--recommendation-half-life must not be negative")
+		}
 		if cpuCap := resource.NewQuantity(o.cpuCap, resource.DecimalSI); cpuCap.Sign() <= 0 {
 			return errors.New("--cpu-cap must be greater than 0")
 		}
 		if memoryCap := resource.MustParse(o.memoryCap); memoryCap.Sign() <= 0 {
 			return errors.New("--memory-cap must be greater than 0")
 		}
+		if ephemeralStorageCap, err := resource.ParseQuantity(o.ephemeralStorageCap); err != nil {
+			return fmt.Errorf("--ephemeral-storage-cap is invalid: %w", err)
+		} else if ephemeralStorageCap.Sign() <= 0 {
+			return errors.New("--ephemeral-storage-cap must be greater than 0")
+		}
+		if err := o.validatePolicies(); err != nil {
+			return err
+		}
 		if err := o.resultsOptions.Validate(); err != nil {
 			return err
 		}
@@ -129,7 +174,16 @@ func (o *options) validate() error {
 	default:
 		return errors.New("--mode must be either \"producer\", \"consumer.ui\", or \"consumer.admission\"")
 	}
-	if o.cacheDir == "" {
+	if o.cacheBucket != "" && o.cacheS3Bucket != "" {
+		return errors.New("--cache-bucket and --cache-s3-bucket are mutually exclusive")
+	}
+	if o.cacheBoltDB != "" && (o.cacheBucket != "" || o.cacheS3Bucket != "") {
+		return errors.New("--cache-bolt-db is mutually exclusive with --cache-bucket and --cache-s3-bucket")
+	}
+	if o.cacheS3Bucket == "" && (o.cacheS3Endpoint != "" || o.cacheS3Region != "") {
+		return errors.New("--cache-s3-endpoint and --cache-s3-region require --cache-s3-bucket")
+	}
+	if o.cacheDir == "" && o.cacheS3Bucket == "" && o.cacheBoltDB == "" {
 		if o.cacheBucket == "" {
 			return errors.New("--cache-bucket is required")
 		}
@@ -177,6 +231,25 @@ func main() {
 	var cache Cache
 	if opts.cacheDir != "" {
 		cache = &LocalCache{Dir: opts.cacheDir}
+	} else if opts.cacheBoltDB != "" {
+		cache = &BoltCache{Path: opts.cacheBoltDB}
+	} else if opts.cacheS3Bucket != "" {
+		var configOpts []func(*awsconfig.LoadOptions) error
+		if opts.cacheS3Region != "" {
+			configOpts = append(configOpts, awsconfig.WithRegion(opts.cacheS3Region))
+		}
+		awsConfig, err := awsconfig.LoadDefaultConfig(interrupts.Context(), configOpts...)
+		if err != nil {
+			logrus.WithError(err).Fatal("Could not load AWS configuration.")
+		}
+		client := s3.NewFromConfig(awsConfig, func(o *s3.Options) {
+			if opts.cacheS3Endpoint != "" {
+				o.BaseEndpoint = aws.String(opts.cacheS3Endpoint)
+				// most S3-compatible services do not support virtual-hosted buckets
+				o.UsePathStyle = true
+			}
+		})
+		cache = &S3Cache{Client: client, Bucket: opts.cacheS3Bucket}
 	} else {
 		gcsClient, err := storage.NewClient(interrupts.Context(), option.WithCredentialsFile(opts.gcsCredentialsFile))
 		if err != nil {
@@ -199,6 +272,16 @@ func main() {
 	}
 }
 
+func (o *options) validatePolicies() error {
+	if o.policyFile == "" {
+		return nil
+	}
+	if _, err := podscaler.LoadPolicies(o.policyFile); err != nil {
+		return fmt.Errorf("--policy-file is invalid: %w", err)
+	}
+	return nil
+}
+
 func mainProduce(opts *options, cache Cache) {
 	kubeconfigChangedCallBack := func() {
 		logrus.Fatal("Kubeconfig changed, exiting to get restarted by Kubelet and pick up the changes")
@@ -241,12 +324,18 @@ func mainProduce(opts *options, cache Cache) {
 		logger.Debugf("Loaded Prometheus client.")
 	}
 
-	produce(clients, cache, opts.ignoreLatest, opts.once)
+	produce(clients, cache, opts.ignoreLatest, opts.once, opts.compactAfter)
 
 }
 
 func mainUI(opts *options, cache Cache) {
-	go serveUI(opts.uiPort, opts.instrumentationOptions.HealthPort, opts.dataDir, loaders(cache))
+	policies, err := newPolicyReloader(opts.policyFile)
+	if err != nil {
+		logrus.WithError(err).Fatal("Failed to load policies.")
+	}
+	l := loaders(cache)
+	refreshOnPolicyChange(policies, l)
+	go serveUI(opts.uiPort, opts.instrumentationOptions.HealthPort, opts.dataDir, l, podscaler.Weighting{HalfLife: opts.recommendationHalfLife}, policies)
 }
 
 func mainAdmission(opts *options, cache Cache) {
@@ -264,15 +353,34 @@ func mainAdmission(opts *options, cache Cache) {
 	if err != nil {
 		logrus.WithError(err).Fatal("Failed to create pod-scaler reporter.")
 	}
+	policies, err := newPolicyReloader(opts.policyFile)
+	if err != nil {
+		logrus.WithError(err).Fatal("Failed to load policies.")
+	}
 
-	go admit(opts.port, opts.instrumentationOptions.HealthPort, opts.certDir, client, loaders(cache), opts.mutateResourceLimits, opts.cpuCap, opts.memoryCap, opts.cpuPriorityScheduling, reporter)
+	l := loaders(cache)
+	refreshOnPolicyChange(policies, l)
+	go admit(opts.port, opts.instrumentationOptions.HealthPort, opts.certDir, client, l, opts.mutateResourceLimits, opts.cpuCap, opts.memoryCap, opts.ephemeralStorageCap, opts.cpuPriorityScheduling, podscaler.Weighting{HalfLife: opts.recommendationHalfLife}, policies, opts.shadow, reporter)
+}
+
+// refreshOnPolicyChange digests the data again whenever the policies change,
+// as the quantile of usage we recommend is determined when digesting.
+func refreshOnPolicyChange(policies *policyReloader, loaders map[string][]*cacheReloader) {
+	policies.onChange(func() {
+		for _, reloaders := range loaders {
+			for _, reloader := range reloaders {
+				go reloader.refresh()
+			}
+		}
+	})
 }
 
 func loaders(cache Cache) map[string][]*cacheReloader {
 	l := map[string][]*cacheReloader{}
 	for _, prefix := range []string{ProwjobsCachePrefix, PodsCachePrefix, StepsCachePrefix} {
-		l[MetricNameCPUUsage] = append(l[MetricNameCPUUsage], newReloader(prefix+"/"+MetricNameCPUUsage, cache))
-		l[MetricNameMemoryWorkingSet] = append(l[MetricNameMemoryWorkingSet], newReloader(prefix+"/"+MetricNameMemoryWorkingSet, cache))
+		l[podscaler.MetricNameCPUUsage] = append(l[podscaler.MetricNameCPUUsage], newReloader(prefix+"/"+podscaler.MetricNameCPUUsage, cache))
+		l[podscaler.MetricNameMemoryWorkingSet] = append(l[podscaler.MetricNameMemoryWorkingSet], newReloader(prefix+"/"+podscaler.MetricNameMemoryWorkingSet, cache))
+		l[podscaler.MetricNameFSUsage] = append(l[podscaler.MetricNameFSUsage], newReloader(prefix+"/"+podscaler.MetricNameFSUsage, cache))
 	}
 	return l
 }
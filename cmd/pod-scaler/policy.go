@@ -0,0 +1,104 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"sigs.k8s.io/prow/pkg/interrupts"
+
+	podscaler "github.com/openshift/ci-tools/pkg/pod-scaler"
+)
+
+// newPolicyReloader loads the policies from the file and reloads them whenever
+// the file changes. Policies are not required, so no file yields no policies.
+func newPolicyReloader(path string) (*policyReloader, error) {
+	reloader := &policyReloader{
+		path:   path,
+		logger: logrus.WithFields(logrus.Fields{"component": "pod-scaler policy reloader", "path": path}),
+	}
+	if path == "" {
+		return reloader, nil
+	}
+	if err := reloader.reload(); err != nil {
+		return nil, err
+	}
+	interrupts.TickLiteral(func() {
+		if err := reloader.reload(); err != nil {
+			// we keep serving the last valid policies until the file is fixed
+			reloader.logger.WithError(err).Error("Failed to reload policies, keeping previous policies.")
+		}
+	}, time.Minute)
+	return reloader, nil
+}
+
+type policyReloader struct {
+	path   string
+	logger *logrus.Entry
+
+	lock     sync.RWMutex
+	raw      []byte
+	policies *podscaler.Policies
+	// changed are called when reloading changes the policies
+	changed []func()
+}
+
+func (r *policyReloader) reload() error {
+	raw, err := os.ReadFile(r.path)
+	if err != nil {
+		return fmt.Errorf("could not read policies: %w", err)
+	}
+	r.lock.RLock()
+	unchanged := r.policies != nil && bytes.Equal(raw, r.raw)
+	r.lock.RUnlock()
+	if unchanged {
+		return nil
+	}
+	policies, err := podscaler.ParsePolicies(raw)
+	if err != nil {
+		return err
+	}
+	r.lock.Lock()
+	initial := r.policies == nil
+	r.raw = raw
+	r.policies = policies
+	changed := r.changed
+	r.lock.Unlock()
+	r.logger.Infof("Loaded %d policies.", len(policies.Policies))
+	if !initial {
+		for _, f := range changed {
+			f()
+		}
+	}
+	return nil
+}
+
+// onChange registers a function to call whenever reloading changes the
+// policies, e.g. to digest data again with the quantiles now in effect.
+func (r *policyReloader) onChange(f func()) {
+	if r == nil {
+		return
+	}
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	r.changed = append(r.changed, f)
+}
+
+// current returns the policies that are currently loaded.
+func (r *policyReloader) current() *podscaler.Policies {
+	if r == nil {
+		return nil
+	}
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+	return r.policies
+}
+
+// For applies the current policies to the workload.
+func (r *policyReloader) For(meta podscaler.FullMetadata) podscaler.AppliedPolicy {
+	return r.current().For(meta)
+}
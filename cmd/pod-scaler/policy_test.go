@@ -0,0 +1,71 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/sirupsen/logrus"
+
+	podscaler "github.com/openshift/ci-tools/pkg/pod-scaler"
+)
+
+func TestPolicyReloader(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "policies.yaml")
+	reloader := &policyReloader{path: path, logger: logrus.WithField("test", t.Name())}
+	var changes int
+	reloader.onChange(func() { changes++ })
+	write := func(raw string) {
+		if err := os.WriteFile(path, []byte(raw), 0644); err != nil {
+			t.Fatalf("failed to write policies: %v", err)
+		}
+	}
+	names := func() []string {
+		var names []string
+		for _, policy := range reloader.current().Policies {
+			names = append(names, policy.Name)
+		}
+		return names
+	}
+
+	if policies := (*policyReloader)(nil).current(); policies != nil {
+		t.Errorf("expected no policies without a reloader, got %v", policies)
+	}
+	if diff := cmp.Diff(podscaler.AppliedPolicy{}, (*policyReloader)(nil).For(podscaler.FullMetadata{})); diff != "" {
+		t.Errorf("expected no policy to apply without a reloader: %v", diff)
+	}
+
+	write("policies:\n- name: first\n  match:\n    org: org\n")
+	if err := reloader.reload(); err != nil {
+		t.Fatalf("failed to load valid policies: %v", err)
+	}
+	if diff := cmp.Diff([]string{"first"}, names()); diff != "" {
+		t.Errorf("unexpected policies after loading: %v", diff)
+	}
+	if err := reloader.reload(); err != nil {
+		t.Fatalf("failed to reload unchanged policies: %v", err)
+	}
+	if changes != 0 {
+		t.Errorf("expected no changes to be signalled for the initial or unchanged policies, got %d", changes)
+	}
+
+	write("policies:\n- name: second\n  match:\n    repo: repo\n")
+	if err := reloader.reload(); err == nil {
+		t.Error("expected an error when loading invalid policies")
+	}
+	if diff := cmp.Diff([]string{"first"}, names()); diff != "" {
+		t.Errorf("expected previous policies to be kept when loading invalid policies: %v", diff)
+	}
+
+	write("policies:\n- name: second\n  match:\n    org: org\n    repo: repo\n")
+	if err := reloader.reload(); err != nil {
+		t.Fatalf("failed to reload valid policies: %v", err)
+	}
+	if diff := cmp.Diff([]string{"second"}, names()); diff != "" {
+		t.Errorf("unexpected policies after reloading: %v", diff)
+	}
+	if changes != 1 {
+		t.Errorf("expected the change of the policies to be signalled once, got %d", changes)
+	}
+}
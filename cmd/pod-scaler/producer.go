@@ -21,9 +21,6 @@ import (
 )
 
 const (
-	MetricNameCPUUsage         = `container_cpu_usage_seconds_total`
-	MetricNameMemoryWorkingSet = `container_memory_working_set_bytes`
-
 	containerFilter = `{container!="POD",container!=""}`
 
 	// MaxSamplesPerRequest is the maximum number of samples that Prometheus will allow a client to ask for in
@@ -61,8 +58,9 @@ func queriesByMetric() map[string]string {
 		},
 	} {
 		for name, metric := range map[string]string{
-			MetricNameCPUUsage:         `rate(` + MetricNameCPUUsage + containerFilter + `[3m])`,
-			MetricNameMemoryWorkingSet: MetricNameMemoryWorkingSet + containerFilter,
+			podscaler.MetricNameCPUUsage:         `rate(` + podscaler.MetricNameCPUUsage + containerFilter + `[3m])`,
+			podscaler.MetricNameMemoryWorkingSet: podscaler.MetricNameMemoryWorkingSet + containerFilter,
+			podscaler.MetricNameFSUsage:          podscaler.MetricNameFSUsage + containerFilter,
 		} {
 			queries[fmt.Sprintf("%s/%s", info.prefix, name)] = queryFor(metric, info.selector, info.labels)
 		}
@@ -70,7 +68,7 @@ func queriesByMetric() map[string]string {
 	return queries
 }
 
-func produce(clients map[string]prometheusapi.API, dataCache Cache, ignoreLatest time.Duration, once bool) {
+func produce(clients map[string]prometheusapi.API, dataCache Cache, ignoreLatest time.Duration, once bool, compactAfter int) {
 	var execute func(func())
 	if once {
 		execute = func(f func()) {
@@ -89,6 +87,8 @@ func produce(clients map[string]prometheusapi.API, dataCache Cache, ignoreLatest
 				"version": "v2",
 				"metric":  name,
 			})
+			_, err := LastUpdated(dataCache, name)
+			compacted := err == nil
 			cache, err := LoadCache(dataCache, name, logger)
 			if errors.Is(err, notExist{}) {
 				ranges := map[string][]podscaler.TimeRange{}
@@ -105,6 +105,7 @@ func produce(clients map[string]prometheusapi.API, dataCache Cache, ignoreLatest
 				logrus.WithError(err).Error("Failed to load data from storage.")
 				continue
 			}
+			checkpoint := cache.Checkpoint()
 			until := time.Now().Add(-ignoreLatest)
 			q := querier{
 				lock: &sync.RWMutex{},
@@ -138,7 +139,7 @@ func produce(clients map[string]prometheusapi.API, dataCache Cache, ignoreLatest
 				}()
 			}
 			wg.Wait()
-			if err := storeCache(dataCache, name, cache, logger); err != nil {
+			if err := persistCache(dataCache, name, cache, checkpoint, compacted, compactAfter, logger); err != nil {
 				logger.WithError(err).Error("Failed to write cached data.")
 			}
 		}
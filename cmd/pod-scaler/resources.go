@@ -2,8 +2,8 @@ package main
 
 import (
 	"sync"
+	"time"
 
-	"github.com/openhistogram/circonusllhist"
 	"github.com/sirupsen/logrus"
 
 	corev1 "k8s.io/api/core/v1"
@@ -13,16 +13,19 @@ import (
 	podscaler "github.com/openshift/ci-tools/pkg/pod-scaler"
 )
 
-func newResourceServer(loaders map[string][]*cacheReloader, health *pjutil.Health) *resourceServer {
+func newResourceServer(loaders map[string][]*cacheReloader, health *pjutil.Health, weighting podscaler.Weighting, policies *policyReloader) *resourceServer {
 	logger := logrus.WithField("component", "pod-scaler request server")
 	server := &resourceServer{
 		logger:     logger,
 		lock:       sync.RWMutex{},
 		byMetaData: map[podscaler.FullMetadata]corev1.ResourceRequirements{},
+		weighting:  weighting,
+		policies:   policies,
 	}
 	digestAll(loaders, map[string]digester{
-		MetricNameCPUUsage:         server.digestCPU,
-		MetricNameMemoryWorkingSet: server.digestMemory,
+		podscaler.MetricNameCPUUsage:         server.digestCPU,
+		podscaler.MetricNameMemoryWorkingSet: server.digestMemory,
+		podscaler.MetricNameFSUsage:          server.digestEphemeralStorage,
 	}, health, logger)
 
 	return server
@@ -34,13 +37,12 @@ type resourceServer struct {
 	// byMetaData caches resource requirements calculated for the full assortment of
 	// metadata labels.
 	byMetaData map[podscaler.FullMetadata]corev1.ResourceRequirements
+	// weighting determines how historical data is weighted by recency.
+	weighting podscaler.Weighting
+	// policies may override the quantile of usage we recommend.
+	policies *policyReloader
 }
 
-const (
-	// cpuRequestQuantile is the quantile of CPU core usage data to use as the CPU request
-	cpuRequestQuantile = 0.8
-)
-
 func formatCPU() toQuantity {
 	return func(valueAtQuantile float64) *resource.Quantity {
 		return resource.NewMilliQuantity(int64(valueAtQuantile*1000), resource.DecimalSI)
@@ -49,14 +51,9 @@ func formatCPU() toQuantity {
 
 func (s *resourceServer) digestCPU(data *podscaler.CachedQuery) {
 	s.logger.Debugf("Digesting new CPU consumption metrics.")
-	s.digestData(data, cpuRequestQuantile, corev1.ResourceCPU, formatCPU())
+	s.digestData(data, podscaler.CPURequestQuantile, corev1.ResourceCPU, formatCPU())
 }
 
-const (
-	// memRequestQuantile is the quantile of memory usage data to use as the memory request
-	memRequestQuantile = 0.8
-)
-
 func formatMemory() toQuantity {
 	return func(valueAtQuantile float64) *resource.Quantity {
 		return resource.NewQuantity(int64(valueAtQuantile), resource.BinarySI)
@@ -65,7 +62,12 @@ func formatMemory() toQuantity {
 
 func (s *resourceServer) digestMemory(data *podscaler.CachedQuery) {
 	s.logger.Debugf("Digesting new memory consumption metrics.")
-	s.digestData(data, memRequestQuantile, corev1.ResourceMemory, formatMemory())
+	s.digestData(data, podscaler.MemoryRequestQuantile, corev1.ResourceMemory, formatMemory())
+}
+
+func (s *resourceServer) digestEphemeralStorage(data *podscaler.CachedQuery) {
+	s.logger.Debugf("Digesting new ephemeral storage consumption metrics.")
+	s.digestData(data, podscaler.EphemeralStorageRequestQuantile, corev1.ResourceEphemeralStorage, formatMemory())
 }
 
 type toQuantity func(valueAtQuantile float64) (quantity *resource.Quantity)
@@ -73,15 +75,16 @@ type toQuantity func(valueAtQuantile float64) (quantity *resource.Quantity)
 func (s *resourceServer) digestData(data *podscaler.CachedQuery, quantile float64, request corev1.ResourceName, quantity toQuantity) {
 	logger := s.logger.WithField("resource", request)
 	logger.Debugf("Digesting %d identifiers.", len(data.DataByMetaData))
+	now := time.Now()
 	for meta, fingerprintTimes := range data.DataByMetaData {
-		overall := circonusllhist.New()
 		metaLogger := logger.WithField("meta", meta)
 		metaLogger.Tracef("digesting %d fingerprints", len(fingerprintTimes))
-		for _, fingerprintTime := range fingerprintTimes {
-			overall.Merge(data.Data[fingerprintTime.Fingerprint].Histogram())
-		}
+		recommendation := data.Recommend(fingerprintTimes, s.policies.For(meta).Resource(request).QuantileOr(quantile), s.weighting, now)
 		metaLogger.Trace("merged all fingerprints")
-		valueAtQuantile := overall.ValueAtQuantile(quantile)
+		if recommendation.Trend != nil {
+			metaLogger.Tracef("following upward trend of %f per day", recommendation.Trend.Slope)
+		}
+		valueAtQuantile := recommendation.Value
 		metaLogger.Trace("locking for value update")
 		s.lock.Lock()
 		if _, exists := s.byMetaData[meta]; !exists {
@@ -1,6 +1,7 @@
 package main
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
 	"errors"
@@ -9,10 +10,20 @@ import (
 	"os"
 	"path"
 	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
 	"time"
 
 	"cloud.google.com/go/storage"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	s3types "github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"github.com/openhistogram/circonusllhist"
+	"github.com/prometheus/common/model"
 	"github.com/sirupsen/logrus"
+	"go.etcd.io/bbolt"
+	"google.golang.org/api/iterator"
 
 	kerrors "k8s.io/apimachinery/pkg/util/errors"
 	"sigs.k8s.io/prow/pkg/interrupts"
@@ -25,6 +36,8 @@ type Cache interface {
 	loader
 	storer
 	attributeResolver
+	lister
+	remover
 }
 
 // loader closes over how we load cached data
@@ -42,6 +55,17 @@ type attributeResolver interface {
 	lastUpdated(ctx context.Context, name string) (time.Time, error)
 }
 
+// lister closes over how we list cached data, returning the sorted names of
+// all items with the prefix
+type lister interface {
+	list(ctx context.Context, prefix string) ([]string, error)
+}
+
+// remover closes over how we remove cached data
+type remover interface {
+	remove(ctx context.Context, name string) error
+}
+
 type BucketCache struct {
 	Bucket *storage.BucketHandle
 }
@@ -71,6 +95,31 @@ func (b *BucketCache) lastUpdated(ctx context.Context, name string) (time.Time,
 	return attrs.Updated, nil
 }
 
+func (b *BucketCache) list(ctx context.Context, prefix string) ([]string, error) {
+	var names []string
+	objects := b.Bucket.Objects(ctx, &storage.Query{Prefix: prefix})
+	for {
+		attrs, err := objects.Next()
+		if errors.Is(err, iterator.Done) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("could not list Cache: %w", err)
+		}
+		names = append(names, attrs.Name)
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+func (b *BucketCache) remove(ctx context.Context, name string) error {
+	err := b.Bucket.Object(name).Delete(ctx)
+	if errors.Is(err, storage.ErrObjectNotExist) {
+		err = notExist{wrapped: err}
+	}
+	return err
+}
+
 type LocalCache struct {
 	Dir string
 }
@@ -101,6 +150,276 @@ func (l *LocalCache) lastUpdated(_ context.Context, name string) (time.Time, err
 	return info.ModTime(), nil
 }
 
+func (l *LocalCache) list(_ context.Context, prefix string) ([]string, error) {
+	dir, base := path.Split(prefix)
+	entries, err := os.ReadDir(path.Join(l.Dir, dir))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("could not list Cache: %w", err)
+	}
+	var names []string
+	for _, entry := range entries {
+		if !strings.HasPrefix(entry.Name(), base) {
+			continue
+		}
+		if !entry.IsDir() {
+			names = append(names, path.Join(dir, entry.Name()))
+			continue
+		}
+		// we only ever nest one level deeper than the prefix
+		children, err := os.ReadDir(path.Join(l.Dir, dir, entry.Name()))
+		if err != nil {
+			return nil, fmt.Errorf("could not list Cache: %w", err)
+		}
+		for _, child := range children {
+			names = append(names, path.Join(dir, entry.Name(), child.Name()))
+		}
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+func (l *LocalCache) remove(_ context.Context, name string) error {
+	err := os.Remove(path.Join(l.Dir, name))
+	if os.IsNotExist(err) {
+		err = notExist{wrapped: err}
+	}
+	return err
+}
+
+// s3Client is the subset of the S3 API we use to interact with cached data
+type s3Client interface {
+	GetObject(ctx context.Context, params *s3.GetObjectInput, optFns ...func(*s3.Options)) (*s3.GetObjectOutput, error)
+	PutObject(ctx context.Context, params *s3.PutObjectInput, optFns ...func(*s3.Options)) (*s3.PutObjectOutput, error)
+	HeadObject(ctx context.Context, params *s3.HeadObjectInput, optFns ...func(*s3.Options)) (*s3.HeadObjectOutput, error)
+	ListObjectsV2(ctx context.Context, params *s3.ListObjectsV2Input, optFns ...func(*s3.Options)) (*s3.ListObjectsV2Output, error)
+	DeleteObject(ctx context.Context, params *s3.DeleteObjectInput, optFns ...func(*s3.Options)) (*s3.DeleteObjectOutput, error)
+}
+
+// S3Cache stores cached data in a bucket of S3 or of any S3-compatible object storage
+type S3Cache struct {
+	Client s3Client
+	Bucket string
+}
+
+var _ Cache = &S3Cache{}
+
+func (c *S3Cache) load(ctx context.Context, name string) (io.ReadCloser, error) {
+	output, err := c.Client.GetObject(ctx, &s3.GetObjectInput{Bucket: aws.String(c.Bucket), Key: aws.String(name)})
+	if err != nil {
+		noSuchKey := &s3types.NoSuchKey{}
+		if errors.As(err, &noSuchKey) {
+			err = notExist{wrapped: err}
+		}
+		return nil, err
+	}
+	return output.Body, nil
+}
+
+func (c *S3Cache) store(ctx context.Context, name string) (io.WriteCloser, error) {
+	return &s3Writer{ctx: ctx, cache: c, name: name}, nil
+}
+
+// s3Writer buffers data, as S3 needs to know the size of an object before it is uploaded
+type s3Writer struct {
+	bytes.Buffer
+	ctx   context.Context
+	cache *S3Cache
+	name  string
+}
+
+func (w *s3Writer) Close() error {
+	_, err := w.cache.Client.PutObject(w.ctx, &s3.PutObjectInput{
+		Bucket: aws.String(w.cache.Bucket),
+		Key:    aws.String(w.name),
+		Body:   bytes.NewReader(w.Bytes()),
+	})
+	return err
+}
+
+func (c *S3Cache) lastUpdated(ctx context.Context, name string) (time.Time, error) {
+	output, err := c.Client.HeadObject(ctx, &s3.HeadObjectInput{Bucket: aws.String(c.Bucket), Key: aws.String(name)})
+	if err != nil {
+		return time.Time{}, fmt.Errorf("could not query Cache for attributes: %w", err)
+	}
+	return aws.ToTime(output.LastModified), nil
+}
+
+func (c *S3Cache) list(ctx context.Context, prefix string) ([]string, error) {
+	var names []string
+	paginator := s3.NewListObjectsV2Paginator(c.Client, &s3.ListObjectsV2Input{Bucket: aws.String(c.Bucket), Prefix: aws.String(prefix)})
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("could not list Cache: %w", err)
+		}
+		for _, object := range page.Contents {
+			names = append(names, aws.ToString(object.Key))
+		}
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+func (c *S3Cache) remove(ctx context.Context, name string) error {
+	// S3 does not report deletions of objects that do not exist
+	_, err := c.Client.DeleteObject(ctx, &s3.DeleteObjectInput{Bucket: aws.String(c.Bucket), Key: aws.String(name)})
+	return err
+}
+
+// BoltCache stores cached data in an embedded bbolt database file, which lets a
+// producer and consumers that share a volume run without any object storage.
+// The database is opened for each operation, as bbolt locks the file for as long
+// as it is open and the producer and consumers run in separate processes.
+type BoltCache struct {
+	Path string
+}
+
+var _ Cache = &BoltCache{}
+
+var (
+	// boltObjectsBucket holds the cached data by name
+	boltObjectsBucket = []byte("objects")
+	// boltUpdatedBucket holds the time at which each object was last stored
+	boltUpdatedBucket = []byte("updated")
+)
+
+// boltTimeout bounds how long we wait for another process to release the database
+const boltTimeout = time.Minute
+
+// view runs the function in a read-only transaction, reporting a database that
+// was not yet written to as nonexistent
+func (b *BoltCache) view(fn func(objects, updated *bbolt.Bucket) error) error {
+	if _, err := os.Stat(b.Path); err != nil {
+		if os.IsNotExist(err) {
+			err = notExist{wrapped: err}
+		}
+		return err
+	}
+	db, err := bbolt.Open(b.Path, 0644, &bbolt.Options{Timeout: boltTimeout, ReadOnly: true})
+	if err != nil {
+		return fmt.Errorf("could not open Cache: %w", err)
+	}
+	defer db.Close()
+	return db.View(func(tx *bbolt.Tx) error {
+		objects, updated := tx.Bucket(boltObjectsBucket), tx.Bucket(boltUpdatedBucket)
+		if objects == nil || updated == nil {
+			return notExist{wrapped: fmt.Errorf("%s holds no cached data", b.Path)}
+		}
+		return fn(objects, updated)
+	})
+}
+
+// update runs the function in a read-write transaction, creating the database as necessary
+func (b *BoltCache) update(fn func(objects, updated *bbolt.Bucket) error) error {
+	if err := os.MkdirAll(filepath.Dir(b.Path), 0777); err != nil {
+		return err
+	}
+	db, err := bbolt.Open(b.Path, 0644, &bbolt.Options{Timeout: boltTimeout})
+	if err != nil {
+		return fmt.Errorf("could not open Cache: %w", err)
+	}
+	defer db.Close()
+	return db.Update(func(tx *bbolt.Tx) error {
+		objects, err := tx.CreateBucketIfNotExists(boltObjectsBucket)
+		if err != nil {
+			return err
+		}
+		updated, err := tx.CreateBucketIfNotExists(boltUpdatedBucket)
+		if err != nil {
+			return err
+		}
+		return fn(objects, updated)
+	})
+}
+
+func (b *BoltCache) load(_ context.Context, name string) (io.ReadCloser, error) {
+	var data []byte
+	if err := b.view(func(objects, _ *bbolt.Bucket) error {
+		value := objects.Get([]byte(name))
+		if value == nil {
+			return notExist{wrapped: fmt.Errorf("object %s does not exist", name)}
+		}
+		// values are only valid for the lifetime of the transaction
+		data = bytes.Clone(value)
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+	return io.NopCloser(bytes.NewReader(data)), nil
+}
+
+func (b *BoltCache) store(_ context.Context, name string) (io.WriteCloser, error) {
+	return &boltWriter{cache: b, name: name}, nil
+}
+
+// boltWriter buffers data, as it is written to the database in one transaction
+type boltWriter struct {
+	bytes.Buffer
+	cache *BoltCache
+	name  string
+}
+
+func (w *boltWriter) Close() error {
+	stored, err := time.Now().MarshalBinary()
+	if err != nil {
+		return err
+	}
+	return w.cache.update(func(objects, updated *bbolt.Bucket) error {
+		if err := objects.Put([]byte(w.name), w.Bytes()); err != nil {
+			return err
+		}
+		return updated.Put([]byte(w.name), stored)
+	})
+}
+
+func (b *BoltCache) lastUpdated(_ context.Context, name string) (time.Time, error) {
+	var stored time.Time
+	if err := b.view(func(_, updated *bbolt.Bucket) error {
+		value := updated.Get([]byte(name))
+		if value == nil {
+			return notExist{wrapped: fmt.Errorf("object %s does not exist", name)}
+		}
+		return stored.UnmarshalBinary(value)
+	}); err != nil {
+		return time.Time{}, fmt.Errorf("could not query Cache for attributes: %w", err)
+	}
+	return stored, nil
+}
+
+func (b *BoltCache) list(_ context.Context, prefix string) ([]string, error) {
+	var names []string
+	err := b.view(func(objects, _ *bbolt.Bucket) error {
+		// keys are iterated in byte order, so they are already sorted
+		cursor := objects.Cursor()
+		for key, _ := cursor.Seek([]byte(prefix)); key != nil && bytes.HasPrefix(key, []byte(prefix)); key, _ = cursor.Next() {
+			names = append(names, string(key))
+		}
+		return nil
+	})
+	if errors.Is(err, notExist{}) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("could not list Cache: %w", err)
+	}
+	return names, nil
+}
+
+func (b *BoltCache) remove(_ context.Context, name string) error {
+	return b.update(func(objects, updated *bbolt.Bucket) error {
+		if objects.Get([]byte(name)) == nil {
+			return notExist{wrapped: fmt.Errorf("object %s does not exist", name)}
+		}
+		if err := objects.Delete([]byte(name)); err != nil {
+			return err
+		}
+		return updated.Delete([]byte(name))
+	})
+}
+
 // notExist closes over the different ways in which storage libraries may expose a nonexistent file
 type notExist struct {
 	wrapped error
@@ -119,8 +438,33 @@ func (e notExist) Unwrap() error {
 	return e.wrapped
 }
 
-// LoadCache loads cached query data from the given storage loader.
-func LoadCache(loader loader, metricName string, logger *logrus.Entry) (*podscaler.CachedQuery, error) {
+// LoadCache loads cached query data from the given storage, along with any deltas
+// that were appended to it since it was last compacted.
+func LoadCache(cache Cache, metricName string, logger *logrus.Entry) (*podscaler.CachedQuery, error) {
+	data, loadErr := loadCache(cache, metricName, logger)
+	if loadErr != nil && !errors.Is(loadErr, notExist{}) {
+		return nil, loadErr
+	}
+	deltas, err := listDeltas(cache, metricName)
+	if err != nil {
+		return nil, err
+	}
+	if data == nil {
+		if len(deltas) == 0 {
+			return nil, loadErr
+		}
+		data = &podscaler.CachedQuery{
+			RangesByCluster: map[string][]podscaler.TimeRange{},
+			Data:            map[model.Fingerprint]*circonusllhist.HistogramWithoutLookups{},
+			DataByMetaData:  map[podscaler.FullMetadata][]podscaler.FingerprintTime{},
+		}
+	}
+	data, _ = applyDeltas(cache, data, deltas, logger)
+	return data, nil
+}
+
+// loadCache loads compacted query data from the given storage loader.
+func loadCache(loader loader, metricName string, logger *logrus.Entry) (*podscaler.CachedQuery, error) {
 	readStart := time.Now()
 	logger.Info("Reading Prometheus data from Cache.")
 	logger.Debug("Loading Prometheus data from storage.")
@@ -147,9 +491,13 @@ func LoadCache(loader loader, metricName string, logger *logrus.Entry) (*podscal
 }
 
 func loadFrom(loader loader, metricName string) ([]byte, error) {
+	return loadObject(loader, podscaler.CachedQueryFile(metricName))
+}
+
+func loadObject(loader loader, name string) ([]byte, error) {
 	ctx, cancel := context.WithTimeout(interrupts.Context(), 15*time.Minute)
 	defer func() { cancel() }()
-	reader, err := loader.load(ctx, metricName+".json")
+	reader, err := loader.load(ctx, name)
 	if err != nil && !errors.Is(err, context.DeadlineExceeded) {
 		return nil, err
 	}
@@ -189,9 +537,13 @@ func storeCache(storer storer, metricName string, data *podscaler.CachedQuery, l
 }
 
 func storeTo(storer storer, metricName string, data []byte) error {
+	return storeObject(storer, podscaler.CachedQueryFile(metricName), data)
+}
+
+func storeObject(storer storer, name string, data []byte) error {
 	ctx, cancel := context.WithTimeout(interrupts.Context(), 30*time.Minute)
 	defer func() { cancel() }()
-	writer, err := storer.store(ctx, metricName+".json")
+	writer, err := storer.store(ctx, name)
 	if err != nil {
 		return fmt.Errorf("could open Cache for writing: %w", err)
 	}
@@ -207,5 +559,115 @@ func storeTo(storer storer, metricName string, data []byte) error {
 
 // LastUpdated determines the time at which the Cache for this metric was last updated
 func LastUpdated(resolver attributeResolver, metricName string) (time.Time, error) {
-	return resolver.lastUpdated(interrupts.Context(), metricName+".json")
+	return resolver.lastUpdated(interrupts.Context(), podscaler.CachedQueryFile(metricName))
+}
+
+// Deltas hold the data recorded in one producer cycle. They are stored next to
+// the compacted data for a metric, named by the time at which they were stored,
+// so that consumers can apply them in order without reloading the full dataset.
+const deltaSuffix = ".deltas"
+
+func deltaPrefix(metricName string) string {
+	return metricName + deltaSuffix + "/"
+}
+
+func deltaName(metricName string, stored time.Time) string {
+	return fmt.Sprintf("%s%020d.json", deltaPrefix(metricName), stored.UnixNano())
+}
+
+// delta identifies a stored delta
+type delta struct {
+	name   string
+	stored time.Time
+}
+
+// listDeltas lists the deltas stored for the metric, in the order they were stored.
+func listDeltas(lister lister, metricName string) ([]delta, error) {
+	names, err := lister.list(interrupts.Context(), deltaPrefix(metricName))
+	if err != nil {
+		return nil, fmt.Errorf("could not list deltas: %w", err)
+	}
+	var deltas []delta
+	for _, name := range names {
+		nanos, err := strconv.ParseInt(strings.TrimSuffix(strings.TrimPrefix(name, deltaPrefix(metricName)), ".json"), 10, 64)
+		if err != nil {
+			// not a delta we stored, ignore it
+			continue
+		}
+		deltas = append(deltas, delta{name: name, stored: time.Unix(0, nanos)})
+	}
+	return deltas, nil
+}
+
+// applyDeltas applies the deltas not yet included in the data, returning the
+// updated data and the time at which the latest delta applied was stored.
+// Deltas that can't be loaded are skipped, as they are removed when they are
+// compacted and will be loaded as part of the compacted data instead.
+func applyDeltas(loader loader, data *podscaler.CachedQuery, deltas []delta, logger *logrus.Entry) (*podscaler.CachedQuery, time.Time) {
+	var applied int
+	var latest time.Time
+	for _, d := range deltas {
+		if data.IncludesDelta(d.stored) {
+			continue
+		}
+		raw, err := loadObject(loader, d.name)
+		if err != nil {
+			logger.WithError(err).WithField("delta", d.name).Debug("Could not load delta, skipping it.")
+			continue
+		}
+		var update podscaler.CachedQuery
+		if err := json.Unmarshal(raw, &update); err != nil {
+			logger.WithError(err).WithField("delta", d.name).Warn("Could not unmarshal delta, skipping it.")
+			continue
+		}
+		data = data.WithDelta(&update)
+		latest = d.stored
+		applied++
+	}
+	if applied > 0 {
+		// the producer prunes data when compacting it, so we do the same to
+		// get the same data as if we had loaded it after compaction
+		data.Prune()
+		logger.Infof("Applied %d deltas, now holding %d distributions for %d identifiers.", applied, len(data.Data), len(data.DataByMetaData))
+	}
+	return data, latest
+}
+
+// persistCache stores the data recorded since the checkpoint as a delta, unless
+// the data needs to be compacted, in which case the full data is stored and the
+// deltas it includes are removed. Data is compacted when there are compactAfter
+// deltas or more, when there is no compacted data yet or when compactAfter is 0.
+func persistCache(cache Cache, metricName string, data *podscaler.CachedQuery, checkpoint podscaler.Checkpoint, compacted bool, compactAfter int, logger *logrus.Entry) error {
+	deltas, err := listDeltas(cache, metricName)
+	if err != nil {
+		logger.WithError(err).Warn("Could not list deltas, compacting data.")
+	}
+	if err == nil && compacted && compactAfter > 0 && len(deltas) < compactAfter {
+		raw, err := json.Marshal(data.Delta(checkpoint))
+		if err != nil {
+			return fmt.Errorf("could not marshal delta: %w", err)
+		}
+		name := deltaName(metricName, time.Now())
+		if err := storeObject(cache, name, raw); err != nil {
+			return fmt.Errorf("could not write delta: %w", err)
+		}
+		logger.WithField("delta", name).Info("Appended delta to Cache.")
+		return nil
+	}
+
+	if len(deltas) > 0 {
+		data.Compacted(deltas[len(deltas)-1].stored)
+	}
+	if err := storeCache(cache, metricName, data, logger); err != nil {
+		return err
+	}
+	var errs []error
+	for _, d := range deltas {
+		ctx, cancel := context.WithTimeout(interrupts.Context(), time.Minute)
+		if err := cache.remove(ctx, d.name); err != nil && !errors.Is(err, notExist{}) {
+			errs = append(errs, fmt.Errorf("could not remove compacted delta %s: %w", d.name, err))
+		}
+		cancel()
+	}
+	return kerrors.NewAggregate(errs)
 }
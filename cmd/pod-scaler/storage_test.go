@@ -0,0 +1,208 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/openhistogram/circonusllhist"
+	"github.com/prometheus/common/model"
+	"github.com/sirupsen/logrus"
+
+	podscaler "github.com/openshift/ci-tools/pkg/pod-scaler"
+)
+
+func TestPersistCache(t *testing.T) {
+	for _, testCase := range []struct {
+		name  string
+		cache func(dir string) Cache
+	}{
+		{
+			name:  "local directory",
+			cache: func(dir string) Cache { return &LocalCache{Dir: dir} },
+		},
+		{
+			name:  "bbolt database",
+			cache: func(dir string) Cache { return &BoltCache{Path: filepath.Join(dir, "cache.db")} },
+		},
+	} {
+		t.Run(testCase.name, func(t *testing.T) {
+			testPersistCache(t, testCase.cache(t.TempDir()))
+		})
+	}
+}
+
+func testPersistCache(t *testing.T, cache Cache) {
+	const metricName = "steps/metric"
+	logger := logrus.WithField("test", t.Name())
+	now := time.Now()
+	histogram := func(value float64) *circonusllhist.HistogramWithoutLookups {
+		h := circonusllhist.New(circonusllhist.NoLookup())
+		if err := h.RecordValue(value); err != nil {
+			t.Fatalf("failed to record value: %v", err)
+		}
+		return circonusllhist.NewHistogramWithoutLookups(h)
+	}
+	record := func(data *podscaler.CachedQuery, fingerprint model.Fingerprint) {
+		data.RangesByCluster["cluster"] = append(data.RangesByCluster["cluster"], podscaler.TimeRange{Start: now.Add(time.Duration(fingerprint) * time.Minute), End: now.Add(time.Duration(fingerprint+1) * time.Minute)})
+		data.Data[fingerprint] = histogram(float64(fingerprint))
+		meta := podscaler.FullMetadata{Target: "target"}
+		data.DataByMetaData[meta] = append(data.DataByMetaData[meta], podscaler.FingerprintTime{Fingerprint: fingerprint, Added: now})
+	}
+	cycle := func(fingerprint model.Fingerprint, compactAfter int) {
+		_, err := LastUpdated(cache, metricName)
+		compacted := err == nil
+		data, err := LoadCache(cache, metricName, logger)
+		if errors.Is(err, notExist{}) {
+			data = &podscaler.CachedQuery{
+				Query:           "query",
+				RangesByCluster: map[string][]podscaler.TimeRange{},
+				Data:            map[model.Fingerprint]*circonusllhist.HistogramWithoutLookups{},
+				DataByMetaData:  map[podscaler.FullMetadata][]podscaler.FingerprintTime{},
+			}
+		} else if err != nil {
+			t.Fatalf("failed to load cache: %v", err)
+		}
+		checkpoint := data.Checkpoint()
+		record(data, fingerprint)
+		if err := persistCache(cache, metricName, data, checkpoint, compacted, compactAfter, logger); err != nil {
+			t.Fatalf("failed to persist cache: %v", err)
+		}
+	}
+	countDeltas := func() int {
+		deltas, err := listDeltas(cache, metricName)
+		if err != nil {
+			t.Fatalf("failed to list deltas: %v", err)
+		}
+		return len(deltas)
+	}
+	fingerprints := func() []model.Fingerprint {
+		data, err := LoadCache(cache, metricName, logger)
+		if err != nil {
+			t.Fatalf("failed to load cache: %v", err)
+		}
+		var fingerprints []model.Fingerprint
+		for _, fingerprintTime := range data.DataByMetaData[podscaler.FullMetadata{Target: "target"}] {
+			fingerprints = append(fingerprints, fingerprintTime.Fingerprint)
+			if _, ok := data.Data[fingerprintTime.Fingerprint]; !ok {
+				t.Errorf("no data for fingerprint %d", fingerprintTime.Fingerprint)
+			}
+		}
+		return fingerprints
+	}
+
+	// the first cycle always writes the full data, as there is nothing to append to
+	cycle(1, 2)
+	if deltas := countDeltas(); deltas != 0 {
+		t.Errorf("expected no deltas after the first cycle, got %d", deltas)
+	}
+	baseUpdated, err := LastUpdated(cache, metricName)
+	if err != nil {
+		t.Fatalf("expected compacted data to exist: %v", err)
+	}
+	cycle(2, 2)
+	cycle(3, 2)
+	if deltas := countDeltas(); deltas != 2 {
+		t.Errorf("expected two deltas to be appended, got %d", deltas)
+	}
+	if updated, err := LastUpdated(cache, metricName); err != nil || !updated.Equal(baseUpdated) {
+		t.Errorf("expected compacted data not to be rewritten when appending deltas, got %v (err: %v)", updated, err)
+	}
+	if diff := cmp.Diff([]model.Fingerprint{1, 2, 3}, fingerprints()); diff != "" {
+		t.Errorf("unexpected data with deltas applied: %v", diff)
+	}
+
+	// once enough deltas exist, they are compacted into the data
+	cycle(4, 2)
+	if deltas := countDeltas(); deltas != 0 {
+		t.Errorf("expected deltas to be removed after compaction, got %d", deltas)
+	}
+	if diff := cmp.Diff([]model.Fingerprint{1, 2, 3, 4}, fingerprints()); diff != "" {
+		t.Errorf("unexpected data after compaction: %v", diff)
+	}
+	compacted, err := loadCache(cache, metricName, logger)
+	if err != nil {
+		t.Fatalf("failed to load compacted data: %v", err)
+	}
+	if compacted.DeltasUntil == nil {
+		t.Error("expected compacted data to record the deltas it includes")
+	}
+
+	// deltas left over from compaction are not applied again
+	leftover := &podscaler.CachedQuery{Data: map[model.Fingerprint]*circonusllhist.HistogramWithoutLookups{5: histogram(5)}, DataByMetaData: map[podscaler.FullMetadata][]podscaler.FingerprintTime{{Target: "target"}: {{Fingerprint: 5}}}}
+	raw, err := json.Marshal(leftover)
+	if err != nil {
+		t.Fatalf("failed to marshal delta: %v", err)
+	}
+	if err := storeObject(cache, deltaName(metricName, *compacted.DeltasUntil), raw); err != nil {
+		t.Fatalf("failed to store delta: %v", err)
+	}
+	if diff := cmp.Diff([]model.Fingerprint{1, 2, 3, 4}, fingerprints()); diff != "" {
+		t.Errorf("unexpected data with compacted delta: %v", diff)
+	}
+
+	// rewriting the data every cycle does not append deltas
+	if err := cache.remove(context.Background(), deltaName(metricName, *compacted.DeltasUntil)); err != nil {
+		t.Fatalf("failed to remove delta: %v", err)
+	}
+	cycle(5, 0)
+	if deltas := countDeltas(); deltas != 0 {
+		t.Errorf("expected no deltas when compacting every cycle, got %d", deltas)
+	}
+	if diff := cmp.Diff([]model.Fingerprint{1, 2, 3, 4, 5}, fingerprints()); diff != "" {
+		t.Errorf("unexpected data after rewriting: %v", diff)
+	}
+}
+
+func TestBoltCache(t *testing.T) {
+	ctx := context.Background()
+	cache := &BoltCache{Path: filepath.Join(t.TempDir(), "nested", "cache.db")}
+
+	// nothing exists before the database is first written to
+	if _, err := cache.load(ctx, "steps/metric.json"); !errors.Is(err, notExist{}) {
+		t.Errorf("expected loading from a missing database to report a nonexistent object, got %v", err)
+	}
+	if _, err := LastUpdated(cache, "steps/metric"); !errors.Is(err, notExist{}) {
+		t.Errorf("expected querying a missing database to report a nonexistent object, got %v", err)
+	}
+	if names, err := cache.list(ctx, "steps/"); err != nil || len(names) != 0 {
+		t.Errorf("expected listing a missing database to return nothing, got %v (err: %v)", names, err)
+	}
+
+	for _, name := range []string{"steps/metric.deltas/2.json", "steps/metric.json", "steps/metric.deltas/1.json", "pods/metric.json"} {
+		if err := storeObject(cache, name, []byte(name)); err != nil {
+			t.Fatalf("failed to store %s: %v", name, err)
+		}
+	}
+	data, err := loadObject(cache, "steps/metric.json")
+	if err != nil {
+		t.Fatalf("failed to load object: %v", err)
+	}
+	if diff := cmp.Diff("steps/metric.json", string(data)); diff != "" {
+		t.Errorf("unexpected data: %v", diff)
+	}
+	if _, err := cache.load(ctx, "steps/other.json"); !errors.Is(err, notExist{}) {
+		t.Errorf("expected loading a missing object to report it as nonexistent, got %v", err)
+	}
+	names, err := cache.list(ctx, "steps/metric.deltas/")
+	if err != nil {
+		t.Fatalf("failed to list objects: %v", err)
+	}
+	if diff := cmp.Diff([]string{"steps/metric.deltas/1.json", "steps/metric.deltas/2.json"}, names); diff != "" {
+		t.Errorf("unexpected names: %v", diff)
+	}
+
+	if err := cache.remove(ctx, "steps/metric.deltas/1.json"); err != nil {
+		t.Fatalf("failed to remove object: %v", err)
+	}
+	if err := cache.remove(ctx, "steps/metric.deltas/1.json"); !errors.Is(err, notExist{}) {
+		t.Errorf("expected removing a missing object to report it as nonexistent, got %v", err)
+	}
+	if _, err := cache.lastUpdated(ctx, "steps/metric.deltas/1.json"); !errors.Is(err, notExist{}) {
+		t.Errorf("expected the removed object to have no update time, got %v", err)
+	}
+}
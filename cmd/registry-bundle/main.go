@@ -0,0 +1,81 @@
+// registry-bundle creates a signed bundle of the step registry and ci-operator
+// configurations that ci-operator and the configresolver can consume instead
+// of a release repository checkout or a live configresolver
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/openshift/ci-tools/pkg/load"
+	"github.com/openshift/ci-tools/pkg/registry/bundle"
+)
+
+type options struct {
+	registry     string
+	configDir    string
+	signingKey   string
+	output       string
+	flatRegistry bool
+
+	federatedRegistries load.FederatedRegistriesFlag
+}
+
+func (o *options) Validate() error {
+	if o.registry == "" {
+		return errors.New("--registry is required")
+	}
+	if o.signingKey == "" {
+		return errors.New("--signing-key is required")
+	}
+	if o.output == "" {
+		return errors.New("--output is required")
+	}
+	return nil
+}
+
+func gatherOptions() (options, error) {
+	o := options{}
+	fs := flag.NewFlagSet(os.Args[0], flag.ExitOnError)
+	fs.StringVar(&o.registry, "registry", "", "Path to the step registry directory.")
+	fs.StringVar(&o.configDir, "config-dir", "", "Path to the ci-operator configuration directory. Configurations are not bundled if unset.")
+	fs.StringVar(&o.signingKey, "signing-key", "", "Path to the PEM-encoded ed25519 private key the bundle is signed with.")
+	fs.StringVar(&o.output, "output", "", "Path to write the bundle to.")
+	fs.BoolVar(&o.flatRegistry, "flat-registry", false, "Disable directory structure based registry validation")
+	fs.Var(&o.federatedRegistries, "federated-registry", load.FederatedRegistriesUsage)
+	if err := fs.Parse(os.Args[1:]); err != nil {
+		return options{}, fmt.Errorf("could not parse input: %w", err)
+	}
+	return o, nil
+}
+
+func main() {
+	o, err := gatherOptions()
+	if err != nil {
+		logrus.WithError(err).Fatal("failed to gather options")
+	}
+	if err := o.Validate(); err != nil {
+		logrus.WithError(err).Fatal("invalid options")
+	}
+
+	key, err := bundle.LoadPrivateKey(o.signingKey)
+	if err != nil {
+		logrus.WithError(err).Fatal("failed to load signing key")
+	}
+	var flags load.RegistryFlag
+	if o.flatRegistry {
+		flags |= load.RegistryFlat
+	}
+	b, err := bundle.Load(o.registry, o.configDir, flags, o.federatedRegistries)
+	if err != nil {
+		logrus.WithError(err).Fatal("failed to load bundle content")
+	}
+	if err := bundle.WriteFile(o.output, b, key); err != nil {
+		logrus.WithError(err).Fatal("failed to write bundle")
+	}
+	logrus.WithFields(logrus.Fields{"output": o.output, "version": b.RegistryVersion}).Info("Wrote registry bundle")
+}
@@ -40,10 +40,17 @@ var (
 		},
 		[]string{"workload_name", "workload_type", "configured_amount", "determined_amount", "resource_type"},
 	)
+	podScalerShadowMutationCounter = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "pod_scaler_admission_shadow_mutation",
+			Help: "number of times pod-scaler in shadow mode would have changed the resources of a workload, sorted by type, resource, requirement and direction",
+		},
+		[]string{"workload_type", "resource_type", "requirement", "direction"},
+	)
 )
 
 func init() {
-	prometheus.MustRegister(errorRate, podScalerHighResourceCounter)
+	prometheus.MustRegister(errorRate, podScalerHighResourceCounter, podScalerShadowMutationCounter)
 }
 
 type options struct {
@@ -115,6 +122,31 @@ func validatePodScalerRequest(request *results.PodScalerRequest) error {
 	return nil
 }
 
+func validatePodScalerShadowRequest(request *results.PodScalerShadowRequest) error {
+	if request.WorkloadName == "" {
+		return fmt.Errorf("workload_name field in request is empty")
+	}
+	if request.WorkloadType == "" {
+		return fmt.Errorf("workload_type field in request is empty")
+	}
+	if request.ResourceType == "" {
+		return fmt.Errorf("resource_type field in request is empty")
+	}
+	if request.Requirement == "" {
+		return fmt.Errorf("requirement field in request is empty")
+	}
+	switch request.Direction {
+	case "added", "removed", "increased", "decreased":
+	default:
+		return fmt.Errorf("direction field in request must be one of added, removed, increased or decreased, not %q", request.Direction)
+	}
+	// amounts are empty when pod-scaler would have set or removed a requirement
+	if request.ConfiguredAmount == "" && request.DeterminedAmount == "" {
+		return fmt.Errorf("configured_amount and determined_amount fields in request are empty")
+	}
+	return nil
+}
+
 func handleError(w http.ResponseWriter, err error) {
 	w.WriteHeader(http.StatusBadRequest)
 	fmt.Fprint(w, html.EscapeString(err.Error()))
@@ -142,6 +174,27 @@ func recordHighResource(request *results.PodScalerRequest) {
 	podScalerHighResourceCounter.With(labels).Inc()
 }
 
+// recordShadowMutation counts the mutation on bounded labels only, as workload names and
+// amounts are unbounded; the details of each mutation are logged instead.
+func recordShadowMutation(request *results.PodScalerShadowRequest) {
+	labels := prometheus.Labels{
+		"workload_type": request.WorkloadType,
+		"resource_type": request.ResourceType,
+		"requirement":   request.Requirement,
+		"direction":     request.Direction,
+	}
+	podScalerShadowMutationCounter.With(labels).Inc()
+	log.WithFields(log.Fields{
+		"workload_name":     request.WorkloadName,
+		"workload_type":     request.WorkloadType,
+		"resource_type":     request.ResourceType,
+		"requirement":       request.Requirement,
+		"direction":         request.Direction,
+		"configured_amount": request.ConfiguredAmount,
+		"determined_amount": request.DeterminedAmount,
+	}).Info("Pod-scaler would have mutated workload")
+}
+
 type validator interface {
 	Validate(username, password string) bool
 }
@@ -212,6 +265,36 @@ func handlePodScalerResult() http.HandlerFunc {
 	}
 }
 
+func handlePodScalerShadowResult() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		bytes, err := io.ReadAll(r.Body)
+		if err != nil {
+			handleError(w, fmt.Errorf("unable to read pod-scaler shadow request body: %w", err))
+			return
+		}
+
+		report := &results.PodScalerShadowReport{}
+		if err = json.Unmarshal(bytes, report); err != nil {
+			handleError(w, fmt.Errorf("unable to decode pod-scaler shadow request body: %w", err))
+			return
+		}
+
+		for i := range report.Mutations {
+			if err := validatePodScalerShadowRequest(&report.Mutations[i]); err != nil {
+				handleError(w, fmt.Errorf("mutations[%d]: %w", i, err))
+				return
+			}
+		}
+
+		for i := range report.Mutations {
+			recordShadowMutation(&report.Mutations[i])
+		}
+		w.WriteHeader(http.StatusOK)
+		log.WithFields(log.Fields{"mutations": len(report.Mutations), "duration": time.Since(start).String()}).Info("Pod-scaler shadow request processed")
+	}
+}
+
 func main() {
 	o, err := gatherOptions()
 	if err != nil {
@@ -232,6 +315,7 @@ func main() {
 
 	http.Handle("/result", loginHandler(validator, handleCIOperatorResult()))
 	http.Handle("/pod-scaler", loginHandler(validator, handlePodScalerResult()))
+	http.Handle("/pod-scaler/shadow", loginHandler(validator, handlePodScalerShadowResult()))
 
 	metrics.ExposeMetrics("result-aggregator", prowConfig.PushGateway{}, flagutil.DefaultMetricsPort)
 
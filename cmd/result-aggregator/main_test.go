@@ -234,3 +234,80 @@ func TestValidatePodScalerRequest(t *testing.T) {
 		})
 	}
 }
+
+func TestValidatePodScalerShadowRequest(t *testing.T) {
+	var testCases = []struct {
+		name     string
+		request  *results.PodScalerShadowRequest
+		expected error
+	}{
+		{
+			name: "everything ok",
+			request: &results.PodScalerShadowRequest{
+				WorkloadName:     "name",
+				WorkloadType:     "build",
+				ResourceType:     "memory",
+				Requirement:      "request",
+				Direction:        "increased",
+				ConfiguredAmount: "100",
+				DeterminedAmount: "400",
+			},
+			expected: nil,
+		},
+		{
+			name: "requirement that would have been set",
+			request: &results.PodScalerShadowRequest{
+				WorkloadName:     "name",
+				WorkloadType:     "step",
+				ResourceType:     "cpu",
+				Requirement:      "request",
+				Direction:        "added",
+				DeterminedAmount: "400m",
+			},
+			expected: nil,
+		},
+		{
+			name: "empty requirement",
+			request: &results.PodScalerShadowRequest{
+				WorkloadName:     "name",
+				WorkloadType:     "build",
+				ResourceType:     "memory",
+				ConfiguredAmount: "100",
+				DeterminedAmount: "400",
+			},
+			expected: fmt.Errorf("requirement field in request is empty"),
+		},
+		{
+			name: "empty amounts",
+			request: &results.PodScalerShadowRequest{
+				WorkloadName: "name",
+				WorkloadType: "build",
+				ResourceType: "memory",
+				Requirement:  "limit",
+				Direction:    "removed",
+			},
+			expected: fmt.Errorf("configured_amount and determined_amount fields in request are empty"),
+		},
+		{
+			name: "unknown direction",
+			request: &results.PodScalerShadowRequest{
+				WorkloadName:     "name",
+				WorkloadType:     "build",
+				ResourceType:     "memory",
+				Requirement:      "limit",
+				Direction:        "sideways",
+				ConfiguredAmount: "100",
+			},
+			expected: fmt.Errorf(`direction field in request must be one of added, removed, increased or decreased, not "sideways"`),
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			actual := validatePodScalerShadowRequest(testCase.request)
+			if diff := cmp.Diff(testCase.expected, actual, testhelper.EquateErrorMessage); diff != "" {
+				t.Fatalf("actual error doesn't match expected error, diff: %v", diff)
+			}
+		})
+	}
+}
@@ -104,6 +104,14 @@ func (p *DeferredParameters) Add(name string, fn func() (string, error)) {
 	p.fns[name] = fn
 }
 
+// Restore records the value a parameter had in a previous execution, so it
+// is not evaluated again.
+func (p *DeferredParameters) Restore(name, value string) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	p.values[name] = value
+}
+
 // HasInput returns true if the named parameter is an input from outside the graph, rather
 // than provided either by the graph caller or another node.
 func (p *DeferredParameters) HasInput(name string) bool {
@@ -130,6 +130,17 @@ type ReleaseBuildConfiguration struct {
 	// input types. The special name '*' may be used to set default
 	// requests and limits.
 	Resources ResourceConfiguration `json:"resources,omitempty"`
+
+	// Concurrency limits how many steps are executed at the same
+	// time, complementing the per-step Resources.
+	Concurrency *ConcurrencyConfiguration `json:"concurrency,omitempty"`
+
+	// RegistryVersion pins the step registry content used to resolve the
+	// multi-stage tests in this configuration. The configuration resolver
+	// reports the version it resolved against, so a run can be reproduced
+	// exactly by pinning it. An empty value resolves against the latest
+	// registry.
+	RegistryVersion string `json:"registry_version,omitempty"`
 }
 
 // RefCommands pairs a ref (in org/repo format) with commands
@@ -233,6 +244,21 @@ func (c ResourceConfiguration) RequirementsForStep(name string) ResourceRequirem
 	return req
 }
 
+// ConcurrencyConfiguration limits the number of steps in the execution
+// graph that run at the same time. A zero value means no limit. When a
+// limit is reached, ready steps wait and are started in order of the
+// longest chain of steps that depend on them.
+type ConcurrencyConfiguration struct {
+	// MaxParallelism is the maximum number of steps running at once.
+	MaxParallelism int `json:"max_parallelism,omitempty"`
+	// Builds is the maximum number of image builds running at once.
+	Builds int `json:"builds,omitempty"`
+	// Tests is the maximum number of tests running at once.
+	Tests int `json:"tests,omitempty"`
+	// Imports is the maximum number of image imports running at once.
+	Imports int `json:"imports,omitempty"`
+}
+
 // ResourceRequirements are resource requests and limits applied
 // to the individual steps in the job. They are passed directly to
 // builds or pods.
@@ -420,6 +446,19 @@ const (
 	NodeArchitectureARM64 NodeArchitecture = "arm64"
 )
 
+// ExecutionPolicy determines how the execution of the step graph
+// continues after a step fails.
+type ExecutionPolicy string
+
+const (
+	// ExecutionPolicyKeepGoing runs every branch of the graph that does
+	// not depend on a failed step and reports all failures.
+	ExecutionPolicyKeepGoing ExecutionPolicy = "keep-going"
+	// ExecutionPolicyFailFast cancels all running steps on the first
+	// failure of a step that is not optional.
+	ExecutionPolicyFailFast ExecutionPolicy = "fail-fast"
+)
+
 type ReleaseStream string
 
 const (
@@ -603,6 +642,68 @@ type StepConfiguration struct {
 	ResolvedReleaseImagesStepConfiguration      *ReleaseConfiguration                        `json:"resolved_release_images_step,omitempty"`
 	TestStepConfiguration                       *TestStepConfiguration                       `json:"test_step,omitempty"`
 	ProjectDirectoryImageBuildInputs            *ProjectDirectoryImageBuildInputs            `json:"project_directory_image_build_inputs,omitempty"`
+	ExternalStepConfiguration                   *ExternalStepConfiguration                   `json:"external_step,omitempty"`
+}
+
+// ExternalStepConfiguration describes a step implemented outside of
+// ci-operator by an image that speaks the external step protocol: the
+// image is run with the `run` argument and a JSON ExternalStepRequest in
+// $CI_EXTERNAL_STEP_REQUEST, and writes a JSON ExternalStepResponse to
+// /dev/termination-log before exiting. The image is not asked to describe
+// itself: what the step requires, creates and provides is declared here,
+// since the execution graph is resolved before anything runs.
+type ExternalStepConfiguration struct {
+	// As is the name of the step and of its Pod. It must be a valid
+	// Kubernetes object name. The step runs as the service account
+	// external-<as>.
+	As string `json:"as"`
+	// Image is the pull spec of the image implementing the step.
+	Image string `json:"image"`
+	// Parameters lists the parameters passed to the step. Parameters
+	// exposing images, like IMAGE_FORMAT or LOCAL_IMAGE_SRC, make the step
+	// depend on the steps producing the images.
+	Parameters []string `json:"parameters,omitempty"`
+	// Creates lists the pipeline images that the step tags into the
+	// pipeline image stream. The step runs as a service account that is
+	// allowed to tag images into the pipeline image stream.
+	Creates []PipelineImageStreamTagReference `json:"creates,omitempty"`
+	// Provides lists the parameters the step exposes to later steps.
+	Provides []string `json:"provides,omitempty"`
+	// Config is passed verbatim to the implementation and is part of the
+	// inputs of the job.
+	Config map[string]string `json:"config,omitempty"`
+	// Resources are the resource requirements of the step Pod.
+	Resources ResourceRequirements `json:"resources,omitempty"`
+}
+
+func (config ExternalStepConfiguration) TargetName() string {
+	return config.As
+}
+
+// ServiceAccountName is the name of the service account the step runs as, and
+// of the role and binding that grant it access to the pipeline image stream.
+// It is prefixed so that it cannot be the name of a service account created by
+// the cluster or by other steps, which would be granted the same access.
+func (config ExternalStepConfiguration) ServiceAccountName() string {
+	return "external-" + config.As
+}
+
+// ExternalStepRequest is passed to the implementation of an external step.
+type ExternalStepRequest struct {
+	// Name is the name of the step.
+	Name string `json:"name"`
+	// Namespace is the namespace the job executes in.
+	Namespace string `json:"namespace"`
+	// Parameters holds the values of the parameters passed to the step.
+	Parameters map[string]string `json:"parameters,omitempty"`
+	// Config is the configuration of the step.
+	Config map[string]string `json:"config,omitempty"`
+}
+
+// ExternalStepResponse is returned by the implementation of an external step.
+type ExternalStepResponse struct {
+	// Provides holds the values of the parameters the step exposes.
+	Provides map[string]string `json:"provides,omitempty"`
 }
 
 // InputImageTagStepConfiguration describes a step that
@@ -822,6 +923,10 @@ type TestStepConfiguration struct {
 	// RestrictNetworkAccess restricts network access to RedHat intranet.
 	RestrictNetworkAccess *bool `json:"restrict_network_access,omitempty"`
 
+	// ExecutionPolicy determines how the execution continues after a step
+	// fails when this test is targeted. Defaults to keep-going.
+	ExecutionPolicy ExecutionPolicy `json:"execution_policy,omitempty"`
+
 	// Only one of the following can be not-null.
 	ContainerTestConfiguration                                *ContainerTestConfiguration                                `json:"container,omitempty"`
 	MultiStageTestConfiguration                               *MultiStageTestConfiguration                               `json:"steps,omitempty"`
@@ -921,6 +1026,8 @@ type RegistryReference struct {
 	LiteralTestStep `json:",inline"`
 	// Documentation describes what the step being referenced does.
 	Documentation string `json:"documentation,omitempty"`
+	// Deprecation marks the step as deprecated.
+	Deprecation *RegistryDeprecation `json:"deprecation,omitempty"`
 }
 
 // RegistryChainConfig is the struct that chain references are unmarshalled into.
@@ -941,6 +1048,8 @@ type RegistryChain struct {
 	Environment []StepParameter `json:"env,omitempty"`
 	// Leases lists resources that should be acquired for the test.
 	Leases []StepLease `json:"leases,omitempty"`
+	// Deprecation marks the chain as deprecated.
+	Deprecation *RegistryDeprecation `json:"deprecation,omitempty"`
 }
 
 // RegistryWorkflowConfig is the struct that workflow references are unmarshalled into.
@@ -957,8 +1066,24 @@ type RegistryWorkflow struct {
 	Steps MultiStageTestConfiguration `json:"steps,omitempty"`
 	// Documentation describes what the workflow does.
 	Documentation string `json:"documentation,omitempty"`
+	// Deprecation marks the workflow as deprecated.
+	Deprecation *RegistryDeprecation `json:"deprecation,omitempty"`
 }
 
+// RegistryDeprecation announces that a registry component is deprecated and
+// will be removed from the registry.
+type RegistryDeprecation struct {
+	// Message explains why the component is deprecated and how to migrate away from it.
+	Message string `json:"message"`
+	// Replacement is the name of the component of the same type that should be used instead, if any.
+	Replacement string `json:"replacement,omitempty"`
+	// RemovalDate is the date, in the YYYY-MM-DD format, after which the component may be removed.
+	RemovalDate string `json:"removal_date,omitempty"`
+}
+
+// RegistryDeprecationDateFormat is the layout of the removal date of a deprecated registry component
+const RegistryDeprecationDateFormat = "2006-01-02"
+
 // RegistryObserverConfig is the struct that observer configs are unmarshalled into
 type RegistryObserverConfig struct {
 	// Observer is the top level field of an observer config
@@ -984,6 +1109,8 @@ type RegistryInfo struct {
 	Path string `json:"path,omitempty"`
 	// Owners is the OWNERS config for the registry component
 	Owners repoowners.Config `json:"owners,omitempty"`
+	// Deprecation is set when the registry component is deprecated
+	Deprecation *RegistryDeprecation `json:"deprecation,omitempty"`
 }
 
 // Observer is the configuration for an observer Pod that will run in parallel
@@ -1072,6 +1199,108 @@ type LiteralTestStep struct {
 	// NodeArchitecture is the architecture for the node where the test will run.
 	// If set, the generated test pod will include a nodeSelector for this architecture.
 	NodeArchitecture *NodeArchitecture `json:"node_architecture,omitempty"`
+	// Retry is the (optional) policy used to run the step again when it fails
+	// because of a transient infrastructure problem.
+	Retry *StepRetryPolicy `json:"retry,omitempty"`
+	// Outputs lists the values this step publishes for later steps. Outputs
+	// are stored in ${SHARED_DIR}, so they share its size limit of 1 MiB.
+	Outputs []StepOutput `json:"outputs,omitempty"`
+	// Inputs lists the outputs of earlier steps that this step requires.
+	Inputs []StepInput `json:"inputs,omitempty"`
+	// When is an (optional) expression that determines whether the step runs,
+	// evaluated right before the step would start. For example, `failure()`
+	// runs the step only if an earlier step failed and `env.UPGRADE == "true"`
+	// only if the UPGRADE parameter of the step is set to "true". See
+	// WhenExpression for the syntax.
+	When string `json:"when,omitempty"`
+}
+
+// StepOutput is a named value published by a step. The step publishes it by
+// writing a file with the name of the output to ${SHARED_DIR}. The step fails
+// if it succeeds without publishing all of its outputs. Outputs are stored in
+// the Secret backing ${SHARED_DIR} and are subject to its limit: all files in
+// ${SHARED_DIR}, outputs included, cannot exceed 1 MiB in total. Larger
+// artifacts need to be passed between steps through other means, like an
+// image or external storage.
+type StepOutput struct {
+	// Name of the output, which is also the name of the file in ${SHARED_DIR}.
+	Name string `json:"name"`
+	// Type of the output, `string` or `file`.
+	Type StepOutputType `json:"type"`
+	// Documentation is a textual description of the output.
+	Documentation string `json:"documentation,omitempty"`
+}
+
+// StepOutputType is the type of the value of an output.
+type StepOutputType string
+
+const (
+	// StepOutputTypeString is a single line of text. It is exposed to the
+	// steps that require it as the value of the environment variable.
+	StepOutputTypeString StepOutputType = "string"
+	// StepOutputTypeFile is arbitrary content. It is exposed to the steps that
+	// require it as the name of the file in ${SHARED_DIR} that holds it.
+	StepOutputTypeFile StepOutputType = "file"
+)
+
+// StepInput is an output of an earlier step required by a step.
+type StepInput struct {
+	// Name of the output.
+	Name string `json:"name"`
+	// Type is the (optional) expected type of the output.
+	Type StepOutputType `json:"type,omitempty"`
+	// Env is the environment variable used to expose the output to the step.
+	Env string `json:"env"`
+}
+
+// StepRetryPolicy defines how a step is retried when its Pod fails because of
+// a transient infrastructure problem. Failures of the step itself are never
+// retried.
+type StepRetryPolicy struct {
+	// Attempts is the maximum number of times the step is run, including the
+	// first attempt.
+	Attempts int `json:"attempts"`
+	// Backoff is how long we wait before the first retry. The wait is doubled
+	// for each subsequent retry. Defaults to 30 seconds.
+	Backoff *prowv1.Duration `json:"backoff,omitempty"`
+	// On lists the classes of failures that are retried. Defaults to all of
+	// them.
+	On []StepFailureClass `json:"on,omitempty"`
+}
+
+// StepFailureClass is a class of transient infrastructure failures of a step.
+type StepFailureClass string
+
+const (
+	// StepFailureEviction is the eviction of the Pod by the node.
+	StepFailureEviction StepFailureClass = "eviction"
+	// StepFailureImagePull is a failure to pull one of the images of the Pod.
+	StepFailureImagePull StepFailureClass = "image_pull"
+	// StepFailureOOMKilled is a container of the Pod being killed after
+	// running out of memory.
+	StepFailureOOMKilled StepFailureClass = "oom_killed"
+	// StepFailureAPIThrottling is the API server throttling the requests
+	// made while running the Pod.
+	StepFailureAPIThrottling StepFailureClass = "api_throttling"
+)
+
+// StepFailureClasses are all the valid classes of failures.
+var StepFailureClasses = []StepFailureClass{StepFailureEviction, StepFailureImagePull, StepFailureOOMKilled, StepFailureAPIThrottling}
+
+// Retries determines whether a failure of the given class is retried.
+func (p *StepRetryPolicy) Retries(class StepFailureClass) bool {
+	if p == nil {
+		return false
+	}
+	if len(p.On) == 0 {
+		return true
+	}
+	for _, c := range p.On {
+		if c == class {
+			return true
+		}
+	}
+	return false
 }
 
 // StepParameter is a variable set by the test, with an optional default.
@@ -1082,8 +1311,27 @@ type StepParameter struct {
 	Default *string `json:"default,omitempty"`
 	// Documentation is a textual description of the parameter.
 	Documentation string `json:"documentation,omitempty"`
+	// Type of the value of the parameter, one of `string` (the default),
+	// `integer` or `boolean`. Boolean parameters accept `true` or `false`.
+	Type StepParameterType `json:"type,omitempty"`
+	// Enum, if set, lists all values accepted by the parameter.
+	Enum []string `json:"enum,omitempty"`
+	// Pattern, if set, is a regular expression that the entire value of the
+	// parameter must match.
+	// An empty value is always accepted regardless of the type, enum and
+	// pattern, as steps conventionally treat it as unset.
+	Pattern string `json:"pattern,omitempty"`
 }
 
+// StepParameterType is the type of the value of a step parameter
+type StepParameterType string
+
+const (
+	StepParameterTypeString  StepParameterType = "string"
+	StepParameterTypeInteger StepParameterType = "integer"
+	StepParameterTypeBoolean StepParameterType = "boolean"
+)
+
 // CredentialReference defines a secret to mount into a step and where to mount it.
 type CredentialReference struct {
 	// Namespace is where the source secret exists.
@@ -1145,6 +1393,35 @@ type TestStep struct {
 	Reference *string `json:"ref,omitempty"`
 	// Chain is the name of a step chain reference.
 	Chain *string `json:"chain,omitempty"`
+	// Parallel is a group of steps that run concurrently. Changes the
+	// members make to the shared directory are merged once all of them
+	// finish and conflicting changes to the same file fail the group.
+	Parallel []ParallelStep `json:"parallel,omitempty"`
+}
+
+// ParallelStep is a member of a `parallel` group: a reference or a literal
+// test step. Chains and nested groups cannot run in parallel.
+type ParallelStep struct {
+	// LiteralTestStep is a full test step definition.
+	*LiteralTestStep `json:",inline,omitempty"`
+	// Reference is the name of a step reference.
+	Reference *string `json:"ref,omitempty"`
+}
+
+// FlattenParallel returns the steps with the members of `parallel` groups in
+// place of the groups.
+func FlattenParallel(steps []TestStep) []TestStep {
+	var ret []TestStep
+	for _, step := range steps {
+		if step.Parallel == nil {
+			ret = append(ret, step)
+			continue
+		}
+		for _, member := range step.Parallel {
+			ret = append(ret, TestStep{LiteralTestStep: member.LiteralTestStep, Reference: member.Reference})
+		}
+	}
+	return ret
 }
 
 // MultiStageTestConfiguration is a flexible configuration mode that allows tighter control over
@@ -1203,6 +1480,10 @@ type MultiStageTestConfigurationLiteral struct {
 	// Post is the array of test steps run after the tests finish and teardown/deprovision resources.
 	// Post steps always run, even if previous steps fail.
 	Post []LiteralTestStep `json:"post,omitempty"`
+	// ParallelGroups maps the steps resolved from a `parallel` group to the
+	// name of the group and is set by the resolver. Adjacent steps in a phase
+	// with the same group run concurrently.
+	ParallelGroups map[string]string `json:"parallel_groups,omitempty"`
 	// Environment has the values of parameters for the steps.
 	Environment TestEnvironment `json:"env,omitempty"`
 	// Dependencies holds override values for dependency parameters.
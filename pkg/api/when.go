@@ -0,0 +1,335 @@
+package api
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// StepResult is the outcome of a step of a multi-stage test.
+type StepResult string
+
+const (
+	StepResultSucceeded StepResult = "succeeded"
+	StepResultFailed    StepResult = "failed"
+	StepResultSkipped   StepResult = "skipped"
+)
+
+// WhenContext holds the state a `when` expression is evaluated against.
+// +k8s:deepcopy-gen=false
+type WhenContext struct {
+	// Failed is whether any step of the test failed so far.
+	Failed bool
+	// Steps holds the results of the steps that ran so far.
+	Steps map[string]StepResult
+	// Env resolves the parameters of the step.
+	Env func(name string) (string, bool)
+}
+
+// WhenExpression is a parsed `when` expression. The grammar is:
+//
+//	expression := and ( "||" and )*
+//	and        := unary ( "&&" unary )*
+//	unary      := "!" unary | "(" expression ")" | function | comparison
+//	function   := "success()" | "failure()" | "always()"
+//	comparison := operand ( "==" | "!=" ) operand
+//	operand    := "env." NAME | "steps." NAME | '"' STRING '"'
+//
+// `success()` and `failure()` report whether any step of the test failed so
+// far, `env.NAME` is the value of a parameter of the step and `steps.NAME` is
+// the result of a previous step: one of `succeeded`, `failed`, `skipped` or
+// empty if the step did not run.
+// +k8s:deepcopy-gen=false
+type WhenExpression struct {
+	root whenNode
+	env  []string
+	step []string
+}
+
+// ParseWhenExpression parses a `when` expression.
+func ParseWhenExpression(expression string) (*WhenExpression, error) {
+	p := &whenParser{input: expression}
+	if err := p.tokenize(); err != nil {
+		return nil, err
+	}
+	if len(p.tokens) == 0 {
+		return nil, fmt.Errorf("empty expression")
+	}
+	root, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if p.pos != len(p.tokens) {
+		return nil, fmt.Errorf("unexpected %q at position %d", p.tokens[p.pos].value, p.tokens[p.pos].offset)
+	}
+	return &WhenExpression{root: root, env: p.env, step: p.steps}, nil
+}
+
+// Env returns the names of the parameters referenced by the expression.
+func (e *WhenExpression) Env() []string { return e.env }
+
+// Steps returns the names of the steps referenced by the expression.
+func (e *WhenExpression) Steps() []string { return e.step }
+
+// Evaluate determines whether the expression holds.
+func (e *WhenExpression) Evaluate(ctx WhenContext) (bool, error) {
+	return e.root.evaluate(ctx)
+}
+
+// +k8s:deepcopy-gen=false
+type whenNode interface {
+	evaluate(ctx WhenContext) (bool, error)
+}
+
+// +k8s:deepcopy-gen=false
+type whenNot struct{ operand whenNode }
+
+func (n whenNot) evaluate(ctx WhenContext) (bool, error) {
+	ret, err := n.operand.evaluate(ctx)
+	return !ret, err
+}
+
+// +k8s:deepcopy-gen=false
+type whenBinary struct {
+	and         bool
+	left, right whenNode
+}
+
+func (n whenBinary) evaluate(ctx WhenContext) (bool, error) {
+	left, err := n.left.evaluate(ctx)
+	if err != nil {
+		return false, err
+	}
+	if n.and != left {
+		return left, nil
+	}
+	return n.right.evaluate(ctx)
+}
+
+// +k8s:deepcopy-gen=false
+type whenFunction string
+
+func (n whenFunction) evaluate(ctx WhenContext) (bool, error) {
+	switch n {
+	case "success":
+		return !ctx.Failed, nil
+	case "failure":
+		return ctx.Failed, nil
+	default:
+		return true, nil
+	}
+}
+
+// +k8s:deepcopy-gen=false
+type whenComparison struct {
+	equal       bool
+	left, right whenOperand
+}
+
+func (n whenComparison) evaluate(ctx WhenContext) (bool, error) {
+	left, err := n.left.resolve(ctx)
+	if err != nil {
+		return false, err
+	}
+	right, err := n.right.resolve(ctx)
+	if err != nil {
+		return false, err
+	}
+	return (left == right) == n.equal, nil
+}
+
+// +k8s:deepcopy-gen=false
+type whenOperand struct {
+	kind, text string
+}
+
+func (o whenOperand) resolve(ctx WhenContext) (string, error) {
+	switch o.kind {
+	case "env":
+		if ctx.Env == nil {
+			return "", fmt.Errorf("unknown parameter %s", o.text)
+		}
+		value, ok := ctx.Env(o.text)
+		if !ok {
+			return "", fmt.Errorf("unknown parameter %s", o.text)
+		}
+		return value, nil
+	case "steps":
+		return string(ctx.Steps[o.text]), nil
+	default:
+		return o.text, nil
+	}
+}
+
+// +k8s:deepcopy-gen=false
+type whenToken struct {
+	value  string
+	quoted bool
+	offset int
+}
+
+// +k8s:deepcopy-gen=false
+type whenParser struct {
+	input  string
+	tokens []whenToken
+	pos    int
+	env    []string
+	steps  []string
+}
+
+func isWhenIdentifier(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_' || r == '-' || r == '.'
+}
+
+func (p *whenParser) tokenize() error {
+	for i := 0; i < len(p.input); {
+		switch c := p.input[i]; {
+		case c == ' ' || c == '\t' || c == '\n':
+			i++
+		case strings.HasPrefix(p.input[i:], "&&"), strings.HasPrefix(p.input[i:], "||"),
+			strings.HasPrefix(p.input[i:], "=="), strings.HasPrefix(p.input[i:], "!="):
+			p.tokens = append(p.tokens, whenToken{value: p.input[i : i+2], offset: i})
+			i += 2
+		case c == '!' || c == '(' || c == ')':
+			p.tokens = append(p.tokens, whenToken{value: string(c), offset: i})
+			i++
+		case c == '"' || c == '\'':
+			end := strings.IndexByte(p.input[i+1:], c)
+			if end == -1 {
+				return fmt.Errorf("unterminated string at position %d", i)
+			}
+			p.tokens = append(p.tokens, whenToken{value: p.input[i+1 : i+1+end], quoted: true, offset: i})
+			i += end + 2
+		case isWhenIdentifier(rune(c)):
+			start := i
+			for i < len(p.input) && isWhenIdentifier(rune(p.input[i])) {
+				i++
+			}
+			p.tokens = append(p.tokens, whenToken{value: p.input[start:i], offset: start})
+		default:
+			return fmt.Errorf("unexpected %q at position %d", c, i)
+		}
+	}
+	return nil
+}
+
+func (p *whenParser) peek() (whenToken, bool) {
+	if p.pos >= len(p.tokens) {
+		return whenToken{}, false
+	}
+	return p.tokens[p.pos], true
+}
+
+func (p *whenParser) accept(value string) bool {
+	if t, ok := p.peek(); ok && !t.quoted && t.value == value {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+func (p *whenParser) parseOr() (whenNode, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.accept("||") {
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = whenBinary{left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *whenParser) parseAnd() (whenNode, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for p.accept("&&") {
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		left = whenBinary{and: true, left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *whenParser) parseUnary() (whenNode, error) {
+	if p.accept("!") {
+		operand, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return whenNot{operand: operand}, nil
+	}
+	if p.accept("(") {
+		node, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if !p.accept(")") {
+			return nil, p.unexpected("expected \")\"")
+		}
+		return node, nil
+	}
+	for _, function := range []string{"success", "failure", "always"} {
+		if t, ok := p.peek(); ok && !t.quoted && t.value == function {
+			p.pos++
+			if !p.accept("(") || !p.accept(")") {
+				return nil, p.unexpected(fmt.Sprintf("expected \"%s()\"", function))
+			}
+			return whenFunction(function), nil
+		}
+	}
+	left, err := p.parseOperand()
+	if err != nil {
+		return nil, err
+	}
+	var equal bool
+	switch {
+	case p.accept("=="):
+		equal = true
+	case p.accept("!="):
+	default:
+		return nil, p.unexpected("expected \"==\" or \"!=\"")
+	}
+	right, err := p.parseOperand()
+	if err != nil {
+		return nil, err
+	}
+	return whenComparison{equal: equal, left: left, right: right}, nil
+}
+
+func (p *whenParser) parseOperand() (whenOperand, error) {
+	t, ok := p.peek()
+	if !ok {
+		return whenOperand{}, p.unexpected("expected an operand")
+	}
+	p.pos++
+	if t.quoted {
+		return whenOperand{text: t.value}, nil
+	}
+	for _, kind := range []string{"env", "steps"} {
+		if name, ok := strings.CutPrefix(t.value, kind+"."); ok && name != "" {
+			if kind == "env" {
+				p.env = append(p.env, name)
+			} else {
+				p.steps = append(p.steps, name)
+			}
+			return whenOperand{kind: kind, text: name}, nil
+		}
+	}
+	p.pos--
+	return whenOperand{}, p.unexpected("expected env.NAME, steps.NAME or a quoted string")
+}
+
+func (p *whenParser) unexpected(expected string) error {
+	if t, ok := p.peek(); ok {
+		return fmt.Errorf("%s at position %d, got %q", expected, t.offset, t.value)
+	}
+	return fmt.Errorf("%s at the end of the expression", expected)
+}
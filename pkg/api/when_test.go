@@ -0,0 +1,148 @@
+package api
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestParseWhenExpression(t *testing.T) {
+	for _, tc := range []struct {
+		name       string
+		expression string
+		env, steps []string
+		err        string
+	}{{
+		name:       "function",
+		expression: "always()",
+	}, {
+		name:       "references",
+		expression: `!(env.UPGRADE == "true") || steps.install != 'failed'`,
+		env:        []string{"UPGRADE"},
+		steps:      []string{"install"},
+	}, {
+		name: "empty",
+		err:  "empty expression",
+	}, {
+		name:       "unterminated string",
+		expression: `env.A == "a`,
+		err:        "unterminated string at position 9",
+	}, {
+		name:       "missing parenthesis",
+		expression: "(success()",
+		err:        `expected ")" at the end of the expression`,
+	}, {
+		name:       "missing operator",
+		expression: "env.A",
+		err:        `expected "==" or "!=" at the end of the expression`,
+	}, {
+		name:       "unknown operand",
+		expression: `other == "a"`,
+		err:        `expected env.NAME, steps.NAME or a quoted string at position 0, got "other"`,
+	}, {
+		name:       "trailing tokens",
+		expression: "success() failure()",
+		err:        `unexpected "failure" at position 10`,
+	}, {
+		name:       "invalid character",
+		expression: "success() & failure()",
+		err:        `unexpected '&' at position 10`,
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			expression, err := ParseWhenExpression(tc.expression)
+			var actualErr string
+			if err != nil {
+				actualErr = err.Error()
+			}
+			if diff := cmp.Diff(tc.err, actualErr); diff != "" {
+				t.Fatalf("unexpected error: %s", diff)
+			}
+			if err != nil {
+				return
+			}
+			if diff := cmp.Diff(tc.env, expression.Env()); diff != "" {
+				t.Errorf("unexpected env: %s", diff)
+			}
+			if diff := cmp.Diff(tc.steps, expression.Steps()); diff != "" {
+				t.Errorf("unexpected steps: %s", diff)
+			}
+		})
+	}
+}
+
+func TestWhenExpressionEvaluate(t *testing.T) {
+	env := func(name string) (string, bool) {
+		value, ok := map[string]string{"UPGRADE": "true", "EMPTY": ""}[name]
+		return value, ok
+	}
+	for _, tc := range []struct {
+		name       string
+		expression string
+		failed     bool
+		expected   bool
+		err        string
+	}{{
+		name:       "success without failures",
+		expression: "success()",
+		expected:   true,
+	}, {
+		name:       "success with failures",
+		expression: "success()",
+		failed:     true,
+	}, {
+		name:       "failure with failures",
+		expression: "failure()",
+		failed:     true,
+		expected:   true,
+	}, {
+		name:       "always with failures",
+		expression: "always()",
+		failed:     true,
+		expected:   true,
+	}, {
+		name:       "parameter comparison",
+		expression: `env.UPGRADE == "true" && env.EMPTY != "true"`,
+		expected:   true,
+	}, {
+		name:       "step result",
+		expression: `steps.install == "failed" || steps.missing == "succeeded"`,
+		expected:   true,
+	}, {
+		name:       "step that did not run",
+		expression: `steps.missing == ""`,
+		expected:   true,
+	}, {
+		name:       "precedence",
+		expression: `failure() || env.UPGRADE == "true" && !success()`,
+	}, {
+		name:       "short circuit",
+		expression: `success() || env.UNKNOWN == "true"`,
+		expected:   true,
+	}, {
+		name:       "unknown parameter",
+		expression: `env.UNKNOWN == "true"`,
+		err:        "unknown parameter UNKNOWN",
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			expression, err := ParseWhenExpression(tc.expression)
+			if err != nil {
+				t.Fatalf("failed to parse expression: %v", err)
+			}
+			actual, err := expression.Evaluate(WhenContext{
+				Failed: tc.failed,
+				Steps:  map[string]StepResult{"install": StepResultFailed},
+				Env:    env,
+			})
+			var actualErr string
+			if err != nil {
+				actualErr = err.Error()
+			}
+			if diff := cmp.Diff(tc.err, actualErr); diff != "" {
+				t.Fatalf("unexpected error: %s", diff)
+			}
+			if actual != tc.expected {
+				t.Errorf("expected %v, got %v", tc.expected, actual)
+			}
+		})
+	}
+}
@@ -320,6 +320,21 @@ func (in *ClusterTestConfiguration) DeepCopy() *ClusterTestConfiguration {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ConcurrencyConfiguration) DeepCopyInto(out *ConcurrencyConfiguration) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ConcurrencyConfiguration.
+func (in *ConcurrencyConfiguration) DeepCopy() *ConcurrencyConfiguration {
+	if in == nil {
+		return nil
+	}
+	out := new(ConcurrencyConfiguration)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ContainerTestConfiguration) DeepCopyInto(out *ContainerTestConfiguration) {
 	*out = *in
@@ -397,6 +412,95 @@ func (in *ExternalImage) DeepCopy() *ExternalImage {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ExternalStepConfiguration) DeepCopyInto(out *ExternalStepConfiguration) {
+	*out = *in
+	if in.Parameters != nil {
+		in, out := &in.Parameters, &out.Parameters
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Creates != nil {
+		in, out := &in.Creates, &out.Creates
+		*out = make([]PipelineImageStreamTagReference, len(*in))
+		copy(*out, *in)
+	}
+	if in.Provides != nil {
+		in, out := &in.Provides, &out.Provides
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Config != nil {
+		in, out := &in.Config, &out.Config
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	in.Resources.DeepCopyInto(&out.Resources)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ExternalStepConfiguration.
+func (in *ExternalStepConfiguration) DeepCopy() *ExternalStepConfiguration {
+	if in == nil {
+		return nil
+	}
+	out := new(ExternalStepConfiguration)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ExternalStepRequest) DeepCopyInto(out *ExternalStepRequest) {
+	*out = *in
+	if in.Parameters != nil {
+		in, out := &in.Parameters, &out.Parameters
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Config != nil {
+		in, out := &in.Config, &out.Config
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ExternalStepRequest.
+func (in *ExternalStepRequest) DeepCopy() *ExternalStepRequest {
+	if in == nil {
+		return nil
+	}
+	out := new(ExternalStepRequest)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ExternalStepResponse) DeepCopyInto(out *ExternalStepResponse) {
+	*out = *in
+	if in.Provides != nil {
+		in, out := &in.Provides, &out.Provides
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ExternalStepResponse.
+func (in *ExternalStepResponse) DeepCopy() *ExternalStepResponse {
+	if in == nil {
+		return nil
+	}
+	out := new(ExternalStepResponse)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *GraphConfiguration) DeepCopyInto(out *GraphConfiguration) {
 	*out = *in
@@ -721,6 +825,21 @@ func (in *LiteralTestStep) DeepCopyInto(out *LiteralTestStep) {
 		*out = new(NodeArchitecture)
 		**out = **in
 	}
+	if in.Retry != nil {
+		in, out := &in.Retry, &out.Retry
+		*out = new(StepRetryPolicy)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Outputs != nil {
+		in, out := &in.Outputs, &out.Outputs
+		*out = make([]StepOutput, len(*in))
+		copy(*out, *in)
+	}
+	if in.Inputs != nil {
+		in, out := &in.Inputs, &out.Inputs
+		*out = make([]StepInput, len(*in))
+		copy(*out, *in)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LiteralTestStep.
@@ -895,6 +1014,13 @@ func (in *MultiStageTestConfigurationLiteral) DeepCopyInto(out *MultiStageTestCo
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.ParallelGroups != nil {
+		in, out := &in.ParallelGroups, &out.ParallelGroups
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
 	if in.Environment != nil {
 		in, out := &in.Environment, &out.Environment
 		*out = make(TestEnvironment, len(*in))
@@ -1260,6 +1386,31 @@ func (in *OutputImageTagStepConfiguration) DeepCopy() *OutputImageTagStepConfigu
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ParallelStep) DeepCopyInto(out *ParallelStep) {
+	*out = *in
+	if in.LiteralTestStep != nil {
+		in, out := &in.LiteralTestStep, &out.LiteralTestStep
+		*out = new(LiteralTestStep)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Reference != nil {
+		in, out := &in.Reference, &out.Reference
+		*out = new(string)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ParallelStep.
+func (in *ParallelStep) DeepCopy() *ParallelStep {
+	if in == nil {
+		return nil
+	}
+	out := new(ParallelStep)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *PipelineImageCacheStepConfiguration) DeepCopyInto(out *PipelineImageCacheStepConfiguration) {
 	*out = *in
@@ -1506,6 +1657,11 @@ func (in *RegistryChain) DeepCopyInto(out *RegistryChain) {
 		*out = make([]StepLease, len(*in))
 		copy(*out, *in)
 	}
+	if in.Deprecation != nil {
+		in, out := &in.Deprecation, &out.Deprecation
+		*out = new(RegistryDeprecation)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RegistryChain.
@@ -1534,6 +1690,21 @@ func (in *RegistryChainConfig) DeepCopy() *RegistryChainConfig {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RegistryDeprecation) DeepCopyInto(out *RegistryDeprecation) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RegistryDeprecation.
+func (in *RegistryDeprecation) DeepCopy() *RegistryDeprecation {
+	if in == nil {
+		return nil
+	}
+	out := new(RegistryDeprecation)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *RegistryObserver) DeepCopyInto(out *RegistryObserver) {
 	*out = *in
@@ -1570,6 +1741,11 @@ func (in *RegistryObserverConfig) DeepCopy() *RegistryObserverConfig {
 func (in *RegistryReference) DeepCopyInto(out *RegistryReference) {
 	*out = *in
 	in.LiteralTestStep.DeepCopyInto(&out.LiteralTestStep)
+	if in.Deprecation != nil {
+		in, out := &in.Deprecation, &out.Deprecation
+		*out = new(RegistryDeprecation)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RegistryReference.
@@ -1602,6 +1778,11 @@ func (in *RegistryReferenceConfig) DeepCopy() *RegistryReferenceConfig {
 func (in *RegistryWorkflow) DeepCopyInto(out *RegistryWorkflow) {
 	*out = *in
 	in.Steps.DeepCopyInto(&out.Steps)
+	if in.Deprecation != nil {
+		in, out := &in.Deprecation, &out.Deprecation
+		*out = new(RegistryDeprecation)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RegistryWorkflow.
@@ -1718,6 +1899,11 @@ func (in *ReleaseBuildConfiguration) DeepCopyInto(out *ReleaseBuildConfiguration
 			(*out)[key] = *val.DeepCopy()
 		}
 	}
+	if in.Concurrency != nil {
+		in, out := &in.Concurrency, &out.Concurrency
+		*out = new(ConcurrencyConfiguration)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ReleaseBuildConfiguration.
@@ -1946,6 +2132,11 @@ func (in *StepConfiguration) DeepCopyInto(out *StepConfiguration) {
 		*out = new(ProjectDirectoryImageBuildInputs)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.ExternalStepConfiguration != nil {
+		in, out := &in.ExternalStepConfiguration, &out.ExternalStepConfiguration
+		*out = new(ExternalStepConfiguration)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new StepConfiguration.
@@ -1998,6 +2189,21 @@ func (in *StepDependency) DeepCopy() *StepDependency {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *StepInput) DeepCopyInto(out *StepInput) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new StepInput.
+func (in *StepInput) DeepCopy() *StepInput {
+	if in == nil {
+		return nil
+	}
+	out := new(StepInput)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *StepLease) DeepCopyInto(out *StepLease) {
 	*out = *in
@@ -2028,6 +2234,21 @@ func (in *StepLinkOptions) DeepCopy() *StepLinkOptions {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *StepOutput) DeepCopyInto(out *StepOutput) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new StepOutput.
+func (in *StepOutput) DeepCopy() *StepOutput {
+	if in == nil {
+		return nil
+	}
+	out := new(StepOutput)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *StepParameter) DeepCopyInto(out *StepParameter) {
 	*out = *in
@@ -2036,6 +2257,11 @@ func (in *StepParameter) DeepCopyInto(out *StepParameter) {
 		*out = new(string)
 		**out = **in
 	}
+	if in.Enum != nil {
+		in, out := &in.Enum, &out.Enum
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new StepParameter.
@@ -2048,6 +2274,31 @@ func (in *StepParameter) DeepCopy() *StepParameter {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *StepRetryPolicy) DeepCopyInto(out *StepRetryPolicy) {
+	*out = *in
+	if in.Backoff != nil {
+		in, out := &in.Backoff, &out.Backoff
+		*out = new(v1.Duration)
+		**out = **in
+	}
+	if in.On != nil {
+		in, out := &in.On, &out.On
+		*out = make([]StepFailureClass, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new StepRetryPolicy.
+func (in *StepRetryPolicy) DeepCopy() *StepRetryPolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(StepRetryPolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in TestDependencies) DeepCopyInto(out *TestDependencies) {
 	{
@@ -2108,6 +2359,13 @@ func (in *TestStep) DeepCopyInto(out *TestStep) {
 		*out = new(string)
 		**out = **in
 	}
+	if in.Parallel != nil {
+		in, out := &in.Parallel, &out.Parallel
+		*out = make([]ParallelStep, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TestStep.
@@ -187,6 +187,9 @@ func printTreeSteps(o *options, steps []api.TestStep, level uint) {
 			printTreeStep(*s.Reference, level)
 		} else if s.LiteralTestStep != nil {
 			printTreeStep(s.LiteralTestStep.As, level)
+		} else if s.Parallel != nil {
+			printTreeLevel(level, "parallel:\n")
+			printTreeSteps(o, api.FlattenParallel([]api.TestStep{s}), level+1)
 		}
 	}
 }
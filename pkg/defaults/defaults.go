@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"net/http"
 	"os"
 	"strings"
 	"time"
@@ -19,6 +20,7 @@ import (
 	"k8s.io/client-go/rest"
 	utilpointer "k8s.io/utils/pointer"
 	ctrlruntimeclient "sigs.k8s.io/controller-runtime/pkg/client"
+	fakectrlruntimeclient "sigs.k8s.io/controller-runtime/pkg/client/fake"
 	prowapi "sigs.k8s.io/prow/pkg/apis/prowjobs/v1"
 	"sigs.k8s.io/prow/pkg/pod-utils/decorate"
 	"sigs.k8s.io/yaml"
@@ -53,7 +55,8 @@ type inputImageSet map[api.InputImage]struct{}
 // It interprets the human-friendly fields in the release build configuration
 // and pre-parsed graph configuration and generates steps for them, returning
 // the full set of steps requires for the build, including defaulted steps,
-// generated steps and all raw steps that the user provided.
+// generated steps and all raw steps that the user provided. The parameters
+// the steps provide to each other are registered in params.
 func FromConfig(
 	ctx context.Context,
 	config *api.ReleaseBuildConfiguration,
@@ -68,6 +71,7 @@ func FromConfig(
 	requiredTargets []string,
 	cloneAuthConfig *steps.CloneAuthConfig,
 	pullSecret, pushSecret *coreapi.Secret,
+	params *api.DeferredParameters,
 	censor *secrets.DynamicCensor,
 	hiveKubeconfig *rest.Config,
 	nodeName string,
@@ -115,7 +119,40 @@ func FromConfig(
 	httpClient := retryablehttp.NewClient()
 	httpClient.Logger = nil
 
-	return fromConfig(ctx, config, graphConf, jobSpec, templates, paramFile, promote, client, buildClient, templateClient, podClient, leaseClient, hiveClient, httpClient.StandardClient(), requiredTargets, cloneAuthConfig, pullSecret, pushSecret, api.NewDeferredParameters(nil), censor, nodeName, targetAdditionalSuffix, nodeArchitectures, integratedStreams, injectedTest, enableSecretsStoreCSIDriver)
+	return fromConfig(ctx, config, graphConf, jobSpec, templates, paramFile, promote, client, buildClient, templateClient, podClient, leaseClient, hiveClient, httpClient.StandardClient(), requiredTargets, cloneAuthConfig, pullSecret, pushSecret, params, censor, nodeName, targetAdditionalSuffix, nodeArchitectures, integratedStreams, injectedTest, enableSecretsStoreCSIDriver)
+}
+
+// FromConfigOffline generates the same steps as FromConfig, but backs them
+// with an empty, in-memory cluster instead of clients for a real one. The
+// steps describe the execution graph and must not be run.
+func FromConfigOffline(
+	ctx context.Context,
+	config *api.ReleaseBuildConfiguration,
+	graphConf *api.GraphConfiguration,
+	jobSpec *api.JobSpec,
+	templates []*templateapi.Template,
+	promote bool,
+	requiredTargets []string,
+	cloneAuthConfig *steps.CloneAuthConfig,
+	pullSecret, pushSecret *coreapi.Secret,
+	censor *secrets.DynamicCensor,
+	targetAdditionalSuffix string,
+	injectedTest bool,
+	enableSecretsStoreCSIDriver bool,
+) ([]api.Step, []api.Step, error) {
+	client := loggingclient.New(fakectrlruntimeclient.NewClientBuilder().Build())
+	buildClient := steps.NewBuildClient(client, nil, nil, "", "")
+	templateClient := steps.NewTemplateClient(client, nil)
+	podClient := kubernetes.NewPodClient(client, nil, nil, 0)
+	return fromConfig(ctx, config, graphConf, jobSpec, templates, "", promote, client, buildClient, templateClient, podClient, nil, nil, offlineHTTPClient{}, requiredTargets, cloneAuthConfig, pullSecret, pushSecret, api.NewDeferredParameters(nil), censor, "", targetAdditionalSuffix, nil, nil, injectedTest, enableSecretsStoreCSIDriver)
+}
+
+// offlineHTTPClient refuses all requests, so that nothing is resolved over
+// the network while generating steps offline.
+type offlineHTTPClient struct{}
+
+func (offlineHTTPClient) Do(req *http.Request) (*http.Response, error) {
+	return nil, fmt.Errorf("cannot request %s while offline", req.URL)
 }
 
 func fromConfig(
@@ -236,6 +273,12 @@ func fromConfig(
 			addProvidesForStep(step, params)
 			continue
 		}
+		if externalConfig := rawStep.ExternalStepConfiguration; externalConfig != nil {
+			step := steps.ExternalStep(*externalConfig, params, podClient, jobSpec)
+			buildSteps = append(buildSteps, step)
+			addProvidesForStep(step, params)
+			continue
+		}
 		var step api.Step
 		var stepLinks []api.StepLink
 		if rawStep.InputImageTagStepConfiguration != nil {
@@ -69,6 +69,12 @@ func init() {
 // NewFakeConfigAgent returns a new static config agent
 // that can be used for tests
 func NewFakeConfigAgent(configs config.ByOrgRepo) ConfigAgent {
+	return NewStaticConfigAgent(configs)
+}
+
+// NewStaticConfigAgent returns a config agent serving the provided
+// configurations, which are never reloaded
+func NewStaticConfigAgent(configs config.ByOrgRepo) ConfigAgent {
 	a := &configAgent{
 		lock:         &sync.RWMutex{},
 		configs:      configs,
@@ -1,6 +1,7 @@
 package agents
 
 import (
+	"errors"
 	"fmt"
 	"sync"
 	"time"
@@ -21,6 +22,14 @@ type RegistryAgent interface {
 	ResolveConfig(config api.ReleaseBuildConfiguration) (api.ReleaseBuildConfiguration, error)
 	GetRegistryComponents() (registry.ReferenceByName, registry.ChainByName, registry.WorkflowByName, map[string]string, api.RegistryMetadata)
 	GetGeneration() int
+	// GetRegistryGraph returns the graph of the latest registry, allowing to
+	// determine which components use each other
+	GetRegistryGraph() registry.NodeByName
+	// GetRegistryVersion returns the content address of the latest registry
+	GetRegistryVersion() string
+	// GetRegistryVersions returns the versions of all retained registry
+	// snapshots, oldest first
+	GetRegistryVersions() []string
 	GetClusterProfiles() api.ClusterProfilesMap
 	GetClusterProfileDetails(profileName string) (*api.ClusterProfileDetails, error)
 	registry.Resolver
@@ -30,6 +39,7 @@ type registryAgent struct {
 	lock            *sync.RWMutex
 	resolver        registry.Resolver
 	registryPath    string
+	loader          RegistryLoader
 	generation      int
 	errorMetrics    *prometheus.CounterVec
 	flags           load.RegistryFlag
@@ -39,8 +49,27 @@ type registryAgent struct {
 	clusterProfiles api.ClusterProfilesMap
 	documentation   map[string]string
 	metadata        api.RegistryMetadata
+	version         string
+	graph           registry.NodeByName
+	// snapshots holds resolvers for previously loaded registries, keyed by
+	// their version, so that pinned configurations can be resolved against
+	// the registry content they were originally resolved with
+	snapshots     map[string]registry.Resolver
+	snapshotOrder []string
+	maxSnapshots  int
+	// store, if set, persists every loaded registry version so that pinned
+	// versions can be resolved after they are evicted from snapshots
+	store *snapshotStore
 }
 
+// ErrRegistryVersionNotFound is returned when a configuration pins a
+// registry version that the agent does not retain
+var ErrRegistryVersionNotFound = errors.New("registry version not found")
+
+// defaultRegistrySnapshots is how many registry versions are retained
+// unless configured otherwise
+const defaultRegistrySnapshots = 50
+
 var registryReloadTimeMetric = prometheus.NewHistogram(
 	prometheus.HistogramOpts{
 		Name:    "configresolver_registry_reload_duration_seconds",
@@ -53,14 +82,27 @@ func init() {
 	prometheus.MustRegister(registryReloadTimeMetric)
 }
 
+// RegistryLoader loads the full content of a registry
+type RegistryLoader func() (registry.ReferenceByName, registry.ChainByName, registry.WorkflowByName, api.ClusterProfilesMap, map[string]string, api.RegistryMetadata, registry.ObserverByName, error)
+
 type RegistryAgentOptions struct {
 	// ErrorMetric holds the CounterVec to count errors on. It must include a `error` label
 	// or the agent panics on the first error.
 	ErrorMetric *prometheus.CounterVec
 	// FlatRegistry describes if the registry is flat, which means org/repo/branch info can not be inferred
 	// from the filepath. Defaults to true.
-	FlatRegistry            *bool
+	FlatRegistry *bool
+	// Snapshots is how many registry versions are retained in memory for
+	// resolving pinned configurations, including the latest one. Defaults to 50.
+	Snapshots *int
+	// SnapshotDir, if set, is the directory every loaded registry version is
+	// persisted in. Pinned versions that are no longer retained in memory are
+	// read from there.
+	SnapshotDir             string
 	UniversalSymlinkWatcher *UniversalSymlinkWatcher
+	// FederatedRegistries are served next to the default registry, with
+	// their components referenced by qualified names.
+	FederatedRegistries []load.FederatedRegistry
 }
 
 type RegistryAgentOption func(*RegistryAgentOptions)
@@ -77,6 +119,28 @@ func WithRegistryFlat(v bool) RegistryAgentOption {
 	}
 }
 
+// WithRegistrySnapshots sets how many registry versions are retained
+func WithRegistrySnapshots(n int) RegistryAgentOption {
+	return func(o *RegistryAgentOptions) {
+		o.Snapshots = &n
+	}
+}
+
+// WithRegistrySnapshotDir persists every loaded registry version in dir
+func WithRegistrySnapshotDir(dir string) RegistryAgentOption {
+	return func(o *RegistryAgentOptions) {
+		o.SnapshotDir = dir
+	}
+}
+
+// WithFederatedRegistries federates registries with the default one, see
+// registry.Federate for how references between them are resolved
+func WithFederatedRegistries(federated ...load.FederatedRegistry) RegistryAgentOption {
+	return func(o *RegistryAgentOptions) {
+		o.FederatedRegistries = append(o.FederatedRegistries, federated...)
+	}
+}
+
 // NewRegistryAgent returns a RegistryAgent interface that automatically reloads when
 // the registry is changed on disk.
 func NewRegistryAgent(registryPath string, errCh chan error, opts ...RegistryAgentOption) (RegistryAgent, error) {
@@ -90,6 +154,9 @@ func NewRegistryAgent(registryPath string, errCh chan error, opts ...RegistryAge
 	if opt.FlatRegistry == nil {
 		opt.FlatRegistry = utilpointer.Bool(true)
 	}
+	if opt.Snapshots == nil || *opt.Snapshots < 1 {
+		opt.Snapshots = utilpointer.Int(defaultRegistrySnapshots)
+	}
 	flags := load.RegistryMetadata | load.RegistryDocumentation
 	if *opt.FlatRegistry {
 		flags |= load.RegistryFlat
@@ -99,6 +166,14 @@ func NewRegistryAgent(registryPath string, errCh chan error, opts ...RegistryAge
 		lock:         &sync.RWMutex{},
 		errorMetrics: opt.ErrorMetric,
 		flags:        flags,
+		snapshots:    map[string]registry.Resolver{},
+		maxSnapshots: *opt.Snapshots,
+	}
+	if opt.SnapshotDir != "" {
+		a.store = &snapshotStore{dir: opt.SnapshotDir}
+	}
+	a.loader = func() (registry.ReferenceByName, registry.ChainByName, registry.WorkflowByName, api.ClusterProfilesMap, map[string]string, api.RegistryMetadata, registry.ObserverByName, error) {
+		return load.FederatedRegistries(a.registryPath, a.flags, opt.FederatedRegistries)
 	}
 	// Load config once so we fail early if that doesn't work and are ready as soon as we return
 	if err := a.loadRegistry(); err != nil {
@@ -109,14 +184,78 @@ func NewRegistryAgent(registryPath string, errCh chan error, opts ...RegistryAge
 		opt.UniversalSymlinkWatcher.RegistryEventFn = a.loadRegistry
 	}
 
-	return a, startWatchers(registryPath, errCh, a.loadRegistry, a.errorMetrics, opt.UniversalSymlinkWatcher)
+	if err := startWatchers(registryPath, errCh, a.loadRegistry, a.errorMetrics, opt.UniversalSymlinkWatcher); err != nil {
+		return nil, err
+	}
+	for _, federated := range opt.FederatedRegistries {
+		if err := startWatchers(federated.Path, errCh, a.loadRegistry, a.errorMetrics, nil); err != nil {
+			return nil, fmt.Errorf("failed to watch federated registry %s: %w", federated.Namespace, err)
+		}
+	}
+	return a, nil
+}
+
+// NewStaticRegistryAgent returns a RegistryAgent serving a registry that is
+// loaded once by the provided loader and never reloaded, e.g. one read from
+// an offline registry bundle.
+func NewStaticRegistryAgent(loader RegistryLoader, opts ...RegistryAgentOption) (RegistryAgent, error) {
+	opt := &RegistryAgentOptions{}
+	for _, o := range opts {
+		o(opt)
+	}
+	if opt.ErrorMetric == nil {
+		opt.ErrorMetric = prometheus.NewCounterVec(prometheus.CounterOpts{Name: "registry_agent_errors_total"}, []string{"error"})
+	}
+	a := &registryAgent{
+		lock:         &sync.RWMutex{},
+		loader:       loader,
+		errorMetrics: opt.ErrorMetric,
+		snapshots:    map[string]registry.Resolver{},
+		maxSnapshots: 1,
+	}
+	if err := a.loadRegistry(); err != nil {
+		return nil, fmt.Errorf("failed to load registry: %w", err)
+	}
+	return a, nil
 }
 
-// ResolveConfig uses the registryAgent's resolver to resolve a provided ReleaseBuildConfiguration
+// ResolveConfig uses the registryAgent's resolver to resolve a provided ReleaseBuildConfiguration.
+// When the configuration pins a registry version, the snapshot of that version is used instead.
+// The resolved configuration records the registry version it was resolved with.
 func (a *registryAgent) ResolveConfig(config api.ReleaseBuildConfiguration) (api.ReleaseBuildConfiguration, error) {
+	resolver, version, err := a.resolverFor(config.RegistryVersion)
+	if err != nil {
+		return api.ReleaseBuildConfiguration{}, err
+	}
+	resolved, err := registry.ResolveConfig(resolver, config)
+	if err != nil {
+		return api.ReleaseBuildConfiguration{}, err
+	}
+	resolved.RegistryVersion = version
+	return resolved, nil
+}
+
+// resolverFor returns the resolver for a registry version, or for the latest
+// registry if no version is requested. Versions no longer retained in memory
+// are read from the snapshot directory, if there is one, on every request.
+func (a *registryAgent) resolverFor(version string) (registry.Resolver, string, error) {
 	a.lock.RLock()
-	defer a.lock.RUnlock()
-	return registry.ResolveConfig(a.resolver, config)
+	latest, latestVersion := a.resolver, a.version
+	snapshot, retained := a.snapshots[version]
+	a.lock.RUnlock()
+	switch {
+	case version == "":
+		return latest, latestVersion, nil
+	case retained:
+		return snapshot, version, nil
+	case a.store == nil:
+		return nil, "", fmt.Errorf("%w: %s", ErrRegistryVersionNotFound, version)
+	}
+	snapshot, err := a.store.load(version)
+	if err != nil {
+		return nil, "", err
+	}
+	return snapshot, version, nil
 }
 
 func (a *registryAgent) ResolveWorkflow(name string) (api.MultiStageTestConfigurationLiteral, error) {
@@ -137,6 +276,24 @@ func (a *registryAgent) GetGeneration() int {
 	return a.generation
 }
 
+func (a *registryAgent) GetRegistryGraph() registry.NodeByName {
+	a.lock.RLock()
+	defer a.lock.RUnlock()
+	return a.graph
+}
+
+func (a *registryAgent) GetRegistryVersion() string {
+	a.lock.RLock()
+	defer a.lock.RUnlock()
+	return a.version
+}
+
+func (a *registryAgent) GetRegistryVersions() []string {
+	a.lock.RLock()
+	defer a.lock.RUnlock()
+	return append([]string(nil), a.snapshotOrder...)
+}
+
 func (a *registryAgent) GetRegistryComponents() (registry.ReferenceByName, registry.ChainByName, registry.WorkflowByName, map[string]string, api.RegistryMetadata) {
 	return a.references, a.chains, a.workflows, a.documentation, a.metadata
 }
@@ -157,15 +314,28 @@ func (a *registryAgent) GetClusterProfileDetails(profileName string) (*api.Clust
 
 func (a *registryAgent) loadRegistry() error {
 	logrus.Debug("Reloading registry")
+	var snapshot registry.Snapshot
+	var version string
 	duration, err := func() (time.Duration, error) {
 		a.lock.Lock()
 		defer a.lock.Unlock()
 		startTime := time.Now()
-		references, chains, workflows, clusterProfiles, documentation, metadata, observers, err := load.Registry(a.registryPath, a.flags)
+		references, chains, workflows, clusterProfiles, documentation, metadata, observers, err := a.loader()
 		if err != nil {
 			recordErrorForMetric(a.errorMetrics, "failed to load ci-operator registry")
 			return time.Duration(0), fmt.Errorf("failed to load ci-operator registry (%w)", err)
 		}
+		snapshot = registry.Snapshot{Steps: references, Chains: chains, Workflows: workflows, Observers: observers}
+		version, err = registry.Version(references, chains, workflows, observers)
+		if err != nil {
+			recordErrorForMetric(a.errorMetrics, "failed to compute registry version")
+			return time.Duration(0), fmt.Errorf("failed to compute registry version: %w", err)
+		}
+		graph, err := registry.NewGraph(references, chains, workflows, observers)
+		if err != nil {
+			recordErrorForMetric(a.errorMetrics, "failed to build registry graph")
+			return time.Duration(0), fmt.Errorf("failed to build registry graph: %w", err)
+		}
 		a.references = references
 		a.chains = chains
 		a.workflows = workflows
@@ -173,6 +343,9 @@ func (a *registryAgent) loadRegistry() error {
 		a.metadata = metadata
 		a.clusterProfiles = clusterProfiles
 		a.resolver = registry.NewResolver(references, chains, workflows, observers)
+		a.version = version
+		a.graph = graph
+		a.addSnapshot(version, a.resolver)
 		a.generation++
 		return time.Since(startTime), nil
 	}()
@@ -180,10 +353,34 @@ func (a *registryAgent) loadRegistry() error {
 		return err
 	}
 	registryReloadTimeMetric.Observe(duration.Seconds())
-	logrus.WithField("duration", duration).Info("Registry reloaded")
+	logrus.WithField("duration", duration).WithField("version", version).Info("Registry reloaded")
+	if a.store != nil {
+		if err := a.store.save(version, snapshot); err != nil {
+			recordErrorForMetric(a.errorMetrics, "failed to persist registry snapshot")
+			logrus.WithError(err).WithField("version", version).Warn("Failed to persist registry snapshot")
+		}
+	}
 	return nil
 }
 
+// addSnapshot records the resolver for a registry version as the most
+// recent one, evicting the oldest snapshots beyond the retention limit.
+// The caller must hold the write lock.
+func (a *registryAgent) addSnapshot(version string, resolver registry.Resolver) {
+	for i, v := range a.snapshotOrder {
+		if v == version {
+			a.snapshotOrder = append(a.snapshotOrder[:i], a.snapshotOrder[i+1:]...)
+			break
+		}
+	}
+	a.snapshots[version] = resolver
+	a.snapshotOrder = append(a.snapshotOrder, version)
+	for len(a.snapshotOrder) > a.maxSnapshots {
+		delete(a.snapshots, a.snapshotOrder[0])
+		a.snapshotOrder = a.snapshotOrder[1:]
+	}
+}
+
 func (a *registryAgent) Resolve(name string, config api.MultiStageTestConfiguration) (api.MultiStageTestConfigurationLiteral, error) {
 	return a.resolver.Resolve(name, config)
 }
@@ -1,12 +1,16 @@
 package agents
 
 import (
+	"errors"
 	"fmt"
+	"os"
+	"sync"
 	"testing"
 
 	"github.com/google/go-cmp/cmp"
 
 	"github.com/openshift/ci-tools/pkg/api"
+	"github.com/openshift/ci-tools/pkg/registry"
 	"github.com/openshift/ci-tools/pkg/testhelper"
 )
 
@@ -67,3 +71,215 @@ func TestGetClusterProfileDetails(t *testing.T) {
 		})
 	}
 }
+
+func TestResolveConfigRegistryVersion(t *testing.T) {
+	registryFor := func(commands string) registry.Resolver {
+		return registry.NewResolver(registry.ReferenceByName{
+			"step": {As: "step", From: "src", Commands: commands, Resources: api.ResourceRequirements{Requests: api.ResourceList{"cpu": "1"}}},
+		}, registry.ChainByName{}, registry.WorkflowByName{}, registry.ObserverByName{})
+	}
+	agent := &registryAgent{
+		lock:         &sync.RWMutex{},
+		snapshots:    map[string]registry.Resolver{},
+		maxSnapshots: 2,
+	}
+	for _, version := range []string{"old", "older", "current"} {
+		agent.resolver = registryFor(version)
+		agent.version = version
+		agent.addSnapshot(version, agent.resolver)
+	}
+	agent.addSnapshot("older", registryFor("older"))
+	agent.addSnapshot("current", agent.resolver)
+	if diff := cmp.Diff([]string{"older", "current"}, agent.GetRegistryVersions()); diff != "" {
+		t.Errorf("retained versions differ from expected: %s", diff)
+	}
+
+	ref := "step"
+	for _, tc := range []struct {
+		name             string
+		version          string
+		expectedVersion  string
+		expectedCommands string
+		expectedErr      error
+	}{
+		{
+			name:             "no pin resolves against the latest registry",
+			expectedVersion:  "current",
+			expectedCommands: "current",
+		},
+		{
+			name:             "pin resolves against the snapshot",
+			version:          "older",
+			expectedVersion:  "older",
+			expectedCommands: "older",
+		},
+		{
+			name:        "pin to an evicted version fails",
+			version:     "old",
+			expectedErr: errors.New("registry version not found: old"),
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			config := api.ReleaseBuildConfiguration{
+				RegistryVersion: tc.version,
+				Tests: []api.TestStepConfiguration{{
+					As: "test",
+					MultiStageTestConfiguration: &api.MultiStageTestConfiguration{
+						Test: []api.TestStep{{Reference: &ref}},
+					},
+				}},
+			}
+			resolved, err := agent.ResolveConfig(config)
+			if diff := cmp.Diff(tc.expectedErr, err, testhelper.EquateErrorMessage); diff != "" {
+				t.Fatalf("error differs from expected:\n%s", diff)
+			}
+			if tc.expectedErr != nil {
+				if !errors.Is(err, ErrRegistryVersionNotFound) {
+					t.Errorf("expected error to wrap ErrRegistryVersionNotFound, got %v", err)
+				}
+				return
+			}
+			if resolved.RegistryVersion != tc.expectedVersion {
+				t.Errorf("expected resolved version %q, got %q", tc.expectedVersion, resolved.RegistryVersion)
+			}
+			if commands := resolved.Tests[0].MultiStageTestConfigurationLiteral.Test[0].Commands; commands != tc.expectedCommands {
+				t.Errorf("expected commands %q, got %q", tc.expectedCommands, commands)
+			}
+		})
+	}
+}
+
+func TestRegistrySnapshotStore(t *testing.T) {
+	snapshotFor := func(commands string) (registry.Snapshot, string) {
+		snapshot := registry.Snapshot{
+			Steps: registry.ReferenceByName{
+				"step": {As: "step", From: "src", Commands: commands, Resources: api.ResourceRequirements{Requests: api.ResourceList{"cpu": "1"}}},
+			},
+			Chains:    registry.ChainByName{},
+			Workflows: registry.WorkflowByName{},
+			Observers: registry.ObserverByName{},
+		}
+		version, err := registry.Version(snapshot.Steps, snapshot.Chains, snapshot.Workflows, snapshot.Observers)
+		if err != nil {
+			t.Fatalf("failed to determine version: %v", err)
+		}
+		return snapshot, version
+	}
+	current, currentVersion := snapshotFor("current")
+	evicted, evictedVersion := snapshotFor("evicted")
+	tampered, tamperedVersion := snapshotFor("tampered")
+	_, unknownVersion := snapshotFor("unknown")
+
+	store := &snapshotStore{dir: t.TempDir()}
+	for version, snapshot := range map[string]registry.Snapshot{currentVersion: current, evictedVersion: evicted, tamperedVersion: tampered} {
+		if err := store.save(version, snapshot); err != nil {
+			t.Fatalf("failed to save snapshot: %v", err)
+		}
+	}
+	if err := os.Rename(store.path(evictedVersion), store.path(tamperedVersion)); err != nil {
+		t.Fatalf("failed to tamper with snapshot: %v", err)
+	}
+	// restore the evicted snapshot, which was moved to tamper with the other one
+	if err := store.save(evictedVersion, evicted); err != nil {
+		t.Fatalf("failed to save snapshot: %v", err)
+	}
+	agent := &registryAgent{
+		lock:         &sync.RWMutex{},
+		resolver:     registry.NewResolver(current.Steps, current.Chains, current.Workflows, current.Observers),
+		version:      currentVersion,
+		snapshots:    map[string]registry.Resolver{},
+		maxSnapshots: 1,
+		store:        store,
+	}
+	agent.addSnapshot(currentVersion, agent.resolver)
+
+	ref := "step"
+	for _, tc := range []struct {
+		name             string
+		version          string
+		expectedCommands string
+		expectedErr      error
+	}{
+		{
+			name:             "retained version resolves from memory",
+			version:          currentVersion,
+			expectedCommands: "current",
+		},
+		{
+			name:             "evicted version resolves from the snapshot directory",
+			version:          evictedVersion,
+			expectedCommands: "evicted",
+		},
+		{
+			name:        "unknown version is not found",
+			version:     unknownVersion,
+			expectedErr: fmt.Errorf("registry version not found: %s", unknownVersion),
+		},
+		{
+			name:        "malformed version is not found",
+			version:     "../" + evictedVersion,
+			expectedErr: fmt.Errorf("registry version not found: ../%s", evictedVersion),
+		},
+		{
+			name:        "snapshot with different content is rejected",
+			version:     tamperedVersion,
+			expectedErr: fmt.Errorf("snapshot of registry version %s has content of version %s", tamperedVersion, evictedVersion),
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			resolved, err := agent.ResolveConfig(api.ReleaseBuildConfiguration{
+				RegistryVersion: tc.version,
+				Tests: []api.TestStepConfiguration{{
+					As: "test",
+					MultiStageTestConfiguration: &api.MultiStageTestConfiguration{
+						Test: []api.TestStep{{Reference: &ref}},
+					},
+				}},
+			})
+			if diff := cmp.Diff(tc.expectedErr, err, testhelper.EquateErrorMessage); diff != "" {
+				t.Fatalf("error differs from expected:\n%s", diff)
+			}
+			if tc.expectedErr != nil {
+				return
+			}
+			if resolved.RegistryVersion != tc.version {
+				t.Errorf("expected resolved version %q, got %q", tc.version, resolved.RegistryVersion)
+			}
+			if commands := resolved.Tests[0].MultiStageTestConfigurationLiteral.Test[0].Commands; commands != tc.expectedCommands {
+				t.Errorf("expected commands %q, got %q", tc.expectedCommands, commands)
+			}
+		})
+	}
+}
+
+func TestNewStaticRegistryAgent(t *testing.T) {
+	references := registry.ReferenceByName{
+		"step": {As: "step", From: "src", Commands: "make test", Resources: api.ResourceRequirements{Requests: api.ResourceList{"cpu": "1"}}},
+	}
+	profiles := api.ClusterProfilesMap{"aws": {Profile: "aws", Secret: "cluster-secrets-aws"}}
+	loader := func() (registry.ReferenceByName, registry.ChainByName, registry.WorkflowByName, api.ClusterProfilesMap, map[string]string, api.RegistryMetadata, registry.ObserverByName, error) {
+		return references, registry.ChainByName{}, registry.WorkflowByName{}, profiles, nil, nil, registry.ObserverByName{}, nil
+	}
+	agent, err := NewStaticRegistryAgent(loader)
+	if err != nil {
+		t.Fatalf("failed to create agent: %v", err)
+	}
+	version, err := registry.Version(references, registry.ChainByName{}, registry.WorkflowByName{}, registry.ObserverByName{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff([]string{version}, agent.GetRegistryVersions()); diff != "" {
+		t.Errorf("versions differ from expected: %s", diff)
+	}
+	if _, err := agent.GetClusterProfileDetails("aws"); err != nil {
+		t.Errorf("failed to get cluster profile: %v", err)
+	}
+
+	failing := func() (registry.ReferenceByName, registry.ChainByName, registry.WorkflowByName, api.ClusterProfilesMap, map[string]string, api.RegistryMetadata, registry.ObserverByName, error) {
+		return nil, nil, nil, nil, nil, nil, nil, errors.New("oops")
+	}
+	_, err = NewStaticRegistryAgent(failing)
+	if diff := cmp.Diff(errors.New("failed to load registry: failed to load ci-operator registry (oops)"), err, testhelper.EquateErrorMessage); diff != "" {
+		t.Errorf("error differs from expected: %s", diff)
+	}
+}
@@ -0,0 +1,89 @@
+package agents
+
+import (
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+
+	"github.com/openshift/ci-tools/pkg/registry"
+)
+
+// registryVersionPattern matches the versions computed by registry.Version,
+// which are also the names of the persisted snapshots
+var registryVersionPattern = regexp.MustCompile(`^[0-9a-f]{64}$`)
+
+// snapshotStore persists registry snapshots in a directory, one
+// gzip-compressed file per version, so that pinned versions can still be
+// resolved after they are evicted from memory or the agent restarts.
+type snapshotStore struct {
+	dir string
+}
+
+func (s *snapshotStore) path(version string) string {
+	return filepath.Join(s.dir, version+".json.gz")
+}
+
+// save persists the snapshot unless it is already stored. Snapshots are
+// addressed by their content, so a stored snapshot never changes.
+func (s *snapshotStore) save(version string, snapshot registry.Snapshot) error {
+	path := s.path(version)
+	if _, err := os.Stat(path); err == nil {
+		return nil
+	}
+	tmp, err := os.CreateTemp(s.dir, ".snapshot-")
+	if err != nil {
+		return fmt.Errorf("failed to create snapshot file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	gz := gzip.NewWriter(tmp)
+	if err := json.NewEncoder(gz).Encode(snapshot); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write snapshot: %w", err)
+	}
+	if err := gz.Close(); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write snapshot: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write snapshot: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("failed to store snapshot: %w", err)
+	}
+	return nil
+}
+
+// load reads the snapshot of a registry version, verifying that its content
+// still matches the version
+func (s *snapshotStore) load(version string) (registry.Resolver, error) {
+	if !registryVersionPattern.MatchString(version) {
+		return nil, fmt.Errorf("%w: %s", ErrRegistryVersionNotFound, version)
+	}
+	f, err := os.Open(s.path(version))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("%w: %s", ErrRegistryVersionNotFound, version)
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to open snapshot of registry version %s: %w", version, err)
+	}
+	defer f.Close()
+	gz, err := gzip.NewReader(f)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read snapshot of registry version %s: %w", version, err)
+	}
+	var snapshot registry.Snapshot
+	if err := json.NewDecoder(gz).Decode(&snapshot); err != nil {
+		return nil, fmt.Errorf("failed to parse snapshot of registry version %s: %w", version, err)
+	}
+	actual, err := registry.Version(snapshot.Steps, snapshot.Chains, snapshot.Workflows, snapshot.Observers)
+	if err != nil {
+		return nil, fmt.Errorf("failed to determine version of snapshot %s: %w", version, err)
+	}
+	if actual != version {
+		return nil, fmt.Errorf("snapshot of registry version %s has content of version %s", version, actual)
+	}
+	return registry.NewResolver(snapshot.Steps, snapshot.Chains, snapshot.Workflows, snapshot.Observers), nil
+}
@@ -0,0 +1,71 @@
+package load
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/openshift/ci-tools/pkg/api"
+	"github.com/openshift/ci-tools/pkg/registry"
+)
+
+// FederatedRegistry is a registry whose components are referenced under a
+// namespace, e.g. `myteam/install-foo`
+type FederatedRegistry struct {
+	Namespace string
+	Path      string
+}
+
+// FederatedRegistriesFlag collects federated registries passed on the
+// command line in the form namespace=path
+type FederatedRegistriesFlag []FederatedRegistry
+
+// FederatedRegistriesUsage describes the flag that FederatedRegistriesFlag
+// is bound to
+const FederatedRegistriesUsage = "A registry to federate with --registry in the form namespace=path; its components are referenced as namespace/name. Can be passed multiple times."
+
+func (f *FederatedRegistriesFlag) String() string {
+	var raw []string
+	for _, federated := range *f {
+		raw = append(raw, federated.Namespace+"="+federated.Path)
+	}
+	return strings.Join(raw, ",")
+}
+
+func (f *FederatedRegistriesFlag) Set(value string) error {
+	namespace, path, ok := strings.Cut(value, "=")
+	if !ok || namespace == "" || path == "" {
+		return fmt.Errorf("must be in the form namespace=path, got %q", value)
+	}
+	*f = append(*f, FederatedRegistry{Namespace: namespace, Path: path})
+	return nil
+}
+
+// FederatedRegistries loads the registry at root and federates the others
+// with it, see registry.Federate. Documentation and metadata of federated
+// components are keyed by their qualified names. Without federated
+// registries, it is equivalent to Registry.
+func FederatedRegistries(root string, flags RegistryFlag, federated []FederatedRegistry) (registry.ReferenceByName, registry.ChainByName, registry.WorkflowByName, api.ClusterProfilesMap, map[string]string, api.RegistryMetadata, registry.ObserverByName, error) {
+	references, chains, workflows, profiles, documentation, metadata, observers, err := Registry(root, flags)
+	if err != nil || len(federated) == 0 {
+		return references, chains, workflows, profiles, documentation, metadata, observers, err
+	}
+	sources := []registry.Source{{References: references, Chains: chains, Workflows: workflows, Observers: observers}}
+	for _, f := range federated {
+		references, chains, workflows, _, federatedDocumentation, federatedMetadata, observers, err := Registry(f.Path, flags|RegistryFederated)
+		if err != nil {
+			return nil, nil, nil, nil, nil, nil, nil, fmt.Errorf("failed to load federated registry %s: %w", f.Namespace, err)
+		}
+		sources = append(sources, registry.Source{Namespace: f.Namespace, References: references, Chains: chains, Workflows: workflows, Observers: observers})
+		for name, doc := range federatedDocumentation {
+			documentation[registry.QualifiedName(f.Namespace, name)] = doc
+		}
+		for name, info := range federatedMetadata {
+			metadata[registry.QualifiedName(f.Namespace, name)] = info
+		}
+	}
+	references, chains, workflows, observers, err = registry.Federate(sources...)
+	if err != nil {
+		return nil, nil, nil, nil, nil, nil, nil, err
+	}
+	return references, chains, workflows, profiles, documentation, metadata, observers, nil
+}
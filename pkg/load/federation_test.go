@@ -0,0 +1,144 @@
+package load
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	"github.com/openshift/ci-tools/pkg/api"
+	"github.com/openshift/ci-tools/pkg/registry"
+	"github.com/openshift/ci-tools/pkg/testhelper"
+)
+
+func TestFederatedRegistries(t *testing.T) {
+	write := func(t *testing.T, files map[string]string) string {
+		dir := t.TempDir()
+		for name, content := range files {
+			path := filepath.Join(dir, name)
+			if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+				t.Fatal(err)
+			}
+			if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+				t.Fatal(err)
+			}
+		}
+		return dir
+	}
+	ref := func(name string) string {
+		return fmt.Sprintf("ref:\n  as: %s\n  from: src\n  commands: %s-commands.sh\n  resources:\n    requests:\n      cpu: 100m\n  documentation: %s docs\n", name, name, name)
+	}
+	defaultRegistry := write(t, map[string]string{
+		"cluster-profiles/cluster-profiles-config.yaml": "[]\n",
+		"gather-ref.yaml":     ref("gather"),
+		"gather-commands.sh":  "true\n",
+		"install-ref.yaml":    ref("install"),
+		"install-commands.sh": "true\n",
+		"ipi-workflow.yaml":   "workflow:\n  as: ipi\n  steps:\n    pre:\n    - ref: install\n",
+	})
+	myteam := write(t, map[string]string{
+		"install-ref.yaml":    ref("install"),
+		"install-commands.sh": "true\n",
+		"foo-workflow.yaml":   "workflow:\n  as: foo\n  steps:\n    pre:\n    - ref: install\n    post:\n    - ref: gather\n",
+	})
+	broken := write(t, map[string]string{
+		"foo-workflow.yaml": "workflow:\n  as: foo\n  steps:\n    pre:\n    - ref: missing\n",
+	})
+	install, gather := "install", "gather"
+	qualifiedInstall := "myteam/install"
+
+	for _, tc := range []struct {
+		name              string
+		federated         []FederatedRegistry
+		expectedWorkflows registry.WorkflowByName
+		expectedDocs      []string
+		expectedErr       error
+	}{
+		{
+			name: "default registry only",
+			expectedWorkflows: registry.WorkflowByName{
+				"ipi": {Pre: []api.TestStep{{Reference: &install}}},
+			},
+			expectedDocs: []string{"gather", "install", "ipi"},
+		},
+		{
+			name:      "federated registry",
+			federated: []FederatedRegistry{{Namespace: "myteam", Path: myteam}},
+			expectedWorkflows: registry.WorkflowByName{
+				"ipi":        {Pre: []api.TestStep{{Reference: &install}}},
+				"myteam/foo": {Pre: []api.TestStep{{Reference: &qualifiedInstall}}, Post: []api.TestStep{{Reference: &gather}}},
+			},
+			expectedDocs: []string{"gather", "install", "ipi", "myteam/foo", "myteam/install"},
+		},
+		{
+			name:        "invalid cross-registry reference",
+			federated:   []FederatedRegistry{{Namespace: "broken", Path: broken}},
+			expectedErr: errors.New("invalid federated registry: workflow/broken/foo: invalid step reference: missing"),
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			_, _, workflows, _, documentation, _, _, err := FederatedRegistries(defaultRegistry, RegistryFlat|RegistryDocumentation, tc.federated)
+			if diff := cmp.Diff(tc.expectedErr, err, testhelper.EquateErrorMessage); diff != "" {
+				t.Fatalf("unexpected error: %s", diff)
+			}
+			if err != nil {
+				return
+			}
+			if diff := cmp.Diff(tc.expectedWorkflows, workflows); diff != "" {
+				t.Errorf("unexpected workflows: %s", diff)
+			}
+			if diff := cmp.Diff(tc.expectedDocs, sets.List(sets.KeySet(documentation))); diff != "" {
+				t.Errorf("unexpected documentation: %s", diff)
+			}
+		})
+	}
+}
+
+func TestFederatedRegistriesFlag(t *testing.T) {
+	for _, tc := range []struct {
+		name        string
+		values      []string
+		expected    FederatedRegistriesFlag
+		expectedErr error
+	}{
+		{
+			name:     "multiple registries",
+			values:   []string{"myteam=/registries/myteam", "other=/registries/other"},
+			expected: FederatedRegistriesFlag{{Namespace: "myteam", Path: "/registries/myteam"}, {Namespace: "other", Path: "/registries/other"}},
+		},
+		{
+			name:        "missing path",
+			values:      []string{"myteam="},
+			expectedErr: errors.New(`must be in the form namespace=path, got "myteam="`),
+		},
+		{
+			name:        "missing namespace",
+			values:      []string{"/registries/myteam"},
+			expectedErr: errors.New(`must be in the form namespace=path, got "/registries/myteam"`),
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			var actual FederatedRegistriesFlag
+			var err error
+			for _, value := range tc.values {
+				if err = actual.Set(value); err != nil {
+					break
+				}
+			}
+			if diff := cmp.Diff(tc.expectedErr, err, testhelper.EquateErrorMessage); diff != "" {
+				t.Fatalf("unexpected error: %s", diff)
+			}
+			if err != nil {
+				return
+			}
+			if diff := cmp.Diff(tc.expected, actual); diff != "" {
+				t.Errorf("unexpected registries: %s", diff)
+			}
+		})
+	}
+}
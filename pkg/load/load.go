@@ -12,6 +12,7 @@ import (
 	"github.com/sirupsen/logrus"
 
 	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/apimachinery/pkg/util/sets"
 	"sigs.k8s.io/prow/pkg/config"
 	"sigs.k8s.io/yaml"
 
@@ -36,6 +37,15 @@ const (
 	RegistryFlat = RegistryFlag(1) << iota
 	RegistryMetadata
 	RegistryDocumentation
+	// RegistryFederated loads a registry that is federated with others, see
+	// registry.Federate. Its components may reference those of the default
+	// registry, so they are only validated once the registries are federated,
+	// and it has no cluster profiles.
+	RegistryFederated
+	// RegistryAnalyzeCommands statically analyzes the commands of the steps
+	// when validating the registry, see validation.AnalyzeStepCommands. The
+	// analysis is heuristic, so issues are logged as warnings.
+	RegistryAnalyzeCommands
 )
 
 // Registry takes the path to a registry config directory and returns the full set of references, chains,
@@ -46,6 +56,7 @@ func Registry(root string, flags RegistryFlag) (registry.ReferenceByName, regist
 	chains := registry.ChainByName{}
 	workflows := registry.WorkflowByName{}
 	observers := registry.ObserverByName{}
+	deprecations := registry.Deprecations{References: registry.DeprecationByName{}, Chains: registry.DeprecationByName{}, Workflows: registry.DeprecationByName{}}
 	var profiles api.ClusterProfilesMap
 	var clusterProfilesConfigPath string
 	var documentation map[string]string
@@ -98,19 +109,23 @@ func Registry(root string, flags RegistryFlag) (registry.ReferenceByName, regist
 			}
 		}
 		if strings.HasSuffix(path, RefSuffix) {
-			name, doc, ref, err := loadReference(raw, dir, prefix, flat)
+			ref, err := loadReference(raw, dir, prefix, flat)
 			if err != nil {
 				return fmt.Errorf("failed to load registry file %s: %w", path, err)
 			}
+			name := ref.As
 			if !flat && name != prefix {
 				return fmt.Errorf("name of reference in file %s should be %s", path, prefix)
 			}
 			if strings.TrimSuffix(filepath.Base(path), RefSuffix) != name {
 				return fmt.Errorf("filename %s does not match name of reference; filename should be %s", filepath.Base(path), fmt.Sprint(prefix, RefSuffix))
 			}
-			references[name] = ref
+			references[name] = ref.LiteralTestStep
 			if documentation != nil {
-				documentation[name] = doc
+				documentation[name] = ref.Documentation
+			}
+			if ref.Deprecation != nil {
+				deprecations.References[name] = *ref.Deprecation
 			}
 		} else if strings.HasSuffix(path, ChainSuffix) {
 			var chain api.RegistryChainConfig
@@ -128,21 +143,29 @@ func Registry(root string, flags RegistryFlag) (registry.ReferenceByName, regist
 				documentation[chain.Chain.As] = chain.Chain.Documentation
 			}
 			chain.Chain.Documentation = ""
+			if chain.Chain.Deprecation != nil {
+				deprecations.Chains[chain.Chain.As] = *chain.Chain.Deprecation
+				chain.Chain.Deprecation = nil
+			}
 			chains[chain.Chain.As] = chain.Chain
 		} else if strings.HasSuffix(path, WorkflowSuffix) {
-			name, doc, workflow, err := loadWorkflow(raw)
+			workflow, err := loadWorkflow(raw)
 			if err != nil {
 				return fmt.Errorf("failed to load registry file %s: %w", path, err)
 			}
+			name := workflow.As
 			if !flat && name != prefix {
 				return fmt.Errorf("name of workflow in file %s should be %s", path, prefix)
 			}
 			if strings.TrimSuffix(filepath.Base(path), WorkflowSuffix) != name {
 				return fmt.Errorf("filename %s does not match name of workflow; filename should be %s", filepath.Base(path), fmt.Sprint(prefix, WorkflowSuffix))
 			}
-			workflows[name] = workflow
+			workflows[name] = workflow.Steps
 			if documentation != nil {
-				documentation[name] = doc
+				documentation[name] = workflow.Documentation
+			}
+			if workflow.Deprecation != nil {
+				deprecations.Workflows[name] = *workflow.Deprecation
 			}
 		} else if strings.HasSuffix(path, MetadataSuffix) {
 			if metadata == nil {
@@ -193,17 +216,25 @@ func Registry(root string, flags RegistryFlag) (registry.ReferenceByName, regist
 	if err != nil {
 		return nil, nil, nil, nil, nil, nil, nil, err
 	}
-	// create graph to verify that there are no cycles
-	if _, err = registry.NewGraph(references, chains, workflows, observers); err != nil {
-		return nil, nil, nil, nil, nil, nil, nil, err
+	if flags&RegistryFederated == 0 {
+		// create graph to verify that there are no cycles
+		if _, err = registry.NewGraph(references, chains, workflows, observers); err != nil {
+			return nil, nil, nil, nil, nil, nil, nil, err
+		}
+		err = registry.Validate(references, chains, workflows, observers)
+		if err != nil {
+			return nil, nil, nil, nil, nil, nil, nil, err
+		}
+		if err := registry.ValidateDeprecations(references, chains, workflows, deprecations); err != nil {
+			return nil, nil, nil, nil, nil, nil, nil, err
+		}
+		profiles, err = ClusterProfilesConfig(clusterProfilesConfigPath)
+		if err != nil {
+			return nil, nil, nil, nil, nil, nil, nil, err
+		}
 	}
-	err = registry.Validate(references, chains, workflows, observers)
-	if err != nil {
-		return nil, nil, nil, nil, nil, nil, nil, err
-	}
-	profiles, err = ClusterProfilesConfig(clusterProfilesConfigPath)
-	if err != nil {
-		return nil, nil, nil, nil, nil, nil, nil, err
+	if metadata != nil {
+		addDeprecations(metadata, deprecations)
 	}
 	// validate the integrity of each reference
 	v := validation.NewValidator(nil, nil)
@@ -216,36 +247,84 @@ func Registry(root string, flags RegistryFlag) (registry.ReferenceByName, regist
 	if len(validationErrors) > 0 {
 		return nil, nil, nil, nil, nil, nil, nil, utilerrors.NewAggregate(validationErrors)
 	}
+	if flags&RegistryAnalyzeCommands != 0 {
+		issues := registry.AnalyzeCommands(references)
+		for _, name := range sets.List(sets.KeySet(issues)) {
+			for _, issue := range issues[name] {
+				logrus.WithFields(logrus.Fields{"step": name, "issue": issue.Kind}).Warn(issue.String())
+			}
+		}
+	}
 	return references, chains, workflows, profiles, documentation, metadata, observers, nil
 }
 
-func loadReference(bytes []byte, baseDir, prefix string, flat bool) (string, string, api.LiteralTestStep, error) {
+func loadReference(bytes []byte, baseDir, prefix string, flat bool) (api.RegistryReference, error) {
 	step := api.RegistryReferenceConfig{}
 	err := yaml.UnmarshalStrict(bytes, &step)
 	if err != nil {
-		return "", "", api.LiteralTestStep{}, err
+		return api.RegistryReference{}, err
 	}
 	if !flat && step.Reference.Commands != fmt.Sprintf("%s%s%s", prefix, CommandsSuffix, filepath.Ext(step.Reference.Commands)) {
-		return "", "", api.LiteralTestStep{}, fmt.Errorf("reference %s has invalid command file path; command should be set to %s (with an optional extension like .sh)", step.Reference.As, fmt.Sprintf("%s%s", prefix, CommandsSuffix))
+		return api.RegistryReference{}, fmt.Errorf("reference %s has invalid command file path; command should be set to %s (with an optional extension like .sh)", step.Reference.As, fmt.Sprintf("%s%s", prefix, CommandsSuffix))
 	}
 	command, err := gzip.ReadFileMaybeGZIP(filepath.Join(baseDir, step.Reference.Commands))
 	if err != nil {
-		return "", "", api.LiteralTestStep{}, err
+		return api.RegistryReference{}, err
 	}
 	step.Reference.Commands = string(command)
-	return step.Reference.As, step.Reference.Documentation, step.Reference.LiteralTestStep, nil
+	return step.Reference, nil
 }
 
-func loadWorkflow(bytes []byte) (string, string, api.MultiStageTestConfiguration, error) {
+func loadWorkflow(bytes []byte) (api.RegistryWorkflow, error) {
 	workflow := api.RegistryWorkflowConfig{}
 	err := yaml.UnmarshalStrict(bytes, &workflow)
 	if err != nil {
-		return "", "", api.MultiStageTestConfiguration{}, err
+		return api.RegistryWorkflow{}, err
 	}
 	if workflow.Workflow.Steps.Workflow != nil {
-		return "", "", api.MultiStageTestConfiguration{}, errors.New("workflows cannot contain other workflows")
+		return api.RegistryWorkflow{}, errors.New("workflows cannot contain other workflows")
+	}
+	return workflow.Workflow, nil
+}
+
+// addDeprecations records the deprecation notices in the metadata of the
+// registry components, which is keyed by the base name of their files
+func addDeprecations(metadata api.RegistryMetadata, deprecations registry.Deprecations) {
+	for _, item := range []struct {
+		suffix       string
+		deprecations registry.DeprecationByName
+	}{
+		{suffix: RefSuffix, deprecations: deprecations.References},
+		{suffix: ChainSuffix, deprecations: deprecations.Chains},
+		{suffix: WorkflowSuffix, deprecations: deprecations.Workflows},
+	} {
+		for name, deprecation := range item.deprecations {
+			deprecation := deprecation
+			info := metadata[name+item.suffix]
+			info.Deprecation = &deprecation
+			metadata[name+item.suffix] = info
+		}
+	}
+}
+
+// Deprecations extracts the deprecation notices of registry components from
+// the registry metadata
+func Deprecations(metadata api.RegistryMetadata) registry.Deprecations {
+	deprecations := registry.Deprecations{References: registry.DeprecationByName{}, Chains: registry.DeprecationByName{}, Workflows: registry.DeprecationByName{}}
+	for filename, info := range metadata {
+		if info.Deprecation == nil {
+			continue
+		}
+		switch {
+		case strings.HasSuffix(filename, RefSuffix):
+			deprecations.References[strings.TrimSuffix(filename, RefSuffix)] = *info.Deprecation
+		case strings.HasSuffix(filename, ChainSuffix):
+			deprecations.Chains[strings.TrimSuffix(filename, ChainSuffix)] = *info.Deprecation
+		case strings.HasSuffix(filename, WorkflowSuffix):
+			deprecations.Workflows[strings.TrimSuffix(filename, WorkflowSuffix)] = *info.Deprecation
+		}
 	}
-	return workflow.Workflow.As, workflow.Workflow.Documentation, workflow.Workflow.Steps, nil
+	return deprecations
 }
 
 // ClusterProfilesConfig loads cluster profile information from its config in the release repository
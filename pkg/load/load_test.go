@@ -7,8 +7,10 @@ import (
 	"testing"
 
 	"github.com/ghodss/yaml"
+	"github.com/google/go-cmp/cmp"
 
 	"k8s.io/apimachinery/pkg/util/diff"
+	"sigs.k8s.io/prow/pkg/repoowners"
 
 	"github.com/openshift/ci-tools/pkg/api"
 	"github.com/openshift/ci-tools/pkg/registry"
@@ -453,3 +455,125 @@ func TestClusterClaimOwnersConfig(t *testing.T) {
 		})
 	}
 }
+
+func TestRegistryDeprecations(t *testing.T) {
+	files := map[string]string{
+		"cluster-profiles/cluster-profiles-config.yaml": "[]\n",
+		"old-commands.sh": "true\n",
+		"new-commands.sh": "true\n",
+		"old-ref.yaml": `ref:
+  as: old
+  from: src
+  commands: old-commands.sh
+  resources:
+    requests:
+      cpu: 100m
+  deprecation:
+    message: old is slow
+    replacement: new
+    removal_date: "2025-01-01"
+`,
+		"new-ref.yaml": `ref:
+  as: new
+  from: src
+  commands: new-commands.sh
+  resources:
+    requests:
+      cpu: 100m
+`,
+		"chain-chain.yaml": `chain:
+  as: chain
+  steps:
+  - ref: old
+  deprecation:
+    message: use the new step directly
+`,
+		"workflow-workflow.yaml": `workflow:
+  as: workflow
+  steps:
+    test:
+    - ref: new
+`,
+	}
+	deprecation := api.RegistryDeprecation{Message: "old is slow", Replacement: "new", RemovalDate: "2025-01-01"}
+	chainDeprecation := api.RegistryDeprecation{Message: "use the new step directly"}
+	for _, tc := range []struct {
+		name                 string
+		flags                RegistryFlag
+		overrides            map[string]string
+		expectedMetadata     api.RegistryMetadata
+		expectedDeprecations registry.Deprecations
+		expectedErr          bool
+	}{{
+		name:  "deprecations are recorded in the metadata",
+		flags: RegistryFlat | RegistryMetadata,
+		expectedMetadata: api.RegistryMetadata{
+			"old-ref.yaml":     {Deprecation: &deprecation},
+			"chain-chain.yaml": {Deprecation: &chainDeprecation},
+		},
+		expectedDeprecations: registry.Deprecations{
+			References: registry.DeprecationByName{"old": deprecation},
+			Chains:     registry.DeprecationByName{"chain": chainDeprecation},
+			Workflows:  registry.DeprecationByName{},
+		},
+	}, {
+		name:  "deprecations are merged with existing metadata",
+		flags: RegistryFlat | RegistryMetadata,
+		overrides: map[string]string{
+			"old-ref.metadata.json": `{"path": "old/old-ref.yaml", "owners": {"approvers": ["alice"]}}`,
+		},
+		expectedMetadata: api.RegistryMetadata{
+			"old-ref.yaml":     {Path: "old/old-ref.yaml", Owners: repoowners.Config{Approvers: []string{"alice"}}, Deprecation: &deprecation},
+			"chain-chain.yaml": {Deprecation: &chainDeprecation},
+		},
+		expectedDeprecations: registry.Deprecations{
+			References: registry.DeprecationByName{"old": deprecation},
+			Chains:     registry.DeprecationByName{"chain": chainDeprecation},
+			Workflows:  registry.DeprecationByName{},
+		},
+	}, {
+		name:  "invalid deprecations are rejected without metadata",
+		flags: RegistryFlat,
+		overrides: map[string]string{
+			"workflow-workflow.yaml": `workflow:
+  as: workflow
+  steps:
+    test:
+    - ref: new
+  deprecation:
+    message: gone
+    replacement: missing
+    removal_date: next week
+`,
+		},
+		expectedErr: true,
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			dir := t.TempDir()
+			for _, contents := range []map[string]string{files, tc.overrides} {
+				for name, content := range contents {
+					path := filepath.Join(dir, name)
+					if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+						t.Fatalf("failed to create directory: %v", err)
+					}
+					if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+						t.Fatalf("failed to write %s: %v", name, err)
+					}
+				}
+			}
+			_, _, _, _, _, metadata, _, err := Registry(dir, tc.flags)
+			if (err != nil) != tc.expectedErr {
+				t.Fatalf("expected error: %t, got: %v", tc.expectedErr, err)
+			}
+			if err != nil {
+				return
+			}
+			if diff := cmp.Diff(tc.expectedMetadata, metadata); diff != "" {
+				t.Errorf("unexpected metadata: %s", diff)
+			}
+			if diff := cmp.Diff(tc.expectedDeprecations, Deprecations(metadata)); diff != "" {
+				t.Errorf("unexpected deprecations: %s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,110 @@
+package pod_scaler
+
+import (
+	"time"
+
+	"github.com/openhistogram/circonusllhist"
+	"github.com/prometheus/common/model"
+)
+
+// Checkpoint records the state of a CachedQuery, so that the data recorded
+// since can be stored as a delta instead of rewriting the whole query.
+type Checkpoint struct {
+	counts map[model.Fingerprint]uint64
+}
+
+// Checkpoint records the current state of the data.
+func (q *CachedQuery) Checkpoint() Checkpoint {
+	counts := make(map[model.Fingerprint]uint64, len(q.Data))
+	for fingerprint, data := range q.Data {
+		counts[fingerprint] = data.Histogram().Count()
+	}
+	return Checkpoint{counts: counts}
+}
+
+// Delta determines the data recorded since the checkpoint. Histograms are only
+// ever added to, so any histogram with a changed count holds new data and is
+// recorded in full. Ranges are small and are recorded in full, as well.
+func (q *CachedQuery) Delta(since Checkpoint) *CachedQuery {
+	delta := &CachedQuery{
+		Query:           q.Query,
+		RangesByCluster: map[string][]TimeRange{},
+		Data:            map[model.Fingerprint]*circonusllhist.HistogramWithoutLookups{},
+		DataByMetaData:  map[FullMetadata][]FingerprintTime{},
+	}
+	for cluster, ranges := range q.RangesByCluster {
+		delta.RangesByCluster[cluster] = append([]TimeRange{}, ranges...)
+	}
+	for fingerprint, data := range q.Data {
+		if count, seen := since.counts[fingerprint]; !seen || count != data.Histogram().Count() {
+			delta.Data[fingerprint] = data
+		}
+	}
+	for meta, fingerprintTimes := range q.DataByMetaData {
+		for _, fingerprintTime := range fingerprintTimes {
+			if _, seen := since.counts[fingerprintTime.Fingerprint]; !seen {
+				delta.DataByMetaData[meta] = append(delta.DataByMetaData[meta], fingerprintTime)
+			}
+		}
+	}
+	return delta
+}
+
+// WithDelta returns a copy of the query with the delta applied. The histograms
+// are shared with the original, but they are replaced instead of mutated when
+// applying deltas, so the original can still be read safely.
+func (q *CachedQuery) WithDelta(delta *CachedQuery) *CachedQuery {
+	merged := &CachedQuery{
+		Query:           q.Query,
+		RangesByCluster: make(map[string][]TimeRange, len(q.RangesByCluster)),
+		Data:            make(map[model.Fingerprint]*circonusllhist.HistogramWithoutLookups, len(q.Data)+len(delta.Data)),
+		DataByMetaData:  make(map[FullMetadata][]FingerprintTime, len(q.DataByMetaData)),
+		DeltasUntil:     q.DeltasUntil,
+	}
+	if merged.Query == "" {
+		merged.Query = delta.Query
+	}
+	for cluster, ranges := range q.RangesByCluster {
+		merged.RangesByCluster[cluster] = append([]TimeRange{}, ranges...)
+	}
+	// deltas hold all ranges recorded when they were stored, which supersede ours
+	for cluster, ranges := range delta.RangesByCluster {
+		merged.RangesByCluster[cluster] = append([]TimeRange{}, ranges...)
+	}
+	for fingerprint, data := range q.Data {
+		merged.Data[fingerprint] = data
+	}
+	for fingerprint, data := range delta.Data {
+		merged.Data[fingerprint] = data
+	}
+	for meta, fingerprintTimes := range q.DataByMetaData {
+		merged.DataByMetaData[meta] = append([]FingerprintTime{}, fingerprintTimes...)
+	}
+	for meta, fingerprintTimes := range delta.DataByMetaData {
+		for _, fingerprintTime := range fingerprintTimes {
+			if !containsFingerprint(merged.DataByMetaData[meta], fingerprintTime.Fingerprint) {
+				merged.DataByMetaData[meta] = append(merged.DataByMetaData[meta], fingerprintTime)
+			}
+		}
+	}
+	return merged
+}
+
+func containsFingerprint(fingerprintTimes []FingerprintTime, fingerprint model.Fingerprint) bool {
+	for _, fingerprintTime := range fingerprintTimes {
+		if fingerprintTime.Fingerprint == fingerprint {
+			return true
+		}
+	}
+	return false
+}
+
+// Compacted records that all deltas up to the given time are included in the data.
+func (q *CachedQuery) Compacted(until time.Time) {
+	q.DeltasUntil = &until
+}
+
+// IncludesDelta determines if the delta stored at the given time is already included in the data.
+func (q *CachedQuery) IncludesDelta(stored time.Time) bool {
+	return q.DeltasUntil != nil && !stored.After(*q.DeltasUntil)
+}
@@ -0,0 +1,90 @@
+package pod_scaler
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/openhistogram/circonusllhist"
+	"github.com/prometheus/common/model"
+)
+
+func TestCachedQuery_DeltaRoundTrip(t *testing.T) {
+	start := time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC)
+	histogram := func(values ...float64) *circonusllhist.HistogramWithoutLookups {
+		h := circonusllhist.New(circonusllhist.NoLookup())
+		for _, value := range values {
+			if err := h.RecordValue(value); err != nil {
+				t.Fatalf("failed to record value: %v", err)
+			}
+		}
+		return circonusllhist.NewHistogramWithoutLookups(h)
+	}
+	first, second := FullMetadata{Target: "first"}, FullMetadata{Target: "second"}
+	query := &CachedQuery{
+		Query:           "query",
+		RangesByCluster: map[string][]TimeRange{"cluster": {{Start: start, End: start.Add(time.Hour)}}},
+		Data: map[model.Fingerprint]*circonusllhist.HistogramWithoutLookups{
+			1: histogram(1, 2, 3),
+			2: histogram(4, 5, 6),
+		},
+		DataByMetaData: map[FullMetadata][]FingerprintTime{
+			first: {{Fingerprint: 1, Added: start.Add(time.Hour)}, {Fingerprint: 2, Added: start.Add(time.Hour)}},
+		},
+	}
+	original := query.WithDelta(&CachedQuery{})
+	checkpoint := query.Checkpoint()
+
+	// record more data for an existing fingerprint and data for a new one
+	query.RangesByCluster["cluster"] = coalesce(append(query.RangesByCluster["cluster"], TimeRange{Start: start.Add(time.Hour), End: start.Add(2 * time.Hour)}))
+	query.Data[2] = histogram(4, 5, 6, 7)
+	query.Data[3] = histogram(8)
+	query.DataByMetaData[second] = []FingerprintTime{{Fingerprint: 3, Added: start.Add(2 * time.Hour)}}
+
+	delta := query.Delta(checkpoint)
+	var fingerprints []model.Fingerprint
+	for fingerprint := range delta.Data {
+		fingerprints = append(fingerprints, fingerprint)
+	}
+	if len(fingerprints) != 2 || delta.Data[1] != nil {
+		t.Errorf("expected delta to hold the changed fingerprints 2 and 3, got %v", fingerprints)
+	}
+	if diff := cmp.Diff(map[FullMetadata][]FingerprintTime{second: {{Fingerprint: 3, Added: start.Add(2 * time.Hour)}}}, delta.DataByMetaData); diff != "" {
+		t.Errorf("unexpected indices in delta: %v", diff)
+	}
+
+	applied := original.WithDelta(delta)
+	if diff := cmp.Diff(query.RangesByCluster, applied.RangesByCluster); diff != "" {
+		t.Errorf("unexpected ranges after applying delta: %v", diff)
+	}
+	if diff := cmp.Diff(query.DataByMetaData, applied.DataByMetaData); diff != "" {
+		t.Errorf("unexpected indices after applying delta: %v", diff)
+	}
+	for fingerprint, data := range query.Data {
+		if !data.Histogram().Equals(applied.Data[fingerprint].Histogram()) {
+			t.Errorf("unexpected data for fingerprint %d after applying delta", fingerprint)
+		}
+	}
+	if _, ok := original.Data[3]; ok {
+		t.Error("applying a delta mutated the original data")
+	}
+	// applying a delta twice must not duplicate any index
+	if diff := cmp.Diff(query.DataByMetaData, applied.WithDelta(delta).DataByMetaData); diff != "" {
+		t.Errorf("unexpected indices after applying delta twice: %v", diff)
+	}
+}
+
+func TestCachedQuery_IncludesDelta(t *testing.T) {
+	compactedAt := time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC)
+	query := &CachedQuery{}
+	if query.IncludesDelta(compactedAt) {
+		t.Error("data that was never compacted can't include deltas")
+	}
+	query.Compacted(compactedAt)
+	if !query.IncludesDelta(compactedAt) || !query.IncludesDelta(compactedAt.Add(-time.Hour)) {
+		t.Error("expected data to include deltas up to compaction")
+	}
+	if query.IncludesDelta(compactedAt.Add(time.Nanosecond)) {
+		t.Error("expected data not to include deltas after compaction")
+	}
+}
@@ -0,0 +1,24 @@
+package pod_scaler
+
+// Names of the metrics usage data is gathered for. The data for each metric
+// is cached in the file named by CachedQueryFile.
+const (
+	MetricNameCPUUsage         = `container_cpu_usage_seconds_total`
+	MetricNameMemoryWorkingSet = `container_memory_working_set_bytes`
+	MetricNameFSUsage          = `container_fs_usage_bytes`
+)
+
+// Quantiles of usage recommended as requests unless a policy overrides them.
+const (
+	// CPURequestQuantile is the quantile of CPU core usage data to use as the CPU request
+	CPURequestQuantile = 0.8
+	// MemoryRequestQuantile is the quantile of memory usage data to use as the memory request
+	MemoryRequestQuantile = 0.8
+	// EphemeralStorageRequestQuantile is the quantile of filesystem usage data to use as the ephemeral storage request
+	EphemeralStorageRequestQuantile = 0.8
+)
+
+// CachedQueryFile is the name of the file the data for the metric is cached in.
+func CachedQueryFile(metricName string) string {
+	return metricName + ".json"
+}
@@ -0,0 +1,258 @@
+package pod_scaler
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"sigs.k8s.io/yaml"
+)
+
+// Policies override how resources are determined and capped for the workloads
+// they match, as the defaults don't suit every repository.
+type Policies struct {
+	Policies []Policy `json:"policies"`
+}
+
+// Policy overrides how resources are determined for matching workloads. Any
+// setting that is not configured falls back to the policies matching the
+// workload less specifically and, finally, to the defaults.
+type Policy struct {
+	// Name identifies the policy.
+	Name string `json:"name"`
+	// Match determines which workloads the policy applies to.
+	Match PolicyMatch `json:"match"`
+
+	CPU              *ResourcePolicy `json:"cpu,omitempty"`
+	Memory           *ResourcePolicy `json:"memory,omitempty"`
+	EphemeralStorage *ResourcePolicy `json:"ephemeral_storage,omitempty"`
+
+	// Limits determines how the limits of matching containers are handled.
+	Limits LimitBehavior `json:"limits,omitempty"`
+}
+
+// PolicyMatch selects workloads by their metadata. Fields that are not set
+// match any value, so an empty match selects all workloads.
+type PolicyMatch struct {
+	Org       string `json:"org,omitempty"`
+	Repo      string `json:"repo,omitempty"`
+	Branch    string `json:"branch,omitempty"`
+	Target    string `json:"target,omitempty"`
+	Container string `json:"container,omitempty"`
+}
+
+// Matches determines if the metadata of a workload is selected.
+func (m PolicyMatch) Matches(meta FullMetadata) bool {
+	for _, field := range []struct{ match, value string }{
+		{match: m.Org, value: meta.Org},
+		{match: m.Repo, value: meta.Repo},
+		{match: m.Branch, value: meta.Branch},
+		{match: m.Target, value: meta.Target},
+		{match: m.Container, value: meta.Container},
+	} {
+		if field.match != "" && field.match != field.value {
+			return false
+		}
+	}
+	return true
+}
+
+// specificity is the number of fields the match selects on.
+func (m PolicyMatch) specificity() int {
+	var specificity int
+	for _, field := range []string{m.Org, m.Repo, m.Branch, m.Target, m.Container} {
+		if field != "" {
+			specificity++
+		}
+	}
+	return specificity
+}
+
+// ResourcePolicy overrides how a resource is determined.
+type ResourcePolicy struct {
+	// Quantile is the quantile of historical usage to recommend as the request.
+	Quantile *float64 `json:"quantile,omitempty"`
+	// Cap is the largest request we set.
+	Cap *resource.Quantity `json:"cap,omitempty"`
+	// Floor is the smallest request we set.
+	Floor *resource.Quantity `json:"floor,omitempty"`
+}
+
+// LimitBehavior determines how the limits of containers are handled.
+type LimitBehavior string
+
+const (
+	// LimitBehaviorDefault handles limits as configured for the admission webhook.
+	LimitBehaviorDefault LimitBehavior = ""
+	// LimitBehaviorReconcile removes CPU limits and raises other limits to twice the requests.
+	LimitBehaviorReconcile LimitBehavior = "reconcile"
+	// LimitBehaviorKeep leaves limits as they were configured.
+	LimitBehaviorKeep LimitBehavior = "keep"
+	// LimitBehaviorRemove removes all limits, so containers never get any.
+	LimitBehaviorRemove LimitBehavior = "remove"
+)
+
+// LoadPolicies reads and validates policies from a file.
+func LoadPolicies(path string) (*Policies, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("could not read policies: %w", err)
+	}
+	return ParsePolicies(raw)
+}
+
+// ParsePolicies parses and validates policies.
+func ParsePolicies(raw []byte) (*Policies, error) {
+	var policies Policies
+	if err := yaml.UnmarshalStrict(raw, &policies); err != nil {
+		return nil, fmt.Errorf("could not unmarshal policies: %w", err)
+	}
+	if err := policies.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid policies: %w", err)
+	}
+	return &policies, nil
+}
+
+// Validate ensures that the policies are well-formed and unambiguous.
+func (p *Policies) Validate() error {
+	var errs []error
+	names := map[string]bool{}
+	matches := map[PolicyMatch]string{}
+	for i, policy := range p.Policies {
+		if policy.Name == "" {
+			errs = append(errs, fmt.Errorf("policies[%d]: name is required", i))
+		} else if names[policy.Name] {
+			errs = append(errs, fmt.Errorf("policies[%d]: name %q is not unique", i, policy.Name))
+		}
+		names[policy.Name] = true
+		if other, duplicate := matches[policy.Match]; duplicate {
+			errs = append(errs, fmt.Errorf("policies[%d]: match is the same as for policy %q", i, other))
+		} else {
+			matches[policy.Match] = policy.Name
+		}
+		if policy.Match.Repo != "" && policy.Match.Org == "" {
+			errs = append(errs, fmt.Errorf("policies[%d]: match.org is required when matching on match.repo", i))
+		}
+		for _, resourcePolicy := range []struct {
+			name   string
+			policy *ResourcePolicy
+		}{
+			{name: "cpu", policy: policy.CPU},
+			{name: "memory", policy: policy.Memory},
+			{name: "ephemeral_storage", policy: policy.EphemeralStorage},
+		} {
+			if err := resourcePolicy.policy.validate(); err != nil {
+				errs = append(errs, fmt.Errorf("policies[%d].%s: %w", i, resourcePolicy.name, err))
+			}
+		}
+		switch policy.Limits {
+		case LimitBehaviorDefault, LimitBehaviorReconcile, LimitBehaviorKeep, LimitBehaviorRemove:
+		default:
+			errs = append(errs, fmt.Errorf("policies[%d]: limits must be one of %q, %q or %q, not %q", i, LimitBehaviorReconcile, LimitBehaviorKeep, LimitBehaviorRemove, policy.Limits))
+		}
+	}
+	return utilerrors.NewAggregate(errs)
+}
+
+func (p *ResourcePolicy) validate() error {
+	if p == nil {
+		return nil
+	}
+	var errs []error
+	if p.Quantile != nil && (*p.Quantile <= 0 || *p.Quantile > 1) {
+		errs = append(errs, fmt.Errorf("quantile must be in (0, 1], not %v", *p.Quantile))
+	}
+	if p.Cap != nil && p.Cap.Sign() <= 0 {
+		errs = append(errs, fmt.Errorf("cap must be positive, not %s", p.Cap.String()))
+	}
+	if p.Floor != nil && p.Floor.Sign() < 0 {
+		errs = append(errs, fmt.Errorf("floor must not be negative, not %s", p.Floor.String()))
+	}
+	if p.Cap != nil && p.Floor != nil && p.Floor.Cmp(*p.Cap) == 1 {
+		errs = append(errs, errors.New("floor must not be larger than cap"))
+	}
+	return utilerrors.NewAggregate(errs)
+}
+
+// AppliedPolicy is the result of applying all policies matching a workload.
+type AppliedPolicy struct {
+	// Policies are the names of the policies that matched, from the least to
+	// the most specific, in the order they were applied.
+	Policies []string `json:"policies,omitempty"`
+
+	CPU              ResourcePolicy `json:"cpu"`
+	Memory           ResourcePolicy `json:"memory"`
+	EphemeralStorage ResourcePolicy `json:"ephemeral_storage"`
+
+	Limits LimitBehavior `json:"limits,omitempty"`
+}
+
+// For applies all policies matching the workload, with the settings of more
+// specific policies overriding those of less specific ones. When policies are
+// equally specific, those listed later take precedence.
+func (p *Policies) For(meta FullMetadata) AppliedPolicy {
+	var applied AppliedPolicy
+	if p == nil {
+		return applied
+	}
+	var matching []Policy
+	for _, policy := range p.Policies {
+		if policy.Match.Matches(meta) {
+			matching = append(matching, policy)
+		}
+	}
+	sort.SliceStable(matching, func(i, j int) bool {
+		return matching[i].Match.specificity() < matching[j].Match.specificity()
+	})
+	for _, policy := range matching {
+		applied.Policies = append(applied.Policies, policy.Name)
+		applied.CPU.override(policy.CPU)
+		applied.Memory.override(policy.Memory)
+		applied.EphemeralStorage.override(policy.EphemeralStorage)
+		if policy.Limits != LimitBehaviorDefault {
+			applied.Limits = policy.Limits
+		}
+	}
+	return applied
+}
+
+func (p *ResourcePolicy) override(with *ResourcePolicy) {
+	if with == nil {
+		return
+	}
+	if with.Quantile != nil {
+		p.Quantile = with.Quantile
+	}
+	if with.Cap != nil {
+		p.Cap = with.Cap
+	}
+	if with.Floor != nil {
+		p.Floor = with.Floor
+	}
+}
+
+// Resource returns the policy applied to the resource.
+func (a AppliedPolicy) Resource(name corev1.ResourceName) ResourcePolicy {
+	switch name {
+	case corev1.ResourceCPU:
+		return a.CPU
+	case corev1.ResourceMemory:
+		return a.Memory
+	case corev1.ResourceEphemeralStorage:
+		return a.EphemeralStorage
+	default:
+		return ResourcePolicy{}
+	}
+}
+
+// QuantileOr returns the quantile set by the policy, or the default.
+func (p ResourcePolicy) QuantileOr(defaultQuantile float64) float64 {
+	if p.Quantile != nil {
+		return *p.Quantile
+	}
+	return defaultQuantile
+}
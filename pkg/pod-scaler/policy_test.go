@@ -0,0 +1,179 @@
+package pod_scaler
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"k8s.io/apimachinery/pkg/api/resource"
+
+	"github.com/openshift/ci-tools/pkg/api"
+	"github.com/openshift/ci-tools/pkg/testhelper"
+)
+
+func TestParsePolicies(t *testing.T) {
+	quantile := func(q float64) *float64 { return &q }
+	quantity := func(q string) *resource.Quantity {
+		parsed := resource.MustParse(q)
+		return &parsed
+	}
+	var testCases = []struct {
+		name          string
+		raw           string
+		expected      *Policies
+		expectedError error
+	}{
+		{
+			name: "valid policies",
+			raw: `policies:
+- name: org
+  match:
+    org: org
+  cpu:
+    quantile: 0.9
+    cap: "16"
+  limits: remove
+- name: container
+  match:
+    org: org
+    repo: repo
+    container: test
+  memory:
+    floor: 1Gi
+    cap: 40Gi
+`,
+			expected: &Policies{Policies: []Policy{
+				{Name: "org", Match: PolicyMatch{Org: "org"}, CPU: &ResourcePolicy{Quantile: quantile(0.9), Cap: quantity("16")}, Limits: LimitBehaviorRemove},
+				{Name: "container", Match: PolicyMatch{Org: "org", Repo: "repo", Container: "test"}, Memory: &ResourcePolicy{Floor: quantity("1Gi"), Cap: quantity("40Gi")}},
+			}},
+		},
+		{
+			name: "unknown field",
+			raw: `policies:
+- name: org
+  match:
+    organization: org
+`,
+			expectedError: errors.New(`could not unmarshal policies: error unmarshaling JSON: while decoding JSON: json: unknown field "organization"`),
+		},
+		{
+			name: "invalid policies",
+			raw: `policies:
+- name: org
+  match:
+    repo: repo
+  cpu:
+    quantile: 1.5
+    cap: "0"
+  memory:
+    floor: 2Gi
+    cap: 1Gi
+  limits: sometimes
+- name: org
+  match:
+    repo: repo
+- match:
+    org: org
+`,
+			expectedError: errors.New(`invalid policies: [policies[0]: match.org is required when matching on match.repo, policies[0].cpu: [quantile must be in (0, 1], not 1.5, cap must be positive, not 0], policies[0].memory: floor must not be larger than cap, policies[0]: limits must be one of "reconcile", "keep" or "remove", not "sometimes", policies[1]: name "org" is not unique, policies[1]: match is the same as for policy "org", policies[1]: match.org is required when matching on match.repo, policies[2]: name is required]`),
+		},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			policies, err := ParsePolicies([]byte(testCase.raw))
+			if diff := cmp.Diff(testCase.expectedError, err, testhelper.EquateErrorMessage); diff != "" {
+				t.Fatalf("unexpected error: %v", diff)
+			}
+			if diff := cmp.Diff(testCase.expected, policies); diff != "" {
+				t.Errorf("unexpected policies: %v", diff)
+			}
+		})
+	}
+}
+
+func TestPolicies_For(t *testing.T) {
+	quantile := func(q float64) *float64 { return &q }
+	quantity := func(q string) *resource.Quantity {
+		parsed := resource.MustParse(q)
+		return &parsed
+	}
+	policies := &Policies{Policies: []Policy{
+		{
+			Name:   "container",
+			Match:  PolicyMatch{Org: "org", Repo: "repo", Container: "test"},
+			CPU:    &ResourcePolicy{Cap: quantity("20")},
+			Limits: LimitBehaviorKeep,
+		},
+		{
+			Name:   "org",
+			Match:  PolicyMatch{Org: "org"},
+			CPU:    &ResourcePolicy{Quantile: quantile(0.9), Cap: quantity("16")},
+			Memory: &ResourcePolicy{Floor: quantity("1Gi")},
+			Limits: LimitBehaviorRemove,
+		},
+		{
+			Name:  "target",
+			Match: PolicyMatch{Target: "e2e"},
+			CPU:   &ResourcePolicy{Quantile: quantile(0.95)},
+		},
+		{
+			Name:  "everything",
+			Match: PolicyMatch{},
+			EphemeralStorage: &ResourcePolicy{
+				Cap: quantity("100Gi"),
+			},
+		},
+	}}
+	var testCases = []struct {
+		name     string
+		policies *Policies
+		meta     FullMetadata
+		expected AppliedPolicy
+	}{
+		{
+			name: "no policies",
+			meta: FullMetadata{Metadata: api.Metadata{Org: "org"}},
+		},
+		{
+			name:     "only the policy matching everything",
+			policies: policies,
+			meta:     FullMetadata{Metadata: api.Metadata{Org: "other"}, Container: "test"},
+			expected: AppliedPolicy{
+				Policies:         []string{"everything"},
+				EphemeralStorage: ResourcePolicy{Cap: quantity("100Gi")},
+			},
+		},
+		{
+			name:     "more specific policies override less specific ones",
+			policies: policies,
+			meta:     FullMetadata{Metadata: api.Metadata{Org: "org", Repo: "repo", Branch: "branch"}, Container: "test"},
+			expected: AppliedPolicy{
+				Policies:         []string{"everything", "org", "container"},
+				CPU:              ResourcePolicy{Quantile: quantile(0.9), Cap: quantity("20")},
+				Memory:           ResourcePolicy{Floor: quantity("1Gi")},
+				EphemeralStorage: ResourcePolicy{Cap: quantity("100Gi")},
+				Limits:           LimitBehaviorKeep,
+			},
+		},
+		{
+			name:     "equally specific policies apply in order",
+			policies: policies,
+			meta:     FullMetadata{Metadata: api.Metadata{Org: "org", Repo: "other"}, Target: "e2e", Container: "test"},
+			expected: AppliedPolicy{
+				Policies:         []string{"everything", "org", "target"},
+				CPU:              ResourcePolicy{Quantile: quantile(0.95), Cap: quantity("16")},
+				Memory:           ResourcePolicy{Floor: quantity("1Gi")},
+				EphemeralStorage: ResourcePolicy{Cap: quantity("100Gi")},
+				Limits:           LimitBehaviorRemove,
+			},
+		},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			if diff := cmp.Diff(testCase.expected, testCase.policies.For(testCase.meta)); diff != "" {
+				t.Errorf("unexpected applied policy: %v", diff)
+			}
+		})
+	}
+}
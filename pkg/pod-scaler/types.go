@@ -65,6 +65,10 @@ type CachedQuery struct {
 	// The list of fingerprintTimes is guaranteed to be unique for any set of labels
 	// and will never contain more than twenty-five items.
 	DataByMetaData map[FullMetadata][]FingerprintTime `json:"data_by_meta_data"`
+	// DeltasUntil is the time at which the latest delta included in this data was
+	// stored, if deltas were compacted into it. Deltas stored up to this time must
+	// not be applied again.
+	DeltasUntil *time.Time `json:"deltas_until,omitempty"`
 }
 
 // FingerprintTime holds both the fingerprint for referencing the data, and the time at which it was added for later pruning
@@ -326,6 +330,22 @@ func coalesceOnce(input []TimeRange) []TimeRange {
 	return input
 }
 
+// ValueAtQuantile determines the value at the quantile of all data recorded for
+// the fully-qualified set of labels, if any data was recorded for them.
+func (q *CachedQuery) ValueAtQuantile(meta FullMetadata, quantile float64) (float64, bool) {
+	fingerprintTimes, ok := q.DataByMetaData[meta]
+	if !ok || len(fingerprintTimes) == 0 {
+		return 0, false
+	}
+	overall := circonusllhist.New()
+	for _, fingerprintTime := range fingerprintTimes {
+		if data, ok := q.Data[fingerprintTime.Fingerprint]; ok {
+			overall.Merge(data.Histogram())
+		}
+	}
+	return overall.ValueAtQuantile(quantile), true
+}
+
 // Prune ensures that no identifying set of labels contains more than twenty-five entries,
 // as well as removing any data that was added more than 90 days ago.
 // We know that an entry fingerprint can only exist for one fully-qualified label set,
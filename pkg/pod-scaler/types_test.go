@@ -1037,3 +1037,37 @@ func TestMetadataFor(t *testing.T) {
 		})
 	}
 }
+
+func TestCachedQuery_ValueAtQuantile(t *testing.T) {
+	histogram := func(values ...float64) *circonusllhist.HistogramWithoutLookups {
+		inner := circonusllhist.New()
+		for _, value := range values {
+			if err := inner.RecordValue(value); err != nil {
+				t.Fatalf("failed to insert value into histogram, this should never happen: %v", err)
+			}
+		}
+		return circonusllhist.NewHistogramWithoutLookups(inner)
+	}
+	meta := FullMetadata{Target: "target", Step: "step", Pod: "target-step", Container: "test"}
+	query := CachedQuery{
+		Data: map[model.Fingerprint]*circonusllhist.HistogramWithoutLookups{
+			1: histogram(1, 2),
+			2: histogram(3, 4),
+		},
+		DataByMetaData: map[FullMetadata][]FingerprintTime{
+			meta: {{Fingerprint: 1}, {Fingerprint: 2}},
+		},
+	}
+
+	if _, ok := query.ValueAtQuantile(FullMetadata{Target: "other"}, 0.5); ok {
+		t.Error("expected no value for metadata without data")
+	}
+	low, ok := query.ValueAtQuantile(meta, 0)
+	if !ok {
+		t.Fatal("expected a value for metadata with data")
+	}
+	high, _ := query.ValueAtQuantile(meta, 1)
+	if low >= 2 || high < 4 {
+		t.Errorf("expected values to span all fingerprints, got %v to %v", low, high)
+	}
+}
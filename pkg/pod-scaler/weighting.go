@@ -0,0 +1,203 @@
+package pod_scaler
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/openhistogram/circonusllhist"
+)
+
+// Weighting determines how much each execution in the historical data counts
+// towards a recommendation. Recent executions are more representative of the
+// usage of future ones, so the weight of data decays exponentially with its age.
+type Weighting struct {
+	// HalfLife is the age at which the data of an execution is given half of
+	// the weight of fresh data. All data is weighted equally when unset.
+	HalfLife time.Duration `json:"half_life,omitempty"`
+}
+
+// Enabled determines if data is weighted by recency at all.
+func (w Weighting) Enabled() bool {
+	return w.HalfLife > 0
+}
+
+// WeightedFingerprintTime records the weight given to the data of a fingerprint
+// when recommending resources, so the effect of the weighting can be explained.
+type WeightedFingerprintTime struct {
+	FingerprintTime `json:",inline"`
+	// Weight is the relative weight of the data, between 0 and 1.
+	Weight float64 `json:"weight"`
+}
+
+// Weigh determines the weight of the data of every fingerprint at the given time.
+func (w Weighting) Weigh(fingerprintTimes []FingerprintTime, now time.Time) []WeightedFingerprintTime {
+	weighted := make([]WeightedFingerprintTime, 0, len(fingerprintTimes))
+	for _, fingerprintTime := range fingerprintTimes {
+		weight := 1.0
+		if w.Enabled() {
+			// data from the future is not expected, but is certainly not stale
+			if age := now.Sub(fingerprintTime.Added); age > 0 {
+				weight = math.Pow(2, -float64(age)/float64(w.HalfLife))
+			}
+		}
+		weighted = append(weighted, WeightedFingerprintTime{FingerprintTime: fingerprintTime, Weight: weight})
+	}
+	return weighted
+}
+
+// Trend describes a change in the resource usage of recent executions.
+type Trend struct {
+	// Slope is the change in usage at the requested quantile, per day.
+	Slope float64 `json:"slope"`
+	// Projected is the usage we expect for the next execution.
+	Projected float64 `json:"projected"`
+}
+
+// Recommendation is the usage at a quantile of the historical data for some
+// container, along with the data that determined it.
+type Recommendation struct {
+	// Value is the recommended value for the resource.
+	Value float64
+	// Merged holds the weighted data of all executions.
+	Merged *circonusllhist.Histogram
+	// Weighted holds the weights of the data of every execution.
+	Weighted []WeightedFingerprintTime
+	// Trend is set when the usage is growing, in which case the recommendation
+	// follows the projected usage instead of lagging behind it.
+	Trend *Trend
+}
+
+const (
+	// weightResolution scales the counts in histograms before they are weighted,
+	// as histograms can only hold integral counts
+	weightResolution = 100
+	// minimumTrendSamples is the number of executions we need to see before we
+	// try to determine a trend in their usage
+	minimumTrendSamples = 3
+)
+
+// Recommend determines the value at the quantile of the data of the given fingerprints.
+// When the weighting is enabled, recent data is given more weight and the recommendation
+// follows upward trends in usage.
+func (q *CachedQuery) Recommend(fingerprintTimes []FingerprintTime, quantile float64, weighting Weighting, now time.Time) Recommendation {
+	recommendation := Recommendation{
+		Merged:   circonusllhist.New(),
+		Weighted: weighting.Weigh(fingerprintTimes, now),
+	}
+	for _, weighted := range recommendation.Weighted {
+		data, ok := q.Data[weighted.Fingerprint]
+		if !ok {
+			continue
+		}
+		if !weighting.Enabled() {
+			recommendation.Merged.Merge(data.Histogram())
+			continue
+		}
+		recommendation.Merged.Merge(scaled(data.Histogram(), weighted.Weight*weightResolution))
+	}
+	recommendation.Value = recommendation.Merged.ValueAtQuantile(quantile)
+	if !weighting.Enabled() {
+		return recommendation
+	}
+	if trend := q.trend(recommendation.Weighted, quantile, now); trend != nil && trend.Projected > recommendation.Value {
+		recommendation.Trend = trend
+		recommendation.Value = trend.Projected
+	}
+	return recommendation
+}
+
+// trend fits the usage at the quantile of every execution over time with a weighted
+// linear regression, projecting the usage of an execution started now. Projections
+// never exceed the usage we have seen, as they are not reliable beyond it.
+func (q *CachedQuery) trend(weighted []WeightedFingerprintTime, quantile float64, now time.Time) *Trend {
+	var xs, ys, ws []float64
+	for _, item := range weighted {
+		data, ok := q.Data[item.Fingerprint]
+		if !ok {
+			continue
+		}
+		xs = append(xs, item.Added.Sub(now).Hours()/24)
+		ys = append(ys, data.Histogram().ValueAtQuantile(quantile))
+		ws = append(ws, item.Weight)
+	}
+	if len(xs) < minimumTrendSamples {
+		return nil
+	}
+	var sumW, meanX, meanY, maxY float64
+	for i := range xs {
+		sumW += ws[i]
+		meanX += ws[i] * xs[i]
+		meanY += ws[i] * ys[i]
+		maxY = math.Max(maxY, ys[i])
+	}
+	if sumW == 0 {
+		return nil
+	}
+	meanX, meanY = meanX/sumW, meanY/sumW
+	var covariance, variance float64
+	for i := range xs {
+		covariance += ws[i] * (xs[i] - meanX) * (ys[i] - meanY)
+		variance += ws[i] * (xs[i] - meanX) * (xs[i] - meanX)
+	}
+	if variance == 0 {
+		return nil
+	}
+	slope := covariance / variance
+	if slope <= 0 {
+		return nil
+	}
+	// the intercept of the regression is the projection for now, as x is relative to it
+	return &Trend{Slope: slope, Projected: math.Min(meanY-slope*meanX, maxY)}
+}
+
+// scaled creates a copy of the histogram with the counts in every bin scaled
+// by the factor. Histograms do not expose their bins, so we read them from
+// their string representation and record the midpoint of every bin again.
+func scaled(histogram *circonusllhist.Histogram, factor float64) *circonusllhist.Histogram {
+	output := circonusllhist.New()
+	for _, bin := range histogram.DecStrings() {
+		value, count, err := parseBin(bin)
+		if err != nil {
+			continue
+		}
+		if n := int64(math.Round(float64(count) * factor)); n > 0 {
+			// errors are only returned for values that cannot be represented,
+			// which is not possible for values read from another histogram
+			_ = output.RecordValues(value, n)
+		}
+	}
+	return output
+}
+
+// parseBin reads the midpoint and count of a bin formatted like `H[1.2e+01]=5`
+func parseBin(bin string) (float64, uint64, error) {
+	parts := strings.SplitN(strings.TrimPrefix(bin, "H["), "]=", 2)
+	if len(parts) != 2 {
+		return 0, 0, fmt.Errorf("malformed bin %q", bin)
+	}
+	count, err := strconv.ParseUint(parts[1], 10, 64)
+	if err != nil {
+		return 0, 0, fmt.Errorf("malformed count in bin %q: %w", bin, err)
+	}
+	mantissa, exponent, ok := strings.Cut(parts[0], "e")
+	if !ok {
+		return 0, 0, fmt.Errorf("malformed value in bin %q", bin)
+	}
+	m, err := strconv.ParseFloat(mantissa, 64)
+	if err != nil {
+		return 0, 0, fmt.Errorf("malformed value in bin %q: %w", bin, err)
+	}
+	e, err := strconv.Atoi(exponent)
+	if err != nil {
+		return 0, 0, fmt.Errorf("malformed value in bin %q: %w", bin, err)
+	}
+	if m == 0 {
+		return 0, count, nil
+	}
+	// bins hold two significant digits, so the midpoint is half a unit in the
+	// second digit away from the edge, which is what is formatted
+	return math.Copysign(math.Abs(m)+0.05, m) * math.Pow10(e), count, nil
+}
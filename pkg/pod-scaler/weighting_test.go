@@ -0,0 +1,172 @@
+package pod_scaler
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	"github.com/openhistogram/circonusllhist"
+	"github.com/prometheus/common/model"
+)
+
+func TestWeighting_Weigh(t *testing.T) {
+	now := time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC)
+	fingerprintTimes := []FingerprintTime{
+		{Fingerprint: 1, Added: now},
+		{Fingerprint: 2, Added: now.Add(-7 * 24 * time.Hour)},
+		{Fingerprint: 3, Added: now.Add(-14 * 24 * time.Hour)},
+		{Fingerprint: 4, Added: now.Add(time.Hour)},
+	}
+	var testCases = []struct {
+		name      string
+		weighting Weighting
+		expected  []float64
+	}{
+		{
+			name:     "no half-life weighs everything equally",
+			expected: []float64{1, 1, 1, 1},
+		},
+		{
+			name:      "weight halves every half-life",
+			weighting: Weighting{HalfLife: 7 * 24 * time.Hour},
+			expected:  []float64{1, 0.5, 0.25, 1},
+		},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			var weights []float64
+			for _, weighted := range testCase.weighting.Weigh(fingerprintTimes, now) {
+				weights = append(weights, weighted.Weight)
+			}
+			if diff := cmp.Diff(testCase.expected, weights, cmpopts.EquateApprox(0, 1e-9)); diff != "" {
+				t.Errorf("%s: got incorrect weights: %v", testCase.name, diff)
+			}
+		})
+	}
+}
+
+func TestCachedQuery_Recommend(t *testing.T) {
+	now := time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC)
+	day := 24 * time.Hour
+	histogram := func(values ...float64) *circonusllhist.HistogramWithoutLookups {
+		h := circonusllhist.New(circonusllhist.NoLookup())
+		for _, value := range values {
+			if err := h.RecordValue(value); err != nil {
+				t.Fatalf("failed to record value: %v", err)
+			}
+		}
+		return circonusllhist.NewHistogramWithoutLookups(h)
+	}
+	repeated := func(value float64, n int) []float64 {
+		var values []float64
+		for i := 0; i < n; i++ {
+			values = append(values, value)
+		}
+		return values
+	}
+	var testCases = []struct {
+		name             string
+		data             map[model.Fingerprint]*circonusllhist.HistogramWithoutLookups
+		fingerprintTimes []FingerprintTime
+		weighting        Weighting
+		expected         float64
+		expectedTrend    bool
+	}{
+		{
+			name: "without weighting, old data dominates",
+			data: map[model.Fingerprint]*circonusllhist.HistogramWithoutLookups{
+				1: histogram(repeated(1000, 100)...),
+				2: histogram(repeated(1000, 100)...),
+				3: histogram(repeated(1000, 100)...),
+				4: histogram(repeated(100, 100)...),
+			},
+			fingerprintTimes: []FingerprintTime{
+				{Fingerprint: 1, Added: now.Add(-60 * day)},
+				{Fingerprint: 2, Added: now.Add(-59 * day)},
+				{Fingerprint: 3, Added: now.Add(-58 * day)},
+				{Fingerprint: 4, Added: now},
+			},
+			expected: 1000,
+		},
+		{
+			name: "with weighting, recent data dominates and no upward trend is found",
+			data: map[model.Fingerprint]*circonusllhist.HistogramWithoutLookups{
+				1: histogram(repeated(1000, 100)...),
+				2: histogram(repeated(1000, 100)...),
+				3: histogram(repeated(1000, 100)...),
+				4: histogram(repeated(100, 100)...),
+			},
+			fingerprintTimes: []FingerprintTime{
+				{Fingerprint: 1, Added: now.Add(-60 * day)},
+				{Fingerprint: 2, Added: now.Add(-59 * day)},
+				{Fingerprint: 3, Added: now.Add(-58 * day)},
+				{Fingerprint: 4, Added: now},
+			},
+			weighting: Weighting{HalfLife: 7 * day},
+			expected:  100,
+		},
+		{
+			name: "with weighting, growing usage is projected",
+			data: map[model.Fingerprint]*circonusllhist.HistogramWithoutLookups{
+				1: histogram(repeated(100, 100)...),
+				2: histogram(repeated(200, 100)...),
+				3: histogram(repeated(300, 100)...),
+				4: histogram(repeated(400, 100)...),
+			},
+			fingerprintTimes: []FingerprintTime{
+				{Fingerprint: 1, Added: now.Add(-3 * day)},
+				{Fingerprint: 2, Added: now.Add(-2 * day)},
+				{Fingerprint: 3, Added: now.Add(-1 * day)},
+				{Fingerprint: 4, Added: now},
+			},
+			weighting:     Weighting{HalfLife: 7 * day},
+			expected:      400,
+			expectedTrend: true,
+		},
+		{
+			name: "missing data is ignored",
+			data: map[model.Fingerprint]*circonusllhist.HistogramWithoutLookups{
+				1: histogram(repeated(100, 100)...),
+			},
+			fingerprintTimes: []FingerprintTime{
+				{Fingerprint: 1, Added: now},
+				{Fingerprint: 2, Added: now},
+			},
+			weighting: Weighting{HalfLife: 7 * day},
+			expected:  100,
+		},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			query := &CachedQuery{Data: testCase.data}
+			recommendation := query.Recommend(testCase.fingerprintTimes, 0.8, testCase.weighting, now)
+			// histograms have two significant digits
+			if math.Abs(recommendation.Value-testCase.expected)/testCase.expected > 0.1 {
+				t.Errorf("%s: expected a recommendation of about %v, got %v", testCase.name, testCase.expected, recommendation.Value)
+			}
+			if actual := recommendation.Trend != nil; actual != testCase.expectedTrend {
+				t.Errorf("%s: expected trend: %v, got %v", testCase.name, testCase.expectedTrend, recommendation.Trend)
+			}
+			if len(recommendation.Weighted) != len(testCase.fingerprintTimes) {
+				t.Errorf("%s: expected weights for %d fingerprints, got %d", testCase.name, len(testCase.fingerprintTimes), len(recommendation.Weighted))
+			}
+		})
+	}
+}
+
+func TestScaled(t *testing.T) {
+	original := circonusllhist.New()
+	for _, value := range []float64{0, 0.0019, 0.29, 1, 12, 99, 1234, 5.7e9} {
+		if err := original.RecordValues(value, 3); err != nil {
+			t.Fatalf("failed to record value: %v", err)
+		}
+	}
+	if diff := cmp.Diff(original.DecStrings(), scaled(original, 1).DecStrings()); diff != "" {
+		t.Errorf("scaling by one changed the histogram: %v", diff)
+	}
+	if actual, expected := scaled(original, 2).Count(), 2*original.Count(); actual != expected {
+		t.Errorf("expected %d samples after scaling, got %d", expected, actual)
+	}
+}
@@ -0,0 +1,235 @@
+// Package bundle implements a signed, self-contained snapshot of the step
+// registry and ci-operator configurations. A bundle allows configurations to
+// be resolved without a checkout of the release repository or access to the
+// configresolver, e.g. in disconnected environments.
+package bundle
+
+import (
+	"bytes"
+	"compress/gzip"
+	"crypto/ed25519"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/openshift/ci-tools/pkg/api"
+	"github.com/openshift/ci-tools/pkg/config"
+	"github.com/openshift/ci-tools/pkg/load"
+	"github.com/openshift/ci-tools/pkg/load/agents"
+	"github.com/openshift/ci-tools/pkg/registry"
+	gziputil "github.com/openshift/ci-tools/pkg/util/gzip"
+)
+
+// FormatVersion identifies the layout of the bundles written by this package.
+// Bundles in any other format are rejected when read.
+const FormatVersion = "v1"
+
+// Bundle holds everything needed to resolve ci-operator configurations offline
+type Bundle struct {
+	FormatVersion string `json:"format_version"`
+	// RegistryVersion is the content address of the bundled registry, as
+	// computed by registry.Version. The registry fields are serialized without
+	// omitempty, so that reading the bundle yields the same version.
+	RegistryVersion string                   `json:"registry_version"`
+	References      registry.ReferenceByName `json:"references"`
+	Chains          registry.ChainByName     `json:"chains"`
+	Workflows       registry.WorkflowByName  `json:"workflows"`
+	Observers       registry.ObserverByName  `json:"observers"`
+	ClusterProfiles api.ClusterProfilesMap   `json:"cluster_profiles,omitempty"`
+	Documentation   map[string]string        `json:"documentation,omitempty"`
+	Metadata        api.RegistryMetadata     `json:"metadata,omitempty"`
+	Configs         config.ByOrgRepo         `json:"configs,omitempty"`
+}
+
+// envelope is the serialized form of a bundle: the bundle content and the
+// signature over it. Envelopes are stored gzip-compressed.
+type envelope struct {
+	Payload   []byte `json:"payload"`
+	Signature []byte `json:"signature"`
+}
+
+// Load creates a bundle from the registry and ci-operator configurations on
+// disk. The configuration directory is optional. Components of federated
+// registries are bundled under their qualified names, e.g. `myteam/install`.
+func Load(registryPath, configPath string, flags load.RegistryFlag, federated []load.FederatedRegistry) (*Bundle, error) {
+	references, chains, workflows, clusterProfiles, documentation, metadata, observers, err := load.FederatedRegistries(registryPath, flags|load.RegistryMetadata|load.RegistryDocumentation, federated)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load registry: %w", err)
+	}
+	version, err := registry.Version(references, chains, workflows, observers)
+	if err != nil {
+		return nil, fmt.Errorf("failed to determine registry version: %w", err)
+	}
+	b := &Bundle{
+		FormatVersion:   FormatVersion,
+		RegistryVersion: version,
+		References:      references,
+		Chains:          chains,
+		Workflows:       workflows,
+		Observers:       observers,
+		ClusterProfiles: clusterProfiles,
+		Documentation:   documentation,
+		Metadata:        metadata,
+	}
+	if configPath != "" {
+		if b.Configs, err = config.LoadByOrgRepo(configPath); err != nil {
+			return nil, fmt.Errorf("failed to load ci-operator configuration: %w", err)
+		}
+	}
+	return b, nil
+}
+
+// Write signs the bundle with the key and writes it to w
+func Write(w io.Writer, b *Bundle, key ed25519.PrivateKey) error {
+	payload, err := json.Marshal(b)
+	if err != nil {
+		return fmt.Errorf("failed to serialize bundle: %w", err)
+	}
+	raw, err := json.Marshal(envelope{Payload: payload, Signature: ed25519.Sign(key, payload)})
+	if err != nil {
+		return fmt.Errorf("failed to serialize bundle envelope: %w", err)
+	}
+	gz := gzip.NewWriter(w)
+	if _, err := gz.Write(raw); err != nil {
+		return fmt.Errorf("failed to write bundle: %w", err)
+	}
+	return gz.Close()
+}
+
+// WriteFile signs the bundle with the key and writes it to path
+func WriteFile(path string, b *Bundle, key ed25519.PrivateKey) error {
+	var buf bytes.Buffer
+	if err := Write(&buf, b, key); err != nil {
+		return err
+	}
+	return os.WriteFile(path, buf.Bytes(), 0644)
+}
+
+// Read reads a bundle from r, verifying its signature with the key and its
+// content against the recorded registry version
+func Read(r io.Reader, key ed25519.PublicKey) (*Bundle, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read bundle: %w", err)
+	}
+	if data, err = gziputil.ReadBytesMaybeGZIP(data); err != nil {
+		return nil, fmt.Errorf("failed to decompress bundle: %w", err)
+	}
+	var e envelope
+	if err := json.Unmarshal(data, &e); err != nil {
+		return nil, fmt.Errorf("failed to parse bundle envelope: %w", err)
+	}
+	if !ed25519.Verify(key, e.Payload, e.Signature) {
+		return nil, errors.New("bundle signature verification failed")
+	}
+	var b Bundle
+	if err := json.Unmarshal(e.Payload, &b); err != nil {
+		return nil, fmt.Errorf("failed to parse bundle: %w", err)
+	}
+	if b.FormatVersion != FormatVersion {
+		return nil, fmt.Errorf("unsupported bundle format %q, expected %q", b.FormatVersion, FormatVersion)
+	}
+	if err := registry.Validate(b.References, b.Chains, b.Workflows, b.Observers); err != nil {
+		return nil, fmt.Errorf("bundled registry is invalid: %w", err)
+	}
+	version, err := registry.Version(b.References, b.Chains, b.Workflows, b.Observers)
+	if err != nil {
+		return nil, fmt.Errorf("failed to determine registry version: %w", err)
+	}
+	if version != b.RegistryVersion {
+		return nil, fmt.Errorf("bundle records registry version %s, but its content is at version %s", b.RegistryVersion, version)
+	}
+	return &b, nil
+}
+
+// ReadFile reads and verifies the bundle stored at path
+func ReadFile(path string, key ed25519.PublicKey) (*Bundle, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open bundle: %w", err)
+	}
+	defer f.Close()
+	return Read(f, key)
+}
+
+// Resolver returns a resolver for the bundled registry
+func (b *Bundle) Resolver() registry.Resolver {
+	return registry.NewResolver(b.References, b.Chains, b.Workflows, b.Observers, registry.WithDeprecations(load.Deprecations(b.Metadata), nil))
+}
+
+// Loader returns a loader for the bundled registry, for use with
+// agents.NewStaticRegistryAgent
+func (b *Bundle) Loader() agents.RegistryLoader {
+	return func() (registry.ReferenceByName, registry.ChainByName, registry.WorkflowByName, api.ClusterProfilesMap, map[string]string, api.RegistryMetadata, registry.ObserverByName, error) {
+		return b.References, b.Chains, b.Workflows, b.ClusterProfiles, b.Documentation, b.Metadata, b.Observers, nil
+	}
+}
+
+// ResolveConfig resolves the configuration against the bundled registry.
+// Configurations pinned to another registry version are rejected.
+func (b *Bundle) ResolveConfig(c api.ReleaseBuildConfiguration) (api.ReleaseBuildConfiguration, error) {
+	if c.RegistryVersion != "" && c.RegistryVersion != b.RegistryVersion {
+		return api.ReleaseBuildConfiguration{}, fmt.Errorf("configuration pins registry version %s, but the bundle contains registry version %s", c.RegistryVersion, b.RegistryVersion)
+	}
+	resolved, err := registry.ResolveConfig(b.Resolver(), c)
+	if err != nil {
+		return api.ReleaseBuildConfiguration{}, err
+	}
+	resolved.RegistryVersion = b.RegistryVersion
+	return resolved, nil
+}
+
+// LoadPrivateKey reads a PEM-encoded PKCS #8 ed25519 private key, as created
+// by `openssl genpkey -algorithm ed25519`
+func LoadPrivateKey(path string) (ed25519.PrivateKey, error) {
+	der, err := readPEM(path, "PRIVATE KEY")
+	if err != nil {
+		return nil, err
+	}
+	key, err := x509.ParsePKCS8PrivateKey(der)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse private key in %s: %w", path, err)
+	}
+	ret, ok := key.(ed25519.PrivateKey)
+	if !ok {
+		return nil, fmt.Errorf("private key in %s is a %T, not an ed25519 key", path, key)
+	}
+	return ret, nil
+}
+
+// LoadPublicKey reads a PEM-encoded PKIX ed25519 public key, as created by
+// `openssl pkey -pubout`
+func LoadPublicKey(path string) (ed25519.PublicKey, error) {
+	der, err := readPEM(path, "PUBLIC KEY")
+	if err != nil {
+		return nil, err
+	}
+	key, err := x509.ParsePKIXPublicKey(der)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse public key in %s: %w", path, err)
+	}
+	ret, ok := key.(ed25519.PublicKey)
+	if !ok {
+		return nil, fmt.Errorf("public key in %s is a %T, not an ed25519 key", path, key)
+	}
+	return ret, nil
+}
+
+func readPEM(path, blockType string) ([]byte, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read key: %w", err)
+	}
+	block, _ := pem.Decode(raw)
+	if block == nil {
+		return nil, fmt.Errorf("no PEM data found in %s", path)
+	}
+	if block.Type != blockType {
+		return nil, fmt.Errorf("expected a %q PEM block in %s, found %q", blockType, path, block.Type)
+	}
+	return block.Bytes, nil
+}
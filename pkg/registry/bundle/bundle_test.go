@@ -0,0 +1,321 @@
+package bundle
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+
+	"github.com/openshift/ci-tools/pkg/api"
+	"github.com/openshift/ci-tools/pkg/load"
+	"github.com/openshift/ci-tools/pkg/testhelper"
+)
+
+func writeFiles(t *testing.T, root string, files map[string]string) {
+	t.Helper()
+	for name, content := range files {
+		path := filepath.Join(root, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func testBundle(t *testing.T) *Bundle {
+	t.Helper()
+	root := t.TempDir()
+	writeFiles(t, filepath.Join(root, "registry"), map[string]string{
+		"cluster-profiles/cluster-profiles-config.yaml": `- profile: aws
+  secret: cluster-secrets-aws
+`,
+		"step-commands.sh": "make test\n",
+		"step-ref.yaml": `ref:
+  as: step
+  from: src
+  commands: step-commands.sh
+  resources:
+    requests:
+      cpu: 100m
+`,
+		"workflow-workflow.yaml": `workflow:
+  as: workflow
+  steps:
+    cluster_profile: aws
+    test:
+    - ref: step
+`,
+	})
+	writeFiles(t, filepath.Join(root, "myteam"), map[string]string{
+		"gather-commands.sh": "gather\n",
+		"gather-ref.yaml": `ref:
+  as: gather
+  from: src
+  commands: gather-commands.sh
+  resources:
+    requests:
+      cpu: 100m
+`,
+	})
+	writeFiles(t, filepath.Join(root, "config"), map[string]string{
+		"org/repo/org-repo-main.yaml": `resources:
+  '*':
+    requests:
+      cpu: 10m
+tests:
+- as: e2e
+  steps:
+    post:
+    - ref: myteam/gather
+    workflow: workflow
+zz_generated_metadata:
+  branch: main
+  org: org
+  repo: repo
+`,
+		"org/other/org-other-main.yaml": `resources:
+  '*':
+    requests:
+      cpu: 10m
+tests:
+- as: unit
+  commands: make unit
+  container:
+    from: src
+zz_generated_metadata:
+  branch: main
+  org: org
+  repo: other
+`,
+	})
+	b, err := Load(filepath.Join(root, "registry"), filepath.Join(root, "config"), load.RegistryFlat, []load.FederatedRegistry{{Namespace: "myteam", Path: filepath.Join(root, "myteam")}})
+	if err != nil {
+		t.Fatalf("failed to load bundle: %v", err)
+	}
+	return b
+}
+
+func TestWriteRead(t *testing.T) {
+	public, private, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	otherPublic, _, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b := testBundle(t)
+
+	signed := func(mutate func(*Bundle)) []byte {
+		copied := *b
+		if mutate != nil {
+			mutate(&copied)
+		}
+		var buf bytes.Buffer
+		if err := Write(&buf, &copied, private); err != nil {
+			t.Fatalf("failed to write bundle: %v", err)
+		}
+		return buf.Bytes()
+	}
+	tampered := func() []byte {
+		payload, err := json.Marshal(b)
+		if err != nil {
+			t.Fatal(err)
+		}
+		raw, err := json.Marshal(envelope{Payload: payload, Signature: ed25519.Sign(private, append(payload, ' '))})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return raw
+	}
+
+	for _, tc := range []struct {
+		name        string
+		data        []byte
+		key         ed25519.PublicKey
+		expectedErr error
+	}{
+		{
+			name: "valid bundle",
+			data: signed(nil),
+			key:  public,
+		},
+		{
+			name:        "signed with another key",
+			data:        signed(nil),
+			key:         otherPublic,
+			expectedErr: errors.New("bundle signature verification failed"),
+		},
+		{
+			name:        "signature does not match payload",
+			data:        tampered(),
+			key:         public,
+			expectedErr: errors.New("bundle signature verification failed"),
+		},
+		{
+			name:        "unknown format",
+			data:        signed(func(b *Bundle) { b.FormatVersion = "v0" }),
+			key:         public,
+			expectedErr: errors.New(`unsupported bundle format "v0", expected "v1"`),
+		},
+		{
+			name:        "content does not match version",
+			data:        signed(func(b *Bundle) { b.RegistryVersion = "abc" }),
+			key:         public,
+			expectedErr: errors.New("bundle records registry version abc, but its content is at version " + b.RegistryVersion),
+		},
+		{
+			name:        "invalid registry",
+			data:        signed(func(b *Bundle) { b.References = nil }),
+			key:         public,
+			expectedErr: errors.New("bundled registry is invalid: workflow/workflow: invalid step reference: step"),
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			read, err := Read(bytes.NewReader(tc.data), tc.key)
+			if diff := cmp.Diff(tc.expectedErr, err, testhelper.EquateErrorMessage); diff != "" {
+				t.Fatalf("unexpected error: %s", diff)
+			}
+			if err != nil {
+				return
+			}
+			if diff := cmp.Diff(b, read, cmpopts.EquateEmpty()); diff != "" {
+				t.Errorf("read bundle differs from the written one: %s", diff)
+			}
+		})
+	}
+}
+
+func TestLoadKeys(t *testing.T) {
+	public, private, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	privateDER, err := x509.MarshalPKCS8PrivateKey(private)
+	if err != nil {
+		t.Fatal(err)
+	}
+	publicDER, err := x509.MarshalPKIXPublicKey(public)
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir := t.TempDir()
+	privatePath, publicPath := filepath.Join(dir, "key.pem"), filepath.Join(dir, "key.pub")
+	writeFiles(t, dir, map[string]string{
+		"key.pem": string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: privateDER})),
+		"key.pub": string(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: publicDER})),
+	})
+
+	loadedPrivate, err := LoadPrivateKey(privatePath)
+	if err != nil {
+		t.Fatalf("failed to load private key: %v", err)
+	}
+	if !private.Equal(loadedPrivate) {
+		t.Error("loaded private key differs")
+	}
+	loadedPublic, err := LoadPublicKey(publicPath)
+	if err != nil {
+		t.Fatalf("failed to load public key: %v", err)
+	}
+	if !public.Equal(loadedPublic) {
+		t.Error("loaded public key differs")
+	}
+	expected := errors.New(`expected a "PUBLIC KEY" PEM block in ` + privatePath + `, found "PRIVATE KEY"`)
+	if _, err := LoadPublicKey(privatePath); err == nil || err.Error() != expected.Error() {
+		t.Errorf("expected %v, got %v", expected, err)
+	}
+}
+
+func TestResolverClient(t *testing.T) {
+	b := testBundle(t)
+	client := NewResolverClient(b)
+
+	config, err := client.Config(&api.Metadata{Org: "org", Repo: "repo", Branch: "main"})
+	if err != nil {
+		t.Fatalf("failed to resolve config: %v", err)
+	}
+	if config.RegistryVersion != b.RegistryVersion {
+		t.Errorf("expected registry version %s, got %s", b.RegistryVersion, config.RegistryVersion)
+	}
+	if config.Tests[0].MultiStageTestConfigurationLiteral == nil || len(config.Tests[0].MultiStageTestConfigurationLiteral.Test) != 1 {
+		t.Fatalf("expected the workflow to be resolved, got %#v", config.Tests[0])
+	}
+	if diff := cmp.Diff("make test\n", config.Tests[0].MultiStageTestConfigurationLiteral.Test[0].Commands); diff != "" {
+		t.Errorf("unexpected commands: %s", diff)
+	}
+	var post []string
+	for _, step := range config.Tests[0].MultiStageTestConfigurationLiteral.Post {
+		post = append(post, step.As)
+	}
+	if diff := cmp.Diff([]string{"myteam-gather"}, post); diff != "" {
+		t.Errorf("unexpected steps of the federated registry: %s", diff)
+	}
+
+	injected, err := client.ConfigWithTest(&api.Metadata{Org: "org", Repo: "repo", Branch: "main"}, &api.MetadataWithTest{Metadata: api.Metadata{Org: "org", Repo: "other", Branch: "main"}, Test: "unit"})
+	if err != nil {
+		t.Fatalf("failed to resolve config with injected test: %v", err)
+	}
+	var names []string
+	for _, test := range injected.Tests {
+		names = append(names, test.As)
+	}
+	if diff := cmp.Diff([]string{"unit"}, names); diff != "" {
+		t.Errorf("unexpected tests: %s", diff)
+	}
+
+	for _, tc := range []struct {
+		name        string
+		call        func() error
+		expectedErr error
+	}{
+		{
+			name: "missing config",
+			call: func() error {
+				_, err := client.Config(&api.Metadata{Org: "org", Repo: "missing", Branch: "main"})
+				return err
+			},
+			expectedErr: errors.New("failed to find configuration in bundle: could not find any config for repo org/missing"),
+		},
+		{
+			name: "config pinned to another registry version",
+			call: func() error {
+				_, err := client.Resolve([]byte("registry_version: abc\nresources:\n  '*':\n    requests:\n      cpu: 10m\n"))
+				return err
+			},
+			expectedErr: errors.New("failed to resolve configuration: configuration pins registry version abc, but the bundle contains registry version " + b.RegistryVersion),
+		},
+		{
+			name: "missing cluster profile",
+			call: func() error {
+				_, err := client.ClusterProfile("missing")
+				return err
+			},
+			expectedErr: errors.New("cluster profile missing not found in bundle"),
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			if diff := cmp.Diff(tc.expectedErr, tc.call(), testhelper.EquateErrorMessage); diff != "" {
+				t.Errorf("unexpected error: %s", diff)
+			}
+		})
+	}
+
+	profile, err := client.ClusterProfile("aws")
+	if err != nil {
+		t.Fatalf("failed to get cluster profile: %v", err)
+	}
+	if diff := cmp.Diff("cluster-secrets-aws", profile.Secret); diff != "" {
+		t.Errorf("unexpected cluster profile secret: %s", diff)
+	}
+}
@@ -0,0 +1,83 @@
+package bundle
+
+import (
+	"errors"
+	"fmt"
+
+	"sigs.k8s.io/yaml"
+
+	"github.com/openshift/ci-tools/pkg/api"
+	"github.com/openshift/ci-tools/pkg/api/configresolver"
+	"github.com/openshift/ci-tools/pkg/load/agents"
+)
+
+// ResolverClient serves configresolver requests from a bundle. It implements
+// server.ResolverClient so that ci-operator can run without the configresolver.
+type ResolverClient struct {
+	bundle  *Bundle
+	configs agents.ConfigAgent
+}
+
+// NewResolverClient returns a client resolving configurations from the bundle
+func NewResolverClient(b *Bundle) *ResolverClient {
+	return &ResolverClient{bundle: b, configs: agents.NewStaticConfigAgent(b.Configs)}
+}
+
+// Config returns the resolved bundled configuration matching the metadata
+func (c *ResolverClient) Config(info *api.Metadata) (*api.ReleaseBuildConfiguration, error) {
+	config, err := c.configs.GetMatchingConfig(*info)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find configuration in bundle: %w", err)
+	}
+	return c.resolve(config)
+}
+
+// ConfigWithTest returns the resolved bundled configuration matching the base
+// metadata, with a test injected from another bundled configuration
+func (c *ResolverClient) ConfigWithTest(base *api.Metadata, testSource *api.MetadataWithTest) (*api.ReleaseBuildConfiguration, error) {
+	config, err := c.configs.GetMatchingConfig(*base)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find configuration in bundle: %w", err)
+	}
+	source, err := c.configs.GetMatchingConfig(testSource.Metadata)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find configuration to inject from in bundle: %w", err)
+	}
+	merged, err := config.WithPresubmitFrom(&source, testSource.Test)
+	if err != nil {
+		return nil, fmt.Errorf("failed to inject test into config: %w", err)
+	}
+	return c.resolve(*merged)
+}
+
+// Resolve resolves a raw configuration against the bundled registry
+func (c *ResolverClient) Resolve(raw []byte) (*api.ReleaseBuildConfiguration, error) {
+	var config api.ReleaseBuildConfiguration
+	if err := yaml.UnmarshalStrict(raw, &config); err != nil {
+		return nil, fmt.Errorf("invalid configuration: %w", err)
+	}
+	return c.resolve(config)
+}
+
+// ClusterProfile returns the details of a bundled cluster profile
+func (c *ResolverClient) ClusterProfile(profileName string) (*api.ClusterProfileDetails, error) {
+	details, ok := c.bundle.ClusterProfiles[api.ClusterProfile(profileName)]
+	if !ok {
+		return nil, fmt.Errorf("cluster profile %s not found in bundle", profileName)
+	}
+	return &details, nil
+}
+
+// IntegratedStream is not supported: integrated streams reflect the live state
+// of the build farm and cannot be bundled
+func (c *ResolverClient) IntegratedStream(namespace, name string) (*configresolver.IntegratedStream, error) {
+	return nil, errors.New("integrated streams are not available from a registry bundle")
+}
+
+func (c *ResolverClient) resolve(config api.ReleaseBuildConfiguration) (*api.ReleaseBuildConfiguration, error) {
+	resolved, err := c.bundle.ResolveConfig(config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve configuration: %w", err)
+	}
+	return &resolved, nil
+}
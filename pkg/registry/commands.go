@@ -0,0 +1,18 @@
+package registry
+
+import (
+	"github.com/openshift/ci-tools/pkg/validation"
+)
+
+// AnalyzeCommands statically analyzes the commands of all steps, returning
+// the issues found by step name. Steps without issues are omitted. The
+// analysis is heuristic, so unlike Validate it does not fail on issues.
+func AnalyzeCommands(stepsByName ReferenceByName) map[string][]validation.CommandIssue {
+	ret := map[string][]validation.CommandIssue{}
+	for name, step := range stepsByName {
+		if issues := validation.AnalyzeStepCommands(step); len(issues) != 0 {
+			ret[name] = issues
+		}
+	}
+	return ret
+}
@@ -0,0 +1,138 @@
+package registry
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	"github.com/openshift/ci-tools/pkg/api"
+)
+
+// DeprecationByName maps the names of deprecated registry components to their
+// deprecation notices
+type DeprecationByName map[string]api.RegistryDeprecation
+
+// Deprecations holds the deprecation notices of registry components
+type Deprecations struct {
+	References DeprecationByName
+	Chains     DeprecationByName
+	Workflows  DeprecationByName
+}
+
+// Get returns the deprecation notice of a registry component, if it is deprecated
+func (d Deprecations) Get(nodeType Type, name string) (api.RegistryDeprecation, bool) {
+	var deprecations DeprecationByName
+	switch nodeType {
+	case Reference:
+		deprecations = d.References
+	case Chain:
+		deprecations = d.Chains
+	case Workflow:
+		deprecations = d.Workflows
+	}
+	deprecation, ok := deprecations[name]
+	return deprecation, ok
+}
+
+// DeprecationWarning describes the use of a deprecated registry component
+type DeprecationWarning struct {
+	// Path locates the use of the component, e.g. `test/e2e: chain/foo`
+	Path        string
+	Type        Type
+	Name        string
+	Deprecation api.RegistryDeprecation
+}
+
+func (w DeprecationWarning) String() string {
+	var b strings.Builder
+	if w.Path != "" {
+		b.WriteString(w.Path)
+		b.WriteString(": ")
+	}
+	fmt.Fprintf(&b, "%s %s is deprecated", w.Type, w.Name)
+	if w.Deprecation.Message != "" {
+		fmt.Fprintf(&b, ": %s", w.Deprecation.Message)
+	}
+	if w.Deprecation.Replacement != "" {
+		fmt.Fprintf(&b, "; use %s %s instead", w.Type, w.Deprecation.Replacement)
+	}
+	if w.Deprecation.RemovalDate != "" {
+		fmt.Fprintf(&b, "; it will be removed after %s", w.Deprecation.RemovalDate)
+	}
+	return b.String()
+}
+
+// ResolverOption configures optional behavior of a Resolver
+type ResolverOption func(*registry)
+
+// WithDeprecations makes the Resolver report each use of a deprecated
+// registry component to warn. Warnings are logged when warn is nil.
+func WithDeprecations(deprecations Deprecations, warn func(DeprecationWarning)) ResolverOption {
+	if warn == nil {
+		warn = func(w DeprecationWarning) {
+			logrus.Warn(w.String())
+		}
+	}
+	return func(r *registry) {
+		r.deprecations = deprecations
+		r.warn = warn
+	}
+}
+
+func (r *registry) checkDeprecated(stack stack, nodeType Type, name string) {
+	if r.warn == nil {
+		return
+	}
+	if deprecation, ok := r.deprecations.Get(nodeType, name); ok {
+		r.warn(DeprecationWarning{Path: stack.path(), Type: nodeType, Name: name, Deprecation: deprecation})
+	}
+}
+
+// ValidateDeprecations verifies that the deprecation notices refer to
+// existing components and have valid replacements and removal dates.
+func ValidateDeprecations(stepsByName ReferenceByName, chainsByName ChainByName, workflowsByName WorkflowByName, deprecations Deprecations) error {
+	var ret []error
+	for _, item := range []struct {
+		nodeType     Type
+		deprecations DeprecationByName
+		exists       func(string) bool
+	}{
+		{nodeType: Reference, deprecations: deprecations.References, exists: func(name string) bool { _, ok := stepsByName[name]; return ok }},
+		{nodeType: Chain, deprecations: deprecations.Chains, exists: func(name string) bool { _, ok := chainsByName[name]; return ok }},
+		{nodeType: Workflow, deprecations: deprecations.Workflows, exists: func(name string) bool { _, ok := workflowsByName[name]; return ok }},
+	} {
+		for _, name := range sets.List(sets.KeySet(item.deprecations)) {
+			deprecation := item.deprecations[name]
+			if !item.exists(name) {
+				ret = append(ret, fmt.Errorf("%s/%s: deprecated %s does not exist", item.nodeType, name, item.nodeType))
+				continue
+			}
+			if deprecation.Message == "" {
+				ret = append(ret, fmt.Errorf("%s/%s: deprecation: message must be set", item.nodeType, name))
+			}
+			if deprecation.RemovalDate != "" {
+				if _, err := time.Parse(api.RegistryDeprecationDateFormat, deprecation.RemovalDate); err != nil {
+					ret = append(ret, fmt.Errorf("%s/%s: deprecation: removal_date %q is not in the YYYY-MM-DD format", item.nodeType, name, deprecation.RemovalDate))
+				}
+			}
+			if replacement := deprecation.Replacement; replacement != "" {
+				switch {
+				case replacement == name:
+					ret = append(ret, fmt.Errorf("%s/%s: deprecation: %s cannot be replaced by itself", item.nodeType, name, item.nodeType))
+				case !item.exists(replacement):
+					ret = append(ret, fmt.Errorf("%s/%s: deprecation: replacement %s %s does not exist", item.nodeType, name, item.nodeType, replacement))
+				default:
+					if _, deprecated := item.deprecations[replacement]; deprecated {
+						ret = append(ret, fmt.Errorf("%s/%s: deprecation: replacement %s %s is deprecated", item.nodeType, name, item.nodeType, replacement))
+					}
+				}
+			}
+		}
+	}
+	return utilerrors.NewAggregate(ret)
+}
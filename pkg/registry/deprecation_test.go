@@ -0,0 +1,95 @@
+package registry
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/openshift/ci-tools/pkg/api"
+	"github.com/openshift/ci-tools/pkg/testhelper"
+)
+
+func TestDeprecationWarnings(t *testing.T) {
+	oldRef, newRef, chain, workflow := "old", "new", "chain", "workflow"
+	refs := ReferenceByName{
+		oldRef: {As: oldRef, From: "src", Commands: "true"},
+		newRef: {As: newRef, From: "src", Commands: "true"},
+	}
+	chains := ChainByName{
+		chain: {As: chain, Steps: []api.TestStep{{Reference: &oldRef}}},
+	}
+	workflows := WorkflowByName{
+		workflow: {Test: []api.TestStep{{Chain: &chain}}},
+	}
+	deprecations := Deprecations{
+		References: DeprecationByName{oldRef: {Message: "old is slow", Replacement: newRef, RemovalDate: "2025-01-01"}},
+		Workflows:  DeprecationByName{workflow: {Message: "workflow is unmaintained"}},
+	}
+	for _, tc := range []struct {
+		name     string
+		config   api.MultiStageTestConfiguration
+		expected []string
+	}{{
+		name:   "no deprecated components",
+		config: api.MultiStageTestConfiguration{Test: []api.TestStep{{Reference: &newRef}}},
+	}, {
+		name:     "deprecated step",
+		config:   api.MultiStageTestConfiguration{Test: []api.TestStep{{Reference: &oldRef}}},
+		expected: []string{"test/e2e: reference old is deprecated: old is slow; use reference new instead; it will be removed after 2025-01-01"},
+	}, {
+		name:   "deprecated workflow and step in a chain",
+		config: api.MultiStageTestConfiguration{Workflow: &workflow},
+		expected: []string{
+			"test/e2e: workflow workflow is deprecated: workflow is unmaintained",
+			"test/e2e: workflow/workflow: chain/chain: reference old is deprecated: old is slow; use reference new instead; it will be removed after 2025-01-01",
+		},
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			var warnings []string
+			resolver := NewResolver(refs, chains, workflows, ObserverByName{}, WithDeprecations(deprecations, func(w DeprecationWarning) {
+				warnings = append(warnings, w.String())
+			}))
+			if _, err := resolver.Resolve("e2e", tc.config); err != nil {
+				t.Fatalf("failed to resolve: %v", err)
+			}
+			if diff := cmp.Diff(tc.expected, warnings); diff != "" {
+				t.Errorf("unexpected warnings: %s", diff)
+			}
+		})
+	}
+}
+
+func TestValidateDeprecations(t *testing.T) {
+	refs := ReferenceByName{"old": {}, "new": {}, "other": {}}
+	for _, tc := range []struct {
+		name         string
+		deprecations Deprecations
+		expected     error
+	}{{
+		name: "valid deprecation",
+		deprecations: Deprecations{References: DeprecationByName{
+			"old": {Message: "use new", Replacement: "new", RemovalDate: "2025-01-01"},
+		}},
+	}, {
+		name: "invalid deprecations",
+		deprecations: Deprecations{References: DeprecationByName{
+			"missing": {Message: "gone"},
+			"new":     {Message: "use other", Replacement: "old"},
+			"old":     {Message: "use new", Replacement: "new", RemovalDate: "next week"},
+			"other":   {Replacement: "other"},
+		}},
+		expected: errors.New(`[reference/missing: deprecated reference does not exist, reference/new: deprecation: replacement reference old is deprecated, reference/old: deprecation: removal_date "next week" is not in the YYYY-MM-DD format, reference/old: deprecation: replacement reference new is deprecated, reference/other: deprecation: message must be set, reference/other: deprecation: reference cannot be replaced by itself]`),
+	}, {
+		name:         "replacement must exist",
+		deprecations: Deprecations{Chains: DeprecationByName{"chain": {Message: "use another", Replacement: "another"}}},
+		expected:     errors.New("chain/chain: deprecation: replacement chain another does not exist"),
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			err := ValidateDeprecations(refs, ChainByName{"chain": {}}, WorkflowByName{}, tc.deprecations)
+			if diff := cmp.Diff(tc.expected, err, testhelper.EquateErrorMessage); diff != "" {
+				t.Errorf("unexpected error: %s", diff)
+			}
+		})
+	}
+}
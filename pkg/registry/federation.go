@@ -0,0 +1,240 @@
+package registry
+
+import (
+	"fmt"
+	"strings"
+
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apimachinery/pkg/util/validation"
+
+	"github.com/openshift/ci-tools/pkg/api"
+)
+
+// NamespaceSeparator separates the namespace of a federated registry from the
+// name of a component, e.g. `myteam/install-foo`
+const NamespaceSeparator = "/"
+
+// Source is a registry federated with others. Components of federated
+// registries are referenced by their qualified name, e.g. `myteam/install-foo`.
+type Source struct {
+	// Namespace qualifies the names of the components of the registry. It is
+	// empty for the default registry, whose components are referenced by their
+	// plain names.
+	Namespace  string
+	References ReferenceByName
+	Chains     ChainByName
+	Workflows  WorkflowByName
+	Observers  ObserverByName
+}
+
+// QualifiedName returns the name a component of a registry is referenced by
+func QualifiedName(namespace, name string) string {
+	if namespace == "" {
+		return name
+	}
+	return namespace + NamespaceSeparator + name
+}
+
+// SplitQualifiedName returns the namespace and the name of a component
+// reference. The namespace is empty for components of the default registry.
+func SplitQualifiedName(qualified string) (string, string) {
+	if i := strings.Index(qualified, NamespaceSeparator); i != -1 {
+		return qualified[:i], qualified[i+len(NamespaceSeparator):]
+	}
+	return "", qualified
+}
+
+// Federate merges registries into one, keyed by qualified names. The default
+// registry must come first, followed by the federated ones. Plain references
+// in a federated registry resolve to the component of that registry if it
+// defines one and to the default registry otherwise; qualified references
+// reach into any registry. The default registry cannot reference federated
+// components and every reference must resolve in the merged registry.
+// Steps and observers of federated registries run as `namespace-name`, so
+// they do not collide with the steps of other registries in a test; names
+// that still collide are rejected.
+func Federate(sources ...Source) (ReferenceByName, ChainByName, WorkflowByName, ObserverByName, error) {
+	references, chains, workflows, observers := ReferenceByName{}, ChainByName{}, WorkflowByName{}, ObserverByName{}
+	var errs []error
+	namespaces := sets.New[string]()
+	for i, source := range sources {
+		switch {
+		case i == 0 && source.Namespace != "":
+			errs = append(errs, fmt.Errorf("the default registry must not have a namespace, got %q", source.Namespace))
+			continue
+		case i != 0 && source.Namespace == "":
+			errs = append(errs, fmt.Errorf("federated registry %d: namespace must be set", i))
+			continue
+		case i != 0 && len(validation.IsDNS1123Label(source.Namespace)) != 0:
+			errs = append(errs, fmt.Errorf("federated registry %s: invalid namespace: %s", source.Namespace, strings.Join(validation.IsDNS1123Label(source.Namespace), ", ")))
+			continue
+		case namespaces.Has(source.Namespace):
+			errs = append(errs, fmt.Errorf("federated registry %s: namespace is used more than once", source.Namespace))
+			continue
+		}
+		namespaces.Insert(source.Namespace)
+		f := federator{source: source}
+		for name, step := range source.References {
+			if f.checkName(Reference, name, &errs) {
+				step.As = f.runName(step.As)
+				step.Observers = f.observers(step.Observers)
+				references[QualifiedName(source.Namespace, name)] = step
+			}
+		}
+		for name, chain := range source.Chains {
+			if f.checkName(Chain, name, &errs) {
+				chain.Steps = f.steps(chain.Steps)
+				chains[QualifiedName(source.Namespace, name)] = chain
+			}
+		}
+		for name, workflow := range source.Workflows {
+			if f.checkName(Workflow, name, &errs) {
+				workflow.Pre, workflow.Test, workflow.Post = f.steps(workflow.Pre), f.steps(workflow.Test), f.steps(workflow.Post)
+				if workflow.Observers != nil {
+					workflow.Observers = &api.Observers{Enable: f.observers(workflow.Observers.Enable), Disable: f.observers(workflow.Observers.Disable)}
+				}
+				workflows[QualifiedName(source.Namespace, name)] = workflow
+			}
+		}
+		for name, observer := range source.Observers {
+			if f.checkName(Observer, name, &errs) {
+				observer.Name = f.runName(observer.Name)
+				observers[QualifiedName(source.Namespace, name)] = observer
+			}
+		}
+	}
+	errs = append(errs, checkRunNames(references, observers)...)
+	if len(errs) == 0 {
+		if err := Validate(references, chains, workflows, observers); err != nil {
+			errs = append(errs, fmt.Errorf("invalid federated registry: %w", err))
+		}
+	}
+	if err := utilerrors.NewAggregate(errs); err != nil {
+		return nil, nil, nil, nil, err
+	}
+	return references, chains, workflows, observers, nil
+}
+
+// federator qualifies the references made by the components of a source
+type federator struct {
+	source Source
+}
+
+func (f federator) checkName(nodeType Type, name string, errs *[]error) bool {
+	if strings.Contains(name, NamespaceSeparator) {
+		*errs = append(*errs, fmt.Errorf("%s %s: names of registry components must not contain %q", nodeType, QualifiedName(f.source.Namespace, name), NamespaceSeparator))
+		return false
+	}
+	return true
+}
+
+// runName is the name a step or observer of the source runs as
+func (f federator) runName(name string) string {
+	if f.source.Namespace == "" {
+		return name
+	}
+	return f.source.Namespace + "-" + name
+}
+
+// checkRunNames rejects steps and observers of different registries that
+// run under the same name
+func checkRunNames(references ReferenceByName, observers ObserverByName) []error {
+	var errs []error
+	stepNames := map[string]string{}
+	for _, name := range sets.List(sets.KeySet(references)) {
+		as := references[name].As
+		if other, ok := stepNames[as]; ok {
+			errs = append(errs, fmt.Errorf("%s %s: runs as %s, like %s %s", Reference, name, as, Reference, other))
+			continue
+		}
+		stepNames[as] = name
+	}
+	observerNames := map[string]string{}
+	for _, name := range sets.List(sets.KeySet(observers)) {
+		as := observers[name].Name
+		if other, ok := observerNames[as]; ok {
+			errs = append(errs, fmt.Errorf("%s %s: runs as %s, like %s %s", Observer, name, as, Observer, other))
+			continue
+		}
+		observerNames[as] = name
+	}
+	return errs
+}
+
+// qualify resolves a reference made by a component of the source: qualified
+// references are kept, plain ones resolve to the source itself when it
+// defines the component and to the default registry otherwise
+func (f federator) qualify(name string, definedLocally bool) string {
+	if f.source.Namespace == "" || strings.Contains(name, NamespaceSeparator) || !definedLocally {
+		return name
+	}
+	return QualifiedName(f.source.Namespace, name)
+}
+
+func (f federator) steps(steps []api.TestStep) []api.TestStep {
+	if steps == nil {
+		return nil
+	}
+	ret := make([]api.TestStep, 0, len(steps))
+	for _, step := range steps {
+		switch {
+		case step.LiteralTestStep != nil:
+			step.LiteralTestStep = f.literal(step.LiteralTestStep)
+		case step.Reference != nil:
+			step.Reference = f.reference(*step.Reference)
+		case step.Chain != nil:
+			_, ok := f.source.Chains[*step.Chain]
+			name := f.qualify(*step.Chain, ok)
+			step.Chain = &name
+		case step.Parallel != nil:
+			members := make([]api.ParallelStep, 0, len(step.Parallel))
+			for _, member := range step.Parallel {
+				if member.LiteralTestStep != nil {
+					member.LiteralTestStep = f.literal(member.LiteralTestStep)
+				} else if member.Reference != nil {
+					member.Reference = f.reference(*member.Reference)
+				}
+				members = append(members, member)
+			}
+			step.Parallel = members
+		}
+		ret = append(ret, step)
+	}
+	return ret
+}
+
+func (f federator) reference(name string) *string {
+	_, ok := f.source.References[name]
+	name = f.qualify(name, ok)
+	return &name
+}
+
+func (f federator) literal(step *api.LiteralTestStep) *api.LiteralTestStep {
+	ret := *step
+	ret.As = f.runName(step.As)
+	ret.Observers = f.observers(step.Observers)
+	return &ret
+}
+
+func (f federator) observers(names []string) []string {
+	if names == nil {
+		return nil
+	}
+	ret := make([]string, 0, len(names))
+	for _, name := range names {
+		_, ok := f.source.Observers[name]
+		ret = append(ret, f.qualify(name, ok))
+	}
+	return ret
+}
+
+// NewFederatedResolver returns a resolver for federated registries, see
+// Federate for how references between the registries are resolved.
+func NewFederatedResolver(sources []Source, opts ...ResolverOption) (Resolver, error) {
+	references, chains, workflows, observers, err := Federate(sources...)
+	if err != nil {
+		return nil, err
+	}
+	return NewResolver(references, chains, workflows, observers, opts...), nil
+}
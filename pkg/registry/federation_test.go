@@ -0,0 +1,137 @@
+package registry
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/openshift/ci-tools/pkg/api"
+	"github.com/openshift/ci-tools/pkg/testhelper"
+)
+
+func TestFederate(t *testing.T) {
+	ref := func(name string) api.TestStep { return api.TestStep{Reference: &name} }
+	chain := func(name string) api.TestStep { return api.TestStep{Chain: &name} }
+	otherCheck := "other/check"
+	step := func(name string) api.LiteralTestStep {
+		return api.LiteralTestStep{As: name, From: "src", Commands: "true", Resources: api.ResourceRequirements{Requests: api.ResourceList{"cpu": "1"}}}
+	}
+	defaultRegistry := Source{
+		References: ReferenceByName{"install": step("install"), "gather": step("gather")},
+		Chains:     ChainByName{"teardown": {As: "teardown", Steps: []api.TestStep{ref("gather")}}},
+		Workflows:  WorkflowByName{"ipi": {Pre: []api.TestStep{ref("install")}, Post: []api.TestStep{chain("teardown")}}},
+		Observers:  ObserverByName{"watch": {Name: "watch", From: "src", Commands: "true", Resources: api.ResourceRequirements{Requests: api.ResourceList{"cpu": "1"}}}},
+	}
+	myteam := Source{
+		Namespace:  "myteam",
+		References: ReferenceByName{"install": step("install"), "install-foo": step("install-foo")},
+		Chains: ChainByName{"install-all": {As: "install-all", Steps: []api.TestStep{
+			ref("install"),
+			ref("install-foo"),
+			ref("gather"),
+			{Parallel: []api.ParallelStep{{Reference: &otherCheck}}},
+		}}},
+		Workflows: WorkflowByName{"foo": {
+			Pre:       []api.TestStep{chain("install-all")},
+			Post:      []api.TestStep{chain("teardown")},
+			Observers: &api.Observers{Enable: []string{"watch"}},
+		}},
+	}
+	other := Source{Namespace: "other", References: ReferenceByName{"check": step("check")}}
+
+	for _, tc := range []struct {
+		name              string
+		sources           []Source
+		expectedChains    ChainByName
+		expectedWorkflows WorkflowByName
+		expectedErr       error
+	}{
+		{
+			name:    "federated registries",
+			sources: []Source{defaultRegistry, myteam, other},
+			expectedChains: ChainByName{
+				"teardown": defaultRegistry.Chains["teardown"],
+				"myteam/install-all": {As: "install-all", Steps: []api.TestStep{
+					ref("myteam/install"),
+					ref("myteam/install-foo"),
+					ref("gather"),
+					{Parallel: []api.ParallelStep{{Reference: &otherCheck}}},
+				}},
+			},
+			expectedWorkflows: WorkflowByName{
+				"ipi": defaultRegistry.Workflows["ipi"],
+				"myteam/foo": {
+					Pre:       []api.TestStep{chain("myteam/install-all")},
+					Post:      []api.TestStep{chain("teardown")},
+					Observers: &api.Observers{Enable: []string{"watch"}},
+				},
+			},
+		},
+		{
+			name:        "reference to a missing registry",
+			sources:     []Source{defaultRegistry, myteam},
+			expectedErr: errors.New("invalid federated registry: [chain/myteam/install-all: invalid step reference: other/check, workflow/myteam/foo: chain/myteam/install-all: invalid step reference: other/check]"),
+		},
+		{
+			name: "invalid namespaces",
+			sources: []Source{
+				{Namespace: "default"},
+				{},
+				{Namespace: "My_Team"},
+				other,
+				other,
+			},
+			expectedErr: errors.New(`[the default registry must not have a namespace, got "default", federated registry 1: namespace must be set, federated registry My_Team: invalid namespace: a lowercase RFC 1123 label must consist of lower case alphanumeric characters or '-', and must start and end with an alphanumeric character (e.g. 'my-name',  or '123-abc', regex used for validation is '[a-z0-9]([-a-z0-9]*[a-z0-9])?'), federated registry other: namespace is used more than once]`),
+		},
+		{
+			name: "federated step runs under the name of another step",
+			sources: []Source{
+				{References: ReferenceByName{"myteam-install": step("myteam-install")}, Observers: ObserverByName{"myteam-watch": {Name: "myteam-watch"}}},
+				{Namespace: "myteam", References: ReferenceByName{"install": step("install")}, Observers: ObserverByName{"watch": {Name: "watch"}}},
+			},
+			expectedErr: errors.New(`[reference myteam/install: runs as myteam-install, like reference myteam-install, observer myteam/watch: runs as myteam-watch, like observer myteam-watch]`),
+		},
+		{
+			name:        "qualified component name",
+			sources:     []Source{{References: ReferenceByName{"myteam/install": step("install")}}},
+			expectedErr: errors.New(`reference myteam/install: names of registry components must not contain "/"`),
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			references, chains, workflows, _, err := Federate(tc.sources...)
+			if diff := cmp.Diff(tc.expectedErr, err, testhelper.EquateErrorMessage); diff != "" {
+				t.Fatalf("unexpected error: %s", diff)
+			}
+			if err != nil {
+				return
+			}
+			if _, ok := references["myteam/install-foo"]; !ok {
+				t.Errorf("expected qualified reference myteam/install-foo, got %v", references)
+			}
+			if diff := cmp.Diff(tc.expectedChains, chains); diff != "" {
+				t.Errorf("unexpected chains: %s", diff)
+			}
+			if diff := cmp.Diff(tc.expectedWorkflows, workflows); diff != "" {
+				t.Errorf("unexpected workflows: %s", diff)
+			}
+
+			resolver, err := NewFederatedResolver(tc.sources)
+			if err != nil {
+				t.Fatalf("failed to create resolver: %v", err)
+			}
+			workflow := "myteam/foo"
+			resolved, err := resolver.Resolve("e2e", api.MultiStageTestConfiguration{Workflow: &workflow})
+			if err != nil {
+				t.Fatalf("failed to resolve: %v", err)
+			}
+			var names []string
+			for _, s := range append(resolved.Pre, resolved.Post...) {
+				names = append(names, s.As)
+			}
+			if diff := cmp.Diff([]string{"myteam-install", "myteam-install-foo", "gather", "other-check", "gather"}, names); diff != "" {
+				t.Errorf("unexpected resolved steps: %s", diff)
+			}
+		})
+	}
+}
@@ -2,10 +2,14 @@ package registry
 
 import (
 	"fmt"
+	"sort"
 
 	"github.com/sirupsen/logrus"
 
 	"k8s.io/apimachinery/pkg/util/sets"
+
+	"github.com/openshift/ci-tools/pkg/api"
+	"github.com/openshift/ci-tools/pkg/util"
 )
 
 // Type identifies the type of registry element a Node refers to
@@ -235,6 +239,68 @@ func (n *chainNode) addReferenceChild(child *referenceNode) {
 	child.chainParents.insert(n)
 }
 
+// String returns the name of the registry element type
+func (t Type) String() string {
+	return nodeTypes[t]
+}
+
+// AffectedNodes returns a sorted list of all nodes affected by a seed list
+// of changed nodes. Affected node is either a directly changed node or any of
+// its ancestors. Each node is present at most once.
+func AffectedNodes(changed []Node) []Node {
+	all := changed
+	for _, node := range changed {
+		all = append(all, node.Ancestors()...)
+	}
+
+	var worklist []Node
+	seen := sets.New[string]()
+	keyFunc := func(node Node) string { return fmt.Sprintf("type=%d name=%s", node.Type(), node.Name()) }
+	for _, node := range all {
+		key := keyFunc(node)
+		if !seen.Has(key) {
+			seen.Insert(key)
+			worklist = append(worklist, node)
+		}
+	}
+	sort.Slice(worklist, func(i, j int) bool {
+		if worklist[i].Name() == worklist[j].Name() {
+			return worklist[i].Type() < worklist[j].Type()
+		}
+		return worklist[i].Name() < worklist[j].Name()
+	})
+	return worklist
+}
+
+// TestUsesNode determines whether an unresolved multi-stage test directly
+// uses the registry element a Node refers to: a workflow it is based on, an
+// observer it enables or a chain or reference in one of its phases. Elements
+// used indirectly are found by checking the ancestors of the node as well.
+func TestUsesNode(test api.MultiStageTestConfiguration, node Node) bool {
+	switch node.Type() {
+	// TODO: Handle workflows with overridden fields.
+	// Workflows can have overridden fields and thus may have overridden the field that made the workflow an ancestor.
+	// This should be handled to reduce the number of false positives, but requires much more information than
+	// the graph alone provides.
+	case Workflow:
+		return test.Workflow != nil && node.Name() == *test.Workflow
+	case Observer:
+		return test.Observers != nil && util.Contains(test.Observers.Enable, node.Name())
+	}
+	var testSteps []api.TestStep
+	for _, phase := range [][]api.TestStep{test.Pre, test.Test, test.Post} {
+		testSteps = append(testSteps, phase...)
+	}
+	for _, testStep := range api.FlattenParallel(testSteps) {
+		hasRef := testStep.Reference != nil && node.Type() == Reference && node.Name() == *testStep.Reference
+		hasChain := testStep.Chain != nil && node.Type() == Chain && node.Name() == *testStep.Chain
+		if hasRef || hasChain {
+			return true
+		}
+	}
+	return false
+}
+
 func FieldsForNode(n Node) logrus.Fields {
 	return logrus.Fields{
 		"node-name": n.Name(),
@@ -319,7 +385,7 @@ func NewGraph(stepsByName ReferenceByName, chainsByName ChainByName, workflowsBy
 		}
 		chainNodes[name] = node
 		nodesByName.Chains[name] = node
-		for _, step := range chain.Steps {
+		for _, step := range api.FlattenParallel(chain.Steps) {
 			if step.Reference != nil {
 				if _, exists := referenceNodes[*step.Reference]; !exists {
 					return nodesByName, fmt.Errorf("Chain %s contains non-existent reference %s", name, *step.Reference)
@@ -370,7 +436,7 @@ func NewGraph(stepsByName ReferenceByName, chainsByName ChainByName, workflowsBy
 			}
 		}
 		steps := append(workflow.Pre, append(workflow.Test, workflow.Post...)...)
-		for _, step := range steps {
+		for _, step := range api.FlattenParallel(steps) {
 			if step.Reference != nil {
 				if _, exists := referenceNodes[*step.Reference]; !exists {
 					return nodesByName, fmt.Errorf("Workflow %s contains non-existent reference %s", name, *step.Reference)
@@ -3,6 +3,8 @@ package registry
 import (
 	"testing"
 
+	"github.com/google/go-cmp/cmp"
+
 	"github.com/openshift/ci-tools/pkg/api"
 )
 
@@ -355,3 +357,82 @@ func TestNewGraph(t *testing.T) {
 		}
 	}
 }
+
+func TestAffectedNodes(t *testing.T) {
+	graph, err := NewGraph(referenceMap, chainMap, workflowMap, observerMap)
+	if err != nil {
+		t.Fatalf("failed to create graph: %v", err)
+	}
+	affected := AffectedNodes([]Node{graph.References[ipiInstallRBAC], graph.Chains[ipiInstall]})
+	var names []string
+	for _, node := range affected {
+		names = append(names, node.Type().String()+"/"+node.Name())
+	}
+	expected := []string{"workflow/ipi", "chain/ipi-install", "reference/ipi-install-rbac", "chain/nested"}
+	if diff := cmp.Diff(expected, names); diff != "" {
+		t.Errorf("affected nodes differ from expected: %s", diff)
+	}
+}
+
+func TestTestUsesNode(t *testing.T) {
+	graph, err := NewGraph(referenceMap, chainMap, workflowMap, observerMap)
+	if err != nil {
+		t.Fatalf("failed to create graph: %v", err)
+	}
+	testCases := []struct {
+		name     string
+		test     api.MultiStageTestConfiguration
+		node     Node
+		expected bool
+	}{
+		{
+			name:     "test based on the workflow",
+			test:     api.MultiStageTestConfiguration{Workflow: &ipi},
+			node:     graph.Workflows[ipi],
+			expected: true,
+		},
+		{
+			name: "test not based on the workflow",
+			test: api.MultiStageTestConfiguration{Test: []api.TestStep{{Reference: &ipiConf}}},
+			node: graph.Workflows[ipi],
+		},
+		{
+			name:     "test enabling the observer",
+			test:     api.MultiStageTestConfiguration{Observers: &api.Observers{Enable: []string{simpleObserver}}},
+			node:     graph.Observers[simpleObserver],
+			expected: true,
+		},
+		{
+			name:     "test using the chain in a phase",
+			test:     api.MultiStageTestConfiguration{Post: []api.TestStep{{Chain: &ipiDeprovision}}},
+			node:     graph.Chains[ipiDeprovision],
+			expected: true,
+		},
+		{
+			name: "test using the reference through a chain is not a direct use",
+			test: api.MultiStageTestConfiguration{Post: []api.TestStep{{Chain: &ipiDeprovision}}},
+			node: graph.References[ipiDeprovisionMustGather],
+		},
+		{
+			name: "test using the reference in a parallel group",
+			test: api.MultiStageTestConfiguration{Test: []api.TestStep{{Parallel: []api.ParallelStep{
+				{Reference: &ipiConf},
+				{Reference: &ipiConfAWS},
+			}}}},
+			node:     graph.References[ipiConfAWS],
+			expected: true,
+		},
+		{
+			name: "a chain and a reference with the same name are different nodes",
+			test: api.MultiStageTestConfiguration{Test: []api.TestStep{{Reference: &ipiConfAWS}}},
+			node: graph.Chains[ipiConfAWS],
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if actual := TestUsesNode(tc.test, tc.node); actual != tc.expected {
+				t.Errorf("expected %t, got %t", tc.expected, actual)
+			}
+		})
+	}
+}
@@ -0,0 +1,219 @@
+// Package lint implements checks for registry components that go beyond the
+// structural validation done by registry.Validate. Rules are pluggable and
+// their findings can be configured per rule to be errors or warnings, or be
+// suppressed for individual components.
+package lint
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"sort"
+
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"sigs.k8s.io/yaml"
+
+	"github.com/openshift/ci-tools/pkg/api"
+	"github.com/openshift/ci-tools/pkg/registry"
+	"github.com/openshift/ci-tools/pkg/validation"
+)
+
+// Severity determines how a finding is treated
+type Severity string
+
+const (
+	// SeverityError findings fail the validation
+	SeverityError Severity = "error"
+	// SeverityWarning findings are reported, but do not fail the validation
+	SeverityWarning Severity = "warning"
+	// SeverityOff disables a rule
+	SeverityOff Severity = "off"
+)
+
+// Registry is the content the rules are checked against
+type Registry struct {
+	References registry.ReferenceByName
+	Chains     registry.ChainByName
+	Workflows  registry.WorkflowByName
+	Observers  registry.ObserverByName
+	// Configs are all the ci-operator configurations using the registry. When
+	// nil, rules that need to know every user of a component are skipped.
+	Configs []api.ReleaseBuildConfiguration
+
+	// commandIssues caches the analysis of step commands shared by rules
+	commandIssues map[string][]validation.CommandIssue
+}
+
+// Finding is a problem a rule found in a registry component
+type Finding struct {
+	Rule     string
+	Severity Severity
+	Type     registry.Type
+	Name     string
+	Message  string
+}
+
+func (f Finding) String() string {
+	return fmt.Sprintf("%s/%s: %s [%s]", f.Type, f.Name, f.Message, f.Rule)
+}
+
+// Rule is a single check over the registry. Rules do not need to set the
+// rule name or severity on their findings.
+type Rule struct {
+	Name            string
+	Description     string
+	DefaultSeverity Severity
+	Check           func(r *Registry) []Finding
+}
+
+// Config configures the severity of rules and suppresses findings
+type Config struct {
+	// Severities overrides the default severity of rules, by rule name
+	Severities map[string]Severity `json:"severities,omitempty"`
+	// Suppressions silence findings for individual components
+	Suppressions []Suppression `json:"suppressions,omitempty"`
+}
+
+// Suppression silences the findings of a rule for matching components
+type Suppression struct {
+	// Rule is the name of the suppressed rule
+	Rule string `json:"rule"`
+	// Type restricts the suppression to steps (`reference`), chains or
+	// workflows. All types match when unset.
+	Type string `json:"type,omitempty"`
+	// Name is a glob matched against component names, as in path.Match
+	Name string `json:"name"`
+	// Reason explains why the finding is acceptable
+	Reason string `json:"reason"`
+}
+
+func (s Suppression) matches(f Finding) bool {
+	if s.Rule != f.Rule || (s.Type != "" && s.Type != f.Type.String()) {
+		return false
+	}
+	// the pattern was validated when the linter was created
+	matched, _ := path.Match(s.Name, f.Name)
+	return matched
+}
+
+// LoadConfig reads a lint configuration file
+func LoadConfig(filename string) (Config, error) {
+	var config Config
+	raw, err := os.ReadFile(filename)
+	if err != nil {
+		return config, fmt.Errorf("failed to read lint configuration: %w", err)
+	}
+	if err := yaml.UnmarshalStrict(raw, &config); err != nil {
+		return config, fmt.Errorf("failed to parse lint configuration %s: %w", filename, err)
+	}
+	return config, nil
+}
+
+// Linter runs rules over a registry
+type Linter struct {
+	rules        []Rule
+	severities   map[string]Severity
+	suppressions []Suppression
+}
+
+// NewLinter creates a linter running the rules, configured by the config
+func NewLinter(rules []Rule, config Config) (*Linter, error) {
+	l := &Linter{rules: rules, severities: map[string]Severity{}}
+	names := sets.New[string]()
+	var errs []error
+	for _, rule := range rules {
+		if names.Has(rule.Name) {
+			errs = append(errs, fmt.Errorf("rule %s is defined more than once", rule.Name))
+		}
+		names.Insert(rule.Name)
+		l.severities[rule.Name] = rule.DefaultSeverity
+	}
+	for _, name := range sortedNames(config.Severities) {
+		severity := config.Severities[name]
+		if !names.Has(name) {
+			errs = append(errs, fmt.Errorf("severity configured for unknown rule %s", name))
+			continue
+		}
+		switch severity {
+		case SeverityError, SeverityWarning, SeverityOff:
+			l.severities[name] = severity
+		default:
+			errs = append(errs, fmt.Errorf("rule %s: invalid severity %q, must be one of %s, %s or %s", name, severity, SeverityError, SeverityWarning, SeverityOff))
+		}
+	}
+	for i, s := range config.Suppressions {
+		if s.Type == "step" {
+			s.Type = registry.Reference.String()
+		}
+		switch {
+		case !names.Has(s.Rule):
+			errs = append(errs, fmt.Errorf("suppressions[%d]: unknown rule %q", i, s.Rule))
+		case s.Type != "" && s.Type != registry.Reference.String() && s.Type != registry.Chain.String() && s.Type != registry.Workflow.String() && s.Type != registry.Observer.String():
+			errs = append(errs, fmt.Errorf("suppressions[%d]: invalid type %q", i, s.Type))
+		case s.Name == "":
+			errs = append(errs, fmt.Errorf("suppressions[%d]: name must be set", i))
+		case s.Reason == "":
+			errs = append(errs, fmt.Errorf("suppressions[%d]: reason must be set", i))
+		default:
+			if _, err := path.Match(s.Name, ""); err != nil {
+				errs = append(errs, fmt.Errorf("suppressions[%d]: invalid name pattern %q: %w", i, s.Name, err))
+				continue
+			}
+			l.suppressions = append(l.suppressions, s)
+		}
+	}
+	if err := utilerrors.NewAggregate(errs); err != nil {
+		return nil, fmt.Errorf("invalid lint configuration: %w", err)
+	}
+	return l, nil
+}
+
+// Lint runs all enabled rules and returns the findings that are not
+// suppressed, ordered by component and rule
+func (l *Linter) Lint(r *Registry) []Finding {
+	var ret []Finding
+	for _, rule := range l.rules {
+		severity := l.severities[rule.Name]
+		if severity == SeverityOff {
+			continue
+		}
+		for _, f := range rule.Check(r) {
+			f.Rule, f.Severity = rule.Name, severity
+			if !l.suppressed(f) {
+				ret = append(ret, f)
+			}
+		}
+	}
+	sort.SliceStable(ret, func(i, j int) bool {
+		if ret[i].Type != ret[j].Type {
+			return ret[i].Type < ret[j].Type
+		}
+		if ret[i].Name != ret[j].Name {
+			return ret[i].Name < ret[j].Name
+		}
+		return ret[i].Rule < ret[j].Rule
+	})
+	return ret
+}
+
+func (l *Linter) suppressed(f Finding) bool {
+	for _, s := range l.suppressions {
+		if s.matches(f) {
+			return true
+		}
+	}
+	return false
+}
+
+// Errors returns an error for each finding with error severity
+func Errors(findings []Finding) []error {
+	var ret []error
+	for _, f := range findings {
+		if f.Severity == SeverityError {
+			ret = append(ret, errors.New(f.String()))
+		}
+	}
+	return ret
+}
@@ -0,0 +1,134 @@
+package lint
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/openshift/ci-tools/pkg/registry"
+	"github.com/openshift/ci-tools/pkg/testhelper"
+)
+
+func TestNewLinter(t *testing.T) {
+	for _, tc := range []struct {
+		name        string
+		config      Config
+		expectedErr error
+	}{
+		{
+			name: "valid configuration",
+			config: Config{
+				Severities:   map[string]Severity{MissingTimeoutRule: SeverityError, UnusedStepRule: SeverityOff},
+				Suppressions: []Suppression{{Rule: UnusedParameterRule, Type: "step", Name: "ipi-*", Reason: "read by sourced scripts"}},
+			},
+		},
+		{
+			name: "invalid configuration",
+			config: Config{
+				Severities: map[string]Severity{"no-such-rule": SeverityError, MissingTimeoutRule: "fatal"},
+				Suppressions: []Suppression{
+					{Rule: "no-such-rule", Name: "a", Reason: "r"},
+					{Rule: MissingTimeoutRule, Type: "job", Name: "a", Reason: "r"},
+					{Rule: MissingTimeoutRule, Reason: "r"},
+					{Rule: MissingTimeoutRule, Name: "a"},
+					{Rule: MissingTimeoutRule, Name: "[", Reason: "r"},
+				},
+			},
+			expectedErr: errors.New(`invalid lint configuration: [rule missing-timeout: invalid severity "fatal", must be one of error, warning or off, severity configured for unknown rule no-such-rule, suppressions[0]: unknown rule "no-such-rule", suppressions[1]: invalid type "job", suppressions[2]: name must be set, suppressions[3]: reason must be set, suppressions[4]: invalid name pattern "[": syntax error in pattern]`),
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := NewLinter(DefaultRules(), tc.config)
+			if diff := cmp.Diff(tc.expectedErr, err, testhelper.EquateErrorMessage); diff != "" {
+				t.Errorf("unexpected error: %s", diff)
+			}
+		})
+	}
+}
+
+func TestLint(t *testing.T) {
+	rules := []Rule{
+		{
+			Name:            "first",
+			DefaultSeverity: SeverityWarning,
+			Check: func(*Registry) []Finding {
+				return []Finding{
+					{Type: registry.Reference, Name: "b", Message: "first b"},
+					{Type: registry.Chain, Name: "a", Message: "first a"},
+					{Type: registry.Reference, Name: "ipi-a", Message: "first ipi-a"},
+				}
+			},
+		},
+		{
+			Name:            "second",
+			DefaultSeverity: SeverityWarning,
+			Check: func(*Registry) []Finding {
+				return []Finding{{Type: registry.Reference, Name: "b", Message: "second b"}}
+			},
+		},
+		{
+			Name:            "disabled",
+			DefaultSeverity: SeverityOff,
+			Check: func(*Registry) []Finding {
+				t.Error("disabled rule was run")
+				return nil
+			},
+		},
+	}
+	for _, tc := range []struct {
+		name     string
+		config   Config
+		expected []Finding
+	}{
+		{
+			name: "default severities",
+			expected: []Finding{
+				{Rule: "first", Severity: SeverityWarning, Type: registry.Chain, Name: "a", Message: "first a"},
+				{Rule: "first", Severity: SeverityWarning, Type: registry.Reference, Name: "b", Message: "first b"},
+				{Rule: "second", Severity: SeverityWarning, Type: registry.Reference, Name: "b", Message: "second b"},
+				{Rule: "first", Severity: SeverityWarning, Type: registry.Reference, Name: "ipi-a", Message: "first ipi-a"},
+			},
+		},
+		{
+			name:   "configured severities",
+			config: Config{Severities: map[string]Severity{"first": SeverityOff, "second": SeverityError}},
+			expected: []Finding{
+				{Rule: "second", Severity: SeverityError, Type: registry.Reference, Name: "b", Message: "second b"},
+			},
+		},
+		{
+			name: "suppressions",
+			config: Config{Suppressions: []Suppression{
+				{Rule: "first", Type: "step", Name: "ipi-*", Reason: "r"},
+				{Rule: "second", Name: "b", Reason: "r"},
+				{Rule: "first", Type: "workflow", Name: "a", Reason: "r"},
+			}},
+			expected: []Finding{
+				{Rule: "first", Severity: SeverityWarning, Type: registry.Chain, Name: "a", Message: "first a"},
+				{Rule: "first", Severity: SeverityWarning, Type: registry.Reference, Name: "b", Message: "first b"},
+			},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			linter, err := NewLinter(rules, tc.config)
+			if err != nil {
+				t.Fatalf("failed to create linter: %v", err)
+			}
+			if diff := cmp.Diff(tc.expected, linter.Lint(&Registry{})); diff != "" {
+				t.Errorf("unexpected findings: %s", diff)
+			}
+		})
+	}
+}
+
+func TestErrors(t *testing.T) {
+	findings := []Finding{
+		{Rule: "first", Severity: SeverityWarning, Type: registry.Reference, Name: "a", Message: "warned"},
+		{Rule: "second", Severity: SeverityError, Type: registry.Chain, Name: "b", Message: "failed"},
+	}
+	expected := []error{errors.New("chain/b: failed [second]")}
+	if diff := cmp.Diff(expected, Errors(findings), testhelper.EquateErrorMessage); diff != "" {
+		t.Errorf("unexpected errors: %s", diff)
+	}
+}
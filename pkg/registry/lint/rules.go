@@ -0,0 +1,207 @@
+package lint
+
+import (
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	"github.com/openshift/ci-tools/pkg/api"
+	"github.com/openshift/ci-tools/pkg/registry"
+	"github.com/openshift/ci-tools/pkg/validation"
+)
+
+const (
+	UnusedStepRule         = "unused-step"
+	UnusedParameterRule    = "unused-parameter"
+	GatherBestEffortRule   = "gather-best-effort"
+	MissingTimeoutRule     = "missing-timeout"
+	BroadCredentialsRule   = "broad-credentials"
+	UndeclaredVariableRule = "undeclared-variable"
+	MissingPipefailRule    = "missing-pipefail"
+)
+
+// gatherStepNameSubstring identifies steps that gather artifacts
+const gatherStepNameSubstring = "gather"
+
+// DefaultRules returns the rules implemented by this package
+func DefaultRules() []Rule {
+	return []Rule{
+		{
+			Name:            UnusedStepRule,
+			Description:     "Steps that are not used by any chain, workflow or configuration.",
+			DefaultSeverity: SeverityWarning,
+			Check:           unusedSteps,
+		},
+		{
+			Name:            UnusedParameterRule,
+			Description:     "Step parameters, dependency and lease variables that are never read by the step's commands.",
+			DefaultSeverity: SeverityWarning,
+			Check:           commandIssues(validation.UnusedVariable),
+		},
+		{
+			Name:            GatherBestEffortRule,
+			Description:     "Steps gathering artifacts in the post phase of a workflow that are not best_effort, so that their failure fails the job.",
+			DefaultSeverity: SeverityWarning,
+			Check:           gatherWithoutBestEffort,
+		},
+		{
+			Name:            MissingTimeoutRule,
+			Description:     "Steps that do not set a timeout.",
+			DefaultSeverity: SeverityWarning,
+			Check:           missingTimeouts,
+		},
+		{
+			Name:            BroadCredentialsRule,
+			Description:     "Credentials mounted at top-level directories or never referenced by the step's commands.",
+			DefaultSeverity: SeverityWarning,
+			Check:           broadCredentials,
+		},
+		{
+			Name:            UndeclaredVariableRule,
+			Description:     "Variables read by the step's commands that are not declared by the step, set by ci-operator or assigned by the commands.",
+			DefaultSeverity: SeverityWarning,
+			Check:           commandIssues(validation.UndeclaredVariable),
+		},
+		{
+			Name:            MissingPipefailRule,
+			Description:     "Step commands using pipelines without `set -o pipefail`.",
+			DefaultSeverity: SeverityWarning,
+			Check:           commandIssues(validation.MissingPipefail),
+		},
+	}
+}
+
+func sortedNames[T any](m map[string]T) []string {
+	names := make([]string, 0, len(m))
+	for name := range m {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// referencesIn returns the names of the steps used by the steps, following
+// chains
+func referencesIn(steps []api.TestStep, chains registry.ChainByName, seenChains sets.Set[string]) sets.Set[string] {
+	ret := sets.New[string]()
+	for _, step := range steps {
+		switch {
+		case step.Reference != nil:
+			ret.Insert(*step.Reference)
+		case step.Chain != nil:
+			if seenChains.Has(*step.Chain) {
+				continue
+			}
+			seenChains.Insert(*step.Chain)
+			ret = ret.Union(referencesIn(chains[*step.Chain].Steps, chains, seenChains))
+		}
+	}
+	return ret
+}
+
+func unusedSteps(r *Registry) []Finding {
+	if r.Configs == nil {
+		return nil
+	}
+	used := sets.New[string]()
+	addUsed := func(steps ...[]api.TestStep) {
+		for _, s := range steps {
+			for _, step := range s {
+				if step.Reference != nil {
+					used.Insert(*step.Reference)
+				}
+			}
+		}
+	}
+	for _, chain := range r.Chains {
+		addUsed(chain.Steps)
+	}
+	for _, workflow := range r.Workflows {
+		addUsed(workflow.Pre, workflow.Test, workflow.Post)
+	}
+	for _, config := range r.Configs {
+		for _, test := range config.Tests {
+			if test.MultiStageTestConfiguration != nil {
+				addUsed(test.MultiStageTestConfiguration.Pre, test.MultiStageTestConfiguration.Test, test.MultiStageTestConfiguration.Post)
+			}
+		}
+	}
+	var ret []Finding
+	for _, name := range sortedNames(r.References) {
+		if !used.Has(name) {
+			ret = append(ret, Finding{Type: registry.Reference, Name: name, Message: "step is not used by any chain, workflow or configuration"})
+		}
+	}
+	return ret
+}
+
+// commandIssues reports the issues of a kind found by the static analysis of
+// step commands
+func commandIssues(kind validation.CommandIssueKind) func(r *Registry) []Finding {
+	return func(r *Registry) []Finding {
+		if r.commandIssues == nil {
+			r.commandIssues = registry.AnalyzeCommands(r.References)
+		}
+		var ret []Finding
+		for _, name := range sortedNames(r.commandIssues) {
+			for _, issue := range r.commandIssues[name] {
+				if issue.Kind == kind {
+					ret = append(ret, Finding{Type: registry.Reference, Name: name, Message: issue.String()})
+				}
+			}
+		}
+		return ret
+	}
+}
+
+func gatherWithoutBestEffort(r *Registry) []Finding {
+	postOf := map[string][]string{}
+	for _, name := range sortedNames(r.Workflows) {
+		for ref := range referencesIn(r.Workflows[name].Post, r.Chains, sets.New[string]()) {
+			postOf[ref] = append(postOf[ref], name)
+		}
+	}
+	var ret []Finding
+	for _, name := range sortedNames(postOf) {
+		step, ok := r.References[name]
+		if !ok || !strings.Contains(name, gatherStepNameSubstring) || (step.BestEffort != nil && *step.BestEffort) {
+			continue
+		}
+		ret = append(ret, Finding{
+			Type:    registry.Reference,
+			Name:    name,
+			Message: fmt.Sprintf("step gathers artifacts in the post phase of workflow %s but is not best_effort, so its failure fails the job", strings.Join(postOf[name], ", ")),
+		})
+	}
+	return ret
+}
+
+func missingTimeouts(r *Registry) []Finding {
+	var ret []Finding
+	for _, name := range sortedNames(r.References) {
+		if r.References[name].Timeout == nil {
+			ret = append(ret, Finding{Type: registry.Reference, Name: name, Message: "step does not set a timeout"})
+		}
+	}
+	return ret
+}
+
+func broadCredentials(r *Registry) []Finding {
+	var ret []Finding
+	for _, name := range sortedNames(r.References) {
+		step := r.References[name]
+		for _, credential := range step.Credentials {
+			mountPath := path.Clean(credential.MountPath)
+			switch {
+			case strings.Count(mountPath, "/") <= 1:
+				ret = append(ret, Finding{Type: registry.Reference, Name: name, Message: fmt.Sprintf("credential %s/%s is mounted at top-level directory %s", credential.Namespace, credential.Name, mountPath)})
+			case !strings.Contains(step.Commands, mountPath):
+				ret = append(ret, Finding{Type: registry.Reference, Name: name, Message: fmt.Sprintf("credential %s/%s mounted at %s is never referenced by the commands", credential.Namespace, credential.Name, mountPath)})
+			}
+		}
+	}
+	return ret
+}
@@ -0,0 +1,90 @@
+package lint
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+
+	prowv1 "sigs.k8s.io/prow/pkg/apis/prowjobs/v1"
+
+	"github.com/openshift/ci-tools/pkg/api"
+	"github.com/openshift/ci-tools/pkg/registry"
+)
+
+func TestDefaultRules(t *testing.T) {
+	ref := func(name string) api.TestStep { return api.TestStep{Reference: &name} }
+	chain := func(name string) api.TestStep { return api.TestStep{Chain: &name} }
+	yes := true
+	timeout := &prowv1.Duration{Duration: time.Hour}
+	r := &Registry{
+		References: registry.ReferenceByName{
+			"install": {
+				As:          "install",
+				Commands:    "openshift-install --dir ${SHARED_DIR} --log-level \"$LOG_LEVEL\"\ncat /var/run/secrets/install/pull-secret",
+				Timeout:     timeout,
+				Environment: []api.StepParameter{{Name: "LOG_LEVEL"}, {Name: "UNUSED"}, {Name: "LOG"}},
+				Credentials: []api.CredentialReference{
+					{Namespace: "test-credentials", Name: "install", MountPath: "/var/run/secrets/install"},
+					{Namespace: "test-credentials", Name: "extra", MountPath: "/var/run/secrets/extra"},
+					{Namespace: "test-credentials", Name: "root", MountPath: "/tmp/"},
+				},
+			},
+			"gather-logs":         {As: "gather-logs", Commands: "oc adm must-gather", Timeout: timeout},
+			"gather-best-effort":  {As: "gather-best-effort", Commands: "true", Timeout: timeout, BestEffort: &yes},
+			"gather-unused":       {As: "gather-unused", Commands: "true", Timeout: timeout},
+			"gather-in-test-only": {As: "gather-in-test-only", Commands: "true", Timeout: timeout},
+			"used-by-config":      {As: "used-by-config", Commands: "true", Timeout: timeout},
+			"no-timeout":          {As: "no-timeout", Commands: "true"},
+			"scripted": {
+				As:           "scripted",
+				Commands:     "oc get pods | grep Running\necho \"${SOURCE}\" \"$UNDECLARED\"",
+				Timeout:      timeout,
+				Dependencies: []api.StepDependency{{Name: "src", Env: "SOURCE"}, {Name: "bin", Env: "BINARY"}},
+			},
+		},
+		Chains: registry.ChainByName{
+			"teardown": {As: "teardown", Steps: []api.TestStep{ref("gather-logs"), ref("gather-best-effort")}},
+		},
+		Workflows: registry.WorkflowByName{
+			"ipi": {
+				Pre:  []api.TestStep{ref("install")},
+				Test: []api.TestStep{ref("gather-in-test-only"), ref("no-timeout"), ref("scripted")},
+				Post: []api.TestStep{chain("teardown")},
+			},
+			"upi": {Post: []api.TestStep{ref("gather-logs")}},
+		},
+		Configs: []api.ReleaseBuildConfiguration{{
+			Tests: []api.TestStepConfiguration{{
+				As:                          "e2e",
+				MultiStageTestConfiguration: &api.MultiStageTestConfiguration{Test: []api.TestStep{ref("used-by-config")}},
+			}},
+		}},
+	}
+	linter, err := NewLinter(DefaultRules(), Config{})
+	if err != nil {
+		t.Fatalf("failed to create linter: %v", err)
+	}
+	expected := []Finding{
+		{Rule: GatherBestEffortRule, Severity: SeverityWarning, Type: registry.Reference, Name: "gather-logs", Message: "step gathers artifacts in the post phase of workflow ipi, upi but is not best_effort, so its failure fails the job"},
+		{Rule: UnusedStepRule, Severity: SeverityWarning, Type: registry.Reference, Name: "gather-unused", Message: "step is not used by any chain, workflow or configuration"},
+		{Rule: BroadCredentialsRule, Severity: SeverityWarning, Type: registry.Reference, Name: "install", Message: "credential test-credentials/extra mounted at /var/run/secrets/extra is never referenced by the commands"},
+		{Rule: BroadCredentialsRule, Severity: SeverityWarning, Type: registry.Reference, Name: "install", Message: "credential test-credentials/root is mounted at top-level directory /tmp"},
+		{Rule: UnusedParameterRule, Severity: SeverityWarning, Type: registry.Reference, Name: "install", Message: "parameter LOG is never read by the commands"},
+		{Rule: UnusedParameterRule, Severity: SeverityWarning, Type: registry.Reference, Name: "install", Message: "parameter UNUSED is never read by the commands"},
+		{Rule: MissingTimeoutRule, Severity: SeverityWarning, Type: registry.Reference, Name: "no-timeout", Message: "step does not set a timeout"},
+		{Rule: MissingPipefailRule, Severity: SeverityWarning, Type: registry.Reference, Name: "scripted", Message: "line 1: commands use a pipeline but do not `set -o pipefail`, so failures in the pipeline are ignored"},
+		{Rule: UndeclaredVariableRule, Severity: SeverityWarning, Type: registry.Reference, Name: "scripted", Message: "line 2: variable UNDECLARED is read but not declared in `env`, `dependencies` or `leases`"},
+		{Rule: UnusedParameterRule, Severity: SeverityWarning, Type: registry.Reference, Name: "scripted", Message: "dependency variable BINARY is never read by the commands"},
+	}
+	if diff := cmp.Diff(expected, linter.Lint(r)); diff != "" {
+		t.Errorf("unexpected findings: %s", diff)
+	}
+
+	r.Configs, r.commandIssues = nil, nil
+	for _, f := range linter.Lint(r) {
+		if f.Rule == UnusedStepRule {
+			t.Errorf("expected %s to be skipped without configurations, got %s", UnusedStepRule, f)
+		}
+	}
+}
@@ -25,21 +25,28 @@ type ObserverByName map[string]api.Observer
 // A superset of this validation is performed later when actual test
 // configurations are resolved.
 func Validate(stepsByName ReferenceByName, chainsByName ChainByName, workflowsByName WorkflowByName, observersByName ObserverByName) error {
-	reg := registry{stepsByName, chainsByName, workflowsByName, observersByName}
+	reg := registry{stepsByName: stepsByName, chainsByName: chainsByName, workflowsByName: workflowsByName, observersByName: observersByName}
 	var ret []error
 	for k := range chainsByName {
-		if _, err := reg.process([]api.TestStep{{Chain: &k}}, sets.New[string](), stackForChain()); err != nil {
+		if _, err := reg.process([]api.TestStep{{Chain: &k}}, sets.New[string](), map[string]string{}, stackForChain()); err != nil {
 			ret = append(ret, err...)
 		}
 	}
 	for k, v := range workflowsByName {
 		stack := stackForWorkflow(k, v.Environment, v.Dependencies, v.DNSConfig, v.NodeArchitecture)
+		var phases [][]api.LiteralTestStep
+		groups := map[string]string{}
 		for _, s := range [][]api.TestStep{v.Pre, v.Test, v.Post} {
-			if _, err := reg.process(s, sets.New[string](), stack); err != nil {
+			steps, err := reg.process(s, sets.New[string](), groups, stack)
+			if err != nil {
 				ret = append(ret, err...)
 			}
+			phases = append(phases, steps)
 		}
 		ret = append(ret, stack.checkUnused(&stack.records[0], nil, &reg)...)
+		// workflows are completed by the configurations using them, which may
+		// provide the steps publishing the inputs of the workflow
+		ret = append(ret, checkOutputs(stack, groups, false, phases...)...)
 	}
 	for _, v := range observersByName {
 		ret = append(ret, validation.Observer(v)...)
@@ -55,15 +62,21 @@ type registry struct {
 	chainsByName    ChainByName
 	workflowsByName WorkflowByName
 	observersByName ObserverByName
+	deprecations    Deprecations
+	warn            func(DeprecationWarning)
 }
 
-func NewResolver(stepsByName ReferenceByName, chainsByName ChainByName, workflowsByName WorkflowByName, observersByName ObserverByName) Resolver {
-	return &registry{
+func NewResolver(stepsByName ReferenceByName, chainsByName ChainByName, workflowsByName WorkflowByName, observersByName ObserverByName, opts ...ResolverOption) Resolver {
+	r := &registry{
 		stepsByName:     stepsByName,
 		chainsByName:    chainsByName,
 		workflowsByName: workflowsByName,
 		observersByName: observersByName,
 	}
+	for _, opt := range opts {
+		opt(r)
+	}
+	return r
 }
 
 func (r *registry) Resolve(name string, config api.MultiStageTestConfiguration) (api.MultiStageTestConfigurationLiteral, error) {
@@ -138,19 +151,24 @@ func (r *registry) resolveTest(
 		DependencyOverrides:      config.DependencyOverrides,
 	}
 	if config.Workflow != nil {
+		r.checkDeprecated(stack, Workflow, *config.Workflow)
 		stack.push(stackRecordForTest("workflow/"+*config.Workflow, nil, nil, nil, nil))
 	}
-	pre, errs := r.process(config.Pre, sets.New[string](), stack)
+	groups := map[string]string{}
+	pre, errs := r.process(config.Pre, sets.New[string](), groups, stack)
 	expandedFlow.Pre = append(expandedFlow.Pre, pre...)
 	resolveErrors = append(resolveErrors, errs...)
 
-	test, errs := r.process(config.Test, sets.New[string](), stack)
+	test, errs := r.process(config.Test, sets.New[string](), groups, stack)
 	expandedFlow.Test = append(expandedFlow.Test, test...)
 	resolveErrors = append(resolveErrors, errs...)
 
-	post, errs := r.process(config.Post, sets.New[string](), stack)
+	post, errs := r.process(config.Post, sets.New[string](), groups, stack)
 	expandedFlow.Post = append(expandedFlow.Post, post...)
 	resolveErrors = append(resolveErrors, errs...)
+	if len(groups) != 0 {
+		expandedFlow.ParallelGroups = groups
+	}
 
 	observerNames := sets.New[string]()
 	for _, step := range append(pre, append(test, post...)...) {
@@ -165,6 +183,7 @@ func (r *registry) resolveTest(
 	expandedFlow.Observers = observers
 
 	resolveErrors = append(resolveErrors, stack.checkUnused(&stack.records[0], overridden, r)...)
+	resolveErrors = append(resolveErrors, checkOutputs(stack, groups, true, expandedFlow.Pre, expandedFlow.Test, expandedFlow.Post)...)
 
 	if resolveErrors != nil {
 		return api.MultiStageTestConfigurationLiteral{}, utilerrors.NewAggregate(resolveErrors)
@@ -183,7 +202,7 @@ func (r *registry) ResolveWorkflow(name string) (api.MultiStageTestConfiguration
 }
 
 func (r *registry) ResolveChain(name string) (api.RegistryChain, error) {
-	steps, err := r.processChain(name, sets.New[string](), stack{})
+	steps, err := r.processChain(name, sets.New[string](), map[string]string{}, stack{})
 	if err != nil {
 		return api.RegistryChain{}, utilerrors.NewAggregate(err)
 	}
@@ -262,10 +281,14 @@ func mergeLeases(dst, src []api.StepLease) ([]api.StepLease, error) {
 	return ret, nil
 }
 
-func (r *registry) process(steps []api.TestStep, seen sets.Set[string], stack stack) (ret []api.LiteralTestStep, errs []error) {
+func (r *registry) process(steps []api.TestStep, seen sets.Set[string], groups map[string]string, stack stack) (ret []api.LiteralTestStep, errs []error) {
 	for _, step := range steps {
 		if step.Chain != nil {
-			steps, err := r.processChain(*step.Chain, seen, stack)
+			steps, err := r.processChain(*step.Chain, seen, groups, stack)
+			errs = append(errs, err...)
+			ret = append(ret, steps...)
+		} else if step.Parallel != nil {
+			steps, err := r.processParallel(step.Parallel, seen, groups, stack)
 			errs = append(errs, err...)
 			ret = append(ret, steps...)
 		} else {
@@ -279,19 +302,39 @@ func (r *registry) process(steps []api.TestStep, seen sets.Set[string], stack st
 	return
 }
 
-func (r *registry) processChain(name string, seen sets.Set[string], stack stack) ([]api.LiteralTestStep, []error) {
+func (r *registry) processChain(name string, seen sets.Set[string], groups map[string]string, stack stack) ([]api.LiteralTestStep, []error) {
 	chain, ok := r.chainsByName[name]
 	if !ok {
 		return nil, []error{stack.errorf("unknown step chain: %s", name)}
 	}
+	r.checkDeprecated(stack, Chain, name)
 	rec := stackRecordForStep("chain/"+name, chain.Environment, nil, nil, nil)
 	stack.push(rec)
 	defer stack.pop()
-	ret, err := r.process(chain.Steps, seen, stack)
+	ret, err := r.process(chain.Steps, seen, groups, stack)
 	err = append(err, stack.checkUnused(&rec, nil, r)...)
 	return ret, err
 }
 
+// processParallel resolves the members of a `parallel` group, which are
+// named after the first member, and records their group in groups.
+func (r *registry) processParallel(members []api.ParallelStep, seen sets.Set[string], groups map[string]string, stack stack) (ret []api.LiteralTestStep, errs []error) {
+	var group string
+	for _, member := range members {
+		step, err := r.processStep(&api.TestStep{LiteralTestStep: member.LiteralTestStep, Reference: member.Reference}, seen, stack)
+		errs = append(errs, err...)
+		if err != nil {
+			continue
+		}
+		if group == "" {
+			group = step.As
+		}
+		groups[step.As] = group
+		ret = append(ret, step)
+	}
+	return
+}
+
 func (r *registry) processStep(step *api.TestStep, seen sets.Set[string], stack stack) (ret api.LiteralTestStep, err []error) {
 	if ref := step.Reference; ref != nil {
 		var ok bool
@@ -299,6 +342,7 @@ func (r *registry) processStep(step *api.TestStep, seen sets.Set[string], stack
 		if !ok {
 			return api.LiteralTestStep{}, []error{stack.errorf("invalid step reference: %s", *ref)}
 		}
+		r.checkDeprecated(stack, Reference, *ref)
 	} else if step.LiteralTestStep != nil {
 		ret = *step.LiteralTestStep
 	} else {
@@ -316,6 +360,9 @@ func (r *registry) processStep(step *api.TestStep, seen sets.Set[string], stack
 		env := make([]api.StepParameter, 0, len(ret.Environment))
 		for _, e := range ret.Environment {
 			if v := stack.resolve(e.Name); v != nil {
+				if err := validation.StepParameterValue(e, *v); err != nil {
+					errs = append(errs, stack.errorf("step/%s: parameter %s: %v", ret.As, e.Name, err))
+				}
 				e.Default = v
 			} else if e.Default == nil && !stack.partial {
 				errs = append(errs, stack.errorf("step/%s: unresolved parameter: %s", ret.As, e.Name))
@@ -353,6 +400,9 @@ func (r *registry) processObservers(observerNames sets.Set[string], stack stack)
 			env := make([]api.StepParameter, 0, len(observer.Environment))
 			for _, e := range observer.Environment {
 				if v := stack.resolve(e.Name); v != nil {
+					if err := validation.StepParameterValue(e, *v); err != nil {
+						errs = append(errs, stack.errorf("observer/%s: parameter %s: %v", observer.Name, e.Name, err))
+					}
 					e.Default = v
 				} else if e.Default == nil && !stack.partial {
 					errs = append(errs, stack.errorf("observer/%s: unresolved parameter: %s", observer.Name, e.Name))
@@ -387,6 +437,12 @@ func (r *registry) iterateSteps(s api.TestStep, f func(*api.LiteralTestStep)) er
 		f(&r)
 	case s.LiteralTestStep != nil:
 		f(s.LiteralTestStep)
+	case s.Parallel != nil:
+		for _, s := range api.FlattenParallel([]api.TestStep{s}) {
+			if err := r.iterateSteps(s, f); err != nil {
+				return err
+			}
+		}
 	}
 	return nil
 }
@@ -412,3 +468,50 @@ func ResolveConfig(resolver Resolver, config api.ReleaseBuildConfiguration) (api
 	config.Tests = resolvedTests
 	return config, nil
 }
+
+// checkOutputs verifies that every input of a step is published as an output
+// of the expected type by exactly one step that runs before it. Inputs that do
+// not declare a type are set to the type of their output. Inputs that no step
+// publishes are only reported when the phases hold all the steps that run.
+func checkOutputs(stack stack, groups map[string]string, complete bool, phases ...[]api.LiteralTestStep) (errs []error) {
+	producers := map[string]api.LiteralTestStep{}
+	types := map[string]api.StepOutputType{}
+	for _, phase := range phases {
+		for _, step := range phase {
+			for _, output := range step.Outputs {
+				if producer, ok := producers[output.Name]; ok {
+					errs = append(errs, stack.errorf("step/%s: output %q is already published by step/%s", step.As, output.Name, producer.As))
+					continue
+				}
+				producers[output.Name] = step
+				types[output.Name] = output.Type
+			}
+		}
+	}
+	seen := sets.New[string]()
+	for _, phase := range phases {
+		for i := range phase {
+			step := &phase[i]
+			// the inputs may be shared with the registry, copy them before
+			// setting their types
+			step.Inputs = append([]api.StepInput(nil), step.Inputs...)
+			for j, input := range step.Inputs {
+				producer, ok := producers[input.Name]
+				switch {
+				case !ok:
+					if complete {
+						errs = append(errs, stack.errorf("step/%s: input %q is not published by any step", step.As, input.Name))
+					}
+				case !seen.Has(producer.As) || (groups[step.As] != "" && groups[producer.As] == groups[step.As]):
+					errs = append(errs, stack.errorf("step/%s: input %q is published by step/%s, which does not run before it", step.As, input.Name, producer.As))
+				case input.Type == "":
+					step.Inputs[j].Type = types[input.Name]
+				case input.Type != types[input.Name]:
+					errs = append(errs, stack.errorf("step/%s: input %q is expected to be a %s, but step/%s publishes a %s", step.As, input.Name, input.Type, producer.As, types[input.Name]))
+				}
+			}
+			seen.Insert(step.As)
+		}
+	}
+	return errs
+}
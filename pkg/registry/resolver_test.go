@@ -1539,7 +1539,13 @@ func TestResolveParameters(t *testing.T) {
 	defaultWorkflow := "workflow"
 	defaultTest := "test"
 	defaultEmpty := ""
+	typed := "typed"
+	typedWorkflow := "typed-workflow"
 	workflows := WorkflowByName{
+		typedWorkflow: api.MultiStageTestConfiguration{
+			Test:        []api.TestStep{{Reference: &typed}},
+			Environment: api.TestEnvironment{"FIPS_ENABLED": "yes"},
+		},
 		workflow: api.MultiStageTestConfiguration{
 			Test:         []api.TestStep{{Chain: &grandGrandParent}},
 			Environment:  api.TestEnvironment{"CHANGED": "workflow"},
@@ -1622,6 +1628,10 @@ func TestResolveParameters(t *testing.T) {
 			},
 			DNSConfig: &api.StepDNSConfig{},
 		},
+		typed: api.LiteralTestStep{
+			As:          typed,
+			Environment: []api.StepParameter{{Name: "FIPS_ENABLED", Type: api.StepParameterTypeBoolean, Default: &defaultEmpty}},
+		},
 		mergeRef: api.LiteralTestStep{
 			As:          mergeRef,
 			Environment: []api.StepParameter{{Name: "FROM_TEST"}},
@@ -1823,6 +1833,24 @@ func TestResolveParameters(t *testing.T) {
 			}},
 		},
 		err: errors.New("test/test: step/step: unresolved parameter: UNRESOLVED"),
+	}, {
+		name: "test parameter not accepted by the step parameter schema",
+		test: api.MultiStageTestConfiguration{
+			Test: []api.TestStep{{
+				LiteralTestStep: &api.LiteralTestStep{
+					As:          "step",
+					Environment: []api.StepParameter{{Name: "REPLICAS", Type: api.StepParameterTypeInteger}},
+				},
+			}},
+			Environment: api.TestEnvironment{"REPLICAS": "three"},
+		},
+		err: errors.New(`test/test: step/step: parameter REPLICAS: value "three" is not an integer`),
+	}, {
+		name: "workflow parameter not accepted by the step parameter schema",
+		test: api.MultiStageTestConfiguration{
+			Workflow: &typedWorkflow,
+		},
+		err: errors.New(`test/test: workflow/typed-workflow: step/typed: parameter FIPS_ENABLED: value "yes" is not a boolean, expected true or false`),
 	}, {
 		name: "unresolved workflow override is not an error",
 		test: api.MultiStageTestConfiguration{
@@ -1970,3 +1998,156 @@ func TestResolveLeasesCopy(t *testing.T) {
 	expected := []api.StepLease{{Count: 42}, {Count: 0}}
 	testhelper.Diff(t, "leases", leases, expected)
 }
+
+func TestResolveParallel(t *testing.T) {
+	gather, unknown := "gather", "unknown"
+	for _, tc := range []struct {
+		name           string
+		config         api.MultiStageTestConfiguration
+		expected       []api.LiteralTestStep
+		expectedGroups map[string]string
+		err            error
+	}{{
+		name: "members of a group share the group",
+		config: api.MultiStageTestConfiguration{
+			Post: []api.TestStep{
+				{Parallel: []api.ParallelStep{
+					{Reference: &gather},
+					{LiteralTestStep: &api.LiteralTestStep{As: "must-gather", From: "cli", Commands: "oc adm must-gather"}},
+				}},
+				{LiteralTestStep: &api.LiteralTestStep{As: "deprovision", From: "installer", Commands: "destroy"}},
+			},
+		},
+		expected: []api.LiteralTestStep{
+			{As: "gather", From: "cli", Commands: "gather"},
+			{As: "must-gather", From: "cli", Commands: "oc adm must-gather"},
+			{As: "deprovision", From: "installer", Commands: "destroy"},
+		},
+		expectedGroups: map[string]string{"gather": "gather", "must-gather": "gather"},
+	}, {
+		name: "unknown member",
+		config: api.MultiStageTestConfiguration{
+			Post: []api.TestStep{{Parallel: []api.ParallelStep{{Reference: &unknown}}}},
+		},
+		err: errors.New("test/test: invalid step reference: unknown"),
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			resolved, err := NewResolver(ReferenceByName{
+				gather: {As: gather, From: "cli", Commands: "gather"},
+			}, nil, nil, nil).Resolve("test", tc.config)
+			if diff := cmp.Diff(tc.err, err, testhelper.EquateErrorMessage); diff != "" {
+				t.Fatalf("unexpected error: %s", diff)
+			}
+			if diff := cmp.Diff(tc.expected, resolved.Post); diff != "" {
+				t.Errorf("unexpected steps: %s", diff)
+			}
+			if diff := cmp.Diff(tc.expectedGroups, resolved.ParallelGroups); diff != "" {
+				t.Errorf("unexpected groups: %s", diff)
+			}
+		})
+	}
+}
+
+func TestResolveOutputs(t *testing.T) {
+	step := func(as string, outputs []api.StepOutput, inputs []api.StepInput) api.TestStep {
+		return api.TestStep{LiteralTestStep: &api.LiteralTestStep{As: as, From: "src", Commands: "true", Outputs: outputs, Inputs: inputs}}
+	}
+	kubeconfig := []api.StepOutput{{Name: "cluster-name", Type: api.StepOutputTypeString}}
+	for _, tc := range []struct {
+		name     string
+		config   api.MultiStageTestConfiguration
+		expected []error
+	}{{
+		name: "input published by an earlier step",
+		config: api.MultiStageTestConfiguration{
+			Pre:  []api.TestStep{step("install", kubeconfig, nil)},
+			Test: []api.TestStep{step("e2e", nil, []api.StepInput{{Name: "cluster-name", Type: api.StepOutputTypeString, Env: "CLUSTER_NAME"}})},
+			Post: []api.TestStep{step("teardown", nil, []api.StepInput{{Name: "cluster-name", Env: "CLUSTER_NAME"}})},
+		},
+	}, {
+		name: "input not published by any step",
+		config: api.MultiStageTestConfiguration{
+			Pre:  []api.TestStep{step("install", kubeconfig, nil)},
+			Test: []api.TestStep{step("e2e", nil, []api.StepInput{{Name: "cluster-nmae", Env: "CLUSTER_NAME"}})},
+		},
+		expected: []error{errors.New(`test/test: step/e2e: input "cluster-nmae" is not published by any step`)},
+	}, {
+		name: "input published by a later step",
+		config: api.MultiStageTestConfiguration{
+			Test: []api.TestStep{step("e2e", nil, []api.StepInput{{Name: "cluster-name", Env: "CLUSTER_NAME"}})},
+			Post: []api.TestStep{step("teardown", kubeconfig, nil)},
+		},
+		expected: []error{errors.New(`test/test: step/e2e: input "cluster-name" is published by step/teardown, which does not run before it`)},
+	}, {
+		name: "input of the wrong type",
+		config: api.MultiStageTestConfiguration{
+			Pre:  []api.TestStep{step("install", kubeconfig, nil)},
+			Test: []api.TestStep{step("e2e", nil, []api.StepInput{{Name: "cluster-name", Type: api.StepOutputTypeFile, Env: "CLUSTER_NAME"}})},
+		},
+		expected: []error{errors.New(`test/test: step/e2e: input "cluster-name" is expected to be a file, but step/install publishes a string`)},
+	}, {
+		name: "output published twice",
+		config: api.MultiStageTestConfiguration{
+			Pre:  []api.TestStep{step("install", kubeconfig, nil)},
+			Test: []api.TestStep{step("e2e", kubeconfig, nil)},
+		},
+		expected: []error{errors.New(`test/test: step/e2e: output "cluster-name" is already published by step/install`)},
+	}, {
+		name: "input published by a member of the same parallel group",
+		config: api.MultiStageTestConfiguration{
+			Test: []api.TestStep{{Parallel: []api.ParallelStep{
+				{LiteralTestStep: step("install", kubeconfig, nil).LiteralTestStep},
+				{LiteralTestStep: step("e2e", nil, []api.StepInput{{Name: "cluster-name", Env: "CLUSTER_NAME"}}).LiteralTestStep},
+			}}},
+		},
+		expected: []error{errors.New(`test/test: step/e2e: input "cluster-name" is published by step/install, which does not run before it`)},
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			resolved, err := NewResolver(nil, nil, nil, nil).Resolve("test", tc.config)
+			if diff := cmp.Diff(utilerrors.NewAggregate(tc.expected), err, testhelper.EquateErrorMessage); diff != "" {
+				t.Errorf("unexpected error: %s", diff)
+			}
+			for _, step := range append(resolved.Test, resolved.Post...) {
+				for _, input := range step.Inputs {
+					if input.Type != api.StepOutputTypeString {
+						t.Errorf("expected the type of input %s of step %s to be resolved, got %q", input.Name, step.As, input.Type)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestValidateWorkflowOutputs(t *testing.T) {
+	step := func(as string, outputs []api.StepOutput, inputs []api.StepInput) api.TestStep {
+		return api.TestStep{LiteralTestStep: &api.LiteralTestStep{As: as, From: "src", Commands: "true", Outputs: outputs, Inputs: inputs}}
+	}
+	report := []api.StepOutput{{Name: "report", Type: api.StepOutputTypeFile}}
+	workflows := WorkflowByName{
+		"gather": {Post: []api.TestStep{step("upload", nil, []api.StepInput{{Name: "report", Env: "REPORT"}})}},
+	}
+	// the workflow is completed by the test steps of configurations
+	if err := Validate(nil, nil, workflows, nil); err != nil {
+		t.Errorf("expected inputs of a workflow not to require producers in the workflow, got %v", err)
+	}
+	gather := "gather"
+	for _, tc := range []struct {
+		name     string
+		config   api.MultiStageTestConfiguration
+		expected []error
+	}{{
+		name:   "configuration publishes the input",
+		config: api.MultiStageTestConfiguration{Workflow: &gather, Test: []api.TestStep{step("e2e", report, nil)}},
+	}, {
+		name:     "configuration does not publish the input",
+		config:   api.MultiStageTestConfiguration{Workflow: &gather, Test: []api.TestStep{step("e2e", nil, nil)}},
+		expected: []error{errors.New(`test/test: workflow/gather: step/upload: input "report" is not published by any step`)},
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := NewResolver(nil, nil, workflows, nil).Resolve("test", tc.config)
+			if diff := cmp.Diff(utilerrors.NewAggregate(tc.expected), err, testhelper.EquateErrorMessage); diff != "" {
+				t.Errorf("unexpected error: %s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,119 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+
+	"github.com/sirupsen/logrus"
+
+	"k8s.io/apimachinery/pkg/util/sets"
+	"sigs.k8s.io/prow/pkg/metrics"
+
+	"github.com/openshift/ci-tools/pkg/api"
+	"github.com/openshift/ci-tools/pkg/config"
+	"github.com/openshift/ci-tools/pkg/load"
+	"github.com/openshift/ci-tools/pkg/load/agents"
+	"github.com/openshift/ci-tools/pkg/registry"
+)
+
+// OwnerQuery restricts the deprecation inventory to a single owner
+const OwnerQuery = "owner"
+
+// Unowned groups the deprecated components whose OWNERS have no approvers
+const Unowned = "unowned"
+
+// DeprecatedComponent is a deprecated registry component along with the
+// tests that still use it
+type DeprecatedComponent struct {
+	Component   Component               `json:"component"`
+	Deprecation api.RegistryDeprecation `json:"deprecation"`
+	Tests       []ImpactedTest          `json:"tests,omitempty"`
+}
+
+// OwnerDeprecations lists the deprecated registry components approved by an
+// owner, which the owner is responsible for retiring
+type OwnerDeprecations struct {
+	Owner      string                `json:"owner"`
+	Components []DeprecatedComponent `json:"components"`
+}
+
+// GetDeprecationInventory groups the deprecated registry components by the
+// approvers in their OWNERS and lists the tests that still use each of them.
+// When owner is set, only the components of that owner are listed.
+func GetDeprecationInventory(graph registry.NodeByName, configs config.ByOrgRepo, metadata api.RegistryMetadata, owner string) ([]OwnerDeprecations, error) {
+	deprecations := load.Deprecations(metadata)
+	byOwner := map[string][]DeprecatedComponent{}
+	for _, item := range []struct {
+		nodeType     registry.Type
+		suffix       string
+		deprecations registry.DeprecationByName
+	}{
+		{nodeType: registry.Reference, suffix: load.RefSuffix, deprecations: deprecations.References},
+		{nodeType: registry.Chain, suffix: load.ChainSuffix, deprecations: deprecations.Chains},
+		{nodeType: registry.Workflow, suffix: load.WorkflowSuffix, deprecations: deprecations.Workflows},
+	} {
+		for _, name := range sets.List(sets.KeySet(item.deprecations)) {
+			owners := metadata[name+item.suffix].Owners.Approvers
+			if len(owners) == 0 {
+				owners = []string{Unowned}
+			}
+			if owner != "" {
+				if !sets.New[string](owners...).Has(owner) {
+					continue
+				}
+				owners = []string{owner}
+			}
+			impact, err := GetImpact(graph, configs, item.nodeType, name)
+			if err != nil {
+				return nil, err
+			}
+			component := DeprecatedComponent{Component: impact.Component, Deprecation: item.deprecations[name], Tests: impact.Tests}
+			for _, o := range owners {
+				byOwner[o] = append(byOwner[o], component)
+			}
+		}
+	}
+	var ret []OwnerDeprecations
+	for _, o := range sets.List(sets.KeySet(byOwner)) {
+		ret = append(ret, OwnerDeprecations{Owner: o, Components: byOwner[o]})
+	}
+	sort.SliceStable(ret, func(i, j int) bool {
+		return ret[i].Owner != Unowned && ret[j].Owner == Unowned
+	})
+	return ret, nil
+}
+
+// ResolveDeprecations responds with the inventory of deprecated registry
+// components and their remaining users, grouped by owner
+func ResolveDeprecations(regAgent agents.RegistryAgent, confAgent agents.ConfigAgent, resolverMetrics *metrics.Metrics) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "GET" {
+			w.WriteHeader(http.StatusNotImplemented)
+			_, _ = w.Write([]byte(http.StatusText(http.StatusNotImplemented)))
+			return
+		}
+		_, _, _, _, metadata := regAgent.GetRegistryComponents()
+		inventory, err := GetDeprecationInventory(regAgent.GetRegistryGraph(), confAgent.GetAll(), metadata, r.URL.Query().Get(OwnerQuery))
+		if err != nil {
+			metrics.RecordError("failed to determine deprecation inventory", resolverMetrics.ErrorRate)
+			w.WriteHeader(http.StatusInternalServerError)
+			fmt.Fprintf(w, "failed to determine deprecation inventory: %v", err)
+			logrus.WithError(err).Error("failed to determine deprecation inventory")
+			return
+		}
+		jsonContent, err := json.MarshalIndent(inventory, "", "  ")
+		if err != nil {
+			metrics.RecordError("failed to marshal deprecation inventory to JSON", resolverMetrics.ErrorRate)
+			w.WriteHeader(http.StatusInternalServerError)
+			fmt.Fprintf(w, "failed to marshal deprecation inventory to JSON: %v", err)
+			logrus.WithError(err).Error("failed to marshal deprecation inventory to JSON")
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+		if _, err := w.Write(jsonContent); err != nil {
+			logrus.WithError(err).Error("Failed to write response")
+		}
+	}
+}
@@ -0,0 +1,97 @@
+package server
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"sigs.k8s.io/prow/pkg/repoowners"
+
+	"github.com/openshift/ci-tools/pkg/api"
+	"github.com/openshift/ci-tools/pkg/config"
+	"github.com/openshift/ci-tools/pkg/registry"
+)
+
+func TestGetDeprecationInventory(t *testing.T) {
+	old, replacement, chain, workflow := "old", "new", "chain", "workflow"
+	graph, err := registry.NewGraph(
+		registry.ReferenceByName{old: {}, replacement: {}},
+		registry.ChainByName{chain: {Steps: []api.TestStep{{Reference: &old}}}},
+		registry.WorkflowByName{workflow: {Test: []api.TestStep{{Reference: &replacement}}}},
+		registry.ObserverByName{},
+	)
+	if err != nil {
+		t.Fatalf("failed to create graph: %v", err)
+	}
+	metadata := api.Metadata{Org: "org", Repo: "repo", Branch: "master"}
+	configs := config.ByOrgRepo{
+		"org": {"repo": {{
+			Metadata: metadata,
+			Tests: []api.TestStepConfiguration{
+				{As: "e2e", MultiStageTestConfiguration: &api.MultiStageTestConfiguration{Test: []api.TestStep{{Chain: &chain}}}},
+				{As: "upgrade", MultiStageTestConfiguration: &api.MultiStageTestConfiguration{Workflow: &workflow}},
+			},
+		}}},
+	}
+	oldDeprecation := api.RegistryDeprecation{Message: "old is slow", Replacement: replacement}
+	workflowDeprecation := api.RegistryDeprecation{Message: "unmaintained"}
+	registryMetadata := api.RegistryMetadata{
+		"old-ref.yaml": {
+			Owners:      repoowners.Config{Approvers: []string{"alice", "bob"}},
+			Deprecation: &oldDeprecation,
+		},
+		"new-ref.yaml":           {Owners: repoowners.Config{Approvers: []string{"alice"}}},
+		"workflow-workflow.yaml": {Deprecation: &workflowDeprecation},
+	}
+	oldComponent := DeprecatedComponent{
+		Component:   Component{Type: "reference", Name: old},
+		Deprecation: oldDeprecation,
+		Tests: []ImpactedTest{{
+			Metadata: metadata,
+			Test:     "e2e",
+			JobName:  "pull-ci-org-repo-master-e2e",
+			JobType:  "presubmit",
+			Via:      Component{Type: "chain", Name: chain},
+		}},
+	}
+	workflowComponent := DeprecatedComponent{
+		Component:   Component{Type: "workflow", Name: workflow},
+		Deprecation: workflowDeprecation,
+		Tests: []ImpactedTest{{
+			Metadata: metadata,
+			Test:     "upgrade",
+			JobName:  "pull-ci-org-repo-master-upgrade",
+			JobType:  "presubmit",
+			Via:      Component{Type: "workflow", Name: workflow},
+		}},
+	}
+	for _, tc := range []struct {
+		name     string
+		owner    string
+		expected []OwnerDeprecations
+	}{{
+		name: "all owners",
+		expected: []OwnerDeprecations{
+			{Owner: "alice", Components: []DeprecatedComponent{oldComponent}},
+			{Owner: "bob", Components: []DeprecatedComponent{oldComponent}},
+			{Owner: Unowned, Components: []DeprecatedComponent{workflowComponent}},
+		},
+	}, {
+		name:     "single owner",
+		owner:    "bob",
+		expected: []OwnerDeprecations{{Owner: "bob", Components: []DeprecatedComponent{oldComponent}}},
+	}, {
+		name:  "owner without deprecated components",
+		owner: "carol",
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			inventory, err := GetDeprecationInventory(graph, configs, registryMetadata, tc.owner)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if diff := cmp.Diff(tc.expected, inventory); diff != "" {
+				t.Errorf("inventory differs from expected:\n%s", diff)
+			}
+		})
+	}
+}
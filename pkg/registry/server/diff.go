@@ -0,0 +1,489 @@
+package server
+
+import (
+	"archive/tar"
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+
+	"k8s.io/apimachinery/pkg/util/sets"
+	"sigs.k8s.io/prow/pkg/metrics"
+
+	"github.com/openshift/ci-tools/pkg/api"
+	"github.com/openshift/ci-tools/pkg/load"
+	"github.com/openshift/ci-tools/pkg/load/agents"
+	"github.com/openshift/ci-tools/pkg/registry"
+)
+
+// Queries used to select the registry versions to compare. When the "to"
+// version is not set, the current registry is used.
+const (
+	FromVersionQuery = "from"
+	ToVersionQuery   = "to"
+)
+
+// UploadedRegistryVersion identifies a registry uploaded as an archive
+const UploadedRegistryVersion = "uploaded"
+
+// Limits for uploaded registry archives. The archive is decompressed and
+// extracted as a stream, so the limits bound both the memory and the disk
+// an upload can use, regardless of how well it compresses.
+const (
+	maxRegistryArchiveSize    = 100 << 20
+	maxRegistryExtractedSize  = 200 << 20
+	maxRegistryArchiveEntries = 20000
+)
+
+// DiffStatus describes how a step differs between two resolved tests
+type DiffStatus string
+
+const (
+	DiffAdded     DiffStatus = "added"
+	DiffRemoved   DiffStatus = "removed"
+	DiffChanged   DiffStatus = "changed"
+	DiffUnchanged DiffStatus = "unchanged"
+)
+
+// FieldDiff is a difference in a field of a resolved test. Before or After
+// is unset when the field is only present on one side. Parameters and
+// dependencies are compared individually as `env/NAME` and
+// `dependencies/ENV`.
+type FieldDiff struct {
+	Field  string  `json:"field"`
+	Before *string `json:"before,omitempty"`
+	After  *string `json:"after,omitempty"`
+}
+
+// StepDiff is the difference of a step between two resolved tests
+type StepDiff struct {
+	Phase  string      `json:"phase"`
+	Name   string      `json:"name"`
+	Status DiffStatus  `json:"status"`
+	Fields []FieldDiff `json:"fields,omitempty"`
+}
+
+// ResolvedDiff compares the resolved multi-stage configuration of a test
+// between two registries
+type ResolvedDiff struct {
+	Metadata api.Metadata `json:"metadata"`
+	Test     string       `json:"test"`
+	// From and To are the versions of the compared registries
+	From string `json:"from"`
+	To   string `json:"to"`
+	// Fields are the differences outside of the steps of the test
+	Fields []FieldDiff `json:"fields,omitempty"`
+	Steps  []StepDiff  `json:"steps,omitempty"`
+}
+
+// Changed determines whether the resolved test differs between the registries
+func (d *ResolvedDiff) Changed() bool {
+	if len(d.Fields) != 0 {
+		return true
+	}
+	for _, step := range d.Steps {
+		if step.Status != DiffUnchanged {
+			return true
+		}
+	}
+	return false
+}
+
+type versionResolver struct {
+	agent   agents.RegistryAgent
+	version string
+}
+
+func (r versionResolver) ResolveConfig(config api.ReleaseBuildConfiguration) (api.ReleaseBuildConfiguration, error) {
+	config.RegistryVersion = r.version
+	return r.agent.ResolveConfig(config)
+}
+
+// VersionResolver resolves configurations with a registry version retained
+// by the agent, or with the current registry if version is empty
+func VersionResolver(agent agents.RegistryAgent, version string) Resolver {
+	return versionResolver{agent: agent, version: version}
+}
+
+type archiveResolver struct {
+	resolver registry.Resolver
+}
+
+func (r archiveResolver) ResolveConfig(config api.ReleaseBuildConfiguration) (api.ReleaseBuildConfiguration, error) {
+	config.RegistryVersion = ""
+	resolved, err := registry.ResolveConfig(r.resolver, config)
+	if err != nil {
+		return api.ReleaseBuildConfiguration{}, err
+	}
+	resolved.RegistryVersion = UploadedRegistryVersion
+	return resolved, nil
+}
+
+// ArchiveResolver loads a registry from a tar archive, optionally gzipped,
+// of a registry directory and resolves configurations with it
+func ArchiveResolver(archive io.Reader) (Resolver, error) {
+	compressed := &limitedReader{reader: archive, limit: maxRegistryArchiveSize, remaining: maxRegistryArchiveSize, what: "registry archive"}
+	buffered := bufio.NewReader(compressed)
+	var content io.Reader = buffered
+	// check if data contains gzip header: http://www.zlib.org/rfc-gzip.html
+	if magic, err := buffered.Peek(2); err == nil && bytes.Equal(magic, []byte("\x1F\x8B")) {
+		gzipReader, err := gzip.NewReader(buffered)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decompress registry archive: %w", err)
+		}
+		defer gzipReader.Close()
+		content = gzipReader
+	}
+	dir, err := os.MkdirTemp("", "registry-")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create directory for registry archive: %w", err)
+	}
+	defer func() {
+		if err := os.RemoveAll(dir); err != nil {
+			logrus.WithError(err).Warn("failed to remove registry archive directory")
+		}
+	}()
+	extracted := &limitedReader{reader: content, limit: maxRegistryExtractedSize, remaining: maxRegistryExtractedSize, what: "extracted registry archive"}
+	if err := extractArchive(tar.NewReader(extracted), dir); err != nil {
+		return nil, fmt.Errorf("failed to extract registry archive: %w", err)
+	}
+	refs, chains, workflows, _, _, _, observers, err := load.Registry(dir, load.RegistryFlag(0))
+	if err != nil {
+		return nil, fmt.Errorf("failed to load registry archive: %w", err)
+	}
+	return archiveResolver{resolver: registry.NewResolver(refs, chains, workflows, observers)}, nil
+}
+
+// limitedReader fails reads once more than the remaining bytes are read,
+// unlike io.LimitReader which silently truncates the stream
+type limitedReader struct {
+	reader    io.Reader
+	limit     int64
+	remaining int64
+	what      string
+}
+
+func (r *limitedReader) Read(p []byte) (int, error) {
+	if r.remaining < 0 {
+		return 0, r.exceeded()
+	}
+	if int64(len(p)) > r.remaining+1 {
+		p = p[:r.remaining+1]
+	}
+	n, err := r.reader.Read(p)
+	r.remaining -= int64(n)
+	if r.remaining < 0 {
+		return n, r.exceeded()
+	}
+	return n, err
+}
+
+func (r *limitedReader) exceeded() error {
+	return fmt.Errorf("%s exceeds %d bytes", r.what, r.limit)
+}
+
+// extractArchive writes the directories and regular files of the archive
+// into dir, rejecting entries that would be written outside of it and
+// archives with too many entries
+func extractArchive(archive *tar.Reader, dir string) error {
+	for entries := 0; ; entries++ {
+		header, err := archive.Next()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if entries >= maxRegistryArchiveEntries {
+			return fmt.Errorf("archive has more than %d entries", maxRegistryArchiveEntries)
+		}
+		name := filepath.Clean(header.Name)
+		if filepath.IsAbs(name) || name == ".." || strings.HasPrefix(name, "../") {
+			return fmt.Errorf("invalid path in archive: %s", header.Name)
+		}
+		path := filepath.Join(dir, name)
+		switch header.Typeflag {
+		case tar.TypeDir:
+			if err := os.MkdirAll(path, 0755); err != nil {
+				return err
+			}
+		case tar.TypeReg:
+			if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+				return err
+			}
+			file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0644)
+			if err != nil {
+				return err
+			}
+			if _, err := io.Copy(file, archive); err != nil {
+				file.Close()
+				return err
+			}
+			if err := file.Close(); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// DiffResolvedTest resolves a multi-stage test of a configuration with two
+// registries and compares the results
+func DiffResolvedTest(config api.ReleaseBuildConfiguration, test string, from, to Resolver) (*ResolvedDiff, error) {
+	var found *api.TestStepConfiguration
+	for i := range config.Tests {
+		if config.Tests[i].As == test {
+			found = &config.Tests[i]
+			break
+		}
+	}
+	if found == nil {
+		return nil, fmt.Errorf("test %s not found in the configuration", test)
+	}
+	if found.MultiStageTestConfiguration == nil {
+		return nil, fmt.Errorf("test %s is not a multi-stage test", test)
+	}
+	// resolve only the requested test, so that other broken tests do not fail the comparison
+	config.Tests = []api.TestStepConfiguration{*found}
+	resolve := func(resolver Resolver) (api.MultiStageTestConfigurationLiteral, string, error) {
+		resolved, err := resolver.ResolveConfig(config)
+		if err != nil {
+			return api.MultiStageTestConfigurationLiteral{}, "", err
+		}
+		return *resolved.Tests[0].MultiStageTestConfigurationLiteral, resolved.RegistryVersion, nil
+	}
+	before, fromVersion, err := resolve(from)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve test with the registry to compare from: %w", err)
+	}
+	after, toVersion, err := resolve(to)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve test with the registry to compare to: %w", err)
+	}
+	diff := DiffLiterals(before, after)
+	diff.Metadata, diff.Test, diff.From, diff.To = config.Metadata, test, fromVersion, toVersion
+	return diff, nil
+}
+
+// DiffLiterals compares two resolved multi-stage tests. Steps are matched by
+// name within each phase.
+func DiffLiterals(before, after api.MultiStageTestConfigurationLiteral) *ResolvedDiff {
+	diff := &ResolvedDiff{}
+	for _, phase := range []struct {
+		name          string
+		before, after []api.LiteralTestStep
+	}{
+		{name: "pre", before: before.Pre, after: after.Pre},
+		{name: "test", before: before.Test, after: after.Test},
+		{name: "post", before: before.Post, after: after.Post},
+	} {
+		diff.Steps = append(diff.Steps, diffPhase(phase.name, phase.before, phase.after)...)
+	}
+	diff.Fields = diffEnvironment(before.Environment, after.Environment)
+	before.Pre, before.Test, before.Post, before.Environment = nil, nil, nil, nil
+	after.Pre, after.Test, after.Post, after.Environment = nil, nil, nil, nil
+	diff.Fields = append(diff.Fields, diffFields(before, after)...)
+	sortFields(diff.Fields)
+	return diff
+}
+
+func diffPhase(phase string, before, after []api.LiteralTestStep) []StepDiff {
+	var ret []StepDiff
+	byName := map[string]api.LiteralTestStep{}
+	for _, step := range before {
+		byName[step.As] = step
+	}
+	seen := sets.New[string]()
+	for _, step := range after {
+		seen.Insert(step.As)
+		previous, ok := byName[step.As]
+		if !ok {
+			ret = append(ret, StepDiff{Phase: phase, Name: step.As, Status: DiffAdded})
+			continue
+		}
+		stepDiff := StepDiff{Phase: phase, Name: step.As, Status: DiffUnchanged, Fields: diffStep(previous, step)}
+		if len(stepDiff.Fields) != 0 {
+			stepDiff.Status = DiffChanged
+		}
+		ret = append(ret, stepDiff)
+	}
+	for _, step := range before {
+		if !seen.Has(step.As) {
+			ret = append(ret, StepDiff{Phase: phase, Name: step.As, Status: DiffRemoved})
+		}
+	}
+	return ret
+}
+
+func diffStep(before, after api.LiteralTestStep) []FieldDiff {
+	values := func(step api.LiteralTestStep) (map[string]string, map[string]string) {
+		env, deps := map[string]string{}, map[string]string{}
+		for _, e := range step.Environment {
+			var value string
+			if e.Default != nil {
+				value = *e.Default
+			}
+			env[e.Name] = value
+		}
+		for _, d := range step.Dependencies {
+			deps[d.Env] = d.Name
+		}
+		return env, deps
+	}
+	beforeEnv, beforeDeps := values(before)
+	afterEnv, afterDeps := values(after)
+	ret := append(diffMaps("env/", beforeEnv, afterEnv), diffMaps("dependencies/", beforeDeps, afterDeps)...)
+	before.Environment, before.Dependencies = nil, nil
+	after.Environment, after.Dependencies = nil, nil
+	ret = append(ret, diffFields(before, after)...)
+	sortFields(ret)
+	return ret
+}
+
+func diffEnvironment(before, after api.TestEnvironment) []FieldDiff {
+	return diffMaps("env/", before, after)
+}
+
+func diffMaps(prefix string, before, after map[string]string) []FieldDiff {
+	var ret []FieldDiff
+	for _, key := range sets.List(sets.KeySet(before).Union(sets.KeySet(after))) {
+		b, inBefore := before[key]
+		a, inAfter := after[key]
+		if inBefore && inAfter && a == b {
+			continue
+		}
+		field := FieldDiff{Field: prefix + key}
+		if inBefore {
+			field.Before = &b
+		}
+		if inAfter {
+			field.After = &a
+		}
+		ret = append(ret, field)
+	}
+	return ret
+}
+
+// diffFields compares the top-level JSON fields of two values. String fields
+// are compared as text, others as their JSON serialization.
+func diffFields(before, after interface{}) []FieldDiff {
+	fields := func(v interface{}) map[string]string {
+		raw, err := json.Marshal(v)
+		if err != nil {
+			return map[string]string{"error": err.Error()}
+		}
+		var byField map[string]json.RawMessage
+		if err := json.Unmarshal(raw, &byField); err != nil {
+			return map[string]string{"error": err.Error()}
+		}
+		ret := map[string]string{}
+		for field, value := range byField {
+			var s string
+			if err := json.Unmarshal(value, &s); err == nil {
+				ret[field] = s
+			} else {
+				ret[field] = string(value)
+			}
+		}
+		return ret
+	}
+	return diffMaps("", fields(before), fields(after))
+}
+
+func sortFields(fields []FieldDiff) {
+	sort.Slice(fields, func(i, j int) bool {
+		return fields[i].Field < fields[j].Field
+	})
+}
+
+// ResolveDiff responds with the differences of a test resolved with two
+// registries. GET requests compare two registry versions retained by the
+// agent. When uploads are allowed, POST requests compare a registry version,
+// or the current registry, with the registry in the archive sent as the
+// request body.
+func ResolveDiff(regAgent agents.RegistryAgent, confAgent agents.ConfigAgent, allowUploads bool, resolverMetrics *metrics.Metrics) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "GET" && r.Method != "POST" {
+			w.WriteHeader(http.StatusNotImplemented)
+			_, _ = w.Write([]byte(http.StatusText(http.StatusNotImplemented)))
+			return
+		}
+		if r.Method == "POST" && !allowUploads {
+			metrics.RecordError("registry uploads disabled", resolverMetrics.ErrorRate)
+			w.WriteHeader(http.StatusForbidden)
+			_, _ = w.Write([]byte("registry uploads are disabled, compare two registry versions instead"))
+			return
+		}
+		metadata, err := metadataFromQuery(w, r)
+		if err != nil {
+			metrics.RecordError("invalid query", resolverMetrics.ErrorRate)
+			logrus.WithError(err).Warning("failed to read query from request")
+			return
+		}
+		test := r.URL.Query().Get(TestQuery)
+		if test == "" {
+			metrics.RecordError("invalid query", resolverMetrics.ErrorRate)
+			MissingQuery(w, TestQuery)
+			return
+		}
+		fromVersion := r.URL.Query().Get(FromVersionQuery)
+		var to Resolver
+		if r.Method == "POST" {
+			if to, err = ArchiveResolver(r.Body); err != nil {
+				metrics.RecordError("invalid registry archive", resolverMetrics.ErrorRate)
+				w.WriteHeader(http.StatusBadRequest)
+				fmt.Fprintf(w, "invalid registry archive: %v", err)
+				logrus.WithError(err).Warning("invalid registry archive")
+				return
+			}
+		} else {
+			if fromVersion == "" {
+				metrics.RecordError("invalid query", resolverMetrics.ErrorRate)
+				MissingQuery(w, FromVersionQuery)
+				return
+			}
+			to = VersionResolver(regAgent, r.URL.Query().Get(ToVersionQuery))
+		}
+		config, err := confAgent.GetMatchingConfig(metadata)
+		if err != nil {
+			metrics.RecordError("config not found", resolverMetrics.ErrorRate)
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprintf(w, "failed to get config: %v", err)
+			logrus.WithError(err).Warning("failed to get config")
+			return
+		}
+		diff, err := DiffResolvedTest(config, test, VersionResolver(regAgent, fromVersion), to)
+		if err != nil {
+			status := http.StatusBadRequest
+			if errors.Is(err, agents.ErrRegistryVersionNotFound) {
+				status = http.StatusNotFound
+			}
+			metrics.RecordError("failed to compare resolved test", resolverMetrics.ErrorRate)
+			w.WriteHeader(status)
+			fmt.Fprintf(w, "failed to compare resolved test: %v", err)
+			logrus.WithError(err).Warning("failed to compare resolved test")
+			return
+		}
+		jsonContent, err := json.MarshalIndent(diff, "", "  ")
+		if err != nil {
+			metrics.RecordError("failed to marshal diff to JSON", resolverMetrics.ErrorRate)
+			w.WriteHeader(http.StatusInternalServerError)
+			fmt.Fprintf(w, "failed to marshal diff to JSON: %v", err)
+			logrus.WithError(err).Error("failed to marshal diff to JSON")
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+		if _, err := w.Write(jsonContent); err != nil {
+			logrus.WithError(err).Error("Failed to write response")
+		}
+	}
+}
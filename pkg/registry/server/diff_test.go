@@ -0,0 +1,218 @@
+package server
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/openshift/ci-tools/pkg/api"
+	"github.com/openshift/ci-tools/pkg/testhelper"
+)
+
+func TestDiffLiterals(t *testing.T) {
+	value, changed := "value", "changed"
+	before := api.MultiStageTestConfigurationLiteral{
+		ClusterProfile: api.ClusterProfileAWS,
+		Pre: []api.LiteralTestStep{
+			{As: "install", From: "installer", Commands: "install", Environment: []api.StepParameter{{Name: "A", Default: &value}, {Name: "B", Default: &value}}},
+			{As: "gather", From: "cli", Commands: "gather"},
+		},
+		Test:        []api.LiteralTestStep{{As: "e2e", From: "tests", Commands: "run", Dependencies: []api.StepDependency{{Name: "release:latest", Env: "RELEASE"}}}},
+		Environment: api.TestEnvironment{"A": "value"},
+	}
+	after := api.MultiStageTestConfigurationLiteral{
+		ClusterProfile: api.ClusterProfileGCP,
+		Pre: []api.LiteralTestStep{
+			{As: "install", From: "installer", Commands: "install --verbose", Environment: []api.StepParameter{{Name: "A", Default: &changed}, {Name: "C", Default: &value}}},
+			{As: "rbac", From: "cli", Commands: "rbac"},
+		},
+		Test:        []api.LiteralTestStep{{As: "e2e", From: "tests", Commands: "run", Dependencies: []api.StepDependency{{Name: "release:latest", Env: "RELEASE"}}}},
+		Environment: api.TestEnvironment{"A": "value"},
+	}
+	str := func(s string) *string { return &s }
+	expected := &ResolvedDiff{
+		Fields: []FieldDiff{{Field: "cluster_profile", Before: str("aws"), After: str("gcp")}},
+		Steps: []StepDiff{
+			{Phase: "pre", Name: "install", Status: DiffChanged, Fields: []FieldDiff{
+				{Field: "commands", Before: str("install"), After: str("install --verbose")},
+				{Field: "env/A", Before: str("value"), After: str("changed")},
+				{Field: "env/B", Before: str("value")},
+				{Field: "env/C", After: str("value")},
+			}},
+			{Phase: "pre", Name: "rbac", Status: DiffAdded},
+			{Phase: "pre", Name: "gather", Status: DiffRemoved},
+			{Phase: "test", Name: "e2e", Status: DiffUnchanged},
+		},
+	}
+	diff := DiffLiterals(before, after)
+	if d := cmp.Diff(expected, diff); d != "" {
+		t.Errorf("diff differs from expected:\n%s", d)
+	}
+	if !diff.Changed() {
+		t.Error("expected the diff to be changed")
+	}
+	if DiffLiterals(before, before).Changed() {
+		t.Error("expected identical tests not to be changed")
+	}
+}
+
+type fakeResolver struct {
+	step    api.LiteralTestStep
+	version string
+	err     error
+}
+
+func (r fakeResolver) ResolveConfig(config api.ReleaseBuildConfiguration) (api.ReleaseBuildConfiguration, error) {
+	if r.err != nil {
+		return api.ReleaseBuildConfiguration{}, r.err
+	}
+	if len(config.Tests) != 1 {
+		return api.ReleaseBuildConfiguration{}, errors.New("expected a single test to be resolved")
+	}
+	config.Tests[0].MultiStageTestConfigurationLiteral = &api.MultiStageTestConfigurationLiteral{Test: []api.LiteralTestStep{r.step}}
+	config.Tests[0].MultiStageTestConfiguration = nil
+	config.RegistryVersion = r.version
+	return config, nil
+}
+
+func TestDiffResolvedTest(t *testing.T) {
+	metadata := api.Metadata{Org: "org", Repo: "repo", Branch: "master"}
+	ref := "e2e"
+	config := api.ReleaseBuildConfiguration{
+		Metadata: metadata,
+		Tests: []api.TestStepConfiguration{
+			{As: "unit", ContainerTestConfiguration: &api.ContainerTestConfiguration{From: "src"}},
+			{As: "e2e", MultiStageTestConfiguration: &api.MultiStageTestConfiguration{Test: []api.TestStep{{Reference: &ref}}}},
+		},
+	}
+	from := fakeResolver{step: api.LiteralTestStep{As: "e2e", Commands: "old"}, version: "v1"}
+	to := fakeResolver{step: api.LiteralTestStep{As: "e2e", Commands: "new"}, version: "v2"}
+	old, new := "old", "new"
+	testCases := []struct {
+		name        string
+		test        string
+		to          Resolver
+		expected    *ResolvedDiff
+		expectedErr error
+	}{
+		{
+			name: "changed commands",
+			test: "e2e",
+			to:   to,
+			expected: &ResolvedDiff{
+				Metadata: metadata, Test: "e2e", From: "v1", To: "v2",
+				Steps: []StepDiff{{Phase: "test", Name: "e2e", Status: DiffChanged, Fields: []FieldDiff{{Field: "commands", Before: &old, After: &new}}}},
+			},
+		},
+		{
+			name:        "missing test",
+			test:        "missing",
+			to:          to,
+			expectedErr: errors.New("test missing not found in the configuration"),
+		},
+		{
+			name:        "container test",
+			test:        "unit",
+			to:          to,
+			expectedErr: errors.New("test unit is not a multi-stage test"),
+		},
+		{
+			name:        "resolution failure",
+			test:        "e2e",
+			to:          fakeResolver{err: errors.New("no chain named foo")},
+			expectedErr: errors.New("failed to resolve test with the registry to compare to: no chain named foo"),
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			diff, err := DiffResolvedTest(config, tc.test, from, tc.to)
+			if d := cmp.Diff(tc.expectedErr, err, testhelper.EquateErrorMessage); d != "" {
+				t.Fatalf("error differs from expected:\n%s", d)
+			}
+			if d := cmp.Diff(tc.expected, diff); d != "" {
+				t.Errorf("diff differs from expected:\n%s", d)
+			}
+		})
+	}
+}
+
+func archive(t *testing.T, files map[string]string) *bytes.Buffer {
+	buf := &bytes.Buffer{}
+	gz := gzip.NewWriter(buf)
+	tw := tar.NewWriter(gz)
+	for name, content := range files {
+		if err := tw.WriteHeader(&tar.Header{Name: name, Mode: 0644, Size: int64(len(content)), Typeflag: tar.TypeReg}); err != nil {
+			t.Fatalf("failed to write header: %v", err)
+		}
+		if _, err := tw.Write([]byte(content)); err != nil {
+			t.Fatalf("failed to write file: %v", err)
+		}
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatalf("failed to close archive: %v", err)
+	}
+	if err := gz.Close(); err != nil {
+		t.Fatalf("failed to close archive: %v", err)
+	}
+	return buf
+}
+
+func TestArchiveResolver(t *testing.T) {
+	registryFiles := map[string]string{
+		"cluster-profiles/cluster-profiles-config.yaml": "[]\n",
+		"e2e/e2e-commands.sh":                           "run\n",
+		"e2e/e2e-ref.yaml": `ref:
+  as: e2e
+  from: tests
+  commands: e2e-commands.sh
+  resources:
+    requests:
+      cpu: 100m
+`,
+	}
+	ref := "e2e"
+	config := api.ReleaseBuildConfiguration{
+		Tests: []api.TestStepConfiguration{
+			{As: "e2e", MultiStageTestConfiguration: &api.MultiStageTestConfiguration{Test: []api.TestStep{{Reference: &ref}}}},
+		},
+	}
+	resolver, err := ArchiveResolver(archive(t, registryFiles))
+	if err != nil {
+		t.Fatalf("failed to load archive: %v", err)
+	}
+	resolved, err := resolver.ResolveConfig(config)
+	if err != nil {
+		t.Fatalf("failed to resolve config: %v", err)
+	}
+	if resolved.RegistryVersion != UploadedRegistryVersion {
+		t.Errorf("expected registry version %s, got %s", UploadedRegistryVersion, resolved.RegistryVersion)
+	}
+	if commands := resolved.Tests[0].MultiStageTestConfigurationLiteral.Test[0].Commands; commands != "run\n" {
+		t.Errorf("expected commands from the archive, got %q", commands)
+	}
+
+	_, err = ArchiveResolver(archive(t, map[string]string{"../escape": "x"}))
+	if d := cmp.Diff(errors.New("failed to extract registry archive: invalid path in archive: ../escape"), err, testhelper.EquateErrorMessage); d != "" {
+		t.Errorf("error differs from expected:\n%s", d)
+	}
+
+	entries := map[string]string{}
+	for i := 0; i <= maxRegistryArchiveEntries; i++ {
+		entries[fmt.Sprintf("step-%d/step-%d-commands.sh", i, i)] = ""
+	}
+	_, err = ArchiveResolver(archive(t, entries))
+	if d := cmp.Diff(fmt.Errorf("failed to extract registry archive: archive has more than %d entries", maxRegistryArchiveEntries), err, testhelper.EquateErrorMessage); d != "" {
+		t.Errorf("error differs from expected:\n%s", d)
+	}
+
+	_, err = ArchiveResolver(archive(t, map[string]string{"bomb/bomb-commands.sh": strings.Repeat("\x00", maxRegistryExtractedSize)}))
+	if d := cmp.Diff(fmt.Errorf("failed to extract registry archive: extracted registry archive exceeds %d bytes", maxRegistryExtractedSize), err, testhelper.EquateErrorMessage); d != "" {
+		t.Errorf("error differs from expected:\n%s", d)
+	}
+}
@@ -0,0 +1,191 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+
+	"github.com/sirupsen/logrus"
+
+	"sigs.k8s.io/prow/pkg/metrics"
+
+	"github.com/openshift/ci-tools/pkg/api"
+	"github.com/openshift/ci-tools/pkg/config"
+	"github.com/openshift/ci-tools/pkg/jobconfig"
+	"github.com/openshift/ci-tools/pkg/load/agents"
+	"github.com/openshift/ci-tools/pkg/registry"
+)
+
+// Queries used to select the registry component to compute the impact of
+const (
+	StepQuery     = "step"
+	ChainQuery    = "chain"
+	WorkflowQuery = "workflow"
+)
+
+// Component identifies an element of the step registry
+type Component struct {
+	Type string `json:"type"`
+	Name string `json:"name"`
+}
+
+func componentFor(node registry.Node) Component {
+	return Component{Type: node.Type().String(), Name: node.Name()}
+}
+
+// ImpactedTest is a test in a ci-operator configuration that uses a registry
+// component, along with the Prow job that runs it
+type ImpactedTest struct {
+	Metadata api.Metadata `json:"metadata"`
+	Test     string       `json:"test"`
+	JobName  string       `json:"job_name"`
+	JobType  string       `json:"job_type"`
+	// Via is the registry component that the test uses directly
+	Via Component `json:"via"`
+}
+
+// Impact lists everything that transitively uses a registry component
+type Impact struct {
+	Component Component `json:"component"`
+	// Users are the registry components that include the component,
+	// directly or through other components
+	Users []Component    `json:"users,omitempty"`
+	Tests []ImpactedTest `json:"tests,omitempty"`
+}
+
+// ComponentFromQuery gets the registry component from the request query.
+// Exactly one of the step, chain or workflow queries must be set.
+func ComponentFromQuery(r *http.Request) (registry.Type, string, error) {
+	var found []string
+	var nodeType registry.Type
+	var name string
+	for _, item := range []struct {
+		query    string
+		nodeType registry.Type
+	}{
+		{query: StepQuery, nodeType: registry.Reference},
+		{query: ChainQuery, nodeType: registry.Chain},
+		{query: WorkflowQuery, nodeType: registry.Workflow},
+	} {
+		if value := r.URL.Query().Get(item.query); value != "" {
+			found = append(found, item.query)
+			nodeType, name = item.nodeType, value
+		}
+	}
+	if len(found) != 1 {
+		return 0, "", fmt.Errorf("exactly one of the %s, %s or %s queries must be set, got %d", StepQuery, ChainQuery, WorkflowQuery, len(found))
+	}
+	return nodeType, name, nil
+}
+
+// GetImpact determines the registry components, ci-operator configuration
+// tests and Prow jobs that transitively use a registry component.
+func GetImpact(graph registry.NodeByName, configs config.ByOrgRepo, nodeType registry.Type, name string) (*Impact, error) {
+	var nodes map[string]registry.Node
+	switch nodeType {
+	case registry.Reference:
+		nodes = graph.References
+	case registry.Chain:
+		nodes = graph.Chains
+	case registry.Workflow:
+		nodes = graph.Workflows
+	case registry.Observer:
+		nodes = graph.Observers
+	}
+	node, ok := nodes[name]
+	if !ok {
+		return nil, fmt.Errorf("%s %s not found in the registry", nodeType, name)
+	}
+
+	impact := &Impact{Component: componentFor(node)}
+	affected := registry.AffectedNodes([]registry.Node{node})
+	for _, n := range affected {
+		if n.Type() != node.Type() || n.Name() != node.Name() {
+			impact.Users = append(impact.Users, componentFor(n))
+		}
+	}
+
+	for _, repos := range configs {
+		for _, repoConfigs := range repos {
+			for i := range repoConfigs {
+				impact.Tests = append(impact.Tests, impactedTests(&repoConfigs[i], affected)...)
+			}
+		}
+	}
+	sort.Slice(impact.Tests, func(i, j int) bool {
+		return impact.Tests[i].JobName < impact.Tests[j].JobName
+	})
+	return impact, nil
+}
+
+func impactedTests(cfg *api.ReleaseBuildConfiguration, affected []registry.Node) []ImpactedTest {
+	var ret []ImpactedTest
+	for _, test := range cfg.Tests {
+		if test.MultiStageTestConfiguration == nil {
+			continue
+		}
+		for _, n := range affected {
+			if !registry.TestUsesNode(*test.MultiStageTestConfiguration, n) {
+				continue
+			}
+			prefix, jobType := jobconfig.PresubmitPrefix, "presubmit"
+			switch {
+			case test.IsPeriodic():
+				prefix, jobType = jobconfig.PeriodicPrefix, "periodic"
+			case test.Postsubmit:
+				prefix, jobType = jobconfig.PostsubmitPrefix, "postsubmit"
+			}
+			ret = append(ret, ImpactedTest{
+				Metadata: cfg.Metadata,
+				Test:     test.As,
+				JobName:  cfg.Metadata.JobName(prefix, test.As),
+				JobType:  jobType,
+				Via:      componentFor(n),
+			})
+			break
+		}
+	}
+	return ret
+}
+
+// ResolveImpact responds with the impact of changing the registry component
+// selected by the request query, allowing step owners to review the users of
+// a component before changing it
+func ResolveImpact(regAgent agents.RegistryAgent, confAgent agents.ConfigAgent, resolverMetrics *metrics.Metrics) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "GET" {
+			w.WriteHeader(http.StatusNotImplemented)
+			_, _ = w.Write([]byte(http.StatusText(http.StatusNotImplemented)))
+			return
+		}
+		nodeType, name, err := ComponentFromQuery(r)
+		if err != nil {
+			metrics.RecordError("invalid impact query", resolverMetrics.ErrorRate)
+			w.WriteHeader(http.StatusBadRequest)
+			fmt.Fprint(w, err.Error())
+			logrus.WithError(err).Warning("failed to read query from request")
+			return
+		}
+		impact, err := GetImpact(regAgent.GetRegistryGraph(), confAgent.GetAll(), nodeType, name)
+		if err != nil {
+			metrics.RecordError("registry component not found", resolverMetrics.ErrorRate)
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprintf(w, "could not determine impact: %v", err)
+			logrus.WithError(err).Warning("registry component not found")
+			return
+		}
+		jsonContent, err := json.MarshalIndent(impact, "", "  ")
+		if err != nil {
+			metrics.RecordError("failed to marshal impact to JSON", resolverMetrics.ErrorRate)
+			w.WriteHeader(http.StatusInternalServerError)
+			fmt.Fprintf(w, "failed to marshal impact to JSON: %v", err)
+			logrus.WithError(err).Error("failed to marshal impact to JSON")
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+		if _, err := w.Write(jsonContent); err != nil {
+			logrus.WithError(err).Error("Failed to write response")
+		}
+	}
+}
@@ -0,0 +1,151 @@
+package server
+
+import (
+	"errors"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/openshift/ci-tools/pkg/api"
+	"github.com/openshift/ci-tools/pkg/config"
+	"github.com/openshift/ci-tools/pkg/registry"
+	"github.com/openshift/ci-tools/pkg/testhelper"
+)
+
+func TestComponentFromQuery(t *testing.T) {
+	testCases := []struct {
+		name         string
+		query        string
+		expectedType registry.Type
+		expectedName string
+		expectedErr  error
+	}{
+		{
+			name:         "step",
+			query:        "step=ipi-install-install",
+			expectedType: registry.Reference,
+			expectedName: "ipi-install-install",
+		},
+		{
+			name:         "workflow",
+			query:        "workflow=ipi-aws",
+			expectedType: registry.Workflow,
+			expectedName: "ipi-aws",
+		},
+		{
+			name:        "no component",
+			expectedErr: errors.New("exactly one of the step, chain or workflow queries must be set, got 0"),
+		},
+		{
+			name:        "multiple components",
+			query:       "step=ipi-install-install&chain=ipi-install",
+			expectedErr: errors.New("exactly one of the step, chain or workflow queries must be set, got 2"),
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			nodeType, name, err := ComponentFromQuery(httptest.NewRequest("GET", "/impact?"+tc.query, nil))
+			if diff := cmp.Diff(tc.expectedErr, err, testhelper.EquateErrorMessage); diff != "" {
+				t.Fatalf("error differs from expected:\n%s", diff)
+			}
+			if nodeType != tc.expectedType || name != tc.expectedName {
+				t.Errorf("expected %s %s, got %s %s", tc.expectedType, tc.expectedName, nodeType, name)
+			}
+		})
+	}
+}
+
+func TestGetImpact(t *testing.T) {
+	install, rbac, conf, ipi, deprovision, ipiInstall := "ipi-install-install", "ipi-install-rbac", "ipi-conf", "ipi", "ipi-deprovision", "ipi-install"
+	graph, err := registry.NewGraph(
+		registry.ReferenceByName{install: {}, rbac: {}, conf: {}, deprovision: {}},
+		registry.ChainByName{"ipi-install": {Steps: []api.TestStep{{Reference: &install}, {Reference: &rbac}}}},
+		registry.WorkflowByName{ipi: {Pre: []api.TestStep{{Chain: &ipiInstall}}, Post: []api.TestStep{{Reference: &deprovision}}}},
+		registry.ObserverByName{},
+	)
+	if err != nil {
+		t.Fatalf("failed to create graph: %v", err)
+	}
+	cron := "@daily"
+	metadata := api.Metadata{Org: "org", Repo: "repo", Branch: "master"}
+	configs := config.ByOrgRepo{
+		"org": {"repo": {{
+			Metadata: metadata,
+			Tests: []api.TestStepConfiguration{
+				{As: "e2e", MultiStageTestConfiguration: &api.MultiStageTestConfiguration{Workflow: &ipi}},
+				{As: "periodic", Cron: &cron, MultiStageTestConfiguration: &api.MultiStageTestConfiguration{Pre: []api.TestStep{{Reference: &install}}}},
+				{As: "conf", MultiStageTestConfiguration: &api.MultiStageTestConfiguration{Test: []api.TestStep{{Reference: &conf}}}},
+				{As: "unit", ContainerTestConfiguration: &api.ContainerTestConfiguration{From: "src"}},
+			},
+		}}},
+	}
+
+	testCases := []struct {
+		name        string
+		nodeType    registry.Type
+		component   string
+		expected    *Impact
+		expectedErr error
+	}{
+		{
+			name:      "step used through a chain and directly",
+			nodeType:  registry.Reference,
+			component: install,
+			expected: &Impact{
+				Component: Component{Type: "reference", Name: install},
+				Users: []Component{
+					{Type: "workflow", Name: ipi},
+					{Type: "chain", Name: "ipi-install"},
+				},
+				Tests: []ImpactedTest{
+					{Metadata: metadata, Test: "periodic", JobName: "periodic-ci-org-repo-master-periodic", JobType: "periodic", Via: Component{Type: "reference", Name: install}},
+					{Metadata: metadata, Test: "e2e", JobName: "pull-ci-org-repo-master-e2e", JobType: "presubmit", Via: Component{Type: "workflow", Name: ipi}},
+				},
+			},
+		},
+		{
+			name:      "workflow",
+			nodeType:  registry.Workflow,
+			component: ipi,
+			expected: &Impact{
+				Component: Component{Type: "workflow", Name: ipi},
+				Tests: []ImpactedTest{
+					{Metadata: metadata, Test: "e2e", JobName: "pull-ci-org-repo-master-e2e", JobType: "presubmit", Via: Component{Type: "workflow", Name: ipi}},
+				},
+			},
+		},
+		{
+			name:      "step used only through a chain",
+			nodeType:  registry.Reference,
+			component: rbac,
+			expected: &Impact{
+				Component: Component{Type: "reference", Name: rbac},
+				Users: []Component{
+					{Type: "workflow", Name: ipi},
+					{Type: "chain", Name: "ipi-install"},
+				},
+				Tests: []ImpactedTest{
+					{Metadata: metadata, Test: "e2e", JobName: "pull-ci-org-repo-master-e2e", JobType: "presubmit", Via: Component{Type: "workflow", Name: ipi}},
+				},
+			},
+		},
+		{
+			name:        "unknown chain",
+			nodeType:    registry.Chain,
+			component:   "missing",
+			expectedErr: errors.New("chain missing not found in the registry"),
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			impact, err := GetImpact(graph, configs, tc.nodeType, tc.component)
+			if diff := cmp.Diff(tc.expectedErr, err, testhelper.EquateErrorMessage); diff != "" {
+				t.Fatalf("error differs from expected:\n%s", diff)
+			}
+			if diff := cmp.Diff(tc.expected, impact); diff != "" {
+				t.Errorf("impact differs from expected:\n%s", diff)
+			}
+		})
+	}
+}
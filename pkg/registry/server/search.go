@@ -0,0 +1,298 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"unicode"
+
+	"github.com/sirupsen/logrus"
+
+	"k8s.io/apimachinery/pkg/util/sets"
+	"sigs.k8s.io/prow/pkg/metrics"
+
+	"github.com/openshift/ci-tools/pkg/api"
+	"github.com/openshift/ci-tools/pkg/load"
+	"github.com/openshift/ci-tools/pkg/load/agents"
+	"github.com/openshift/ci-tools/pkg/registry"
+)
+
+// Queries used to search the step registry
+const (
+	TextQuery       = "q"
+	TypeQuery       = "type"
+	CredentialQuery = "credential"
+	EnvQuery        = "env"
+	CommandQuery    = "command"
+)
+
+// SearchQuery selects registry components. All fields that are set must
+// match for a component to be selected.
+type SearchQuery struct {
+	// Text matches words in the names, documentation and commands of
+	// components. Each word in the text must be the prefix of a word in
+	// the component.
+	Text string `json:"text,omitempty"`
+	// Type restricts the search to steps (`reference`), chains or workflows.
+	Type string `json:"type,omitempty"`
+	// Credential selects steps that mount a credential, identified by its
+	// name or by `namespace/name`.
+	Credential string `json:"credential,omitempty"`
+	// Env selects steps and chains that declare a parameter and workflows
+	// that set it.
+	Env string `json:"env,omitempty"`
+	// Command selects steps whose commands contain the string.
+	Command string `json:"command,omitempty"`
+	// Owner selects components that list the user as an approver or
+	// reviewer in their OWNERS.
+	Owner string `json:"owner,omitempty"`
+}
+
+// SearchResult is a registry component selected by a search
+type SearchResult struct {
+	Component     Component `json:"component"`
+	Documentation string    `json:"documentation,omitempty"`
+}
+
+// SearchQueryFromRequest reads the search query from the request query.
+func SearchQueryFromRequest(r *http.Request) (SearchQuery, error) {
+	values := r.URL.Query()
+	query := SearchQuery{
+		Text:       values.Get(TextQuery),
+		Type:       values.Get(TypeQuery),
+		Credential: values.Get(CredentialQuery),
+		Env:        values.Get(EnvQuery),
+		Command:    values.Get(CommandQuery),
+		Owner:      values.Get(OwnerQuery),
+	}
+	if query.Type == StepQuery {
+		query.Type = registry.Reference.String()
+	}
+	switch query.Type {
+	case "", registry.Reference.String(), registry.Chain.String(), registry.Workflow.String():
+	default:
+		return SearchQuery{}, fmt.Errorf("invalid %s %q, expected one of %s, %s or %s", TypeQuery, query.Type, StepQuery, ChainQuery, WorkflowQuery)
+	}
+	if query == (SearchQuery{Type: query.Type}) {
+		return SearchQuery{}, fmt.Errorf("at least one of the %s, %s, %s, %s or %s queries must be set", TextQuery, CredentialQuery, EnvQuery, CommandQuery, OwnerQuery)
+	}
+	return query, nil
+}
+
+type indexEntry struct {
+	component     Component
+	documentation string
+	commands      string
+}
+
+// Index is a search index over the components of the step registry
+type Index struct {
+	entries []indexEntry
+	// words holds the sorted words occurring in any component, so that
+	// words with a given prefix can be found with a binary search
+	words []string
+	// The remaining fields map words and structured attributes to the
+	// indices of the entries they occur in
+	byWord       map[string]sets.Set[int]
+	byType       map[string]sets.Set[int]
+	byCredential map[string]sets.Set[int]
+	byEnv        map[string]sets.Set[int]
+	byOwner      map[string]sets.Set[int]
+}
+
+// NewIndex indexes the components of the step registry
+func NewIndex(refs registry.ReferenceByName, chains registry.ChainByName, workflows registry.WorkflowByName, docs map[string]string, metadata api.RegistryMetadata) *Index {
+	idx := &Index{
+		byWord:       map[string]sets.Set[int]{},
+		byType:       map[string]sets.Set[int]{},
+		byCredential: map[string]sets.Set[int]{},
+		byEnv:        map[string]sets.Set[int]{},
+		byOwner:      map[string]sets.Set[int]{},
+	}
+	add := func(nodeType registry.Type, name, suffix, commands string, env []string, credentials []api.CredentialReference) {
+		i := len(idx.entries)
+		idx.entries = append(idx.entries, indexEntry{
+			component:     Component{Type: nodeType.String(), Name: name},
+			documentation: docs[name],
+			commands:      commands,
+		})
+		insert(idx.byType, nodeType.String(), i)
+		for _, word := range words(name, docs[name], commands) {
+			insert(idx.byWord, word, i)
+		}
+		for _, e := range env {
+			insert(idx.byEnv, e, i)
+		}
+		for _, c := range credentials {
+			insert(idx.byCredential, c.Name, i)
+			insert(idx.byCredential, c.Namespace+"/"+c.Name, i)
+		}
+		owners := metadata[name+suffix].Owners
+		for _, owner := range append(append([]string{}, owners.Approvers...), owners.Reviewers...) {
+			insert(idx.byOwner, owner, i)
+		}
+	}
+	for _, name := range sets.List(sets.KeySet(refs)) {
+		ref := refs[name]
+		var env []string
+		for _, e := range ref.Environment {
+			env = append(env, e.Name)
+		}
+		add(registry.Reference, name, load.RefSuffix, ref.Commands, env, ref.Credentials)
+	}
+	for _, name := range sets.List(sets.KeySet(chains)) {
+		var env []string
+		for _, e := range chains[name].Environment {
+			env = append(env, e.Name)
+		}
+		add(registry.Chain, name, load.ChainSuffix, "", env, nil)
+	}
+	for _, name := range sets.List(sets.KeySet(workflows)) {
+		add(registry.Workflow, name, load.WorkflowSuffix, "", sets.List(sets.KeySet(workflows[name].Environment)), nil)
+	}
+	idx.words = sets.List(sets.KeySet(idx.byWord))
+	return idx
+}
+
+func insert(index map[string]sets.Set[int], key string, i int) {
+	if _, ok := index[key]; !ok {
+		index[key] = sets.New[int]()
+	}
+	index[key].Insert(i)
+}
+
+// words splits text into lowercase words of letters and digits
+func words(text ...string) []string {
+	var ret []string
+	for _, t := range text {
+		ret = append(ret, strings.FieldsFunc(strings.ToLower(t), func(r rune) bool {
+			return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+		})...)
+	}
+	return ret
+}
+
+// withPrefix returns the entries containing a word that starts with prefix
+func (idx *Index) withPrefix(prefix string) sets.Set[int] {
+	ret := sets.New[int]()
+	for i := sort.SearchStrings(idx.words, prefix); i < len(idx.words) && strings.HasPrefix(idx.words[i], prefix); i++ {
+		ret = ret.Union(idx.byWord[idx.words[i]])
+	}
+	return ret
+}
+
+// Search returns the components selected by the query, sorted by type and name
+func (idx *Index) Search(query SearchQuery) []SearchResult {
+	var candidates sets.Set[int]
+	filter := func(matches sets.Set[int]) {
+		if matches == nil {
+			matches = sets.New[int]()
+		}
+		if candidates == nil {
+			candidates = matches.Clone()
+		} else {
+			candidates = candidates.Intersection(matches)
+		}
+	}
+	for _, word := range words(query.Text) {
+		filter(idx.withPrefix(word))
+	}
+	for _, item := range []struct {
+		value string
+		index map[string]sets.Set[int]
+	}{
+		{value: query.Type, index: idx.byType},
+		{value: query.Credential, index: idx.byCredential},
+		{value: query.Env, index: idx.byEnv},
+		{value: query.Owner, index: idx.byOwner},
+	} {
+		if item.value != "" {
+			filter(item.index[item.value])
+		}
+	}
+	if query.Command != "" {
+		matches := sets.New[int]()
+		for i, entry := range idx.entries {
+			if strings.Contains(entry.commands, query.Command) {
+				matches.Insert(i)
+			}
+		}
+		filter(matches)
+	}
+	var ret []SearchResult
+	for _, i := range sets.List(candidates) {
+		ret = append(ret, SearchResult{Component: idx.entries[i].component, Documentation: idx.entries[i].documentation})
+	}
+	sort.Slice(ret, func(i, j int) bool {
+		if ret[i].Component.Type != ret[j].Component.Type {
+			return ret[i].Component.Type < ret[j].Component.Type
+		}
+		return ret[i].Component.Name < ret[j].Component.Name
+	})
+	return ret
+}
+
+// Searcher searches the registry loaded by an agent, rebuilding its index
+// whenever the agent reloads the registry
+type Searcher struct {
+	agent      agents.RegistryAgent
+	lock       sync.Mutex
+	generation int
+	index      *Index
+}
+
+// NewSearcher creates a Searcher for the registry loaded by the agent
+func NewSearcher(agent agents.RegistryAgent) *Searcher {
+	return &Searcher{agent: agent}
+}
+
+// Search returns the components of the current registry selected by the query
+func (s *Searcher) Search(query SearchQuery) []SearchResult {
+	return s.getIndex().Search(query)
+}
+
+func (s *Searcher) getIndex() *Index {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if generation := s.agent.GetGeneration(); s.index == nil || generation != s.generation {
+		refs, chains, workflows, docs, metadata := s.agent.GetRegistryComponents()
+		s.index = NewIndex(refs, chains, workflows, docs, metadata)
+		s.generation = generation
+	}
+	return s.index
+}
+
+// ResolveSearch responds with the registry components selected by the
+// search query in the request
+func ResolveSearch(searcher *Searcher, resolverMetrics *metrics.Metrics) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "GET" {
+			w.WriteHeader(http.StatusNotImplemented)
+			_, _ = w.Write([]byte(http.StatusText(http.StatusNotImplemented)))
+			return
+		}
+		query, err := SearchQueryFromRequest(r)
+		if err != nil {
+			metrics.RecordError("invalid search query", resolverMetrics.ErrorRate)
+			w.WriteHeader(http.StatusBadRequest)
+			fmt.Fprint(w, err.Error())
+			logrus.WithError(err).Warning("failed to read query from request")
+			return
+		}
+		jsonContent, err := json.MarshalIndent(searcher.Search(query), "", "  ")
+		if err != nil {
+			metrics.RecordError("failed to marshal search results to JSON", resolverMetrics.ErrorRate)
+			w.WriteHeader(http.StatusInternalServerError)
+			fmt.Fprintf(w, "failed to marshal search results to JSON: %v", err)
+			logrus.WithError(err).Error("failed to marshal search results to JSON")
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+		if _, err := w.Write(jsonContent); err != nil {
+			logrus.WithError(err).Error("Failed to write response")
+		}
+	}
+}
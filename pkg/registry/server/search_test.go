@@ -0,0 +1,166 @@
+package server
+
+import (
+	"errors"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"sigs.k8s.io/prow/pkg/repoowners"
+
+	"github.com/openshift/ci-tools/pkg/api"
+	"github.com/openshift/ci-tools/pkg/registry"
+	"github.com/openshift/ci-tools/pkg/testhelper"
+)
+
+func TestSearchQueryFromRequest(t *testing.T) {
+	testCases := []struct {
+		name        string
+		query       string
+		expected    SearchQuery
+		expectedErr error
+	}{
+		{
+			name:     "all queries",
+			query:    "q=install&type=chain&credential=ns/secret&env=FOO&command=oc+adm&owner=alice",
+			expected: SearchQuery{Text: "install", Type: "chain", Credential: "ns/secret", Env: "FOO", Command: "oc adm", Owner: "alice"},
+		},
+		{
+			name:     "step is an alias for references",
+			query:    "type=step&env=FOO",
+			expected: SearchQuery{Type: "reference", Env: "FOO"},
+		},
+		{
+			name:        "invalid type",
+			query:       "type=job&env=FOO",
+			expectedErr: errors.New(`invalid type "job", expected one of step, chain or workflow`),
+		},
+		{
+			name:        "only the type",
+			query:       "type=workflow",
+			expectedErr: errors.New("at least one of the q, credential, env, command or owner queries must be set"),
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			query, err := SearchQueryFromRequest(httptest.NewRequest("GET", "/search?"+tc.query, nil))
+			if diff := cmp.Diff(tc.expectedErr, err, testhelper.EquateErrorMessage); diff != "" {
+				t.Fatalf("error differs from expected:\n%s", diff)
+			}
+			if diff := cmp.Diff(tc.expected, query); diff != "" {
+				t.Errorf("query differs from expected:\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestIndexSearch(t *testing.T) {
+	gather, install := "gather-must-gather", "ipi-install-install"
+	idx := NewIndex(
+		registry.ReferenceByName{
+			gather: {
+				As:          gather,
+				Commands:    "oc adm must-gather --dest-dir ${ARTIFACT_DIR}",
+				Environment: []api.StepParameter{{Name: "GATHER_TIMEOUT"}},
+			},
+			install: {
+				As:          install,
+				Commands:    "openshift-install create cluster",
+				Credentials: []api.CredentialReference{{Namespace: "test-credentials", Name: "cluster-secrets-aws"}},
+				Environment: []api.StepParameter{{Name: "INSTALL_TIMEOUT"}},
+			},
+		},
+		registry.ChainByName{
+			"ipi-install": {Steps: []api.TestStep{{Reference: &install}}, Environment: []api.StepParameter{{Name: "INSTALL_TIMEOUT"}}},
+		},
+		registry.WorkflowByName{
+			"ipi-aws": {Pre: []api.TestStep{{Reference: &install}}, Environment: api.TestEnvironment{"GATHER_TIMEOUT": "1h"}},
+		},
+		map[string]string{
+			gather:        "Collects must-gather output from the cluster.",
+			install:       "Installs an OpenShift cluster.",
+			"ipi-install": "Installs a cluster with IPI.",
+		},
+		api.RegistryMetadata{
+			"gather-must-gather-ref.yaml": {Owners: repoowners.Config{Approvers: []string{"alice"}}},
+			"ipi-aws-workflow.yaml":       {Owners: repoowners.Config{Reviewers: []string{"alice"}}},
+		},
+	)
+	result := func(nodeType registry.Type, name, documentation string) SearchResult {
+		return SearchResult{Component: Component{Type: nodeType.String(), Name: name}, Documentation: documentation}
+	}
+	testCases := []struct {
+		name     string
+		query    SearchQuery
+		expected []SearchResult
+	}{
+		{
+			name:  "text matches word prefixes in names and documentation",
+			query: SearchQuery{Text: "Install clus"},
+			expected: []SearchResult{
+				result(registry.Chain, "ipi-install", "Installs a cluster with IPI."),
+				result(registry.Reference, install, "Installs an OpenShift cluster."),
+			},
+		},
+		{
+			name:  "text matches commands",
+			query: SearchQuery{Text: "artifact_dir"},
+			expected: []SearchResult{
+				result(registry.Reference, gather, "Collects must-gather output from the cluster."),
+			},
+		},
+		{
+			name:  "credential by namespace and name",
+			query: SearchQuery{Credential: "test-credentials/cluster-secrets-aws"},
+			expected: []SearchResult{
+				result(registry.Reference, install, "Installs an OpenShift cluster."),
+			},
+		},
+		{
+			name:  "env declared by steps and chains",
+			query: SearchQuery{Env: "INSTALL_TIMEOUT"},
+			expected: []SearchResult{
+				result(registry.Chain, "ipi-install", "Installs a cluster with IPI."),
+				result(registry.Reference, install, "Installs an OpenShift cluster."),
+			},
+		},
+		{
+			name:  "env set by workflows",
+			query: SearchQuery{Env: "GATHER_TIMEOUT", Type: "workflow"},
+			expected: []SearchResult{
+				result(registry.Workflow, "ipi-aws", ""),
+			},
+		},
+		{
+			name:  "command substring",
+			query: SearchQuery{Command: "oc adm must-gather"},
+			expected: []SearchResult{
+				result(registry.Reference, gather, "Collects must-gather output from the cluster."),
+			},
+		},
+		{
+			name:  "owner as approver or reviewer",
+			query: SearchQuery{Owner: "alice"},
+			expected: []SearchResult{
+				result(registry.Reference, gather, "Collects must-gather output from the cluster."),
+				result(registry.Workflow, "ipi-aws", ""),
+			},
+		},
+		{
+			name:  "all filters must match",
+			query: SearchQuery{Owner: "alice", Command: "openshift-install"},
+		},
+		{
+			name:  "unknown credential",
+			query: SearchQuery{Credential: "missing"},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if diff := cmp.Diff(tc.expected, idx.Search(tc.query)); diff != "" {
+				t.Errorf("results differ from expected:\n%s", diff)
+			}
+		})
+	}
+}
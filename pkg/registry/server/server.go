@@ -2,6 +2,7 @@ package server
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
@@ -23,12 +24,18 @@ const (
 	RepoQuery    = "repo"
 	BranchQuery  = "branch"
 	VariantQuery = "variant"
+	TestQuery    = "test"
 
 	InjectFromOrgQuery     = "injectTestFromOrg"
 	InjectFromRepoQuery    = "injectTestFromRepo"
 	InjectFromBranchQuery  = "injectTestFromBranch"
 	InjectFromVariantQuery = "injectTestFromVariant"
 	InjectTestQuery        = "injectTest"
+
+	// RegistryVersionQuery pins the registry version used to resolve a
+	// configuration, overriding any pin in the configuration itself. It is
+	// accepted by every endpoint that resolves configurations.
+	RegistryVersionQuery = "registryVersion"
 )
 
 // NameQuery is used for fetching cluster profile details by its name
@@ -55,7 +62,10 @@ func MetadataFromQuery(w http.ResponseWriter, r *http.Request) (api.Metadata, er
 		}
 		return api.Metadata{}, err
 	}
+	return metadataFromQuery(w, r)
+}
 
+func metadataFromQuery(w http.ResponseWriter, r *http.Request) (api.Metadata, error) {
 	var metadata api.Metadata
 	for query, field := range map[string]*string{
 		OrgQuery:    &metadata.Org,
@@ -79,8 +89,18 @@ func MissingQuery(w http.ResponseWriter, field string) {
 	fmt.Fprintf(w, "%s query missing or incorrect", field)
 }
 
-func resolveAndRespond(resolver Resolver, config api.ReleaseBuildConfiguration, w http.ResponseWriter, logger *logrus.Entry, resolverMetrics *metrics.Metrics) {
+func resolveAndRespond(resolver Resolver, config api.ReleaseBuildConfiguration, w http.ResponseWriter, r *http.Request, logger *logrus.Entry, resolverMetrics *metrics.Metrics) {
+	if version := r.URL.Query().Get(RegistryVersionQuery); version != "" {
+		config.RegistryVersion = version
+	}
 	config, err := resolver.ResolveConfig(config)
+	if errors.Is(err, agents.ErrRegistryVersionNotFound) {
+		metrics.RecordError("registry version not found", resolverMetrics.ErrorRate)
+		w.WriteHeader(http.StatusNotFound)
+		fmt.Fprintf(w, "failed to resolve config with registry: %v", err)
+		logger.WithError(err).Warning("failed to resolve config with registry")
+		return
+	}
 	if err != nil {
 		metrics.RecordError("failed to resolve config with registry", resolverMetrics.ErrorRate)
 		w.WriteHeader(http.StatusBadRequest)
@@ -189,7 +209,7 @@ func ResolveConfig(configs Getter, resolver Resolver, resolverMetrics *metrics.M
 			logger.WithError(err).Warning("failed to get config")
 			return
 		}
-		resolveAndRespond(resolver, config, w, logger, resolverMetrics)
+		resolveAndRespond(resolver, config, w, r, logger, resolverMetrics)
 	}
 }
 
@@ -214,7 +234,7 @@ func ResolveLiteralConfig(resolver Resolver, resolverMetrics *metrics.Metrics) h
 			_, _ = w.Write([]byte("Could not parse request body as unresolved config."))
 			return
 		}
-		resolveAndRespond(resolver, unresolvedConfig, w, logger, resolverMetrics)
+		resolveAndRespond(resolver, unresolvedConfig, w, r, logger, resolverMetrics)
 	}
 }
 
@@ -413,7 +433,7 @@ func ResolveAndMergeConfigsAndInjectTest(configs Getter, resolver Resolver, reso
 			mergedConfig = injectTest(*mergedConfig, configs, resolverMetrics, w, r, logger)
 		}
 		if mergedConfig != nil {
-			resolveAndRespond(resolver, *mergedConfig, w, logger, resolverMetrics)
+			resolveAndRespond(resolver, *mergedConfig, w, r, logger, resolverMetrics)
 		}
 	}
 }
@@ -38,15 +38,23 @@ func (s *stack) pop() {
 }
 
 func (s *stack) errorf(format string, args ...interface{}) error {
-	var b strings.Builder
-	for i := range s.records {
-		b.WriteString(s.records[i].name)
-		b.WriteString(": ")
+	var prefix string
+	if path := s.path(); path != "" {
+		prefix = path + ": "
 	}
-	args = append([]interface{}{b.String()}, args...)
+	args = append([]interface{}{prefix}, args...)
 	return fmt.Errorf("%s"+format, args...)
 }
 
+// path locates the current position in the stack, e.g. `test/e2e: chain/foo`
+func (s *stack) path() string {
+	names := make([]string, 0, len(s.records))
+	for i := range s.records {
+		names = append(names, s.records[i].name)
+	}
+	return strings.Join(names, ": ")
+}
+
 func (s *stack) resolve(name string) *string {
 	for _, r := range s.records {
 		for j, e := range r.env {
@@ -0,0 +1,30 @@
+package registry
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+)
+
+// Snapshot holds the content of a registry that determines how
+// configurations are resolved, in the form addressed by Version.
+type Snapshot struct {
+	Steps     ReferenceByName `json:"steps"`
+	Chains    ChainByName     `json:"chains"`
+	Workflows WorkflowByName  `json:"workflows"`
+	Observers ObserverByName  `json:"observers"`
+}
+
+// Version returns a content address for the registry: two registries that
+// resolve configurations identically have the same version. Maps are
+// serialized with sorted keys, so the version does not depend on the order
+// in which the registry was loaded.
+func Version(stepsByName ReferenceByName, chainsByName ChainByName, workflowsByName WorkflowByName, observersByName ObserverByName) (string, error) {
+	raw, err := json.Marshal(Snapshot{Steps: stepsByName, Chains: chainsByName, Workflows: workflowsByName, Observers: observersByName})
+	if err != nil {
+		return "", fmt.Errorf("failed to serialize registry: %w", err)
+	}
+	sum := sha256.Sum256(raw)
+	return hex.EncodeToString(sum[:]), nil
+}
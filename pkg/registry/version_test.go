@@ -0,0 +1,38 @@
+package registry
+
+import (
+	"testing"
+
+	"github.com/openshift/ci-tools/pkg/api"
+)
+
+func TestVersion(t *testing.T) {
+	version := func(refs ReferenceByName) string {
+		v, err := Version(refs, ChainByName{}, WorkflowByName{}, ObserverByName{})
+		if err != nil {
+			t.Fatalf("failed to compute version: %v", err)
+		}
+		return v
+	}
+	refs := func(commands string) ReferenceByName {
+		return ReferenceByName{
+			"a": {As: "a", Commands: commands},
+			"b": {As: "b", Commands: "b"},
+		}
+	}
+	base := version(refs("a"))
+	if len(base) != 64 {
+		t.Errorf("expected a hex SHA-256 digest, got %q", base)
+	}
+	for i := 0; i < 10; i++ {
+		if v := version(refs("a")); v != base {
+			t.Fatalf("version is not stable: %s != %s", v, base)
+		}
+	}
+	if v := version(refs("changed")); v == base {
+		t.Errorf("expected a changed step to change the version")
+	}
+	if v := version(ReferenceByName{"a": api.LiteralTestStep{As: "a", Commands: "a"}}); v == base {
+		t.Errorf("expected a removed step to change the version")
+	}
+}
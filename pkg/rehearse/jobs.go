@@ -38,7 +38,6 @@ import (
 	"github.com/openshift/ci-tools/pkg/diffs"
 	"github.com/openshift/ci-tools/pkg/jobconfig"
 	"github.com/openshift/ci-tools/pkg/registry"
-	"github.com/openshift/ci-tools/pkg/util"
 	"github.com/openshift/ci-tools/pkg/util/gzip"
 )
 
@@ -659,29 +658,8 @@ func selectJobsForRegistryStep(node registry.Node, configs []*config.DataWithInf
 				continue
 			}
 
-			// TODO: Handle workflows with overridden logFields.
-			// Workflows can have overridden logFields and thus may have overridden the field that made the workflow an ancestor.
-			// This should be handled to reduce the number of rehearsals being done, but requires much more information than
-			// the graph alone provides.
-			if node.Type() == registry.Workflow {
-				if test.MultiStageTestConfiguration.Workflow != nil && node.Name() == *test.MultiStageTestConfiguration.Workflow {
-					selectJob()
-				}
-				continue
-			}
-			if node.Type() == registry.Observer {
-				if testUsesObserver(test, node.Name()) {
-					selectJob()
-				}
-				continue
-			}
-			testSteps := append(test.MultiStageTestConfiguration.Pre, append(test.MultiStageTestConfiguration.Test, test.MultiStageTestConfiguration.Post...)...)
-			for _, testStep := range testSteps {
-				hasRef := testStep.Reference != nil && node.Type() == registry.Reference && node.Name() == *testStep.Reference
-				hasChain := testStep.Chain != nil && node.Type() == registry.Chain && node.Name() == *testStep.Chain
-				if hasRef || hasChain {
-					selectJob()
-				}
+			if registry.TestUsesNode(*test.MultiStageTestConfiguration, node) {
+				selectJob()
 			}
 		}
 	}
@@ -692,41 +670,6 @@ func selectJobsForRegistryStep(node registry.Node, configs []*config.DataWithInf
 	return selectedPresubmits, selectedPeriodics
 }
 
-func testUsesObserver(test api.TestStepConfiguration, o string) bool {
-	t := test.MultiStageTestConfiguration
-	return t != nil &&
-		t.Observers != nil &&
-		util.Contains(t.Observers.Enable, o)
-}
-
-// getAffectedNodes returns a sorted list of all nodes affected by a seed list
-// of changed nodes. Affected node is either a directly changed node or any of
-// its ancestors. Each node is present at most once.
-func getAffectedNodes(changed []registry.Node) []registry.Node {
-	all := changed
-	for _, node := range changed {
-		all = append(all, node.Ancestors()...)
-	}
-
-	var worklist []registry.Node
-	seen := sets.New[string]()
-	keyFunc := func(node registry.Node) string { return fmt.Sprintf("type=%d name=%s", node.Type(), node.Name()) }
-	for _, node := range all {
-		key := keyFunc(node)
-		if !seen.Has(key) {
-			seen.Insert(key)
-			worklist = append(worklist, node)
-		}
-	}
-	sort.Slice(worklist, func(i, j int) bool {
-		if worklist[i].Name() == worklist[j].Name() {
-			return worklist[i].Type() < worklist[j].Type()
-		}
-		return worklist[i].Name() < worklist[j].Name()
-	})
-	return worklist
-}
-
 func SelectJobsForChangedRegistry(regSteps []registry.Node, allPresubmits presubmitsByRepo, allPeriodics []prowconfig.Periodic, ciopConfigs config.DataByFilename, logger *logrus.Entry) (config.Presubmits, config.Periodics) {
 	// We need a sorted index of ci-operator configs for deterministic behavior
 	var sortedConfigs []*config.DataWithInfo
@@ -740,7 +683,7 @@ func SelectJobsForChangedRegistry(regSteps []registry.Node, allPresubmits presub
 		return moreRelevant(sortedConfigs[i], sortedConfigs[j])
 	})
 
-	stepWorklist := getAffectedNodes(regSteps)
+	stepWorklist := registry.AffectedNodes(regSteps)
 
 	presubmitIndex := presubmitsByName{}
 	for _, jobs := range allPresubmits {
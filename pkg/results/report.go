@@ -10,6 +10,7 @@ import (
 	"net/http"
 	"os"
 	"strings"
+	"time"
 
 	"github.com/sirupsen/logrus"
 
@@ -105,6 +106,26 @@ type PodScalerRequest struct {
 	ResourceType     string
 }
 
+// PodScalerShadowRequest holds the data about a change pod-scaler would have made to
+// the resources of a workload when running in shadow mode, used to compare policies
+type PodScalerShadowRequest struct {
+	WorkloadName string
+	WorkloadType string
+	ResourceType string
+	// Requirement is either "request" or "limit"
+	Requirement string
+	// Direction is one of "added", "removed", "increased" or "decreased"
+	Direction        string
+	ConfiguredAmount string
+	DeterminedAmount string
+}
+
+// PodScalerShadowReport holds all changes pod-scaler would have made to the
+// resources of one Pod when running in shadow mode
+type PodScalerShadowReport struct {
+	Mutations []PodScalerShadowRequest
+}
+
 const (
 	StateSucceeded string = "succeeded"
 	StateFailed    string = "failed"
@@ -173,12 +194,42 @@ func (r *reporter) report(request Request) {
 
 type PodScalerReporter interface {
 	ReportResourceConfigurationWarning(workloadName, workloadType, configuredAmount, determinedAmount, resourceType string)
+	// ReportShadowMutations reports the changes to the resources of a Pod that pod-scaler
+	// would have made, were it not running in shadow mode. Reports are sent asynchronously,
+	// so that admission is never held up by the aggregation server, and are dropped when
+	// too many are waiting to be sent.
+	ReportShadowMutations(mutations []PodScalerShadowRequest)
 }
 
+const (
+	// podScalerReportTimeout bounds how long we wait for the aggregation server
+	podScalerReportTimeout = 10 * time.Second
+	// podScalerShadowReportQueue is the number of shadow reports waiting to be sent
+	podScalerShadowReportQueue = 1000
+)
+
 type podScalerReporter struct {
 	client             *http.Client
 	username, password string
 	address            string
+
+	shadowReports chan PodScalerShadowReport
+}
+
+func newPodScalerReporter(client *http.Client, username, password, address string) *podScalerReporter {
+	r := &podScalerReporter{
+		client:        client,
+		username:      username,
+		password:      password,
+		address:       address,
+		shadowReports: make(chan PodScalerShadowReport, podScalerShadowReportQueue),
+	}
+	go func() {
+		for report := range r.shadowReports {
+			r.report("pod-scaler/shadow", report)
+		}
+	}()
+	return r
 }
 
 func (o *Options) PodScalerReporter() (PodScalerReporter, error) {
@@ -187,12 +238,7 @@ func (o *Options) PodScalerReporter() (PodScalerReporter, error) {
 		return nil, fmt.Errorf("failed to get username and password: %w", err)
 	}
 
-	return &podScalerReporter{
-		client:   &http.Client{},
-		username: username,
-		password: password,
-		address:  o.address,
-	}, nil
+	return newPodScalerReporter(&http.Client{Timeout: podScalerReportTimeout}, username, password, o.address), nil
 }
 
 // ReportResourceConfigurationWarning is used to send the information about resource configuration
@@ -205,14 +251,30 @@ func (r *podScalerReporter) ReportResourceConfigurationWarning(workloadName, wor
 		DeterminedAmount: determinedAmount,
 		ResourceType:     resourceType,
 	}
+	r.report("pod-scaler", request)
+}
+
+// ReportShadowMutations is used to send the information about changes pod-scaler-admission
+// would have made to a Pod in shadow mode to result-aggregator.
+func (r *podScalerReporter) ReportShadowMutations(mutations []PodScalerShadowRequest) {
+	if len(mutations) == 0 {
+		return
+	}
+	select {
+	case r.shadowReports <- PodScalerShadowReport{Mutations: mutations}:
+	default:
+		logrus.Warn("Too many pod-scaler shadow reports are waiting to be sent, dropping report.")
+	}
+}
 
+func (r *podScalerReporter) report(path string, request interface{}) {
 	data, err := json.Marshal(request)
 	if err != nil {
 		logrus.Tracef("could not marshal pod-scaler request: %v", err)
 		return
 	}
 
-	httpRequest, err := http.NewRequest(http.MethodPost, fmt.Sprintf("%s/pod-scaler", r.address), bytes.NewReader(data))
+	httpRequest, err := http.NewRequest(http.MethodPost, fmt.Sprintf("%s/%s", r.address, path), bytes.NewReader(data))
 	if err != nil {
 		logrus.Tracef("could not create pod-scaler request: %v", err)
 		return
@@ -12,6 +12,7 @@ import (
 	"strings"
 	"syscall"
 	"testing"
+	"time"
 
 	"github.com/google/go-cmp/cmp"
 
@@ -273,6 +274,51 @@ func TestReportMemoryConfigurationWarning(t *testing.T) {
 	}
 }
 
+func TestReportShadowMutations(t *testing.T) {
+	expected := `{"Mutations":[{"WorkloadName":"name","WorkloadType":"step","ResourceType":"memory","Requirement":"request","Direction":"increased","ConfiguredAmount":"100","DeterminedAmount":"200"}]}`
+	called := make(chan struct{})
+	testServer := httptest.NewTLSServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		defer close(called)
+		if request.Method != http.MethodPost {
+			t.Errorf("incorrect method: %s", request.Method)
+			return
+		}
+		if !strings.HasSuffix(request.URL.Path, "/pod-scaler/shadow") {
+			t.Errorf("incorrect path: %s", request.URL.Path)
+			return
+		}
+		requestBody, err := io.ReadAll(request.Body)
+		if err != nil {
+			t.Errorf("failed to read request body: %v", err)
+		}
+		if diff := cmp.Diff(expected, string(requestBody)); diff != "" {
+			t.Errorf("actual and expected response don't match, diff: %v", diff)
+		}
+	}))
+	defer testServer.Close()
+
+	podScalerReporter := newPodScalerReporter(&http.Client{
+		Timeout: podScalerReportTimeout,
+		Transport: &http.Transport{
+			TLSClientConfig: &tls.Config{InsecureSkipVerify: true},
+		},
+	}, "", "", testServer.URL)
+	podScalerReporter.ReportShadowMutations([]PodScalerShadowRequest{{
+		WorkloadName:     "name",
+		WorkloadType:     "step",
+		ResourceType:     "memory",
+		Requirement:      "request",
+		Direction:        "increased",
+		ConfiguredAmount: "100",
+		DeterminedAmount: "200",
+	}})
+	select {
+	case <-called:
+	case <-time.After(podScalerReportTimeout):
+		t.Error("expected the shadow mutations to be reported")
+	}
+}
+
 func TestOptions_Validate(t *testing.T) {
 	testCases := []struct {
 		name     string
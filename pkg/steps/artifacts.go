@@ -56,8 +56,9 @@ const (
 //
 // TestCaseNotifier must be called from a single thread.
 type TestCaseNotifier struct {
-	nested  util.ContainerNotifier
-	lastPod *corev1.Pod
+	nested   util.ContainerNotifier
+	lastPod  *corev1.Pod
+	attempts []*junit.TestCase
 }
 
 // NewTestCaseNotifier wraps the provided ContainerNotifier and will
@@ -75,11 +76,29 @@ func (n *TestCaseNotifier) Notify(pod *coreapi.Pod, containerName string) {
 func (n *TestCaseNotifier) Complete(podName string)             { n.nested.Complete(podName) }
 func (n *TestCaseNotifier) Done(podName string) <-chan struct{} { return n.nested.Done(podName) }
 
-// SubTests returns one junit test for each terminated container with a name
-// in the annotation 'ci-operator.openshift.io/container-sub-tests' in the pod.
-// Invoking SubTests clears the last pod, so subsequent calls will return no
-// tests unless Notify() has been called in the meantime.
+// RecordAttempt records one attempt at running a pod that may be retried as
+// a junit test, which is returned by the next call to SubTests.
+func (n *TestCaseNotifier) RecordAttempt(name string, duration time.Duration, err error) {
+	test := &junit.TestCase{Name: name, Duration: duration.Seconds()}
+	if err != nil {
+		test.FailureOutput = &junit.FailureOutput{Output: err.Error()}
+	}
+	n.attempts = append(n.attempts, test)
+}
+
+// SubTests returns the junit tests for the attempts recorded since the last
+// call and one junit test for each terminated container with a name in the
+// annotation 'ci-operator.openshift.io/container-sub-tests' in the pod.
+// Invoking SubTests clears the last pod and the attempts, so subsequent calls
+// will return no tests unless Notify() or RecordAttempt() have been called in
+// the meantime.
 func (n *TestCaseNotifier) SubTests(prefix string) []*junit.TestCase {
+	attempts := n.attempts
+	n.attempts = nil
+	return append(attempts, n.containerTests(prefix)...)
+}
+
+func (n *TestCaseNotifier) containerTests(prefix string) []*junit.TestCase {
 	if n.lastPod == nil {
 		return nil
 	}
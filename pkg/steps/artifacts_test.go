@@ -1,6 +1,7 @@
 package steps
 
 import (
+	"errors"
 	"os"
 	"reflect"
 	"testing"
@@ -487,6 +488,22 @@ func TestTestCaseNotifier_SubTests(t *testing.T) {
 	}
 }
 
+func TestTestCaseNotifier_RecordAttempt(t *testing.T) {
+	n := NewTestCaseNotifier(util.NopNotifier)
+	n.RecordAttempt("pod attempt 1", time.Minute, errors.New("evicted"))
+	n.RecordAttempt("pod attempt 2", 2*time.Minute, nil)
+	expected := []*junit.TestCase{
+		{Name: "pod attempt 1", Duration: 60, FailureOutput: &junit.FailureOutput{Output: "evicted"}},
+		{Name: "pod attempt 2", Duration: 120},
+	}
+	if diff := cmp.Diff(expected, n.SubTests("prefix ")); diff != "" {
+		t.Errorf("unexpected tests: %s", diff)
+	}
+	if tests := n.SubTests("prefix "); tests != nil {
+		t.Errorf("expected attempts to be cleared, got %v", tests)
+	}
+}
+
 func TestArtifactWorker(t *testing.T) {
 	tmp, err := os.MkdirTemp("", "")
 	if err != nil {
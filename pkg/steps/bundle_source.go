@@ -132,6 +132,8 @@ func (s *bundleSourceStep) Objects() []ctrlruntimeclient.Object {
 
 func (s *bundleSourceStep) Name() string { return s.config.TargetName() }
 
+func (s *bundleSourceStep) Kind() StepKind { return StepKindBuild }
+
 func (s *bundleSourceStep) Description() string {
 	return fmt.Sprintf("Build image %s from the repository", api.PipelineImageStreamTagReferenceBundleSource)
 }
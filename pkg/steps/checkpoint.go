@@ -0,0 +1,179 @@
+package steps
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"sort"
+
+	coreapi "k8s.io/api/core/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrlruntimeclient "sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/openshift/ci-tools/pkg/api"
+)
+
+const (
+	// CheckpointConfigMapName is the name of the ConfigMap in the test
+	// namespace that holds the step graph checkpoint.
+	CheckpointConfigMapName = "ci-operator-checkpoint"
+	// checkpointConfigMapKey is the key in the ConfigMap data that holds
+	// the serialized checkpoint.
+	checkpointConfigMapKey = "checkpoint.json"
+)
+
+// Checkpoint records the steps of a graph that have completed successfully,
+// so that an interrupted execution can be resumed without re-running them.
+type Checkpoint struct {
+	Completed []CompletedStep `json:"completed,omitempty"`
+}
+
+// CompletedStep describes a single step that finished successfully.
+type CompletedStep struct {
+	// Name is the name of the step.
+	Name string `json:"name"`
+	// Creates holds a description of the links the step created. It is
+	// used to detect steps that changed between executions.
+	Creates []string `json:"creates,omitempty"`
+	// Provides holds the parameters the step exposed when it finished.
+	Provides map[string]string `json:"provides,omitempty"`
+}
+
+// CheckpointStore persists a Checkpoint between executions.
+type CheckpointStore interface {
+	// Load returns the stored checkpoint, or nil if there is none.
+	Load(ctx context.Context) (*Checkpoint, error)
+	// Save replaces the stored checkpoint.
+	Save(ctx context.Context, checkpoint *Checkpoint) error
+}
+
+type configMapCheckpointStore struct {
+	client    ctrlruntimeclient.Client
+	namespace string
+}
+
+// NewConfigMapCheckpointStore returns a CheckpointStore that persists the
+// checkpoint in a ConfigMap in the given namespace.
+func NewConfigMapCheckpointStore(client ctrlruntimeclient.Client, namespace string) CheckpointStore {
+	return &configMapCheckpointStore{client: client, namespace: namespace}
+}
+
+func (s *configMapCheckpointStore) Load(ctx context.Context) (*Checkpoint, error) {
+	cm := &coreapi.ConfigMap{}
+	if err := s.client.Get(ctx, ctrlruntimeclient.ObjectKey{Namespace: s.namespace, Name: CheckpointConfigMapName}, cm); err != nil {
+		if kerrors.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("could not get checkpoint configmap: %w", err)
+	}
+	raw, ok := cm.Data[checkpointConfigMapKey]
+	if !ok {
+		return nil, nil
+	}
+	var checkpoint Checkpoint
+	if err := json.Unmarshal([]byte(raw), &checkpoint); err != nil {
+		return nil, fmt.Errorf("could not unmarshal checkpoint: %w", err)
+	}
+	return &checkpoint, nil
+}
+
+func (s *configMapCheckpointStore) Save(ctx context.Context, checkpoint *Checkpoint) error {
+	raw, err := json.Marshal(checkpoint)
+	if err != nil {
+		return fmt.Errorf("could not marshal checkpoint: %w", err)
+	}
+	cm := &coreapi.ConfigMap{
+		ObjectMeta: meta.ObjectMeta{
+			Name:      CheckpointConfigMapName,
+			Namespace: s.namespace,
+		},
+		Data: map[string]string{checkpointConfigMapKey: string(raw)},
+	}
+	if err := s.client.Create(ctx, cm); err != nil {
+		if !kerrors.IsAlreadyExists(err) {
+			return fmt.Errorf("could not create checkpoint configmap: %w", err)
+		}
+		if err := s.client.Update(ctx, cm); err != nil {
+			return fmt.Errorf("could not update checkpoint configmap: %w", err)
+		}
+	}
+	return nil
+}
+
+// completedStepFor records the links and parameters of a finished step.
+// Parameters that cannot be resolved are omitted.
+func completedStepFor(step api.Step) CompletedStep {
+	completed := CompletedStep{Name: step.Name(), Creates: describeLinks(step.Creates())}
+	for name, fn := range step.Provides() {
+		value, err := fn()
+		if err != nil {
+			continue
+		}
+		if completed.Provides == nil {
+			completed.Provides = map[string]string{}
+		}
+		completed.Provides[name] = value
+	}
+	return completed
+}
+
+func describeLinks(links []api.StepLink) []string {
+	var ret []string
+	for _, link := range links {
+		ret = append(ret, fmt.Sprintf("%#v", link))
+	}
+	sort.Strings(ret)
+	return ret
+}
+
+// resumableNodes determines which nodes of the graph were completed by a
+// previous execution recorded in the checkpoint, and which nodes form the
+// frontier that must be scheduled first. A node is only considered completed
+// if all of its ancestors are and it still creates the same links as when it
+// was recorded.
+func resumableNodes(graph api.StepGraph, checkpoint *Checkpoint) (completed []*api.StepNode, frontier []*api.StepNode) {
+	recorded := map[string]CompletedStep{}
+	if checkpoint != nil {
+		for _, step := range checkpoint.Completed {
+			recorded[step.Name] = step
+		}
+	}
+	// IterateAllEdges visits children before their parents, so the
+	// reverse of the visiting order is a topological order.
+	var order []*api.StepNode
+	graph.IterateAllEdges(func(node *api.StepNode) {
+		order = append(order, node)
+	})
+	parents := parentsOf(graph)
+	done := map[*api.StepNode]bool{}
+	for i := len(order) - 1; i >= 0; i-- {
+		node := order[i]
+		ancestorsDone := true
+		for _, parent := range parents[node] {
+			ancestorsDone = ancestorsDone && done[parent]
+		}
+		if !ancestorsDone {
+			continue
+		}
+		if step, ok := recorded[node.Step.Name()]; ok && slices.Equal(step.Creates, describeLinks(node.Step.Creates())) {
+			done[node] = true
+			completed = append(completed, node)
+			continue
+		}
+		frontier = append(frontier, node)
+	}
+	return completed, frontier
+}
+
+// parentsOf maps every node in the graph to the nodes it depends on.
+func parentsOf(graph api.StepGraph) map[*api.StepNode][]*api.StepNode {
+	parents := map[*api.StepNode][]*api.StepNode{}
+	graph.IterateAllEdges(func(node *api.StepNode) {
+		for _, child := range node.Children {
+			parents[child] = append(parents[child], node)
+		}
+	})
+	return parents
+}
@@ -0,0 +1,146 @@
+package steps
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	fakectrlruntimeclient "sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/openshift/ci-tools/pkg/api"
+)
+
+func TestConfigMapCheckpointStore(t *testing.T) {
+	ctx := context.Background()
+	store := NewConfigMapCheckpointStore(fakectrlruntimeclient.NewClientBuilder().Build(), "ns")
+	loaded, err := store.Load(ctx)
+	if err != nil {
+		t.Fatalf("unexpected error loading missing checkpoint: %v", err)
+	}
+	if loaded != nil {
+		t.Fatalf("expected no checkpoint, got %v", loaded)
+	}
+	for _, checkpoint := range []*Checkpoint{
+		{Completed: []CompletedStep{{Name: "src", Creates: []string{"a"}}}},
+		{Completed: []CompletedStep{{Name: "src", Creates: []string{"a"}}, {Name: "bin", Provides: map[string]string{"IMAGE": "pullspec"}}}},
+	} {
+		if err := store.Save(ctx, checkpoint); err != nil {
+			t.Fatalf("unexpected error saving checkpoint: %v", err)
+		}
+		loaded, err := store.Load(ctx)
+		if err != nil {
+			t.Fatalf("unexpected error loading checkpoint: %v", err)
+		}
+		if diff := cmp.Diff(checkpoint, loaded); diff != "" {
+			t.Errorf("loaded checkpoint differs from saved one: %s", diff)
+		}
+	}
+}
+
+func TestResumableNodes(t *testing.T) {
+	root := &fakeStep{name: "root", creates: []api.StepLink{api.InternalImageLink(api.PipelineImageStreamTagReferenceRoot)}}
+	src := &fakeStep{name: "src", requires: root.creates, creates: []api.StepLink{api.InternalImageLink(api.PipelineImageStreamTagReferenceSource)}}
+	bin := &fakeStep{name: "bin", requires: src.creates, creates: []api.StepLink{api.InternalImageLink(api.PipelineImageStreamTagReferenceBinaries)}}
+	other := &fakeStep{name: "other", creates: []api.StepLink{api.InternalImageLink("other")}}
+	final := &fakeStep{name: "final", requires: append(append([]api.StepLink{}, bin.creates...), other.creates...)}
+	graph := api.BuildGraph([]api.Step{root, src, bin, other, final})
+	completedStep := func(step api.Step) CompletedStep {
+		return CompletedStep{Name: step.Name(), Creates: describeLinks(step.Creates())}
+	}
+
+	var testCases = []struct {
+		name              string
+		checkpoint        *Checkpoint
+		expectedCompleted []string
+		expectedFrontier  []string
+	}{
+		{
+			name:             "no checkpoint runs the roots",
+			expectedFrontier: []string{"other", "root"},
+		},
+		{
+			name:              "partially completed chain",
+			checkpoint:        &Checkpoint{Completed: []CompletedStep{completedStep(root), completedStep(src)}},
+			expectedCompleted: []string{"root", "src"},
+			expectedFrontier:  []string{"bin", "other"},
+		},
+		{
+			name:              "step waiting for an unfinished parent is not scheduled",
+			checkpoint:        &Checkpoint{Completed: []CompletedStep{completedStep(root), completedStep(src), completedStep(bin)}},
+			expectedCompleted: []string{"bin", "root", "src"},
+			expectedFrontier:  []string{"other"},
+		},
+		{
+			name:              "step that changed its links is run again, with its children",
+			checkpoint:        &Checkpoint{Completed: []CompletedStep{completedStep(root), {Name: "src", Creates: []string{"something else"}}, completedStep(bin)}},
+			expectedCompleted: []string{"root"},
+			expectedFrontier:  []string{"other", "src"},
+		},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			completed, frontier := resumableNodes(graph, testCase.checkpoint)
+			if diff := cmp.Diff(testCase.expectedCompleted, sortedNames(completed)); diff != "" {
+				t.Errorf("incorrect completed nodes: %s", diff)
+			}
+			if diff := cmp.Diff(testCase.expectedFrontier, sortedNames(frontier)); diff != "" {
+				t.Errorf("incorrect frontier: %s", diff)
+			}
+		})
+	}
+}
+
+func TestRunResume(t *testing.T) {
+	root := &fakeStep{name: "root", creates: []api.StepLink{api.InternalImageLink(api.PipelineImageStreamTagReferenceRoot)}}
+	src := &fakeStep{name: "src", requires: root.creates, creates: []api.StepLink{api.InternalImageLink(api.PipelineImageStreamTagReferenceSource)}}
+	bin := &fakeStep{name: "bin", requires: src.creates, creates: []api.StepLink{api.InternalImageLink(api.PipelineImageStreamTagReferenceBinaries)}}
+	graph := api.BuildGraph([]api.Step{root, src, bin})
+
+	ctx := context.Background()
+	store := NewConfigMapCheckpointStore(fakectrlruntimeclient.NewClientBuilder().Build(), "ns")
+	recorded := completedStepFor(root)
+	recorded.Provides = map[string]string{"IMAGE": "pullspec"}
+	if err := store.Save(ctx, &Checkpoint{Completed: []CompletedStep{recorded}}); err != nil {
+		t.Fatalf("failed to save checkpoint: %v", err)
+	}
+	params := api.NewDeferredParameters(nil)
+	params.Add("IMAGE", func() (string, error) { return "", errors.New("root has not run") })
+	suites, _, errs := Run(ctx, graph, WithCheckpoint(store, params))
+	if len(errs) != 0 {
+		t.Fatalf("unexpected errors: %v", errs)
+	}
+	for step, expected := range map[*fakeStep]int{root: 0, src: 1, bin: 1} {
+		if step.numRuns != expected {
+			t.Errorf("expected step %s to run %d times, ran %d times", step.name, expected, step.numRuns)
+		}
+	}
+	if suite := suites.Suites[0]; suite.NumTests != 3 || suite.NumSkipped != 1 {
+		t.Errorf("unexpected junit output: %#v", suite)
+	}
+	if value, err := params.Get("IMAGE"); err != nil || value != "pullspec" {
+		t.Errorf("expected the parameter provided by the skipped step to be restored, got %q: %v", value, err)
+	}
+	checkpoint, err := store.Load(ctx)
+	if err != nil {
+		t.Fatalf("failed to load checkpoint: %v", err)
+	}
+	var names []string
+	for _, step := range checkpoint.Completed {
+		names = append(names, step.Name)
+	}
+	if diff := cmp.Diff([]string{"root", "src", "bin"}, names); diff != "" {
+		t.Errorf("incorrect checkpoint after execution: %s", diff)
+	}
+}
+
+func sortedNames(nodes []*api.StepNode) []string {
+	var names []string
+	for _, node := range nodes {
+		names = append(names, node.Step.Name())
+	}
+	sort.Strings(names)
+	return names
+}
@@ -61,6 +61,7 @@ func (s *clusterClaimStep) Requires() []api.StepLink            { return s.wrapp
 func (s *clusterClaimStep) Creates() []api.StepLink             { return s.wrapped.Creates() }
 func (s *clusterClaimStep) Objects() []ctrlruntimeclient.Object { return s.wrapped.Objects() }
 func (s *clusterClaimStep) Provides() api.ParameterMap          { return s.wrapped.Provides() }
+func (s *clusterClaimStep) Kind() StepKind                      { return kindOf(s.wrapped) }
 
 func (s *clusterClaimStep) Run(ctx context.Context) error {
 	return results.ForReason("utilizing_cluster_claim").ForError(s.run(ctx))
@@ -0,0 +1,252 @@
+package steps
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	coreapi "k8s.io/api/core/v1"
+	rbacapi "k8s.io/api/rbac/v1"
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrlruntimeclient "sigs.k8s.io/controller-runtime/pkg/client"
+
+	imagev1 "github.com/openshift/api/image/v1"
+
+	"github.com/openshift/ci-tools/pkg/api"
+	"github.com/openshift/ci-tools/pkg/kubernetes"
+	"github.com/openshift/ci-tools/pkg/results"
+	"github.com/openshift/ci-tools/pkg/steps/utils"
+	"github.com/openshift/ci-tools/pkg/util"
+)
+
+const (
+	// ExternalStepRequestEnv holds the JSON request for an external step.
+	ExternalStepRequestEnv = "CI_EXTERNAL_STEP_REQUEST"
+	// externalStepContainer is the name of the container running the step
+	externalStepContainer = "step"
+	// externalStepLabel identifies the Pods running external steps
+	externalStepLabel = "ci.openshift.io/external-step"
+)
+
+// externalStep runs a step implemented by an image speaking the external
+// step protocol: the image gets the values of its parameters in a JSON
+// request and returns the values of the parameters it provides in a JSON
+// response in its termination message. The links of the step are declared
+// in its configuration, as the graph is built before any Pod can run; the
+// response and the pipeline image stream are checked against them.
+type externalStep struct {
+	config    api.ExternalStepConfiguration
+	params    api.Parameters
+	client    kubernetes.PodClient
+	jobSpec   *api.JobSpec
+	lock      sync.Mutex
+	responded *api.ExternalStepResponse
+}
+
+// ExternalStep creates a step that runs the external step implementation.
+func ExternalStep(config api.ExternalStepConfiguration, params api.Parameters, client kubernetes.PodClient, jobSpec *api.JobSpec) api.Step {
+	return &externalStep{
+		config:  config,
+		params:  params,
+		client:  client,
+		jobSpec: jobSpec,
+	}
+}
+
+func (s *externalStep) Inputs() (api.InputDefinition, error) {
+	inputs := api.InputDefinition{s.config.Image}
+	for key, value := range s.config.Config {
+		inputs = append(inputs, fmt.Sprintf("%s=%s", key, value))
+	}
+	sort.Strings(inputs[1:])
+	return inputs, nil
+}
+
+func (*externalStep) Validate() error { return nil }
+
+func (s *externalStep) Run(ctx context.Context) error {
+	return results.ForReason("running_external_step").ForError(s.run(ctx))
+}
+
+func (s *externalStep) run(ctx context.Context) error {
+	request := api.ExternalStepRequest{
+		Name:      s.config.As,
+		Namespace: s.jobSpec.Namespace(),
+		Config:    s.config.Config,
+	}
+	for _, name := range s.config.Parameters {
+		value, err := s.params.Get(name)
+		if err != nil {
+			return fmt.Errorf("could not resolve parameter %s: %w", name, err)
+		}
+		if request.Parameters == nil {
+			request.Parameters = map[string]string{}
+		}
+		request.Parameters[name] = value
+	}
+	raw, err := json.Marshal(request)
+	if err != nil {
+		return fmt.Errorf("could not marshal request: %w", err)
+	}
+	resources, err := ResourcesFor(s.config.Resources)
+	if err != nil {
+		return fmt.Errorf("invalid resources for step %s: %w", s.config.As, err)
+	}
+
+	if err := s.setupRBAC(ctx); err != nil {
+		return fmt.Errorf("could not set up RBAC for external step %s: %w", s.config.As, err)
+	}
+
+	logrus.Infof("Running external step %s with %s", s.config.As, s.config.Image)
+	pod, err := RunPod(ctx, s.client, &coreapi.Pod{
+		ObjectMeta: meta.ObjectMeta{
+			Name:      s.config.As,
+			Namespace: s.jobSpec.Namespace(),
+			Labels:    LabelsFor(s.jobSpec, map[string]string{externalStepLabel: s.config.As}, ""),
+		},
+		Spec: coreapi.PodSpec{
+			RestartPolicy:      coreapi.RestartPolicyNever,
+			ServiceAccountName: s.config.ServiceAccountName(),
+			Containers: []coreapi.Container{{
+				Name:      externalStepContainer,
+				Image:     s.config.Image,
+				Args:      []string{"run"},
+				Env:       []coreapi.EnvVar{{Name: ExternalStepRequestEnv, Value: string(raw)}},
+				Resources: resources,
+			}},
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("external step %s failed: %w", s.config.As, err)
+	}
+	response, err := responseFrom(pod)
+	if err != nil {
+		return fmt.Errorf("external step %s did not respond correctly: %w", s.config.As, err)
+	}
+	for _, name := range s.config.Provides {
+		if _, ok := response.Provides[name]; !ok {
+			return fmt.Errorf("external step %s did not provide parameter %s", s.config.As, name)
+		}
+	}
+	for _, tag := range s.config.Creates {
+		if err := s.client.Get(ctx, ctrlruntimeclient.ObjectKey{Namespace: s.jobSpec.Namespace(), Name: fmt.Sprintf("%s:%s", api.PipelineImageStream, tag)}, &imagev1.ImageStreamTag{}); err != nil {
+			return fmt.Errorf("external step %s did not create %s:%s: %w", s.config.As, api.PipelineImageStream, tag, err)
+		}
+	}
+	s.lock.Lock()
+	s.responded = response
+	s.lock.Unlock()
+	return nil
+}
+
+// setupRBAC creates the service account the step runs as. It may read the
+// pipeline image stream and, if the step creates images, tag them into it.
+func (s *externalStep) setupRBAC(ctx context.Context) error {
+	name := s.config.ServiceAccountName()
+	m := meta.ObjectMeta{
+		Namespace: s.jobSpec.Namespace(),
+		Name:      name,
+		Labels:    map[string]string{externalStepLabel: s.config.As},
+	}
+	sa := &coreapi.ServiceAccount{
+		ObjectMeta:       m,
+		ImagePullSecrets: []coreapi.LocalObjectReference{{Name: api.RegistryPullCredentialsSecret}},
+	}
+	role := &rbacapi.Role{
+		ObjectMeta: m,
+		Rules: []rbacapi.PolicyRule{{
+			APIGroups:     []string{"", "image.openshift.io"},
+			Resources:     []string{"imagestreams", "imagestreams/layers"},
+			ResourceNames: []string{api.PipelineImageStream},
+			Verbs:         []string{"get"},
+		}},
+	}
+	if len(s.config.Creates) > 0 {
+		role.Rules = append(role.Rules, rbacapi.PolicyRule{
+			APIGroups:     []string{"", "image.openshift.io"},
+			Resources:     []string{"imagestreams", "imagestreams/layers"},
+			ResourceNames: []string{api.PipelineImageStream},
+			Verbs:         []string{"update", "patch"},
+		}, rbacapi.PolicyRule{
+			APIGroups: []string{"", "image.openshift.io"},
+			Resources: []string{"imagestreamtags"},
+			Verbs:     []string{"get", "create", "update", "patch"},
+		})
+	}
+	bindings := []rbacapi.RoleBinding{{
+		ObjectMeta: m,
+		RoleRef:    rbacapi.RoleRef{Kind: "Role", Name: name},
+		Subjects:   []rbacapi.Subject{{Kind: "ServiceAccount", Name: name}},
+	}}
+	return util.CreateRBACs(ctx, sa, role, bindings, s.client, 1*time.Second, 1*time.Minute)
+}
+
+// responseFrom parses the response of the step from the termination
+// message of its container.
+func responseFrom(pod *coreapi.Pod) (*api.ExternalStepResponse, error) {
+	for _, status := range pod.Status.ContainerStatuses {
+		if status.Name != externalStepContainer {
+			continue
+		}
+		if status.State.Terminated == nil {
+			return nil, errors.New("container did not terminate")
+		}
+		response := &api.ExternalStepResponse{}
+		if message := status.State.Terminated.Message; message != "" {
+			if err := json.Unmarshal([]byte(message), response); err != nil {
+				return nil, fmt.Errorf("could not parse termination message: %w", err)
+			}
+		}
+		return response, nil
+	}
+	return nil, errors.New("container status is missing")
+}
+
+func (s *externalStep) Requires() []api.StepLink {
+	var links []api.StepLink
+	for _, name := range s.config.Parameters {
+		if link, ok := utils.LinkForEnv(name); ok {
+			links = append(links, link)
+		}
+	}
+	return links
+}
+
+func (s *externalStep) Creates() []api.StepLink {
+	var links []api.StepLink
+	for _, tag := range s.config.Creates {
+		links = append(links, api.InternalImageLink(tag))
+	}
+	return links
+}
+
+func (s *externalStep) Provides() api.ParameterMap {
+	provides := api.ParameterMap{}
+	for _, name := range s.config.Provides {
+		provides[name] = func() (string, error) {
+			s.lock.Lock()
+			defer s.lock.Unlock()
+			if s.responded == nil {
+				return "", fmt.Errorf("external step %s has not run", s.config.As)
+			}
+			return s.responded.Provides[name], nil
+		}
+	}
+	return provides
+}
+
+func (s *externalStep) Name() string { return s.config.TargetName() }
+
+func (s *externalStep) Description() string {
+	return fmt.Sprintf("Run external step %s implemented by %s", s.config.As, s.config.Image)
+}
+
+func (s *externalStep) Objects() []ctrlruntimeclient.Object {
+	return s.client.Objects()
+}
@@ -0,0 +1,203 @@
+package steps
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+
+	coreapi "k8s.io/api/core/v1"
+	rbacapi "k8s.io/api/rbac/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrlruntimeclient "sigs.k8s.io/controller-runtime/pkg/client"
+	fakectrlruntimeclient "sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	imagev1 "github.com/openshift/api/image/v1"
+
+	"github.com/openshift/ci-tools/pkg/api"
+	"github.com/openshift/ci-tools/pkg/steps/loggingclient"
+	testhelper_kube "github.com/openshift/ci-tools/pkg/testhelper/kubernetes"
+)
+
+func TestExternalStepLinks(t *testing.T) {
+	step := ExternalStep(api.ExternalStepConfiguration{
+		As:         "custom",
+		Image:      "quay.io/org/custom:latest",
+		Parameters: []string{"RELEASE_IMAGE_LATEST", "IMAGE_FORMAT", "SOMETHING_ELSE"},
+		Creates:    []api.PipelineImageStreamTagReference{"custom-image"},
+		Provides:   []string{"CUSTOM_VALUE"},
+		Config:     map[string]string{"b": "2", "a": "1"},
+	}, api.NewDeferredParameters(nil), nil, &api.JobSpec{})
+	examineStep(t, step, stepExpectation{
+		name:     "custom",
+		requires: []api.StepLink{api.ReleasePayloadImageLink(api.LatestReleaseName), api.ImagesReadyLink()},
+		creates:  []api.StepLink{api.InternalImageLink("custom-image")},
+		inputs:   inputsExpectation{values: api.InputDefinition{"quay.io/org/custom:latest", "a=1", "b=2"}},
+	})
+	getter, ok := step.Provides()["CUSTOM_VALUE"]
+	if !ok {
+		t.Fatalf("expected step to provide CUSTOM_VALUE")
+	}
+	if _, err := getter(); err == nil {
+		t.Errorf("expected an error reading a parameter before the step ran")
+	}
+}
+
+func TestExternalStepRun(t *testing.T) {
+	jobSpec := &api.JobSpec{}
+	jobSpec.SetNamespace("namespace")
+	imageRules := []rbacapi.PolicyRule{{
+		APIGroups:     []string{"", "image.openshift.io"},
+		Resources:     []string{"imagestreams", "imagestreams/layers"},
+		ResourceNames: []string{"pipeline"},
+		Verbs:         []string{"get"},
+	}}
+	for _, tc := range []struct {
+		name          string
+		config        api.ExternalStepConfiguration
+		objects       []ctrlruntimeclient.Object
+		expectedErr   string
+		expectedRules []rbacapi.PolicyRule
+	}{{
+		name:          "step runs",
+		config:        api.ExternalStepConfiguration{As: "custom", Image: "custom:latest", Parameters: []string{"VALUE"}},
+		expectedRules: imageRules,
+	}, {
+		name:        "step does not provide declared parameter",
+		config:      api.ExternalStepConfiguration{As: "custom", Image: "custom:latest", Provides: []string{"CUSTOM_VALUE"}},
+		expectedErr: "external step custom did not provide parameter CUSTOM_VALUE",
+	}, {
+		name:   "step creates declared image",
+		config: api.ExternalStepConfiguration{As: "custom", Image: "custom:latest", Creates: []api.PipelineImageStreamTagReference{"custom-image"}},
+		objects: []ctrlruntimeclient.Object{&imagev1.ImageStreamTag{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "namespace", Name: "pipeline:custom-image"},
+		}},
+		expectedRules: append(imageRules, rbacapi.PolicyRule{
+			APIGroups:     []string{"", "image.openshift.io"},
+			Resources:     []string{"imagestreams", "imagestreams/layers"},
+			ResourceNames: []string{"pipeline"},
+			Verbs:         []string{"update", "patch"},
+		}, rbacapi.PolicyRule{
+			APIGroups: []string{"", "image.openshift.io"},
+			Resources: []string{"imagestreamtags"},
+			Verbs:     []string{"get", "create", "update", "patch"},
+		}),
+	}, {
+		name:        "step does not create declared image",
+		config:      api.ExternalStepConfiguration{As: "custom", Image: "custom:latest", Creates: []api.PipelineImageStreamTagReference{"custom-image"}},
+		expectedErr: `external step custom did not create pipeline:custom-image: imagestreamtags.image.openshift.io "pipeline:custom-image" not found`,
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			podClient := &testhelper_kube.FakePodClient{
+				FakePodExecutor: &testhelper_kube.FakePodExecutor{
+					LoggingClient: loggingclient.New(fakectrlruntimeclient.NewClientBuilder().
+						WithIndex(&coreapi.Pod{}, "metadata.name", func(o ctrlruntimeclient.Object) []string { return []string{o.GetName()} }).
+						WithObjects(append(tc.objects, &coreapi.ServiceAccount{ObjectMeta: metav1.ObjectMeta{Namespace: "namespace", Name: "external-custom"}})...).
+						Build()),
+				},
+				PendingTimeout: time.Hour,
+			}
+			params := api.NewDeferredParameters(nil)
+			params.Add("VALUE", func() (string, error) { return "value", nil })
+			err := ExternalStep(tc.config, params, podClient, jobSpec).Run(context.Background())
+			var actualErr string
+			if err != nil {
+				actualErr = err.Error()
+			}
+			if diff := cmp.Diff(tc.expectedErr, actualErr); diff != "" {
+				t.Fatalf("incorrect error: %s", diff)
+			}
+			if len(podClient.CreatedPods) != 1 {
+				t.Fatalf("expected one pod to be created, got %d", len(podClient.CreatedPods))
+			}
+			if serviceAccount := podClient.CreatedPods[0].Spec.ServiceAccountName; serviceAccount != "external-custom" {
+				t.Errorf("expected pod to run as the step service account, got %q", serviceAccount)
+			}
+			if tc.expectedRules != nil {
+				role := &rbacapi.Role{}
+				if err := podClient.Get(context.Background(), ctrlruntimeclient.ObjectKey{Namespace: "namespace", Name: "external-custom"}, role); err != nil {
+					t.Fatalf("failed to get role: %v", err)
+				}
+				if diff := cmp.Diff(tc.expectedRules, role.Rules); diff != "" {
+					t.Errorf("incorrect role rules: %s", diff)
+				}
+			}
+			container := podClient.CreatedPods[0].Spec.Containers[0]
+			if diff := cmp.Diff([]string{"run"}, container.Args); diff != "" {
+				t.Errorf("incorrect arguments: %s", diff)
+			}
+			if tc.config.Parameters != nil {
+				expected := []coreapi.EnvVar{{Name: ExternalStepRequestEnv, Value: `{"name":"custom","namespace":"namespace","parameters":{"VALUE":"value"}}`}}
+				if diff := cmp.Diff(expected, container.Env); diff != "" {
+					t.Errorf("incorrect request: %s", diff)
+				}
+			}
+		})
+	}
+}
+
+func TestExternalStepResumed(t *testing.T) {
+	jobSpec := &api.JobSpec{}
+	jobSpec.SetNamespace("namespace")
+	params := api.NewDeferredParameters(nil)
+	podClient := &testhelper_kube.FakePodClient{
+		FakePodExecutor: &testhelper_kube.FakePodExecutor{LoggingClient: loggingclient.New(fakectrlruntimeclient.NewClientBuilder().Build())},
+	}
+	step := ExternalStep(api.ExternalStepConfiguration{As: "custom", Image: "custom:latest", Provides: []string{"CUSTOM_VALUE"}}, params, podClient, jobSpec)
+	for name, fn := range step.Provides() {
+		params.Add(name, fn)
+	}
+	ctx := context.Background()
+	store := NewConfigMapCheckpointStore(fakectrlruntimeclient.NewClientBuilder().Build(), "namespace")
+	if err := store.Save(ctx, &Checkpoint{Completed: []CompletedStep{{Name: "custom", Provides: map[string]string{"CUSTOM_VALUE": "value"}}}}); err != nil {
+		t.Fatalf("failed to save checkpoint: %v", err)
+	}
+	if _, _, errs := Run(ctx, api.BuildGraph([]api.Step{step}), WithCheckpoint(store, params)); len(errs) != 0 {
+		t.Fatalf("unexpected errors: %v", errs)
+	}
+	if len(podClient.CreatedPods) != 0 {
+		t.Errorf("expected the completed step not to run, created %d pods", len(podClient.CreatedPods))
+	}
+	value, err := params.Get("CUSTOM_VALUE")
+	if err != nil {
+		t.Fatalf("failed to get parameter provided by the skipped step: %v", err)
+	}
+	if value != "value" {
+		t.Errorf("expected the recorded value of the parameter, got %q", value)
+	}
+}
+
+func TestResponseFrom(t *testing.T) {
+	for _, tc := range []struct {
+		name        string
+		message     string
+		expected    *api.ExternalStepResponse
+		expectedErr bool
+	}{{
+		name:     "empty message",
+		expected: &api.ExternalStepResponse{},
+	}, {
+		name:     "provided parameters",
+		message:  `{"provides":{"CUSTOM_VALUE":"value"}}`,
+		expected: &api.ExternalStepResponse{Provides: map[string]string{"CUSTOM_VALUE": "value"}},
+	}, {
+		name:        "invalid message",
+		message:     "not json",
+		expectedErr: true,
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			pod := &coreapi.Pod{Status: coreapi.PodStatus{ContainerStatuses: []coreapi.ContainerStatus{{
+				Name:  externalStepContainer,
+				State: coreapi.ContainerState{Terminated: &coreapi.ContainerStateTerminated{Message: tc.message}},
+			}}}}
+			response, err := responseFrom(pod)
+			if (err != nil) != tc.expectedErr {
+				t.Fatalf("expected error %t, got %v", tc.expectedErr, err)
+			}
+			if diff := cmp.Diff(tc.expected, response); diff != "" {
+				t.Errorf("incorrect response: %s", diff)
+			}
+		})
+	}
+}
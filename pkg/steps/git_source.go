@@ -73,6 +73,8 @@ func (s *gitSourceStep) Name() string {
 	return root
 }
 
+func (s *gitSourceStep) Kind() StepKind { return StepKindBuild }
+
 func (s *gitSourceStep) Description() string {
 	return fmt.Sprintf("Build git source code into an image and tag it as %s", api.PipelineImageStreamTagReferenceRoot)
 }
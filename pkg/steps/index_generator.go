@@ -190,6 +190,8 @@ func (s *indexGeneratorStep) Provides() api.ParameterMap {
 
 func (s *indexGeneratorStep) Name() string { return s.config.TargetName() }
 
+func (s *indexGeneratorStep) Kind() StepKind { return StepKindBuild }
+
 func (s *indexGeneratorStep) Description() string {
 	return fmt.Sprintf("Build image %s from the repository", s.config.To)
 }
@@ -28,6 +28,13 @@ func InputEnvironmentStep(name string, values map[string]string, links []api.Ste
 
 var _ api.Step = &inputEnvironmentStep{}
 
+// IsInputEnvironmentStep determines if the step was stubbed out because its
+// outputs are provided by the environment, so that it does no work.
+func IsInputEnvironmentStep(step api.Step) bool {
+	_, ok := step.(*inputEnvironmentStep)
+	return ok
+}
+
 func (s *inputEnvironmentStep) Inputs() (api.InputDefinition, error) {
 	var values []string
 	for _, v := range s.values {
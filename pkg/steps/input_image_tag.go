@@ -178,6 +178,8 @@ func (s *inputImageTagStep) Provides() api.ParameterMap {
 
 func (s *inputImageTagStep) Name() string { return s.config.TargetName() }
 
+func (s *inputImageTagStep) Kind() StepKind { return StepKindImport }
+
 func (s *inputImageTagStep) Description() string {
 	return fmt.Sprintf("Find the input image %s and tag it into the pipeline", s.config.To)
 }
@@ -8,6 +8,8 @@ import (
 	"strings"
 
 	"github.com/sirupsen/logrus"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 
 	utilerrors "k8s.io/apimachinery/pkg/util/errors"
 	ctrlruntimeclient "sigs.k8s.io/controller-runtime/pkg/client"
@@ -16,6 +18,7 @@ import (
 	"github.com/openshift/ci-tools/pkg/junit"
 	"github.com/openshift/ci-tools/pkg/lease"
 	"github.com/openshift/ci-tools/pkg/results"
+	"github.com/openshift/ci-tools/pkg/tracing"
 )
 
 var NoLeaseClientErr = errors.New("step needs a lease but no lease client provided")
@@ -64,6 +67,8 @@ func (s *leaseStep) Requires() []api.StepLink            { return s.wrapped.Requ
 func (s *leaseStep) Creates() []api.StepLink             { return s.wrapped.Creates() }
 func (s *leaseStep) Objects() []ctrlruntimeclient.Object { return s.wrapped.Objects() }
 
+func (s *leaseStep) Kind() StepKind { return kindOf(s.wrapped) }
+
 func (s *leaseStep) Provides() api.ParameterMap {
 	parameters := s.wrapped.Provides()
 	if parameters == nil {
@@ -152,7 +157,9 @@ func acquireLeases(
 	for _, i := range sorted {
 		l := &leases[i]
 		logrus.Debugf("Acquiring %d lease(s) for %s", l.Count, l.ResourceType)
+		_, span := tracing.Start(ctx, fmt.Sprintf("acquire lease %s", l.ResourceType), trace.WithAttributes(attribute.Int("ci.lease.count", int(l.Count))))
 		names, err := client.Acquire(l.ResourceType, l.Count, ctx, cancel)
+		tracing.End(span, err)
 		if err != nil {
 			if err == lease.ErrNotFound {
 				printResourceMetrics(client, l.ResourceType)
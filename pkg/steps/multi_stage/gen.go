@@ -183,6 +183,17 @@ func (s *multiStageTestStep) generatePods(
 		}
 
 		addSecretWrapper(pod, s.vpnConf, !needsKubeConfig, genPodOpts)
+		if s.parallelGroups[step.As] != "" {
+			// members of a parallel group update their own copy of the
+			// shared directory, which is merged once all of them finish.
+			// The wrapper is copied from ci/entrypoint-wrapper:latest, which
+			// is promoted separately from ci-operator, so an entrypoint-wrapper
+			// that knows --shared-dir-secret has to be promoted before a
+			// ci-operator that passes it and before the registry uses
+			// `parallel`: older wrappers fail on the unknown flag.
+			c := &pod.Spec.Containers[0]
+			c.Args = append([]string{"--shared-dir-secret", sharedDirSecretFor(name)}, c.Args...)
+		}
 		if s.vpnConf != nil {
 			s.addVPNClient(pod)
 		}
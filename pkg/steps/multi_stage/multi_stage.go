@@ -20,6 +20,7 @@ import (
 	"github.com/openshift/ci-tools/pkg/junit"
 	"github.com/openshift/ci-tools/pkg/kubernetes"
 	"github.com/openshift/ci-tools/pkg/results"
+	"github.com/openshift/ci-tools/pkg/steps"
 	"github.com/openshift/ci-tools/pkg/steps/loggingclient"
 	"github.com/openshift/ci-tools/pkg/steps/utils"
 )
@@ -90,16 +91,21 @@ type multiStageTestStep struct {
 	profile          api.ClusterProfile
 	config           *api.ReleaseBuildConfiguration
 	// params exposes getters for variables created by other steps
-	params                      api.Parameters
-	env                         api.TestEnvironment
-	client                      kubernetes.PodClient
-	jobSpec                     *api.JobSpec
-	observers                   []api.Observer
-	pre, test, post             []api.LiteralTestStep
-	subLock                     *sync.Mutex
-	subTests                    []*junit.TestCase
-	subSteps                    []api.CIOperatorStepDetailInfo
-	flags                       stepFlag
+	params          api.Parameters
+	env             api.TestEnvironment
+	client          kubernetes.PodClient
+	jobSpec         *api.JobSpec
+	observers       []api.Observer
+	pre, test, post []api.LiteralTestStep
+	// parallelGroups maps the steps of `parallel` groups to their group
+	parallelGroups map[string]string
+	subLock        *sync.Mutex
+	subTests       []*junit.TestCase
+	subSteps       []api.CIOperatorStepDetailInfo
+	flags          stepFlag
+	// stepResults holds the results of the steps that ran so far, used to
+	// evaluate `when` expressions
+	stepResults                 map[string]api.StepResult
 	leases                      []api.StepLease
 	clusterClaim                *api.ClusterClaim
 	vpnConf                     *vpnConf
@@ -157,6 +163,7 @@ func newMultiStageTestStep(
 		pre:                         ms.Pre,
 		test:                        ms.Test,
 		post:                        ms.Post,
+		parallelGroups:              ms.ParallelGroups,
 		flags:                       flags,
 		leases:                      leases,
 		clusterClaim:                testConfig.ClusterClaim,
@@ -254,6 +261,8 @@ func (s *multiStageTestStep) run(ctx context.Context) error {
 }
 
 func (s *multiStageTestStep) Name() string { return s.name }
+
+func (s *multiStageTestStep) Kind() steps.StepKind { return steps.StepKindTest }
 func (s *multiStageTestStep) Description() string {
 	return fmt.Sprintf("Run multi-stage test %s", s.name)
 }
@@ -0,0 +1,96 @@
+package multi_stage
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	coreapi "k8s.io/api/core/v1"
+	ctrlruntimeclient "sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/openshift/ci-tools/pkg/api"
+)
+
+// sharedDir retrieves the current contents of the shared directory, which is
+// where steps publish their outputs. Outputs therefore count towards the size
+// limit of the Secret that backs it.
+func (s *multiStageTestStep) sharedDir(ctx context.Context) (map[string][]byte, error) {
+	secret := &coreapi.Secret{}
+	if err := s.client.Get(ctx, ctrlruntimeclient.ObjectKey{Namespace: s.jobSpec.Namespace(), Name: s.name}, secret); err != nil {
+		return nil, fmt.Errorf("cannot read shared directory %q: %w", s.name, err)
+	}
+	return secret.Data, nil
+}
+
+// injectInputs exposes the outputs of earlier steps required by a step to the
+// pod running it. Outputs are only known once their producers have run, so
+// this happens right before the pod is created.
+func (s *multiStageTestStep) injectInputs(ctx context.Context, pod *coreapi.Pod, inputs []api.StepInput) error {
+	if len(inputs) == 0 {
+		return nil
+	}
+	data, err := s.sharedDir(ctx)
+	if err != nil {
+		return err
+	}
+	var env []coreapi.EnvVar
+	for _, input := range inputs {
+		value, ok := data[input.Name]
+		if !ok {
+			return fmt.Errorf("step %s requires output %q, which was not published", pod.Name, input.Name)
+		}
+		env = append(env, coreapi.EnvVar{Name: input.Env, Value: valueForInput(input, value)})
+	}
+	for i := range pod.Spec.Containers {
+		if c := &pod.Spec.Containers[i]; c.Name == containerName {
+			c.Env = append(c.Env, env...)
+		}
+	}
+	return nil
+}
+
+// valueForInput determines the value of the variable exposing an input:
+// strings are exposed directly and files by their name in ${SHARED_DIR}.
+// The type of inputs is set when the test is resolved.
+func valueForInput(input api.StepInput, value []byte) string {
+	if input.Type == api.StepOutputTypeFile {
+		return input.Name
+	}
+	return strings.TrimSuffix(string(value), "\n")
+}
+
+// checkOutputs verifies that a step published all of its declared outputs
+// with values of the declared types.
+func (s *multiStageTestStep) checkOutputs(ctx context.Context, podName string, outputs []api.StepOutput) error {
+	if len(outputs) == 0 {
+		return nil
+	}
+	data, err := s.sharedDir(ctx)
+	if err != nil {
+		return err
+	}
+	var missing, invalid []string
+	for _, output := range outputs {
+		value, ok := data[output.Name]
+		switch {
+		case !ok:
+			missing = append(missing, output.Name)
+		case output.Type == api.StepOutputTypeString && !isStringOutput(value):
+			invalid = append(invalid, output.Name)
+		}
+	}
+	if len(missing) != 0 {
+		return fmt.Errorf("step %s did not publish outputs: %s", podName, strings.Join(missing, ", "))
+	}
+	if len(invalid) != 0 {
+		return fmt.Errorf("step %s published outputs that are not a single line of text: %s", podName, strings.Join(invalid, ", "))
+	}
+	return nil
+}
+
+// isStringOutput determines whether a value is a single line of text, with
+// an optional trailing newline.
+func isStringOutput(value []byte) bool {
+	return utf8.Valid(value) && !strings.Contains(strings.TrimSuffix(string(value), "\n"), "\n")
+}
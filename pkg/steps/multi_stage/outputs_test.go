@@ -0,0 +1,107 @@
+package multi_stage
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	coreapi "k8s.io/api/core/v1"
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+	fakectrlruntimeclient "sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/openshift/ci-tools/pkg/api"
+	"github.com/openshift/ci-tools/pkg/steps/loggingclient"
+	testhelper_kube "github.com/openshift/ci-tools/pkg/testhelper/kubernetes"
+)
+
+func stepWithSharedDir(data map[string][]byte) *multiStageTestStep {
+	jobSpec := &api.JobSpec{}
+	jobSpec.SetNamespace("ns")
+	secret := &coreapi.Secret{ObjectMeta: meta.ObjectMeta{Namespace: "ns", Name: "test"}, Data: data}
+	return &multiStageTestStep{
+		name:    "test",
+		jobSpec: jobSpec,
+		client: &testhelper_kube.FakePodClient{FakePodExecutor: &testhelper_kube.FakePodExecutor{
+			LoggingClient: loggingclient.New(fakectrlruntimeclient.NewClientBuilder().WithObjects(secret).Build()),
+		}},
+	}
+}
+
+func TestInjectInputs(t *testing.T) {
+	data := map[string][]byte{"cluster-name": []byte("my-cluster\n"), "report.json": []byte("{}")}
+	for _, tc := range []struct {
+		name        string
+		inputs      []api.StepInput
+		expected    []coreapi.EnvVar
+		expectedErr string
+	}{{
+		name: "no inputs",
+	}, {
+		name: "string and file inputs",
+		inputs: []api.StepInput{
+			{Name: "cluster-name", Type: api.StepOutputTypeString, Env: "CLUSTER_NAME"},
+			{Name: "report.json", Type: api.StepOutputTypeFile, Env: "REPORT"},
+		},
+		expected: []coreapi.EnvVar{{Name: "CLUSTER_NAME", Value: "my-cluster"}, {Name: "REPORT", Value: "report.json"}},
+	}, {
+		name:        "output was not published",
+		inputs:      []api.StepInput{{Name: "region", Type: api.StepOutputTypeString, Env: "REGION"}},
+		expectedErr: `step test-step requires output "region", which was not published`,
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			pod := &coreapi.Pod{
+				ObjectMeta: meta.ObjectMeta{Name: "test-step"},
+				Spec:       coreapi.PodSpec{Containers: []coreapi.Container{{Name: containerName}, {Name: "sidecar"}}},
+			}
+			err := stepWithSharedDir(data).injectInputs(context.Background(), pod, tc.inputs)
+			var actualErr string
+			if err != nil {
+				actualErr = err.Error()
+			}
+			if diff := cmp.Diff(tc.expectedErr, actualErr); diff != "" {
+				t.Fatalf("unexpected error: %s", diff)
+			}
+			if diff := cmp.Diff(tc.expected, pod.Spec.Containers[0].Env); diff != "" {
+				t.Errorf("unexpected environment: %s", diff)
+			}
+			if env := pod.Spec.Containers[1].Env; env != nil {
+				t.Errorf("expected no environment in other containers, got %v", env)
+			}
+		})
+	}
+}
+
+func TestCheckOutputs(t *testing.T) {
+	data := map[string][]byte{"cluster-name": []byte("my-cluster\n"), "report": []byte("line 1\nline 2\n")}
+	for _, tc := range []struct {
+		name        string
+		outputs     []api.StepOutput
+		expectedErr string
+	}{{
+		name: "all outputs are published",
+		outputs: []api.StepOutput{
+			{Name: "cluster-name", Type: api.StepOutputTypeString},
+			{Name: "report", Type: api.StepOutputTypeFile},
+		},
+	}, {
+		name:        "output is missing",
+		outputs:     []api.StepOutput{{Name: "cluster-name", Type: api.StepOutputTypeString}, {Name: "region", Type: api.StepOutputTypeString}},
+		expectedErr: "step test-step did not publish outputs: region",
+	}, {
+		name:        "string output has more than one line",
+		outputs:     []api.StepOutput{{Name: "report", Type: api.StepOutputTypeString}},
+		expectedErr: "step test-step published outputs that are not a single line of text: report",
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			err := stepWithSharedDir(data).checkOutputs(context.Background(), "test-step", tc.outputs)
+			var actualErr string
+			if err != nil {
+				actualErr = err.Error()
+			}
+			if diff := cmp.Diff(tc.expectedErr, actualErr); diff != "" {
+				t.Errorf("unexpected error: %s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,172 @@
+package multi_stage
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/sirupsen/logrus"
+
+	coreapi "k8s.io/api/core/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/apimachinery/pkg/util/sets"
+	ctrlruntimeclient "sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/openshift/ci-tools/pkg/api"
+	base_steps "github.com/openshift/ci-tools/pkg/steps"
+	"github.com/openshift/ci-tools/pkg/util"
+)
+
+// sharedDirSecretFor is the name of the copy of the shared directory updated
+// by a member of a `parallel` group.
+func sharedDirSecretFor(pod string) string {
+	return pod + "-shared-dir"
+}
+
+// sharedDirSecrets lists the secrets steps can update: the shared directory
+// and the copies of it used by members of `parallel` groups.
+func (s *multiStageTestStep) sharedDirSecrets() []string {
+	ret := []string{s.name}
+	for _, phase := range [][]api.LiteralTestStep{s.pre, s.test, s.post} {
+		for _, step := range phase {
+			if s.parallelGroups[step.As] != "" {
+				ret = append(ret, sharedDirSecretFor(fmt.Sprintf("%s-%s", s.name, step.As)))
+			}
+		}
+	}
+	return ret
+}
+
+// runParallel runs the members of a `parallel` group concurrently. Each member
+// updates its own copy of the shared directory and the changes are merged once
+// all of them finish. The errors of the members that ran are returned by pod;
+// the error returned separately is a failure to merge the shared directory.
+func (s *multiStageTestStep) runParallel(ctx context.Context, pods []coreapi.Pod, stepsByPod map[string]api.LiteralTestStep, failed bool) (map[string]error, error) {
+	errsByPod := map[string]error{}
+	var run []*coreapi.Pod
+	for i := range pods {
+		pod := &pods[i]
+		ok, err := s.prepareStep(ctx, pod, stepsByPod[pod.Name], failed)
+		if err != nil {
+			errsByPod[pod.Name] = err
+		} else if ok {
+			run = append(run, pod)
+		}
+	}
+	if len(run) == 0 {
+		return errsByPod, nil
+	}
+	base, err := s.sharedDir(ctx)
+	if err != nil {
+		return errsByPod, err
+	}
+	var names []string
+	for _, pod := range run {
+		names = append(names, pod.Name)
+		if err := s.copySharedDir(ctx, sharedDirSecretFor(pod.Name), base); err != nil {
+			return errsByPod, err
+		}
+	}
+	logrus.Infof("Running steps %s in parallel.", strings.Join(names, ", "))
+	var wg sync.WaitGroup
+	var lock sync.Mutex
+	for _, pod := range run {
+		wg.Add(1)
+		go func(pod *coreapi.Pod) {
+			defer wg.Done()
+			err := s.runPod(ctx, pod, base_steps.NewTestCaseNotifier(util.NopNotifier), util.WaitForPodFlag(0), stepsByPod[pod.Name].Retry)
+			lock.Lock()
+			errsByPod[pod.Name] = err
+			lock.Unlock()
+		}(pod)
+	}
+	wg.Wait()
+	if err := s.mergeSharedDir(ctx, base, names); err != nil {
+		return errsByPod, err
+	}
+	for _, pod := range run {
+		if errsByPod[pod.Name] == nil {
+			errsByPod[pod.Name] = s.checkOutputs(ctx, pod.Name, stepsByPod[pod.Name].Outputs)
+		}
+	}
+	return errsByPod, nil
+}
+
+// copySharedDir creates a copy of the shared directory for a member of a
+// `parallel` group.
+func (s *multiStageTestStep) copySharedDir(ctx context.Context, name string, data map[string][]byte) error {
+	secret := &coreapi.Secret{
+		ObjectMeta: meta.ObjectMeta{
+			Namespace: s.jobSpec.Namespace(),
+			Name:      name,
+			Labels:    map[string]string{api.SkipCensoringLabel: "true", MultiStageTestLabel: s.name},
+		},
+		Data: data,
+	}
+	if err := s.client.Delete(ctx, secret); err != nil && !kerrors.IsNotFound(err) {
+		return fmt.Errorf("cannot delete shared directory %q: %w", name, err)
+	}
+	if err := s.client.Create(ctx, secret); err != nil {
+		return fmt.Errorf("cannot create shared directory %q: %w", name, err)
+	}
+	return nil
+}
+
+// mergeSharedDir applies the changes the members of a `parallel` group made
+// to their copies of the shared directory, in the order of the members.
+// Files added, modified or removed by more than one member must end up the
+// same in all of them, otherwise the changes conflict and none are applied.
+func (s *multiStageTestStep) mergeSharedDir(ctx context.Context, base map[string][]byte, pods []string) error {
+	merged := map[string][]byte{}
+	for k, v := range base {
+		merged[k] = v
+	}
+	changedBy := map[string]string{}
+	var errs []error
+	for _, pod := range pods {
+		name := sharedDirSecretFor(pod)
+		secret := &coreapi.Secret{}
+		if err := s.client.Get(ctx, ctrlruntimeclient.ObjectKey{Namespace: s.jobSpec.Namespace(), Name: name}, secret); err != nil {
+			errs = append(errs, fmt.Errorf("cannot read shared directory %q: %w", name, err))
+			continue
+		}
+		for _, file := range sets.List(sets.KeySet(base).Union(sets.KeySet(secret.Data))) {
+			value, set := secret.Data[file]
+			old, wasSet := base[file]
+			if set == wasSet && bytes.Equal(value, old) {
+				continue
+			}
+			if other, changed := changedBy[file]; changed {
+				if current, stillSet := merged[file]; set != stillSet || !bytes.Equal(value, current) {
+					errs = append(errs, fmt.Errorf("steps %s and %s made conflicting changes to %s in the shared directory", other, pod, file))
+				}
+				continue
+			}
+			changedBy[file] = pod
+			if set {
+				merged[file] = value
+			} else {
+				delete(merged, file)
+			}
+		}
+		if err := s.client.Delete(ctx, secret); err != nil && !kerrors.IsNotFound(err) {
+			logrus.WithError(err).Warnf("Failed to delete shared directory %q.", name)
+		}
+	}
+	if len(errs) != 0 {
+		return utilerrors.NewAggregate(errs)
+	}
+	secret := &coreapi.Secret{}
+	if err := s.client.Get(ctx, ctrlruntimeclient.ObjectKey{Namespace: s.jobSpec.Namespace(), Name: s.name}, secret); err != nil {
+		return fmt.Errorf("cannot read shared directory %q: %w", s.name, err)
+	}
+	secret.Data = merged
+	if err := s.client.Update(ctx, secret); err != nil {
+		return fmt.Errorf("cannot update shared directory %q: %w", s.name, err)
+	}
+	return nil
+}
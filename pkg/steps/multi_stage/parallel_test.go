@@ -0,0 +1,214 @@
+package multi_stage
+
+import (
+	"context"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+
+	coreapi "k8s.io/api/core/v1"
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+	ctrlruntimeclient "sigs.k8s.io/controller-runtime/pkg/client"
+	fakectrlruntimeclient "sigs.k8s.io/controller-runtime/pkg/client/fake"
+	prowapi "sigs.k8s.io/prow/pkg/apis/prowjobs/v1"
+	prowdapi "sigs.k8s.io/prow/pkg/pod-utils/downwardapi"
+
+	"github.com/openshift/ci-tools/pkg/api"
+	"github.com/openshift/ci-tools/pkg/steps"
+	"github.com/openshift/ci-tools/pkg/steps/loggingclient"
+	testhelper_kube "github.com/openshift/ci-tools/pkg/testhelper/kubernetes"
+)
+
+func TestMergeSharedDir(t *testing.T) {
+	base := map[string][]byte{"kubeconfig": []byte("kubeconfig"), "tmp": []byte("tmp"), "state": []byte("initial")}
+	for _, tc := range []struct {
+		name     string
+		members  map[string]map[string][]byte
+		expected map[string][]byte
+		err      string
+	}{{
+		name: "no changes",
+		members: map[string]map[string][]byte{
+			"test-a": base,
+			"test-b": base,
+		},
+		expected: base,
+	}, {
+		name: "independent changes are merged",
+		members: map[string]map[string][]byte{
+			"test-a": {"kubeconfig": []byte("kubeconfig"), "tmp": []byte("tmp"), "state": []byte("initial"), "a": []byte("a")},
+			"test-b": {"kubeconfig": []byte("kubeconfig"), "state": []byte("b")},
+		},
+		expected: map[string][]byte{"kubeconfig": []byte("kubeconfig"), "state": []byte("b"), "a": []byte("a")},
+	}, {
+		name: "identical changes are merged",
+		members: map[string]map[string][]byte{
+			"test-a": {"kubeconfig": []byte("kubeconfig"), "state": []byte("done")},
+			"test-b": {"kubeconfig": []byte("kubeconfig"), "state": []byte("done")},
+		},
+		expected: map[string][]byte{"kubeconfig": []byte("kubeconfig"), "state": []byte("done")},
+	}, {
+		name: "conflicting changes fail the merge",
+		members: map[string]map[string][]byte{
+			"test-a": {"kubeconfig": []byte("kubeconfig"), "tmp": []byte("tmp"), "state": []byte("a")},
+			"test-b": {"kubeconfig": []byte("kubeconfig"), "state": []byte("b")},
+		},
+		expected: base,
+		err:      "steps test-a and test-b made conflicting changes to state in the shared directory",
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			s := stepWithSharedDir(base)
+			var names []string
+			for name, data := range tc.members {
+				names = append(names, name)
+				if err := s.copySharedDir(context.Background(), sharedDirSecretFor(name), data); err != nil {
+					t.Fatalf("failed to create shared directory: %v", err)
+				}
+			}
+			sort.Strings(names)
+			err := s.mergeSharedDir(context.Background(), base, names)
+			if diff := cmp.Diff(tc.err, errorString(err)); diff != "" {
+				t.Errorf("unexpected error: %s", diff)
+			}
+			actual, err := s.sharedDir(context.Background())
+			if err != nil {
+				t.Fatalf("failed to read shared directory: %v", err)
+			}
+			if diff := cmp.Diff(tc.expected, actual); diff != "" {
+				t.Errorf("unexpected shared directory: %s", diff)
+			}
+			for _, name := range names {
+				err := s.client.Get(context.Background(), ctrlruntimeclient.ObjectKey{Namespace: "ns", Name: sharedDirSecretFor(name)}, &coreapi.Secret{})
+				if err == nil {
+					t.Errorf("shared directory of %s was not deleted", name)
+				}
+			}
+		})
+	}
+}
+
+func errorString(err error) string {
+	if err == nil {
+		return ""
+	}
+	return err.Error()
+}
+
+func TestRunParallel(t *testing.T) {
+	for _, tc := range []struct {
+		name        string
+		failures    sets.Set[string]
+		expectedErr bool
+		expected    []string
+	}{{
+		name: "all members succeed",
+		expected: []string{
+			"Run multi-stage test pre phase",
+			"Run multi-stage test test - test-gather container test",
+			"Run multi-stage test test - test-must-gather container test",
+			"Run multi-stage test test - test-deprovision container test",
+			"Run multi-stage test test phase",
+			"Run multi-stage test post phase",
+		},
+	}, {
+		name:        "a failing member does not stop the others",
+		failures:    sets.New[string]("test-gather"),
+		expectedErr: true,
+		expected: []string{
+			"Run multi-stage test pre phase",
+			"Run multi-stage test test - test-gather container test",
+			"Run multi-stage test test - test-must-gather container test",
+			"Run multi-stage test test phase",
+			"Run multi-stage test post phase",
+		},
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			sa := &coreapi.ServiceAccount{ObjectMeta: meta.ObjectMeta{Name: "test", Namespace: "test-namespace", Labels: map[string]string{"ci.openshift.io/multi-stage-test": "test"}}}
+			crclient := &testhelper_kube.FakePodExecutor{
+				LoggingClient: loggingclient.New(
+					fakectrlruntimeclient.NewClientBuilder().
+						WithIndex(&coreapi.Pod{}, "metadata.name", fakePodNameIndexer).
+						WithObjects(sa).
+						Build()),
+				Failures: tc.failures,
+			}
+			jobSpec := api.JobSpec{
+				JobSpec: prowdapi.JobSpec{
+					Job:       "job",
+					BuildID:   "build_id",
+					ProwJobID: "prow_job_id",
+					Type:      prowapi.PeriodicJob,
+					DecorationConfig: &prowapi.DecorationConfig{
+						Timeout:     &prowapi.Duration{Duration: time.Minute},
+						GracePeriod: &prowapi.Duration{Duration: time.Second},
+						UtilityImages: &prowapi.UtilityImages{
+							Sidecar:    "sidecar",
+							Entrypoint: "entrypoint",
+						},
+					},
+				},
+			}
+			jobSpec.SetNamespace("test-namespace")
+			client := &testhelper_kube.FakePodClient{FakePodExecutor: crclient}
+			step := MultiStageTestStep(api.TestStepConfiguration{
+				As: "test",
+				MultiStageTestConfigurationLiteral: &api.MultiStageTestConfigurationLiteral{
+					Test:           []api.LiteralTestStep{{As: "gather"}, {As: "must-gather"}, {As: "deprovision"}},
+					ParallelGroups: map[string]string{"gather": "gather", "must-gather": "gather"},
+				},
+			}, &api.ReleaseBuildConfiguration{}, nil, client, &jobSpec, nil, "node-name", "", nil, false)
+			if err := step.Run(context.Background()); (err != nil) != tc.expectedErr {
+				t.Fatalf("expected error: %t, got error: %v", tc.expectedErr, err)
+			}
+			secrets := map[string]string{}
+			for _, pod := range crclient.CreatedPods {
+				for _, container := range pod.Spec.Containers {
+					if container.Name != containerName {
+						continue
+					}
+					secrets[pod.Name] = ""
+					for i, arg := range container.Args {
+						if arg == "--shared-dir-secret" {
+							secrets[pod.Name] = container.Args[i+1]
+						}
+					}
+				}
+			}
+			expectedSecrets := map[string]string{
+				"test-gather":      "test-gather-shared-dir",
+				"test-must-gather": "test-must-gather-shared-dir",
+			}
+			if !tc.expectedErr {
+				expectedSecrets["test-deprovision"] = ""
+			}
+			if diff := cmp.Diff(expectedSecrets, secrets); diff != "" {
+				t.Errorf("unexpected shared directories: %s", diff)
+			}
+			var names []string
+			for _, t := range step.(steps.SubtestReporter).SubTests() {
+				names = append(names, t.Name)
+			}
+			// members report their results as they finish
+			sort.Strings(names[1:3])
+			if diff := cmp.Diff(tc.expected, names); diff != "" {
+				t.Errorf("unexpected tests: %s", diff)
+			}
+		})
+	}
+}
+
+func TestSharedDirSecrets(t *testing.T) {
+	s := &multiStageTestStep{
+		name:           "test",
+		pre:            []api.LiteralTestStep{{As: "install"}},
+		post:           []api.LiteralTestStep{{As: "gather"}, {As: "must-gather"}},
+		parallelGroups: map[string]string{"gather": "gather", "must-gather": "gather"},
+	}
+	expected := []string{"test", "test-gather-shared-dir", "test-must-gather-shared-dir"}
+	if diff := cmp.Diff(expected, s.sharedDirSecrets()); diff != "" {
+		t.Errorf("unexpected secrets: %s", diff)
+	}
+}
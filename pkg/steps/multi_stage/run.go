@@ -9,6 +9,8 @@ import (
 	"time"
 
 	"github.com/sirupsen/logrus"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 
 	coreapi "k8s.io/api/core/v1"
 	kerrors "k8s.io/apimachinery/pkg/api/errors"
@@ -20,6 +22,7 @@ import (
 	"github.com/openshift/ci-tools/pkg/api"
 	"github.com/openshift/ci-tools/pkg/junit"
 	base_steps "github.com/openshift/ci-tools/pkg/steps"
+	"github.com/openshift/ci-tools/pkg/tracing"
 	"github.com/openshift/ci-tools/pkg/util"
 )
 
@@ -46,7 +49,11 @@ func (s *multiStageTestStep) runSteps(
 			s.flags |= hasPrevErrs
 		}
 	}()
-	if err := s.runPods(ctx, pods, bestEffortSteps); err != nil {
+	stepsByPod := map[string]api.LiteralTestStep{}
+	for _, step := range steps {
+		stepsByPod[fmt.Sprintf("%s-%s", s.name, step.As)] = step
+	}
+	if err := s.runPods(ctx, pods, bestEffortSteps, stepsByPod); err != nil {
 		errs = append(errs, err)
 	}
 	select {
@@ -81,25 +88,139 @@ func (s *multiStageTestStep) runSteps(
 	return err
 }
 
-func (s *multiStageTestStep) runPods(ctx context.Context, pods []coreapi.Pod, bestEffortSteps sets.Set[string]) error {
+func (s *multiStageTestStep) runPods(ctx context.Context, pods []coreapi.Pod, bestEffortSteps sets.Set[string], stepsByPod map[string]api.LiteralTestStep) error {
 	var errs []error
-	for _, pod := range pods {
-		err := s.runPod(ctx, &pod, base_steps.NewTestCaseNotifier(util.NopNotifier), util.WaitForPodFlag(0))
+	// record stores the result of a step and determines whether to stop
+	record := func(pod *coreapi.Pod, err error) bool {
+		result := api.StepResultSucceeded
+		if err != nil {
+			result = api.StepResultFailed
+		}
+		s.recordResult(stepsByPod[pod.Name].As, result)
 		if err == nil {
-			continue
+			return false
 		}
 		if bestEffortSteps != nil && bestEffortSteps.Has(pod.Name) {
 			logrus.Infof("Pod %s is running in best-effort mode, ignoring the failure...", pod.Name)
-			continue
+			return false
 		}
 		errs = append(errs, err)
-		if s.flags&shortCircuit != 0 {
+		return s.flags&shortCircuit != 0
+	}
+	for len(pods) != 0 {
+		failed := s.flags&hasPrevErrs != 0 || len(errs) != 0
+		if group := s.parallelGroups[stepsByPod[pods[0].Name].As]; group != "" {
+			n := 1
+			for n < len(pods) && s.parallelGroups[stepsByPod[pods[n].Name].As] == group {
+				n++
+			}
+			members := pods[:n]
+			pods = pods[n:]
+			errsByPod, err := s.runParallel(ctx, members, stepsByPod, failed)
+			stop := err != nil && s.flags&shortCircuit != 0
+			if err != nil {
+				errs = append(errs, err)
+			}
+			for i := range members {
+				if err, ran := errsByPod[members[i].Name]; ran {
+					stop = record(&members[i], err) || stop
+				}
+			}
+			if stop {
+				break
+			}
+			continue
+		}
+		pod := pods[0]
+		pods = pods[1:]
+		step := stepsByPod[pod.Name]
+		run, err := s.prepareStep(ctx, &pod, step, failed)
+		if err == nil && !run {
+			continue
+		}
+		if err == nil {
+			err = s.runPod(ctx, &pod, base_steps.NewTestCaseNotifier(util.NopNotifier), util.WaitForPodFlag(0), step.Retry)
+		}
+		if err == nil {
+			err = s.checkOutputs(ctx, pod.Name, step.Outputs)
+		}
+		if record(&pod, err) {
 			break
 		}
 	}
 	return utilerrors.NewAggregate(errs)
 }
 
+// prepareStep determines whether the step should run and exposes its inputs
+// to the pod if it does.
+func (s *multiStageTestStep) prepareStep(ctx context.Context, pod *coreapi.Pod, step api.LiteralTestStep, failed bool) (bool, error) {
+	run, err := s.evaluateWhen(pod, step, failed)
+	if err != nil {
+		return false, err
+	}
+	if !run {
+		s.skipStep(pod, step)
+		return false, nil
+	}
+	return true, s.injectInputs(ctx, pod, step.Inputs)
+}
+
+// evaluateWhen determines whether the step should run, given whether any
+// step of the test failed so far. Steps without a `when` expression always
+// run unless the default behavior skips them.
+func (s *multiStageTestStep) evaluateWhen(pod *coreapi.Pod, step api.LiteralTestStep, failed bool) (bool, error) {
+	if step.When == "" {
+		return true, nil
+	}
+	expression, err := api.ParseWhenExpression(step.When)
+	if err != nil {
+		return false, fmt.Errorf("invalid `when` expression for step %s: %w", step.As, err)
+	}
+	env := map[string]string{}
+	for _, container := range pod.Spec.Containers {
+		if container.Name != containerName {
+			continue
+		}
+		for _, e := range container.Env {
+			env[e.Name] = e.Value
+		}
+	}
+	run, err := expression.Evaluate(api.WhenContext{
+		Failed: failed,
+		Steps:  s.stepResults,
+		Env: func(name string) (string, bool) {
+			value, ok := env[name]
+			return value, ok
+		},
+	})
+	if err != nil {
+		return false, fmt.Errorf("could not evaluate `when` expression for step %s: %w", step.As, err)
+	}
+	return run, nil
+}
+
+// skipStep records a step that was not run because its `when` expression did
+// not hold.
+func (s *multiStageTestStep) skipStep(pod *coreapi.Pod, step api.LiteralTestStep) {
+	logrus.Infof("Skipping step %s: `when` expression %q does not hold.", pod.Name, step.When)
+	s.recordResult(step.As, api.StepResultSkipped)
+	s.subLock.Lock()
+	s.subTests = append(s.subTests, &junit.TestCase{
+		Name:        fmt.Sprintf("%s - %s container %s", s.Description(), pod.Name, containerName),
+		SkipMessage: &junit.SkipMessage{Message: fmt.Sprintf("`when` expression %q does not hold", step.When)},
+	})
+	s.subLock.Unlock()
+}
+
+// recordResult stores the result of a step for `when` expressions of the
+// steps that run after it.
+func (s *multiStageTestStep) recordResult(name string, result api.StepResult) {
+	if s.stepResults == nil {
+		s.stepResults = map[string]api.StepResult{}
+	}
+	s.stepResults[name] = result
+}
+
 func (s *multiStageTestStep) runObservers(ctx, textCtx context.Context, pods []coreapi.Pod, done chan<- struct{}) {
 	wg := sync.WaitGroup{}
 	wg.Add(len(pods))
@@ -113,7 +234,7 @@ func (s *multiStageTestStep) runObservers(ctx, textCtx context.Context, pods []c
 			}
 		}(pod)
 		go func(p coreapi.Pod) {
-			err := s.runPod(textCtx, &p, base_steps.NewTestCaseNotifier(util.NopNotifier), util.Interruptible)
+			err := s.runPod(textCtx, &p, base_steps.NewTestCaseNotifier(util.NopNotifier), util.Interruptible, nil)
 			if ctx.Err() == nil {
 				// when the observer is cancelled, we get an error here that we need to ignore, as it's not an error
 				// for the Pod to be deleted when it's cancelled, it's just expected
@@ -134,12 +255,51 @@ func (s *multiStageTestStep) runObservers(ctx, textCtx context.Context, pods []c
 	done <- struct{}{}
 }
 
-func (s *multiStageTestStep) runPod(ctx context.Context, pod *coreapi.Pod, notifier *base_steps.TestCaseNotifier, flags util.WaitForPodFlag) error {
+// defaultRetryBackoff is the wait before the first retry of a step when its
+// retry policy does not set one.
+const defaultRetryBackoff = 30 * time.Second
+
+// runPod runs the pod to completion. When a retry policy is given, the pod is
+// run again after failures the policy matches, up to the number of attempts
+// it allows, and each attempt is reported as a separate test.
+func (s *multiStageTestStep) runPod(ctx context.Context, pod *coreapi.Pod, notifier *base_steps.TestCaseNotifier, flags util.WaitForPodFlag, retry *api.StepRetryPolicy) error {
+	if retry == nil {
+		_, err := s.runPodAttempt(ctx, pod, notifier, flags, 0)
+		return err
+	}
+	backoff := defaultRetryBackoff
+	if retry.Backoff != nil {
+		backoff = retry.Backoff.Duration
+	}
+	for attempt := 1; ; attempt++ {
+		finished, err := s.runPodAttempt(ctx, pod.DeepCopy(), notifier, flags, attempt)
+		if err == nil || attempt >= retry.Attempts {
+			return err
+		}
+		class, ok := failureClass(finished, err)
+		if !ok || !retry.Retries(class) {
+			return err
+		}
+		logrus.Infof("Step %s failed with a transient failure (%s), retrying in %s (attempt %d of %d).", pod.Name, class, backoff, attempt+1, retry.Attempts)
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+	}
+}
+
+// runPodAttempt runs the pod once and returns it as it finished. Attempts are
+// numbered from one for pods that may be retried and are zero otherwise.
+func (s *multiStageTestStep) runPodAttempt(ctx context.Context, pod *coreapi.Pod, notifier *base_steps.TestCaseNotifier, flags util.WaitForPodFlag, attempt int) (_ *coreapi.Pod, err error) {
+	ctx, span := tracing.Start(ctx, pod.Name, trace.WithAttributes(attribute.String("ci.test", s.name), attribute.Int("ci.attempt", attempt)))
+	defer func() { tracing.End(span, err) }()
 	start := time.Now()
 	logrus.Infof("Running step %s.", pod.Name)
 	client := s.client.WithNewLoggingClient()
 	if _, err := util.CreateOrRestartPod(ctx, client, pod); err != nil {
-		return fmt.Errorf("failed to create or restart %s pod: %w", pod.Name, err)
+		return pod, fmt.Errorf("failed to create or restart %s pod: %w", pod.Name, err)
 	}
 	newPod, err := util.WaitForPodCompletion(ctx, client, pod.Namespace, pod.Name, notifier, flags)
 	if newPod != nil {
@@ -152,17 +312,23 @@ func (s *multiStageTestStep) runPod(ctx context.Context, pod *coreapi.Pod, notif
 		verb = "failed"
 	}
 	logrus.Infof("Step %s %s after %s.", pod.Name, verb, duration.Truncate(time.Second))
+	description, prefix := fmt.Sprintf("Run pod %s", pod.Name), fmt.Sprintf("%s - %s ", s.Description(), pod.Name)
+	if attempt != 0 {
+		description = fmt.Sprintf("%s (attempt %d)", description, attempt)
+		prefix = fmt.Sprintf("%sattempt %d ", prefix, attempt)
+		notifier.RecordAttempt(strings.TrimSpace(prefix), duration, err)
+	}
 	s.subLock.Lock()
 	s.subSteps = append(s.subSteps, api.CIOperatorStepDetailInfo{
 		StepName:    pod.Name,
-		Description: fmt.Sprintf("Run pod %s", pod.Name),
+		Description: description,
 		StartedAt:   &start,
 		FinishedAt:  &finished,
 		Duration:    &duration,
 		Failed:      utilpointer.Bool(err != nil),
 		Manifests:   client.Objects(),
 	})
-	s.subTests = append(s.subTests, notifier.SubTests(fmt.Sprintf("%s - %s ", s.Description(), pod.Name))...)
+	s.subTests = append(s.subTests, notifier.SubTests(prefix)...)
 	s.subLock.Unlock()
 	if err != nil {
 		linksText := strings.Builder{}
@@ -178,7 +344,36 @@ func (s *multiStageTestStep) runPod(ctx context.Context, pod *coreapi.Pod, notif
 				status = fmt.Sprintf("%s activeDeadlineSeconds=%d", status, *pod.Spec.ActiveDeadlineSeconds)
 			}
 		}
-		return fmt.Errorf("%q pod %q %s: %w\n%s", s.name, pod.Name, status, err, linksText.String())
+		return pod, fmt.Errorf("%q pod %q %s: %w\n%s", s.name, pod.Name, status, err, linksText.String())
+	}
+	return pod, nil
+}
+
+// failureClass determines whether a pod failed because of a transient
+// infrastructure problem that a retry policy can match.
+func failureClass(pod *coreapi.Pod, err error) (api.StepFailureClass, bool) {
+	if kerrors.IsTooManyRequests(err) || kerrors.IsServerTimeout(err) {
+		return api.StepFailureAPIThrottling, true
+	}
+	if pod == nil {
+		return "", false
+	}
+	if pod.Status.Reason == "Evicted" {
+		return api.StepFailureEviction, true
+	}
+	statuses := append(append([]coreapi.ContainerStatus{}, pod.Status.InitContainerStatuses...), pod.Status.ContainerStatuses...)
+	for _, status := range statuses {
+		if terminated := status.State.Terminated; terminated != nil && terminated.Reason == "OOMKilled" {
+			return api.StepFailureOOMKilled, true
+		}
+	}
+	for _, status := range statuses {
+		if waiting := status.State.Waiting; waiting != nil {
+			switch waiting.Reason {
+			case "ErrImagePull", "ImagePullBackOff":
+				return api.StepFailureImagePull, true
+			}
+		}
 	}
-	return nil
+	return "", false
 }
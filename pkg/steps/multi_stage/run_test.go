@@ -4,12 +4,14 @@ import (
 	"context"
 	"fmt"
 	"reflect"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/google/go-cmp/cmp"
 
 	v1 "k8s.io/api/core/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/diff"
 	"k8s.io/apimachinery/pkg/util/sets"
@@ -277,6 +279,243 @@ func TestJUnit(t *testing.T) {
 	}
 }
 
+func TestRunRetries(t *testing.T) {
+	for _, tc := range []struct {
+		name         string
+		retry        *api.StepRetryPolicy
+		evictions    int
+		expectedErr  bool
+		expectedPods []string
+		expected     []string
+	}{{
+		name:         "no retry policy, eviction fails the step",
+		evictions:    1,
+		expectedErr:  true,
+		expectedPods: []string{"test-test0"},
+		expected: []string{
+			"Run multi-stage test pre phase",
+			"Run multi-stage test test phase",
+			"Run multi-stage test post phase",
+		},
+	}, {
+		name:         "evicted step is retried",
+		retry:        &api.StepRetryPolicy{Attempts: 3, Backoff: &prowapi.Duration{Duration: time.Millisecond}},
+		evictions:    1,
+		expectedPods: []string{"test-test0", "test-test0"},
+		expected: []string{
+			"Run multi-stage test pre phase",
+			"Run multi-stage test test - test-test0 attempt 1",
+			"Run multi-stage test test - test-test0 attempt 2",
+			"Run multi-stage test test - test-test0 attempt 2 container test",
+			"Run multi-stage test test phase",
+			"Run multi-stage test post phase",
+		},
+	}, {
+		name:         "retries are exhausted",
+		retry:        &api.StepRetryPolicy{Attempts: 2, Backoff: &prowapi.Duration{Duration: time.Millisecond}},
+		evictions:    5,
+		expectedErr:  true,
+		expectedPods: []string{"test-test0", "test-test0"},
+		expected: []string{
+			"Run multi-stage test pre phase",
+			"Run multi-stage test test - test-test0 attempt 1",
+			"Run multi-stage test test - test-test0 attempt 2",
+			"Run multi-stage test test phase",
+			"Run multi-stage test post phase",
+		},
+	}, {
+		name:         "failure class is not retried",
+		retry:        &api.StepRetryPolicy{Attempts: 3, Backoff: &prowapi.Duration{Duration: time.Millisecond}, On: []api.StepFailureClass{api.StepFailureOOMKilled}},
+		evictions:    1,
+		expectedErr:  true,
+		expectedPods: []string{"test-test0"},
+		expected: []string{
+			"Run multi-stage test pre phase",
+			"Run multi-stage test test - test-test0 attempt 1",
+			"Run multi-stage test test phase",
+			"Run multi-stage test post phase",
+		},
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			sa := &v1.ServiceAccount{ObjectMeta: metav1.ObjectMeta{Name: "test", Namespace: "test-namespace", Labels: map[string]string{"ci.openshift.io/multi-stage-test": "test"}}}
+			crclient := &testhelper_kube.FakePodExecutor{
+				LoggingClient: loggingclient.New(
+					fakectrlruntimeclient.NewClientBuilder().
+						WithIndex(&v1.Pod{}, "metadata.name", fakePodNameIndexer).
+						WithObjects(sa).
+						Build()),
+				Evictions: map[string]int{"test-test0": tc.evictions},
+			}
+			jobSpec := api.JobSpec{
+				JobSpec: prowdapi.JobSpec{
+					Job:       "job",
+					BuildID:   "build_id",
+					ProwJobID: "prow_job_id",
+					Type:      prowapi.PeriodicJob,
+					DecorationConfig: &prowapi.DecorationConfig{
+						Timeout:     &prowapi.Duration{Duration: time.Minute},
+						GracePeriod: &prowapi.Duration{Duration: time.Second},
+						UtilityImages: &prowapi.UtilityImages{
+							Sidecar:    "sidecar",
+							Entrypoint: "entrypoint",
+						},
+					},
+				},
+			}
+			jobSpec.SetNamespace("test-namespace")
+			client := &testhelper_kube.FakePodClient{FakePodExecutor: crclient}
+			step := MultiStageTestStep(api.TestStepConfiguration{
+				As: "test",
+				MultiStageTestConfigurationLiteral: &api.MultiStageTestConfigurationLiteral{
+					Test: []api.LiteralTestStep{{As: "test0", Retry: tc.retry}},
+				},
+			}, &api.ReleaseBuildConfiguration{}, nil, client, &jobSpec, nil, "node-name", "", nil, false)
+			if err := step.Run(context.Background()); (err != nil) != tc.expectedErr {
+				t.Fatalf("expected error: %t, got error: %v", tc.expectedErr, err)
+			}
+			var pods []string
+			for _, pod := range crclient.CreatedPods {
+				pods = append(pods, pod.Name)
+			}
+			if diff := cmp.Diff(tc.expectedPods, pods); diff != "" {
+				t.Errorf("unexpected pods: %s", diff)
+			}
+			var names []string
+			for _, t := range step.(steps.SubtestReporter).SubTests() {
+				names = append(names, t.Name)
+			}
+			if diff := cmp.Diff(tc.expected, names); diff != "" {
+				t.Errorf("unexpected tests: %s", diff)
+			}
+		})
+	}
+}
+
+func TestRunWhen(t *testing.T) {
+	upgrade := "false"
+	for _, tc := range []struct {
+		name         string
+		failures     sets.Set[string]
+		expectedErr  bool
+		expectedPods []string
+		skipped      []string
+	}{{
+		name:         "test succeeds, failure steps are skipped",
+		expectedPods: []string{"test-test0", "test-on-success"},
+		skipped:      []string{"test-on-failure", "test-upgrade"},
+	}, {
+		name:         "test fails, failure steps run",
+		failures:     sets.New[string]("test-test0"),
+		expectedErr:  true,
+		expectedPods: []string{"test-test0", "test-on-failure"},
+		skipped:      []string{"test-on-success", "test-upgrade"},
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			sa := &v1.ServiceAccount{ObjectMeta: metav1.ObjectMeta{Name: "test", Namespace: "test-namespace", Labels: map[string]string{"ci.openshift.io/multi-stage-test": "test"}}}
+			crclient := &testhelper_kube.FakePodExecutor{
+				LoggingClient: loggingclient.New(
+					fakectrlruntimeclient.NewClientBuilder().
+						WithIndex(&v1.Pod{}, "metadata.name", fakePodNameIndexer).
+						WithObjects(sa).
+						Build()),
+				Failures: tc.failures,
+			}
+			jobSpec := api.JobSpec{
+				JobSpec: prowdapi.JobSpec{
+					Job:       "job",
+					BuildID:   "build_id",
+					ProwJobID: "prow_job_id",
+					Type:      prowapi.PeriodicJob,
+					DecorationConfig: &prowapi.DecorationConfig{
+						Timeout:     &prowapi.Duration{Duration: time.Minute},
+						GracePeriod: &prowapi.Duration{Duration: time.Second},
+						UtilityImages: &prowapi.UtilityImages{
+							Sidecar:    "sidecar",
+							Entrypoint: "entrypoint",
+						},
+					},
+				},
+			}
+			jobSpec.SetNamespace("test-namespace")
+			client := &testhelper_kube.FakePodClient{FakePodExecutor: crclient}
+			step := MultiStageTestStep(api.TestStepConfiguration{
+				As: "test",
+				MultiStageTestConfigurationLiteral: &api.MultiStageTestConfigurationLiteral{
+					Test: []api.LiteralTestStep{{As: "test0"}},
+					Post: []api.LiteralTestStep{
+						{As: "on-failure", When: "failure()"},
+						{As: "on-success", When: `steps.test0 == "succeeded" && steps.on-failure == "skipped"`},
+						{As: "upgrade", Environment: []api.StepParameter{{Name: "UPGRADE", Default: &upgrade}}, When: `env.UPGRADE == "true"`},
+					},
+				},
+			}, &api.ReleaseBuildConfiguration{}, nil, client, &jobSpec, nil, "node-name", "", nil, false)
+			if err := step.Run(context.Background()); (err != nil) != tc.expectedErr {
+				t.Fatalf("expected error: %t, got error: %v", tc.expectedErr, err)
+			}
+			var pods []string
+			for _, pod := range crclient.CreatedPods {
+				pods = append(pods, pod.Name)
+			}
+			if diff := cmp.Diff(tc.expectedPods, pods); diff != "" {
+				t.Errorf("unexpected pods: %s", diff)
+			}
+			var skipped []string
+			for _, t := range step.(steps.SubtestReporter).SubTests() {
+				if t.SkipMessage != nil {
+					skipped = append(skipped, strings.TrimSuffix(strings.TrimPrefix(t.Name, "Run multi-stage test test - "), " container test"))
+				}
+			}
+			if diff := cmp.Diff(tc.skipped, skipped); diff != "" {
+				t.Errorf("unexpected skipped steps: %s", diff)
+			}
+		})
+	}
+}
+
+func TestFailureClass(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		pod      *v1.Pod
+		err      error
+		expected api.StepFailureClass
+	}{{
+		name: "step failure",
+		pod: &v1.Pod{Status: v1.PodStatus{ContainerStatuses: []v1.ContainerStatus{{
+			State: v1.ContainerState{Terminated: &v1.ContainerStateTerminated{ExitCode: 1, Reason: "Error"}},
+		}}}},
+	}, {
+		name:     "eviction",
+		pod:      &v1.Pod{Status: v1.PodStatus{Reason: "Evicted"}},
+		expected: api.StepFailureEviction,
+	}, {
+		name: "image pull",
+		pod: &v1.Pod{Status: v1.PodStatus{InitContainerStatuses: []v1.ContainerStatus{{
+			State: v1.ContainerState{Waiting: &v1.ContainerStateWaiting{Reason: "ImagePullBackOff"}},
+		}}}},
+		expected: api.StepFailureImagePull,
+	}, {
+		name: "out of memory",
+		pod: &v1.Pod{Status: v1.PodStatus{ContainerStatuses: []v1.ContainerStatus{{
+			State: v1.ContainerState{Terminated: &v1.ContainerStateTerminated{ExitCode: 137, Reason: "OOMKilled"}},
+		}}}},
+		expected: api.StepFailureOOMKilled,
+	}, {
+		name:     "throttling",
+		err:      fmt.Errorf("failed to create pod: %w", kerrors.NewTooManyRequests("slow down", 1)),
+		expected: api.StepFailureAPIThrottling,
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			class, ok := failureClass(tc.pod, tc.err)
+			if ok != (tc.expected != "") {
+				t.Fatalf("expected transient failure: %t, got %t", tc.expected != "", ok)
+			}
+			if diff := cmp.Diff(tc.expected, class); diff != "" {
+				t.Errorf("unexpected class: %s", diff)
+			}
+		})
+	}
+}
+
 func fakePodNameIndexer(object ctrlruntimeclient.Object) []string {
 	p, ok := object.(*v1.Pod)
 	if !ok {
@@ -82,6 +82,8 @@ func (s *pipelineImageCacheStep) Provides() api.ParameterMap {
 
 func (s *pipelineImageCacheStep) Name() string { return s.config.TargetName() }
 
+func (s *pipelineImageCacheStep) Kind() StepKind { return StepKindBuild }
+
 func (s *pipelineImageCacheStep) Description() string {
 	return fmt.Sprintf("Store build results into a layer on top of %s and save as %s", s.config.From, s.config.To)
 }
@@ -154,6 +154,8 @@ func (s *podStep) Provides() api.ParameterMap {
 
 func (s *podStep) Name() string { return s.config.As }
 
+func (s *podStep) Kind() StepKind { return StepKindTest }
+
 func (s *podStep) Description() string {
 	return fmt.Sprintf("Run test %s", s.config.As)
 }
@@ -188,6 +188,8 @@ func (s *projectDirectoryImageBuildStep) Provides() api.ParameterMap {
 
 func (s *projectDirectoryImageBuildStep) Name() string { return s.config.TargetName() }
 
+func (s *projectDirectoryImageBuildStep) Kind() StepKind { return StepKindBuild }
+
 func (s *projectDirectoryImageBuildStep) Description() string {
 	return fmt.Sprintf("Build image %s from the repository", s.config.To)
 }
@@ -304,6 +304,8 @@ func (s *importReleaseStep) Provides() api.ParameterMap {
 
 func (s *importReleaseStep) Name() string { return s.target }
 
+func (s *importReleaseStep) Kind() steps.StepKind { return steps.StepKindImport }
+
 func (s *importReleaseStep) Description() string {
 	return fmt.Sprintf("Import the release payload %q from an external source", s.name)
 }
@@ -18,6 +18,7 @@ import (
 	"github.com/openshift/ci-tools/pkg/api"
 	"github.com/openshift/ci-tools/pkg/api/configresolver"
 	"github.com/openshift/ci-tools/pkg/results"
+	"github.com/openshift/ci-tools/pkg/steps"
 	"github.com/openshift/ci-tools/pkg/steps/loggingclient"
 	"github.com/openshift/ci-tools/pkg/steps/utils"
 )
@@ -124,6 +125,8 @@ func (r *releaseSnapshotStep) Name() string {
 	return fmt.Sprintf("[release-inputs:%s]", r.name)
 }
 
+func (r *releaseSnapshotStep) Kind() steps.StepKind { return steps.StepKindImport }
+
 func (r *releaseSnapshotStep) Description() string {
 	return fmt.Sprintf("Find all of the input images from %s/%s and tag them into the %s stream", r.config.Namespace, r.config.Name, api.ReleaseStreamFor(r.name))
 }
@@ -81,6 +81,8 @@ func (s *rpmImageInjectionStep) Provides() api.ParameterMap {
 
 func (s *rpmImageInjectionStep) Name() string { return s.config.TargetName() }
 
+func (s *rpmImageInjectionStep) Kind() StepKind { return StepKindBuild }
+
 func (s *rpmImageInjectionStep) Description() string {
 	return "Inject an RPM repository that will point at the RPM server"
 }
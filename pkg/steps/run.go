@@ -6,9 +6,16 @@ import (
 	"sync"
 	"time"
 
+	"github.com/sirupsen/logrus"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+
+	"k8s.io/apimachinery/pkg/util/sets"
+
 	"github.com/openshift/ci-tools/pkg/api"
 	"github.com/openshift/ci-tools/pkg/junit"
 	"github.com/openshift/ci-tools/pkg/results"
+	"github.com/openshift/ci-tools/pkg/tracing"
 )
 
 type message struct {
@@ -17,34 +24,154 @@ type message struct {
 	err             error
 	additionalTests []*junit.TestCase
 	stepDetails     api.CIOperatorStepDetails
+	span            trace.SpanContext
+}
+
+// finishedSpan is the span of a step that finished, with the order in
+// which it finished.
+type finishedSpan struct {
+	span  trace.SpanContext
+	order int
 }
 
-func Run(ctx context.Context, graph api.StepGraph) (*junit.TestSuites, []api.CIOperatorStepDetails, []error) {
+// RunOptions configure the execution of a step graph.
+type RunOptions struct {
+	// Checkpoint, if set, is updated every time a step completes successfully
+	// and the steps recorded as completed in it are skipped, scheduling only
+	// the unfinished part of the graph.
+	Checkpoint CheckpointStore
+	// Parameters receive the parameters provided by the skipped steps.
+	Parameters *api.DeferredParameters
+	// Concurrency limits how many steps run at the same time.
+	Concurrency api.ConcurrencyConfiguration
+	// ExecutionPolicy determines how the execution continues after a step fails.
+	ExecutionPolicy api.ExecutionPolicy
+	// Optional holds the names of steps whose failure does not cancel the
+	// execution under the fail-fast policy.
+	Optional sets.Set[string]
+}
+
+type RunOption func(*RunOptions)
+
+// WithCheckpoint continues the execution recorded in the store and records
+// its progress there. The parameters provided by the steps that are skipped
+// are restored into params.
+func WithCheckpoint(store CheckpointStore, params *api.DeferredParameters) RunOption {
+	return func(o *RunOptions) {
+		o.Checkpoint = store
+		o.Parameters = params
+	}
+}
+
+// WithConcurrency limits how many steps run at the same time.
+func WithConcurrency(concurrency api.ConcurrencyConfiguration) RunOption {
+	return func(o *RunOptions) {
+		o.Concurrency = concurrency
+	}
+}
+
+// WithExecutionPolicy determines how the execution continues after a step
+// fails. Failures of the optional steps never cancel the execution.
+func WithExecutionPolicy(policy api.ExecutionPolicy, optional ...string) RunOption {
+	return func(o *RunOptions) {
+		o.ExecutionPolicy = policy
+		o.Optional = sets.New[string](optional...)
+	}
+}
+
+func Run(ctx context.Context, graph api.StepGraph, opts ...RunOption) (*junit.TestSuites, []api.CIOperatorStepDetails, []error) {
+	opt := &RunOptions{}
+	for _, o := range opts {
+		o(opt)
+	}
+	suites := &junit.TestSuites{
+		Suites: []*junit.TestSuite{
+			{
+				Name: "step graph",
+			},
+		},
+	}
+	suite := suites.Suites[0]
+
 	var seen []api.StepLink
+	checkpoint := &Checkpoint{}
+	toRun := []*api.StepNode(graph)
+	if opt.Checkpoint != nil {
+		previous, err := opt.Checkpoint.Load(ctx)
+		if err != nil {
+			return suites, nil, []error{results.ForReason("loading_checkpoint").WithError(err).Errorf("could not load checkpoint: %v", err)}
+		}
+		var completed []*api.StepNode
+		completed, toRun = resumableNodes(graph, previous)
+		for _, node := range completed {
+			logrus.Infof("Skipping step %s, it was completed by a previous execution", node.Step.Name())
+			seen = append(seen, node.Step.Creates()...)
+			for _, step := range previous.Completed {
+				if step.Name != node.Step.Name() {
+					continue
+				}
+				checkpoint.Completed = append(checkpoint.Completed, step)
+				if opt.Parameters != nil {
+					for name, value := range step.Provides {
+						opt.Parameters.Restore(name, value)
+					}
+				}
+			}
+			suite.NumTests++
+			suite.NumSkipped++
+			suite.TestCases = append(suite.TestCases, &junit.TestCase{
+				Name:        node.Step.Description(),
+				SkipMessage: &junit.SkipMessage{Message: "completed by a previous execution"},
+			})
+		}
+	}
+
 	executionResults := make(chan message)
 	done := make(chan bool)
 	ctxDone := ctx.Done()
 	var interrupted bool
 	wg := &sync.WaitGroup{}
-	wg.Add(len(graph))
+	wg.Add(len(toRun))
 	go func() {
 		wg.Wait()
 		done <- true
 	}()
 
-	start := time.Now()
-	for _, root := range graph {
-		go runStep(ctx, root, executionResults)
-	}
+	// steps run with their own context so that they can be cancelled
+	// without interrupting the whole execution
+	stepCtx, cancelSteps := context.WithCancel(ctx)
+	defer cancelSteps()
+	// failedFast holds the name of the step whose failure cancelled
+	// the execution under the fail-fast policy
+	var failedFast string
 
-	suites := &junit.TestSuites{
-		Suites: []*junit.TestSuite{
-			{
-				Name: "step graph",
-			},
-		},
+	// the span of a step is a child of the span of the last of its parents
+	// to finish, which is the one that triggered it, and links to the others
+	spans := map[*api.StepNode]finishedSpan{}
+	parents := parentsOf(graph)
+	launch := func(nodes []*api.StepNode) {
+		for _, node := range nodes {
+			parentCtx, triggeredBy := stepCtx, -1
+			var links []trace.Link
+			for _, parent := range parents[node] {
+				finished, ok := spans[parent]
+				if !ok {
+					continue
+				}
+				links = append(links, trace.Link{SpanContext: finished.span})
+				if finished.order > triggeredBy {
+					parentCtx, triggeredBy = trace.ContextWithSpanContext(stepCtx, finished.span), finished.order
+				}
+			}
+			go runStep(parentCtx, node, links, executionResults)
+		}
 	}
-	suite := suites.Suites[0]
+
+	start := time.Now()
+	sched := newScheduler(graph, opt.Concurrency)
+	sched.enqueue(toRun...)
+	launch(sched.next())
+
 	var executionErrors []error
 	var stepDetails []api.CIOperatorStepDetails
 	for {
@@ -53,15 +180,40 @@ func Run(ctx context.Context, graph api.StepGraph) (*junit.TestSuites, []api.CIO
 			executionErrors = append(executionErrors, results.ForReason("interrupted").ForError(errors.New("execution cancelled")))
 			interrupted = true
 			ctxDone = nil
+			for range sched.drain() {
+				wg.Done()
+			}
 		case out := <-executionResults:
+			sched.finished(out.node)
+			spans[out.node] = finishedSpan{span: out.span, order: len(spans)}
 			testCase := &junit.TestCase{Name: out.node.Step.Description(), Duration: out.duration.Seconds()}
 			stepDetails = append(stepDetails, out.stepDetails)
 			if out.err != nil {
 				testCase.FailureOutput = &junit.FailureOutput{Output: out.err.Error()}
-				executionErrors = append(executionErrors, results.ForReason("step_failed").WithError(out.err).Errorf("step %s failed: %v", out.node.Step.Name(), out.err))
+				name := out.node.Step.Name()
+				switch {
+				case failedFast != "" && errors.Is(out.err, context.Canceled):
+					executionErrors = append(executionErrors, results.ForReason("fail_fast").WithError(out.err).Errorf("step %s was cancelled after step %s failed: %v", name, failedFast, out.err))
+				default:
+					executionErrors = append(executionErrors, results.ForReason("step_failed").WithError(out.err).Errorf("step %s failed: %v", name, out.err))
+					if failedFast == "" && opt.ExecutionPolicy == api.ExecutionPolicyFailFast && !opt.Optional.Has(name) {
+						logrus.Infof("Step %s failed, cancelling all other steps.", name)
+						failedFast = name
+						cancelSteps()
+						for range sched.drain() {
+							wg.Done()
+						}
+					}
+				}
 			} else {
 				seen = append(seen, out.node.Step.Creates()...)
-				if !interrupted {
+				if opt.Checkpoint != nil {
+					checkpoint.Completed = append(checkpoint.Completed, completedStepFor(out.node.Step))
+					if err := opt.Checkpoint.Save(ctx, checkpoint); err != nil {
+						logrus.WithError(err).Warn("Failed to save the step graph checkpoint.")
+					}
+				}
+				if !interrupted && failedFast == "" {
 					for _, child := range out.node.Children {
 						// we can trigger a child if all of it's pre-requisites
 						// have been completed and if it has not yet been triggered.
@@ -70,11 +222,12 @@ func Run(ctx context.Context, graph api.StepGraph) (*junit.TestSuites, []api.CIO
 						// when the last of its parents finishes.
 						if api.HasAllLinks(child.Step.Requires(), seen) {
 							wg.Add(1)
-							go runStep(ctx, child, executionResults)
+							sched.enqueue(child)
 						}
 					}
 				}
 			}
+			launch(sched.next())
 
 			// append all reported tests cases
 			var testCases []*junit.TestCase
@@ -116,9 +269,11 @@ type SubStepReporter interface {
 	SubSteps() []api.CIOperatorStepDetailInfo
 }
 
-func runStep(ctx context.Context, node *api.StepNode, out chan<- message) {
+func runStep(ctx context.Context, node *api.StepNode, links []trace.Link, out chan<- message) {
+	ctx, span := tracing.Start(ctx, node.Step.Name(), trace.WithLinks(links...), trace.WithAttributes(attribute.String("ci.step.description", node.Step.Description())))
 	start := time.Now()
 	err := node.Step.Run(ctx)
+	tracing.End(span, err)
 	var additionalTests []*junit.TestCase
 	if reporter, ok := node.Step.(SubtestReporter); ok {
 		additionalTests = reporter.SubTests()
@@ -137,6 +292,7 @@ func runStep(ctx context.Context, node *api.StepNode, out chan<- message) {
 		duration:        duration,
 		err:             err,
 		additionalTests: additionalTests,
+		span:            span.SpanContext(),
 		stepDetails: api.CIOperatorStepDetails{
 			CIOperatorStepDetailInfo: api.CIOperatorStepDetailInfo{
 				StepName:    node.Step.Name(),
@@ -336,3 +336,111 @@ func TestStepsRun(t *testing.T) {
 		})
 	}
 }
+
+// cancellableStep runs until its context is cancelled, then fails with
+// cancelErr or the error of the context.
+type cancellableStep struct {
+	fakeStep
+	started   chan struct{}
+	cancelErr error
+}
+
+func (c *cancellableStep) Run(ctx context.Context) error {
+	close(c.started)
+	<-ctx.Done()
+	if c.cancelErr != nil {
+		return c.cancelErr
+	}
+	return ctx.Err()
+}
+
+// notifyingStep signals when it finished running.
+type notifyingStep struct {
+	fakeStep
+	finished chan struct{}
+}
+
+func (n *notifyingStep) Run(ctx context.Context) error {
+	defer close(n.finished)
+	return n.fakeStep.Run(ctx)
+}
+
+func TestRunExecutionPolicy(t *testing.T) {
+	var testCases = []struct {
+		name        string
+		policy      api.ExecutionPolicy
+		optional    []string
+		cancelErr   error
+		errExpected []string
+	}{
+		{
+			name:   "fail-fast cancels running steps",
+			policy: api.ExecutionPolicyFailFast,
+			errExpected: []string{
+				"fail_fast: step blocking was cancelled after step failing failed: context canceled",
+				"step_failed: step failing failed: oopsie",
+			},
+		},
+		{
+			name:      "fail-fast does not mark steps failing on their own as cancelled",
+			policy:    api.ExecutionPolicyFailFast,
+			cancelErr: errors.New("cleanup failed"),
+			errExpected: []string{
+				"step_failed: step blocking failed: cleanup failed",
+				"step_failed: step failing failed: oopsie",
+			},
+		},
+		{
+			name:     "failure of optional step does not cancel running steps",
+			policy:   api.ExecutionPolicyFailFast,
+			optional: []string{"failing"},
+			errExpected: []string{
+				"interrupted: execution cancelled",
+				"step_failed: step blocking failed: context canceled",
+				"step_failed: step failing failed: oopsie",
+			},
+		},
+		{
+			name:   "keep-going does not cancel running steps",
+			policy: api.ExecutionPolicyKeepGoing,
+			errExpected: []string{
+				"interrupted: execution cancelled",
+				"step_failed: step blocking failed: context canceled",
+				"step_failed: step failing failed: oopsie",
+			},
+		},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			blocking := &cancellableStep{fakeStep: fakeStep{name: "blocking", creates: []api.StepLink{api.InternalImageLink("blocking")}}, started: make(chan struct{}), cancelErr: testCase.cancelErr}
+			failing := &notifyingStep{fakeStep: fakeStep{name: "failing", runErr: errors.New("oopsie")}, finished: make(chan struct{})}
+			child := &fakeStep{name: "child", requires: []api.StepLink{api.InternalImageLink("blocking")}}
+
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			if testCase.policy != api.ExecutionPolicyFailFast || len(testCase.optional) > 0 {
+				// the blocking step is not cancelled by the policy, so the
+				// execution is interrupted once both steps have run
+				go func() {
+					<-blocking.started
+					<-failing.finished
+					cancel()
+				}()
+			}
+			_, _, errs := Run(ctx, api.BuildGraph([]api.Step{blocking, failing, child}), WithExecutionPolicy(testCase.policy, testCase.optional...))
+			var actual []string
+			for _, err := range errs {
+				for _, reason := range results.Reasons(err) {
+					actual = append(actual, fmt.Sprintf("%s: %s", reason, err.Error()))
+				}
+			}
+			sort.Strings(actual)
+			if diff := cmp.Diff(testCase.errExpected, actual); diff != "" {
+				t.Errorf("unexpected errors: %s", diff)
+			}
+			if child.numRuns != 0 {
+				t.Errorf("expected child of cancelled step not to run")
+			}
+		})
+	}
+}
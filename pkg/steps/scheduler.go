@@ -0,0 +1,119 @@
+package steps
+
+import (
+	"sort"
+
+	"github.com/openshift/ci-tools/pkg/api"
+)
+
+// StepKind classifies steps for the purpose of limiting how many
+// steps of the same kind run at once.
+type StepKind string
+
+const (
+	StepKindOther  StepKind = ""
+	StepKindBuild  StepKind = "build"
+	StepKindTest   StepKind = "test"
+	StepKindImport StepKind = "import"
+)
+
+// KindReporter may be implemented by steps that belong to a StepKind
+// with its own concurrency limit.
+type KindReporter interface {
+	Kind() StepKind
+}
+
+func kindOf(step api.Step) StepKind {
+	if reporter, ok := step.(KindReporter); ok {
+		return reporter.Kind()
+	}
+	return StepKindOther
+}
+
+// scheduler decides which of the steps that are ready to run may be
+// started, honoring the global and per-kind concurrency limits. Steps
+// that head the longest chains of dependent steps are started first.
+// It is not safe for concurrent use.
+type scheduler struct {
+	maxParallelism int
+	limits         map[StepKind]int
+
+	running     int
+	runningKind map[StepKind]int
+
+	priority map[*api.StepNode]int
+	queue    []*api.StepNode
+}
+
+func newScheduler(graph api.StepGraph, config api.ConcurrencyConfiguration) *scheduler {
+	return &scheduler{
+		maxParallelism: config.MaxParallelism,
+		limits: map[StepKind]int{
+			StepKindBuild:  config.Builds,
+			StepKindTest:   config.Tests,
+			StepKindImport: config.Imports,
+		},
+		runningKind: map[StepKind]int{},
+		priority:    criticalPathLengths(graph),
+	}
+}
+
+// criticalPathLengths determines, for every node, the number of steps
+// in the longest chain that starts with it.
+func criticalPathLengths(graph api.StepGraph) map[*api.StepNode]int {
+	lengths := map[*api.StepNode]int{}
+	// IterateAllEdges visits children before their parents
+	graph.IterateAllEdges(func(node *api.StepNode) {
+		longest := 0
+		for _, child := range node.Children {
+			if lengths[child] > longest {
+				longest = lengths[child]
+			}
+		}
+		lengths[node] = longest + 1
+	})
+	return lengths
+}
+
+// enqueue marks the nodes as ready to run.
+func (s *scheduler) enqueue(nodes ...*api.StepNode) {
+	s.queue = append(s.queue, nodes...)
+	sort.SliceStable(s.queue, func(i, j int) bool {
+		return s.priority[s.queue[i]] > s.priority[s.queue[j]]
+	})
+}
+
+// next removes from the queue and returns the nodes that can be started
+// without exceeding any limit, accounting for them as running.
+func (s *scheduler) next() []*api.StepNode {
+	var ready, waiting []*api.StepNode
+	for _, node := range s.queue {
+		kind := kindOf(node.Step)
+		if s.maxParallelism > 0 && s.running >= s.maxParallelism {
+			waiting = append(waiting, node)
+			continue
+		}
+		if limit := s.limits[kind]; limit > 0 && s.runningKind[kind] >= limit {
+			waiting = append(waiting, node)
+			continue
+		}
+		s.running++
+		s.runningKind[kind]++
+		ready = append(ready, node)
+	}
+	s.queue = waiting
+	return ready
+}
+
+// finished releases the capacity held by a running node.
+func (s *scheduler) finished(node *api.StepNode) {
+	s.running--
+	s.runningKind[kindOf(node.Step)]--
+}
+
+// drain removes and returns all nodes that are still waiting to be started.
+func (s *scheduler) drain() []*api.StepNode {
+	queue := s.queue
+	s.queue = nil
+	return queue
+}
@@ -0,0 +1,139 @@
+package steps
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/openshift/ci-tools/pkg/api"
+)
+
+type fakeKindStep struct {
+	fakeStep
+	kind StepKind
+}
+
+func (f *fakeKindStep) Kind() StepKind { return f.kind }
+
+func TestCriticalPathLengths(t *testing.T) {
+	root := &fakeStep{name: "root", creates: []api.StepLink{api.InternalImageLink(api.PipelineImageStreamTagReferenceRoot)}}
+	src := &fakeStep{name: "src", requires: root.creates, creates: []api.StepLink{api.InternalImageLink(api.PipelineImageStreamTagReferenceSource)}}
+	bin := &fakeStep{name: "bin", requires: src.creates, creates: []api.StepLink{api.InternalImageLink(api.PipelineImageStreamTagReferenceBinaries)}}
+	unit := &fakeStep{name: "unit", requires: src.creates}
+	other := &fakeStep{name: "other"}
+	graph := api.BuildGraph([]api.Step{root, src, bin, unit, other})
+
+	lengths := map[string]int{}
+	for node, length := range criticalPathLengths(graph) {
+		lengths[node.Step.Name()] = length
+	}
+	expected := map[string]int{"root": 3, "src": 2, "bin": 1, "unit": 1, "other": 1}
+	if diff := cmp.Diff(expected, lengths); diff != "" {
+		t.Errorf("incorrect critical path lengths: %s", diff)
+	}
+}
+
+func TestSchedulerNext(t *testing.T) {
+	build := func(name string) *api.StepNode {
+		return &api.StepNode{Step: &fakeKindStep{fakeStep: fakeStep{name: name}, kind: StepKindBuild}}
+	}
+	test := func(name string) *api.StepNode {
+		return &api.StepNode{Step: &fakeKindStep{fakeStep: fakeStep{name: name}, kind: StepKindTest}}
+	}
+	other := func(name string) *api.StepNode {
+		return &api.StepNode{Step: &fakeStep{name: name}}
+	}
+	names := func(nodes []*api.StepNode) []string {
+		var ret []string
+		for _, node := range nodes {
+			ret = append(ret, node.Step.Name())
+		}
+		return ret
+	}
+
+	var testCases = []struct {
+		name      string
+		config    api.ConcurrencyConfiguration
+		nodes     []*api.StepNode
+		priority  map[string]int
+		running   []*api.StepNode
+		expected  []string
+		remaining []string
+	}{
+		{
+			name:     "no limits starts everything",
+			nodes:    []*api.StepNode{build("a"), test("b"), other("c")},
+			expected: []string{"a", "b", "c"},
+		},
+		{
+			name:      "global limit",
+			config:    api.ConcurrencyConfiguration{MaxParallelism: 2},
+			nodes:     []*api.StepNode{build("a"), test("b"), other("c")},
+			expected:  []string{"a", "b"},
+			remaining: []string{"c"},
+		},
+		{
+			name:      "global limit accounts for running steps",
+			config:    api.ConcurrencyConfiguration{MaxParallelism: 2},
+			running:   []*api.StepNode{other("running")},
+			nodes:     []*api.StepNode{build("a"), test("b"), other("c")},
+			expected:  []string{"a"},
+			remaining: []string{"b", "c"},
+		},
+		{
+			name:      "per-kind limit lets other kinds through",
+			config:    api.ConcurrencyConfiguration{Builds: 1},
+			nodes:     []*api.StepNode{build("a"), build("b"), test("c"), other("d")},
+			expected:  []string{"a", "c", "d"},
+			remaining: []string{"b"},
+		},
+		{
+			name:      "longest chains are started first",
+			config:    api.ConcurrencyConfiguration{MaxParallelism: 1},
+			nodes:     []*api.StepNode{build("short"), build("long")},
+			priority:  map[string]int{"short": 1, "long": 5},
+			expected:  []string{"long"},
+			remaining: []string{"short"},
+		},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			s := newScheduler(nil, testCase.config)
+			for _, node := range testCase.nodes {
+				s.priority[node] = testCase.priority[node.Step.Name()]
+			}
+			s.enqueue(testCase.running...)
+			s.next()
+			s.enqueue(testCase.nodes...)
+			if diff := cmp.Diff(testCase.expected, names(s.next())); diff != "" {
+				t.Errorf("incorrect nodes started: %s", diff)
+			}
+			if diff := cmp.Diff(testCase.remaining, names(s.drain())); diff != "" {
+				t.Errorf("incorrect nodes remaining: %s", diff)
+			}
+		})
+	}
+}
+
+func TestRunConcurrencyLimit(t *testing.T) {
+	var steps []api.Step
+	var fakes []*fakeStep
+	for _, name := range []string{"a", "b", "c", "d"} {
+		step := &fakeStep{name: name}
+		fakes = append(fakes, step)
+		steps = append(steps, step)
+	}
+	suites, _, errs := Run(context.Background(), api.BuildGraph(steps), WithConcurrency(api.ConcurrencyConfiguration{MaxParallelism: 1}))
+	if len(errs) != 0 {
+		t.Fatalf("unexpected errors: %v", errs)
+	}
+	if suites.Suites[0].NumTests != 4 {
+		t.Errorf("expected all steps to be reported, got %d", suites.Suites[0].NumTests)
+	}
+	for _, step := range fakes {
+		if step.numRuns != 1 {
+			t.Errorf("step %s did not run just once, but %d times", step.name, step.numRuns)
+		}
+	}
+}
@@ -37,6 +37,7 @@ import (
 	"github.com/openshift/ci-tools/pkg/results"
 	"github.com/openshift/ci-tools/pkg/steps/loggingclient"
 	"github.com/openshift/ci-tools/pkg/steps/utils"
+	"github.com/openshift/ci-tools/pkg/tracing"
 	"github.com/openshift/ci-tools/pkg/util"
 )
 
@@ -531,9 +532,11 @@ func constructMultiArchBuilds(build buildapi.Build, stepArchitectures []string)
 	return ret
 }
 
-func handleBuild(ctx context.Context, client BuildClient, podClient kubernetes.PodClient, build buildapi.Build) error {
+func handleBuild(ctx context.Context, client BuildClient, podClient kubernetes.PodClient, build buildapi.Build) (err error) {
 	const attempts = 5
 	ns, name := build.Namespace, build.Name
+	ctx, span := tracing.Start(ctx, fmt.Sprintf("build %s", name))
+	defer func() { tracing.End(span, err) }()
 	var errs []error
 	if err := wait.ExponentialBackoff(wait.Backoff{Duration: time.Minute, Factor: 1.5, Steps: attempts}, func() (bool, error) {
 		var attempt buildapi.Build
@@ -792,6 +795,8 @@ func (s *sourceStep) Provides() api.ParameterMap {
 
 func (s *sourceStep) Name() string { return s.config.TargetName() }
 
+func (s *sourceStep) Kind() StepKind { return StepKindBuild }
+
 func (s *sourceStep) Description() string {
 	return fmt.Sprintf("Clone the correct source code into an image and tag it as %s", s.config.To)
 }
@@ -270,6 +270,8 @@ func (s *templateExecutionStep) Provides() api.ParameterMap {
 
 func (s *templateExecutionStep) Name() string { return s.template.Name }
 
+func (s *templateExecutionStep) Kind() StepKind { return StepKindTest }
+
 func (s *templateExecutionStep) Description() string {
 	return fmt.Sprintf("Run template %s", s.template.Name)
 }
@@ -8,6 +8,8 @@ import (
 	"time"
 
 	"github.com/sirupsen/logrus"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 
 	coreapi "k8s.io/api/core/v1"
 	kerrors "k8s.io/apimachinery/pkg/api/errors"
@@ -21,6 +23,7 @@ import (
 
 	"github.com/openshift/ci-tools/pkg/api"
 	"github.com/openshift/ci-tools/pkg/kubernetes"
+	"github.com/openshift/ci-tools/pkg/tracing"
 	"github.com/openshift/ci-tools/pkg/util"
 )
 
@@ -135,7 +138,9 @@ func getEvaluator(ctx context.Context, client ctrlruntimeclient.Client, ns, name
 }
 
 // WaitForImportingISTag waits for the tags on the image stream are imported
-func WaitForImportingISTag(ctx context.Context, client ctrlruntimeclient.WithWatch, ns, name string, into *imagev1.ImageStream, tags sets.Set[string], timeout time.Duration) error {
+func WaitForImportingISTag(ctx context.Context, client ctrlruntimeclient.WithWatch, ns, name string, into *imagev1.ImageStream, tags sets.Set[string], timeout time.Duration) (err error) {
+	ctx, span := tracing.Start(ctx, fmt.Sprintf("wait for import %s/%s", ns, name), trace.WithAttributes(attribute.StringSlice("ci.image.tags", sets.List(tags))))
+	defer func() { tracing.End(span, err) }()
 	obj := into
 	if obj == nil {
 		obj = &imagev1.ImageStream{}
@@ -144,10 +149,12 @@ func WaitForImportingISTag(ctx context.Context, client ctrlruntimeclient.WithWat
 }
 
 // ImportTagWithRetries imports image with retries
-func ImportTagWithRetries(ctx context.Context, client ctrlruntimeclient.Client, ns, name, tag, sourcePullSpec string, retries int) (string, error) {
+func ImportTagWithRetries(ctx context.Context, client ctrlruntimeclient.Client, ns, name, tag, sourcePullSpec string, retries int) (_ string, err error) {
 	if sourcePullSpec == "" {
 		return "", fmt.Errorf("sourcePullSpec cannot be empty")
 	}
+	ctx, span := tracing.Start(ctx, fmt.Sprintf("import %s/%s:%s", ns, name, tag), trace.WithAttributes(attribute.String("ci.image.source", sourcePullSpec)))
+	defer func() { tracing.End(span, err) }()
 	var pullSpec string
 	step := 0
 	logger := logrus.WithField("tag", fmt.Sprintf(" %s/%s:%s", ns, name, tag)).WithField("sourcePullSpec", sourcePullSpec)
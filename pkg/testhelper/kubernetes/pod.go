@@ -24,7 +24,9 @@ import (
 
 type FakePodExecutor struct {
 	loggingclient.LoggingClient
-	Failures    sets.Set[string]
+	Failures sets.Set[string]
+	// Evictions is the number of times a pod is evicted before it runs
+	Evictions   map[string]int
 	CreatedPods []*coreapi.Pod
 	lock        sync.Mutex
 }
@@ -69,6 +71,11 @@ func (f *FakePodExecutor) Watch(ctx context.Context, list ctrlruntimeclient.Obje
 }
 
 func (f *FakePodExecutor) process(pod *coreapi.Pod) {
+	if f.evicted(pod.Name) {
+		pod.Status.Phase = coreapi.PodFailed
+		pod.Status.Reason = "Evicted"
+		return
+	}
 	fail := f.Failures.Has(pod.Name)
 	if fail {
 		pod.Status.Phase = coreapi.PodFailed
@@ -86,6 +93,19 @@ func (f *FakePodExecutor) process(pod *coreapi.Pod) {
 	}
 }
 
+// evicted determines whether the current run of the pod is evicted.
+func (f *FakePodExecutor) evicted(name string) bool {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	var runs int
+	for _, pod := range f.CreatedPods {
+		if pod.Name == name {
+			runs++
+		}
+	}
+	return runs > 0 && runs <= f.Evictions[name]
+}
+
 // The fake client version we use (v0.12.3) does not implement field selectors.
 func filter(list ctrlruntimeclient.ObjectList, opts ...ctrlruntimeclient.ListOption) {
 	var o ctrlruntimeclient.ListOptions
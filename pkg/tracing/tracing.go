@@ -0,0 +1,139 @@
+// Package tracing exports OpenTelemetry spans describing the execution
+// of ci-operator, either to a file or to an OTLP collector.
+package tracing
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"sync"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+	coltracepb "go.opentelemetry.io/proto/otlp/collector/trace/v1"
+	tracepb "go.opentelemetry.io/proto/otlp/trace/v1"
+	"google.golang.org/protobuf/encoding/protojson"
+
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+)
+
+const instrumentationName = "github.com/openshift/ci-tools"
+
+// Options holds the configuration for exporting spans.
+type Options struct {
+	file     string
+	endpoint string
+}
+
+// Bind adds flags for the options
+func (o *Options) Bind(flag *flag.FlagSet) {
+	flag.StringVar(&o.file, "trace-file", "", "If set, write the spans of the execution to this file in the OTLP/JSON format.")
+	flag.StringVar(&o.endpoint, "trace-endpoint", "", "If set, export the spans of the execution to the OTLP/HTTP collector at this host:port, e.g. localhost:4318.")
+}
+
+// Setup installs a global tracer provider exporting to the configured
+// destinations. The returned function flushes all pending spans and must
+// be called before the process exits. If no destination is configured,
+// spans are not recorded.
+func (o *Options) Setup(ctx context.Context, attributes ...attribute.KeyValue) (func(context.Context) error, error) {
+	var exporters []sdktrace.SpanExporter
+	if o.file != "" {
+		exporter, err := otlptrace.New(ctx, &fileClient{path: o.file})
+		if err != nil {
+			return nil, fmt.Errorf("could not create file exporter: %w", err)
+		}
+		exporters = append(exporters, exporter)
+	}
+	if o.endpoint != "" {
+		exporter, err := otlptracehttp.New(ctx, otlptracehttp.WithEndpoint(o.endpoint), otlptracehttp.WithInsecure())
+		if err != nil {
+			return nil, fmt.Errorf("could not create OTLP exporter: %w", err)
+		}
+		exporters = append(exporters, exporter)
+	}
+	if len(exporters) == 0 {
+		return func(context.Context) error { return nil }, nil
+	}
+
+	opts := []sdktrace.TracerProviderOption{
+		sdktrace.WithResource(resource.NewSchemaless(append([]attribute.KeyValue{attribute.String("service.name", "ci-operator")}, attributes...)...)),
+	}
+	for _, exporter := range exporters {
+		opts = append(opts, sdktrace.WithBatcher(exporter))
+	}
+	provider := sdktrace.NewTracerProvider(opts...)
+	otel.SetTracerProvider(provider)
+	return provider.Shutdown, nil
+}
+
+// Start creates a span as a child of the span in the context.
+func Start(ctx context.Context, name string, opts ...trace.SpanStartOption) (context.Context, trace.Span) {
+	return otel.Tracer(instrumentationName).Start(ctx, name, opts...)
+}
+
+// End records the outcome on the span and ends it.
+func End(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
+// fileClient writes spans to a file, one OTLP/JSON export request per
+// line, the format read by the file receiver of the OpenTelemetry collector.
+type fileClient struct {
+	path string
+
+	lock sync.Mutex
+	file *os.File
+}
+
+func (c *fileClient) Start(context.Context) error {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	file, err := os.OpenFile(c.path, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0644)
+	if err != nil {
+		return fmt.Errorf("could not open trace file: %w", err)
+	}
+	c.file = file
+	return nil
+}
+
+func (c *fileClient) Stop(context.Context) error {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	if c.file == nil {
+		return nil
+	}
+	var errs []error
+	if err := c.file.Sync(); err != nil {
+		errs = append(errs, err)
+	}
+	if err := c.file.Close(); err != nil {
+		errs = append(errs, err)
+	}
+	c.file = nil
+	return utilerrors.NewAggregate(errs)
+}
+
+func (c *fileClient) UploadTraces(_ context.Context, spans []*tracepb.ResourceSpans) error {
+	raw, err := protojson.Marshal(&coltracepb.ExportTraceServiceRequest{ResourceSpans: spans})
+	if err != nil {
+		return fmt.Errorf("could not marshal spans: %w", err)
+	}
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	if c.file == nil {
+		return fmt.Errorf("trace file %s is not open", c.path)
+	}
+	_, err = c.file.Write(append(raw, '\n'))
+	return err
+}
@@ -0,0 +1,81 @@
+package tracing
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"go.opentelemetry.io/otel/attribute"
+	coltracepb "go.opentelemetry.io/proto/otlp/collector/trace/v1"
+	tracepb "go.opentelemetry.io/proto/otlp/trace/v1"
+	"google.golang.org/protobuf/encoding/protojson"
+)
+
+func TestSetupWithoutDestination(t *testing.T) {
+	shutdown, err := (&Options{}).Setup(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := shutdown(context.Background()); err != nil {
+		t.Errorf("unexpected error on shutdown: %v", err)
+	}
+}
+
+func TestSetupWithFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "trace.json")
+	o := Options{file: path}
+	shutdown, err := o.Setup(context.Background(), attribute.String("ci.job", "job"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	ctx, root := Start(context.Background(), "root")
+	_, child := Start(ctx, "child")
+	End(child, errors.New("oops"))
+	End(root, nil)
+	if err := shutdown(context.Background()); err != nil {
+		t.Fatalf("unexpected error on shutdown: %v", err)
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatalf("could not open trace file: %v", err)
+	}
+	defer f.Close()
+	spans := map[string]*tracepb.Span{}
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var request coltracepb.ExportTraceServiceRequest
+		if err := protojson.Unmarshal(scanner.Bytes(), &request); err != nil {
+			t.Fatalf("could not parse line %q: %v", scanner.Text(), err)
+		}
+		for _, resourceSpans := range request.ResourceSpans {
+			for _, scopeSpans := range resourceSpans.ScopeSpans {
+				for _, span := range scopeSpans.Spans {
+					spans[span.Name] = span
+				}
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		t.Fatalf("could not read trace file: %v", err)
+	}
+
+	if diff := cmp.Diff([]string{"child", "root"}, slices.Sorted(maps.Keys(spans))); diff != "" {
+		t.Fatalf("incorrect spans: %s", diff)
+	}
+	if diff := cmp.Diff(spans["root"].SpanId, spans["child"].ParentSpanId); diff != "" {
+		t.Errorf("child is not parented by root: %s", diff)
+	}
+	if code := spans["child"].Status.GetCode(); code != tracepb.Status_STATUS_CODE_ERROR {
+		t.Errorf("expected child to have an error status, got %v", code)
+	}
+	if code := spans["root"].Status.GetCode(); code == tracepb.Status_STATUS_CODE_ERROR {
+		t.Errorf("expected root to not have an error status")
+	}
+}
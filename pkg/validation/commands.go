@@ -0,0 +1,482 @@
+package validation
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	"github.com/openshift/ci-tools/pkg/api"
+)
+
+// CommandIssueKind identifies a problem found by the static analysis of step
+// commands
+type CommandIssueKind string
+
+const (
+	// UndeclaredVariable is a variable the commands read without a default
+	// that is neither declared by the step, set by ci-operator or assigned by
+	// the commands themselves
+	UndeclaredVariable CommandIssueKind = "undeclared-variable"
+	// UnusedVariable is a parameter, dependency or lease variable declared by
+	// the step that the commands never read
+	UnusedVariable CommandIssueKind = "unused-variable"
+	// MissingPipefail is a pipeline in commands that do not set pipefail, so
+	// that failures of all but the last command are ignored
+	MissingPipefail CommandIssueKind = "missing-pipefail"
+)
+
+// CommandIssue is a problem found in the commands of a step. The analysis is
+// heuristic, so issues are meant to be reported as warnings.
+type CommandIssue struct {
+	Kind     CommandIssueKind
+	Variable string
+	// Line is the line of the commands the issue was found on, zero when the
+	// issue is not tied to a line
+	Line    int
+	Message string
+}
+
+func (i CommandIssue) String() string {
+	if i.Line == 0 {
+		return i.Message
+	}
+	return fmt.Sprintf("line %d: %s", i.Line, i.Message)
+}
+
+// wellKnownStepVariables are set by ci-operator, the pod or the shell
+var wellKnownStepVariables = sets.New[string](
+	// ci-operator
+	"ARTIFACT_DIR", "SHARED_DIR", "NAMESPACE", "JOB_NAME_SAFE", "JOB_NAME_HASH", "UNIQUE_HASH",
+	"CLUSTER_TYPE", "CLUSTER_PROFILE_DIR", "CLUSTER_PROFILE_NAME", "LEASED_RESOURCE", "IP_POOL_AVAILABLE",
+	"KUBECONFIG", "KUBECONFIGMINIMAL", "KUBEADMIN_PASSWORD_FILE", "IMAGE_FORMAT", "CLI_DIR",
+	"OPENSHIFT_CI", "CI_EXTERNAL_STEP_REQUEST", "GIT_CONFIG_COUNT",
+	// Prow
+	"CI", "JOB_NAME", "JOB_SPEC", "JOB_TYPE", "BUILD_ID", "PROW_JOB_ID", "REPO_OWNER", "REPO_NAME",
+	"PULL_BASE_REF", "PULL_BASE_SHA", "PULL_HEAD_REF", "PULL_NUMBER", "PULL_PULL_SHA", "PULL_REFS", "PULL_TITLE",
+	// the pod and the shell
+	"HOME", "PATH", "PWD", "OLDPWD", "HOSTNAME", "USER", "UID", "EUID", "SHELL", "TMPDIR", "LANG", "TERM",
+	"IFS", "RANDOM", "SECONDS", "LINENO", "REPLY", "OPTARG", "OPTIND", "PPID", "BASHPID", "BASH_SOURCE",
+	"BASH_LINENO", "BASH_REMATCH", "BASH_VERSION", "FUNCNAME", "PIPESTATUS", "GROUPS", "HOSTTYPE", "OSTYPE",
+	"EPOCHSECONDS", "EPOCHREALTIME",
+)
+
+// wellKnownStepVariablePrefixes are prefixes of the variables ci-operator
+// sets for each release and lease
+var wellKnownStepVariablePrefixes = []string{"RELEASE_IMAGE_", "ORIGINAL_RELEASE_IMAGE_", "KUBERNETES_"}
+
+func isWellKnownStepVariable(name string) bool {
+	if wellKnownStepVariables.Has(name) {
+		return true
+	}
+	for _, prefix := range wellKnownStepVariablePrefixes {
+		if strings.HasPrefix(name, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// AnalyzeStepCommands statically analyzes the commands of a step, reporting
+// variables read but never declared, declared variables never read and
+// pipelines without pipefail. Commands that source other files or use `eval`
+// can define any variable, so undeclared variables are not reported for them.
+func AnalyzeStepCommands(step api.LiteralTestStep) []CommandIssue {
+	a := analyzeCommands(step.Commands)
+	declared := map[string]string{}
+	for _, env := range step.Environment {
+		declared[env.Name] = "parameter"
+	}
+	for _, dependency := range step.Dependencies {
+		declared[dependency.Env] = "dependency variable"
+	}
+	for _, lease := range step.Leases {
+		declared[lease.Env] = "lease variable"
+	}
+
+	var ret []CommandIssue
+	if !a.sources {
+		for _, name := range sets.List(sets.KeySet(a.reads)) {
+			if _, ok := declared[name]; ok || a.assigned.Has(name) || isWellKnownStepVariable(name) {
+				continue
+			}
+			ret = append(ret, CommandIssue{
+				Kind:     UndeclaredVariable,
+				Variable: name,
+				Line:     a.reads[name],
+				Message:  fmt.Sprintf("variable %s is read but not declared in `env`, `dependencies` or `leases`", name),
+			})
+		}
+	}
+	for _, name := range sets.List(sets.KeySet(declared)) {
+		if a.referenced.Has(name) || mentionsWord(step.Commands, name) {
+			continue
+		}
+		ret = append(ret, CommandIssue{
+			Kind:     UnusedVariable,
+			Variable: name,
+			Message:  fmt.Sprintf("%s %s is never read by the commands", declared[name], name),
+		})
+	}
+	if a.pipeline != 0 && !a.pipefail && !a.sources {
+		ret = append(ret, CommandIssue{
+			Kind:    MissingPipefail,
+			Line:    a.pipeline,
+			Message: "commands use a pipeline but do not `set -o pipefail`, so failures in the pipeline are ignored",
+		})
+	}
+	sort.SliceStable(ret, func(i, j int) bool { return ret[i].Line < ret[j].Line })
+	return ret
+}
+
+// mentionsWord determines whether the word appears in the commands at all,
+// e.g. when read by an interpreter other than the shell
+func mentionsWord(commands, word string) bool {
+	for offset := 0; offset < len(commands); {
+		i := strings.Index(commands[offset:], word)
+		if i == -1 {
+			return false
+		}
+		start, end := offset+i, offset+i+len(word)
+		if (start == 0 || !isWordByte(commands[start-1])) && (end == len(commands) || !isWordByte(commands[end])) {
+			return true
+		}
+		offset = start + 1
+	}
+	return false
+}
+
+func isWordByte(c byte) bool {
+	return c == '_' || 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z' || '0' <= c && c <= '9'
+}
+
+// commandAnalysis is the result of scanning shell commands
+type commandAnalysis struct {
+	// reads are the variables expanded without a default value, with the
+	// line of their first expansion
+	reads map[string]int
+	// referenced are all expanded variables, with or without a default
+	referenced sets.Set[string]
+	// assigned are the variables the commands assign
+	assigned sets.Set[string]
+	// sources is set when the commands source files or evaluate code
+	sources bool
+	// pipeline is the line of the first pipeline, zero if there is none
+	pipeline int
+	pipefail bool
+}
+
+type scanFrame int
+
+const (
+	frameCode scanFrame = iota
+	frameSingleQuoted
+	frameDoubleQuoted
+	frameSubstitution
+)
+
+type heredoc struct {
+	delimiter string
+	quoted    bool
+}
+
+// analyzeCommands scans bash commands. It is not a full parser: it tracks
+// quoting, comments, command substitutions and here-documents well enough to
+// find variable expansions, and looks at the unquoted code for assignments
+// and pipelines.
+func analyzeCommands(commands string) commandAnalysis {
+	a := commandAnalysis{reads: map[string]int{}, referenced: sets.New[string](), assigned: sets.New[string]()}
+	// code holds the unquoted text of the commands, with quoted content and
+	// comments blanked out, line by line
+	var code strings.Builder
+	stack := []scanFrame{frameCode}
+	depth := []int{0}
+	var pending []heredoc
+	top := func() scanFrame { return stack[len(stack)-1] }
+	push := func(f scanFrame) { stack, depth = append(stack, f), append(depth, 0) }
+	pop := func() {
+		if len(stack) > 1 {
+			stack, depth = stack[:len(stack)-1], depth[:len(depth)-1]
+		}
+	}
+	lines := strings.Split(commands, "\n")
+	for lineIndex := 0; lineIndex < len(lines); lineIndex++ {
+		text, line := lines[lineIndex], lineIndex+1
+		for i := 0; i < len(text); i++ {
+			c := text[i]
+			if top() == frameSingleQuoted {
+				// nothing is expanded until the closing quote, which may be
+				// on a later line
+				if c == '\'' {
+					pop()
+					code.WriteByte(c)
+				} else {
+					code.WriteByte(' ')
+				}
+				continue
+			}
+			inCode := top() != frameDoubleQuoted
+			switch {
+			case c == '\\':
+				i++
+				code.WriteByte(' ')
+				continue
+			case c == '$':
+				name, defaulted, next := parseExpansion(text, i)
+				if name != "" {
+					a.referenced.Insert(name)
+					if _, seen := a.reads[name]; !defaulted && !seen {
+						a.reads[name] = line
+					}
+				}
+				if next > i+1 && text[i+1] == '(' {
+					push(frameSubstitution)
+					code.WriteString("$(")
+					i++
+					continue
+				}
+				if inCode {
+					code.WriteString(text[i:next])
+				}
+				i = next - 1
+				continue
+			}
+			if !inCode {
+				if c == '"' {
+					pop()
+					code.WriteByte('"')
+				}
+				continue
+			}
+			switch {
+			case c == '\'':
+				push(frameSingleQuoted)
+				code.WriteByte(c)
+			case c == '"':
+				push(frameDoubleQuoted)
+				code.WriteByte('"')
+			case c == '#' && (i == 0 || strings.IndexByte(" \t;&|(", text[i-1]) != -1):
+				i = len(text)
+			case c == '(' && top() == frameSubstitution:
+				depth[len(depth)-1]++
+				code.WriteByte(c)
+			case c == ')' && top() == frameSubstitution:
+				if depth[len(depth)-1] == 0 {
+					pop()
+				} else {
+					depth[len(depth)-1]--
+				}
+				code.WriteByte(c)
+			case c == '<' && strings.HasPrefix(text[i:], "<<") && !strings.HasPrefix(text[i:], "<<<"):
+				h, next := parseHeredoc(text, i)
+				if h.delimiter != "" {
+					pending = append(pending, h)
+				}
+				code.WriteString(text[i:next])
+				i = next - 1
+			default:
+				code.WriteByte(c)
+			}
+		}
+		code.WriteByte('\n')
+		// here-document bodies start on the line after their operator
+		for _, h := range pending {
+			for lineIndex+1 < len(lines) {
+				lineIndex++
+				body := lines[lineIndex]
+				if strings.TrimSpace(body) == h.delimiter {
+					break
+				}
+				if !h.quoted {
+					for i := 0; i < len(body); i++ {
+						if body[i] == '\\' {
+							i++
+							continue
+						}
+						if body[i] != '$' {
+							continue
+						}
+						name, defaulted, next := parseExpansion(body, i)
+						if name != "" {
+							a.referenced.Insert(name)
+							if _, seen := a.reads[name]; !defaulted && !seen {
+								a.reads[name] = lineIndex + 1
+							}
+						}
+						i = next - 1
+					}
+				}
+				code.WriteByte('\n')
+			}
+			code.WriteByte('\n')
+		}
+		pending = nil
+	}
+	a.scanCode(code.String())
+	return a
+}
+
+var nameExpression = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*`)
+
+// parseExpansion parses the expansion starting with the `$` at text[i],
+// returning the expanded variable, whether a default value is provided for
+// it, and the index after the part of the expansion that was consumed.
+// Positional and special parameters are not reported.
+func parseExpansion(text string, i int) (string, bool, int) {
+	if i+1 >= len(text) {
+		return "", false, i + 1
+	}
+	switch next := text[i+1]; {
+	case next == '{':
+		start := i + 2
+		if start < len(text) && (text[start] == '#' || text[start] == '!') {
+			start++
+		}
+		name := nameExpression.FindString(text[start:])
+		if name == "" {
+			return "", false, i + 2
+		}
+		end := start + len(name)
+		rest := text[end:]
+		defaulted := false
+		for _, operator := range []string{":-", ":=", ":+", "-", "=", "+"} {
+			if strings.HasPrefix(rest, operator) {
+				defaulted = true
+				break
+			}
+		}
+		return name, defaulted, end
+	case next == '(':
+		return "", false, i + 2
+	default:
+		name := nameExpression.FindString(text[i+1:])
+		if name == "" {
+			return "", false, i + 2
+		}
+		return name, false, i + 1 + len(name)
+	}
+}
+
+// parseHeredoc parses the here-document operator at text[i], returning the
+// here-document and the index after its delimiter
+func parseHeredoc(text string, i int) (heredoc, int) {
+	j := i + 2
+	if j < len(text) && text[j] == '-' {
+		j++
+	}
+	for j < len(text) && (text[j] == ' ' || text[j] == '\t') {
+		j++
+	}
+	if j >= len(text) {
+		return heredoc{}, j
+	}
+	if quote := text[j]; quote == '\'' || quote == '"' {
+		end := strings.IndexByte(text[j+1:], quote)
+		if end == -1 {
+			return heredoc{}, len(text)
+		}
+		return heredoc{delimiter: text[j+1 : j+1+end], quoted: true}, j + end + 2
+	}
+	start := j
+	for j < len(text) && strings.IndexByte(" \t;&|()<>", text[j]) == -1 {
+		j++
+	}
+	delimiter := text[start:j]
+	if strings.HasPrefix(delimiter, "\\") {
+		return heredoc{delimiter: delimiter[1:], quoted: true}, j
+	}
+	return heredoc{delimiter: delimiter}, j
+}
+
+var (
+	commandSeparators = regexp.MustCompile(`&&|\|\||[;&|(){}\x60]`)
+	assignmentWord    = regexp.MustCompile(`^([A-Za-z_][A-Za-z0-9_]*)(\[[^\]]*\])?\+?=`)
+	casePattern       = regexp.MustCompile(`^\s*[^\s()|]+(\s*\|\s*[^\s()|]+)+\s*\)`)
+	pipe              = regexp.MustCompile(`(^|[^|])\|([^|]|$)`)
+	shellKeywords     = sets.New[string]("then", "do", "else", "elif", "if", "while", "until", "!", "time", "exec")
+	// optionsWithArgument are the options of `read` and `mapfile` that take
+	// an argument
+	optionsWithArgument = map[string]sets.Set[string]{
+		"read":      sets.New[string]("-d", "-i", "-n", "-N", "-p", "-t", "-u"),
+		"mapfile":   sets.New[string]("-d", "-n", "-O", "-s", "-u", "-C", "-c"),
+		"readarray": sets.New[string]("-d", "-n", "-O", "-s", "-u", "-C", "-c"),
+	}
+)
+
+// scanCode looks for assignments, sourced files, pipelines and pipefail in
+// the unquoted code of the commands
+func (a *commandAnalysis) scanCode(code string) {
+	assign := func(word string) {
+		if name := nameExpression.FindString(word); name != "" && (len(name) == len(word) || strings.IndexByte("=[+", word[len(name)]) != -1) {
+			a.assigned.Insert(name)
+		}
+	}
+	for i, line := range strings.Split(code, "\n") {
+		if a.pipeline == 0 && !casePattern.MatchString(line) && pipe.MatchString(line) {
+			a.pipeline = i + 1
+		}
+		for _, command := range commandSeparators.Split(line, -1) {
+			fields := strings.Fields(command)
+			for len(fields) > 0 && shellKeywords.Has(fields[0]) {
+				fields = fields[1:]
+			}
+			for len(fields) > 0 {
+				match := assignmentWord.FindStringSubmatch(fields[0])
+				if match == nil {
+					break
+				}
+				a.assigned.Insert(match[1])
+				fields = fields[1:]
+			}
+			if len(fields) == 0 {
+				continue
+			}
+			args := fields[1:]
+			switch fields[0] {
+			case "export", "local", "declare", "typeset", "readonly":
+				for _, arg := range args {
+					if !strings.HasPrefix(arg, "-") {
+						assign(arg)
+					}
+				}
+			case "read", "mapfile", "readarray":
+				for j := 0; j < len(args); j++ {
+					switch {
+					case args[j] == "-a" && j+1 < len(args):
+						assign(args[j+1])
+						j++
+					case optionsWithArgument[fields[0]].Has(args[j]):
+						j++
+					case strings.HasPrefix(args[j], "-"):
+					case strings.HasPrefix(args[j], "<") || strings.HasPrefix(args[j], ">"):
+						j = len(args)
+					default:
+						assign(args[j])
+					}
+				}
+			case "for", "select":
+				if len(args) > 0 {
+					assign(args[0])
+				}
+			case "getopts":
+				if len(args) > 1 {
+					assign(args[1])
+				}
+			case "printf":
+				if len(args) > 1 && args[0] == "-v" {
+					assign(args[1])
+				}
+			case "source", ".", "eval":
+				a.sources = true
+			case "set":
+				for _, arg := range args {
+					if arg == "pipefail" {
+						a.pipefail = true
+					}
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,129 @@
+package validation
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/openshift/ci-tools/pkg/api"
+)
+
+func TestAnalyzeStepCommands(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		step     api.LiteralTestStep
+		expected []CommandIssue
+	}{
+		{
+			name: "declared, injected and assigned variables",
+			step: api.LiteralTestStep{
+				Commands: `set -o nounset
+set -o pipefail
+export CONFIG="${SHARED_DIR}/config"
+local count=0 names
+declare -A seen
+read -r first second < "${ARTIFACT_DIR}/input"
+mapfile -t lines < <(cat "$CONFIG")
+printf -v stamp '%s' "$(date)"
+for item in "${lines[@]}"; do
+	count=$((count + 1))
+	seen[$item]=1
+done
+while getopts "a:" opt; do echo "$opt $OPTARG"; done
+echo "$first $second $stamp $names ${#seen[@]} $PARAM $SOURCE $LEASE ${RELEASE_IMAGE_LATEST}" | tee -a "$ARTIFACT_DIR/out"
+`,
+				Environment:  []api.StepParameter{{Name: "PARAM"}},
+				Dependencies: []api.StepDependency{{Name: "src", Env: "SOURCE"}},
+				Leases:       []api.StepLease{{ResourceType: "aws-quota-slice", Env: "LEASE"}},
+			},
+		},
+		{
+			name: "undeclared and unused variables",
+			step: api.LiteralTestStep{
+				Commands: `echo "$MISSING"
+echo ${ALSO_MISSING}/path ${OPTIONAL:-default} ${#LENGTH}
+echo "${MISSING}"
+python3 -c 'import os; print(os.environ["READ_BY_PYTHON"])'
+`,
+				Environment:  []api.StepParameter{{Name: "UNUSED"}, {Name: "READ_BY_PYTHON"}},
+				Dependencies: []api.StepDependency{{Name: "src", Env: "UNUSED_DEPENDENCY"}},
+				Leases:       []api.StepLease{{ResourceType: "aws-quota-slice", Env: "UNUSED_LEASE"}},
+			},
+			expected: []CommandIssue{
+				{Kind: UnusedVariable, Variable: "UNUSED", Message: "parameter UNUSED is never read by the commands"},
+				{Kind: UnusedVariable, Variable: "UNUSED_DEPENDENCY", Message: "dependency variable UNUSED_DEPENDENCY is never read by the commands"},
+				{Kind: UnusedVariable, Variable: "UNUSED_LEASE", Message: "lease variable UNUSED_LEASE is never read by the commands"},
+				{Kind: UndeclaredVariable, Variable: "MISSING", Line: 1, Message: "variable MISSING is read but not declared in `env`, `dependencies` or `leases`"},
+				{Kind: UndeclaredVariable, Variable: "ALSO_MISSING", Line: 2, Message: "variable ALSO_MISSING is read but not declared in `env`, `dependencies` or `leases`"},
+				{Kind: UndeclaredVariable, Variable: "LENGTH", Line: 2, Message: "variable LENGTH is read but not declared in `env`, `dependencies` or `leases`"},
+			},
+		},
+		{
+			name: "quoting, comments and here-documents",
+			step: api.LiteralTestStep{
+				Commands: `# echo $IN_COMMENT
+echo '$SINGLE_QUOTED' "\$ESCAPED" "$(echo "$NESTED")" # $TRAILING_COMMENT
+cat <<'EOF'
+$QUOTED_HEREDOC
+EOF
+cat <<-EOF > file
+	$EXPANDED_HEREDOC
+	EOF
+echo $1 $@ $# $? ${10} $((1 + 2))
+`,
+			},
+			expected: []CommandIssue{
+				{Kind: UndeclaredVariable, Variable: "NESTED", Line: 2, Message: "variable NESTED is read but not declared in `env`, `dependencies` or `leases`"},
+				{Kind: UndeclaredVariable, Variable: "EXPANDED_HEREDOC", Line: 7, Message: "variable EXPANDED_HEREDOC is read but not declared in `env`, `dependencies` or `leases`"},
+			},
+		},
+		{
+			name: "single-quoted strings spanning lines",
+			step: api.LiteralTestStep{
+				Commands: `python3 -c '
+import os
+print("$NOT_EXPANDED" | os.environ["ALSO_NOT_EXPANDED"])
+'
+echo "$AFTER_QUOTE"
+`,
+			},
+			expected: []CommandIssue{
+				{Kind: UndeclaredVariable, Variable: "AFTER_QUOTE", Line: 5, Message: "variable AFTER_QUOTE is read but not declared in `env`, `dependencies` or `leases`"},
+			},
+		},
+		{
+			name: "sourced files can define any variable",
+			step: api.LiteralTestStep{
+				Commands: `source "${SHARED_DIR}/env"
+echo "$FROM_ENV_FILE" | grep value
+`,
+			},
+		},
+		{
+			name: "pipeline without pipefail",
+			step: api.LiteralTestStep{
+				Commands: `set -o errexit
+case "$CLUSTER_TYPE" in
+aws|gcp) echo cloud || true ;;
+esac
+oc get pods |& grep Running
+`,
+			},
+			expected: []CommandIssue{
+				{Kind: MissingPipefail, Line: 5, Message: "commands use a pipeline but do not `set -o pipefail`, so failures in the pipeline are ignored"},
+			},
+		},
+		{
+			name: "pipefail set with combined options",
+			step: api.LiteralTestStep{
+				Commands: "set -euo pipefail\noc get pods | grep Running\n",
+			},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			if diff := cmp.Diff(tc.expected, AnalyzeStepCommands(tc.step)); diff != "" {
+				t.Errorf("unexpected issues: %s", diff)
+			}
+		})
+	}
+}
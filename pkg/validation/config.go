@@ -568,6 +568,28 @@ func validateReleaseBuildConfiguration(input *api.ReleaseBuildConfiguration, org
 	}
 
 	validationErrors = append(validationErrors, validateResources("resources", input.Resources)...)
+	validationErrors = append(validationErrors, validateConcurrency("concurrency", input.Concurrency)...)
+	return validationErrors
+}
+
+func validateConcurrency(fieldRoot string, concurrency *api.ConcurrencyConfiguration) []error {
+	if concurrency == nil {
+		return nil
+	}
+	var validationErrors []error
+	for _, limit := range []struct {
+		field string
+		value int
+	}{
+		{field: "max_parallelism", value: concurrency.MaxParallelism},
+		{field: "builds", value: concurrency.Builds},
+		{field: "tests", value: concurrency.Tests},
+		{field: "imports", value: concurrency.Imports},
+	} {
+		if limit.value < 0 {
+			validationErrors = append(validationErrors, fmt.Errorf("%s.%s: limit cannot be negative", fieldRoot, limit.field))
+		}
+	}
 	return validationErrors
 }
 
@@ -656,6 +678,7 @@ func Observer(observer api.Observer) []error {
 		errs = append(errs, fmt.Errorf("%s.commands cannot be empty", fieldRoot))
 	}
 	errs = append(errs, validateResourceRequirements(fieldRoot+".resources", observer.Resources)...)
+	errs = append(errs, validateParameterSchemas(newContext(fieldPath(fieldRoot+".env"), nil, nil, nil), observer.Environment)...)
 	// we're validating unresolved configuration outside of a full test config, so
 	// we cannot know the releases that may or may not be contained in a config using
 	// this observer in the future. This technically disallows users from using `from:`
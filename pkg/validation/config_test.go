@@ -391,6 +391,37 @@ func TestValidateResources(t *testing.T) {
 	}
 }
 
+func TestValidateConcurrency(t *testing.T) {
+	for _, testCase := range []struct {
+		name     string
+		input    *api.ConcurrencyConfiguration
+		expected []error
+	}{
+		{
+			name: "no configuration is valid",
+		},
+		{
+			name:  "positive limits are valid",
+			input: &api.ConcurrencyConfiguration{MaxParallelism: 10, Builds: 4, Tests: 2, Imports: 3},
+		},
+		{
+			name:  "negative limits are invalid",
+			input: &api.ConcurrencyConfiguration{MaxParallelism: -1, Imports: -3},
+			expected: []error{
+				errors.New("concurrency.max_parallelism: limit cannot be negative"),
+				errors.New("concurrency.imports: limit cannot be negative"),
+			},
+		},
+	} {
+		t.Run(testCase.name, func(t *testing.T) {
+			errs := validateConcurrency("concurrency", testCase.input)
+			if diff := cmp.Diff(testCase.expected, errs, testhelper.EquateErrorMessage); diff != "" {
+				t.Errorf("unexpected errors: %s", diff)
+			}
+		})
+	}
+}
+
 func TestValidatePromotion(t *testing.T) {
 	var testCases = []struct {
 		name                    string
@@ -6,6 +6,7 @@ import (
 
 	utilerrors "k8s.io/apimachinery/pkg/util/errors"
 	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apimachinery/pkg/util/validation"
 
 	"github.com/openshift/ci-tools/pkg/api"
 )
@@ -20,6 +21,7 @@ type pipelineImageSet map[api.PipelineImageStreamTagReference]sets.Empty
 func IsValidGraphConfiguration(rawSteps []api.StepConfiguration) error {
 	var ret []error
 	var containerTests, multiStageTests []*api.TestStepConfiguration
+	var externalSteps []*api.ExternalStepConfiguration
 	names := sets.New[string]()
 	pipelineImages := pipelineImageSet{
 		// `src` can only be validated at runtime
@@ -75,6 +77,23 @@ func IsValidGraphConfiguration(rawSteps []api.StepConfiguration) error {
 		} else if c := s.ProjectDirectoryImageBuildInputs; c != nil {
 			addName(string(api.PipelineImageStreamTagReferenceRoot))
 			pipelineImages[api.PipelineImageStreamTagReferenceRoot] = sets.Empty{}
+		} else if c := s.ExternalStepConfiguration; c != nil {
+			addName(c.TargetName())
+			for _, tag := range c.Creates {
+				pipelineImages[tag] = sets.Empty{}
+			}
+			externalSteps = append(externalSteps, c)
+			ret = append(ret, validateExternalStep(c)...)
+		}
+	}
+	// multi-stage tests run as a service account named after the test
+	serviceAccounts := sets.New[string]()
+	for _, t := range multiStageTests {
+		serviceAccounts.Insert(t.As)
+	}
+	for _, c := range externalSteps {
+		if c.As != "" && serviceAccounts.Has(c.ServiceAccountName()) {
+			ret = append(ret, fmt.Errorf("raw_steps[%s].external_step.as: service account %s is also used by test %s", c.As, c.ServiceAccountName(), c.ServiceAccountName()))
 		}
 	}
 	for _, t := range containerTests {
@@ -86,6 +105,18 @@ func IsValidGraphConfiguration(rawSteps []api.StepConfiguration) error {
 	return utilerrors.NewAggregate(ret)
 }
 
+func validateExternalStep(c *api.ExternalStepConfiguration) (ret []error) {
+	if c.As == "" {
+		ret = append(ret, errors.New("raw_steps: external_step.as is required"))
+	} else if len(validation.IsDNS1123Subdomain(c.ServiceAccountName())) != 0 {
+		ret = append(ret, fmt.Errorf("raw_steps[%s].external_step.as: '%s' is not a valid Kubernetes object name", c.As, c.As))
+	}
+	if c.Image == "" {
+		ret = append(ret, fmt.Errorf("raw_steps[%s].external_step.image is required", c.As))
+	}
+	return
+}
+
 func validateContainerTest(
 	pipelineImages pipelineImageSet,
 	s *api.TestStepConfiguration,
@@ -378,3 +378,63 @@ func TestIsValidGraph_MultiStageTestFrom(t *testing.T) {
 		})
 	}
 }
+
+func TestIsValidGraph_ExternalStep(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		step api.ExternalStepConfiguration
+		// test is the name of a multi-stage test in the configuration
+		test     string
+		expected error
+	}{{
+		name: "valid",
+		step: api.ExternalStepConfiguration{As: "custom", Image: "quay.io/org/custom:latest", Creates: []api.PipelineImageStreamTagReference{"custom-image"}},
+	}, {
+		name: "missing fields",
+		step: api.ExternalStepConfiguration{},
+		expected: utilerrors.NewAggregate([]error{
+			errors.New("raw_steps: external_step.as is required"),
+			errors.New("raw_steps[].external_step.image is required"),
+		}),
+	}, {
+		name:     "duplicate target",
+		step:     api.ExternalStepConfiguration{As: "src", Image: "quay.io/org/custom:latest"},
+		expected: utilerrors.NewAggregate([]error{errors.New("configuration contains duplicate target: src")}),
+	}, {
+		name:     "invalid name",
+		step:     api.ExternalStepConfiguration{As: "Custom_Step", Image: "quay.io/org/custom:latest"},
+		expected: utilerrors.NewAggregate([]error{errors.New("raw_steps[Custom_Step].external_step.as: 'Custom_Step' is not a valid Kubernetes object name")}),
+	}, {
+		name:     "service account shared with a test",
+		step:     api.ExternalStepConfiguration{As: "custom", Image: "quay.io/org/custom:latest"},
+		test:     "external-custom",
+		expected: utilerrors.NewAggregate([]error{errors.New("raw_steps[custom].external_step.as: service account external-custom is also used by test external-custom")}),
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			config := api.ReleaseBuildConfiguration{
+				RawSteps: []api.StepConfiguration{{ExternalStepConfiguration: &tc.step}},
+				Tests: []api.TestStepConfiguration{{
+					As:                         "test",
+					ContainerTestConfiguration: &api.ContainerTestConfiguration{From: "custom-image"},
+				}},
+			}
+			if len(tc.step.Creates) == 0 {
+				config.Tests = nil
+			}
+			if tc.test != "" {
+				config.Tests = append(config.Tests, api.TestStepConfiguration{
+					As:                                 tc.test,
+					MultiStageTestConfigurationLiteral: &api.MultiStageTestConfigurationLiteral{},
+				})
+			}
+			graphConf := defaults.FromConfigStatic(&config)
+			graphConf.Steps = append(graphConf.Steps, api.StepConfiguration{
+				SourceStepConfiguration: &api.SourceStepConfiguration{
+					To: api.PipelineImageStreamTagReferenceSource,
+				},
+			})
+			err := IsValidGraphConfiguration(graphConf.Steps)
+			testhelper.Diff(t, "error", err, tc.expected, testhelper.EquateErrorMessage)
+		})
+	}
+}
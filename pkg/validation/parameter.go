@@ -0,0 +1,121 @@
+package validation
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"sync"
+
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	"github.com/openshift/ci-tools/pkg/api"
+)
+
+var stepParameterTypes = sets.New[api.StepParameterType](
+	api.StepParameterTypeString,
+	api.StepParameterTypeInteger,
+	api.StepParameterTypeBoolean,
+)
+
+// validateParameterSchemas verifies that the schema of each parameter is
+// valid and accepts the default value and enum values of the parameter.
+func validateParameterSchemas(context *context, params []api.StepParameter) (ret []error) {
+	for i, param := range params {
+		context := context.addIndex(i)
+		if param.Type != "" && !stepParameterTypes.Has(param.Type) {
+			ret = append(ret, context.errorf("parameter %s: invalid type %q, expected one of %v", param.Name, param.Type, sets.List(stepParameterTypes)))
+			continue
+		}
+		if param.Pattern != "" {
+			if _, err := parameterPattern(param.Pattern); err != nil {
+				ret = append(ret, context.errorf("parameter %s: invalid pattern: %v", param.Name, err))
+				continue
+			}
+		}
+		for _, value := range param.Enum {
+			if value == "" {
+				continue
+			}
+			if err := validateParameterType(param, value); err != nil {
+				ret = append(ret, context.errorf("parameter %s: invalid enum value: %v", param.Name, err))
+			}
+		}
+		if param.Default != nil {
+			if err := StepParameterValue(param, *param.Default); err != nil {
+				ret = append(ret, context.errorf("parameter %s: invalid default: %v", param.Name, err))
+			}
+		}
+	}
+	return
+}
+
+// StepParameterValue verifies that a value is accepted by the schema of a
+// step parameter. The empty value is always accepted.
+func StepParameterValue(param api.StepParameter, value string) error {
+	if value == "" {
+		return nil
+	}
+	if err := validateParameterType(param, value); err != nil {
+		return err
+	}
+	if len(param.Enum) != 0 {
+		found := false
+		for _, v := range param.Enum {
+			if v == value {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return fmt.Errorf("value %q is not one of %q", value, param.Enum)
+		}
+	}
+	return nil
+}
+
+// validateParameterType verifies that a value is of the type of the parameter
+// and matches its pattern.
+func validateParameterType(param api.StepParameter, value string) error {
+	switch param.Type {
+	case api.StepParameterTypeInteger:
+		if _, err := strconv.ParseInt(value, 10, 64); err != nil {
+			return fmt.Errorf("value %q is not an integer", value)
+		}
+	case api.StepParameterTypeBoolean:
+		if value != "true" && value != "false" {
+			return fmt.Errorf("value %q is not a boolean, expected true or false", value)
+		}
+	}
+	if param.Pattern != "" {
+		pattern, err := parameterPattern(param.Pattern)
+		if err != nil {
+			return fmt.Errorf("invalid pattern: %w", err)
+		}
+		if !pattern.MatchString(value) {
+			return fmt.Errorf("value %q does not match pattern %q", value, param.Pattern)
+		}
+	}
+	return nil
+}
+
+// parameterPatterns caches the compiled patterns of parameters, as the values
+// of a parameter are validated for every test that uses the step.
+var parameterPatterns sync.Map
+
+// parameterPattern compiles the pattern of a parameter, which has to match
+// the whole value.
+func parameterPattern(pattern string) (*regexp.Regexp, error) {
+	if compiled, ok := parameterPatterns.Load(pattern); ok {
+		return compiled.(*regexp.Regexp), nil
+	}
+	compiled, err := regexp.Compile(fmt.Sprintf("^(?:%s)$", pattern))
+	if err != nil {
+		// report the error for the pattern as it was written
+		if _, rawErr := regexp.Compile(pattern); rawErr != nil {
+			return nil, rawErr
+		}
+		return nil, err
+	}
+	parameterPatterns.Store(pattern, compiled)
+	return compiled, nil
+}
@@ -0,0 +1,110 @@
+package validation
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/openshift/ci-tools/pkg/api"
+	"github.com/openshift/ci-tools/pkg/testhelper"
+)
+
+func TestValidateParameterSchemas(t *testing.T) {
+	empty, three, yes, zone := "", "3", "yes", "us-east-1a"
+	for _, tc := range []struct {
+		name     string
+		params   []api.StepParameter
+		expected []error
+	}{{
+		name: "valid schemas",
+		params: []api.StepParameter{
+			{Name: "UNTYPED"},
+			{Name: "REPLICAS", Type: api.StepParameterTypeInteger, Default: &three},
+			{Name: "FIPS_ENABLED", Type: api.StepParameterTypeBoolean, Default: &empty},
+			{Name: "NETWORK", Type: api.StepParameterTypeString, Enum: []string{"", "OVNKubernetes", "OpenShiftSDN"}},
+			{Name: "ZONE", Pattern: `[a-z]+-[a-z]+-[0-9][a-z]`, Default: &zone},
+		},
+	}, {
+		name:     "unknown type",
+		params:   []api.StepParameter{{Name: "REPLICAS", Type: "int"}},
+		expected: []error{errors.New(`test.env[0]: parameter REPLICAS: invalid type "int", expected one of [boolean integer string]`)},
+	}, {
+		name:     "invalid pattern",
+		params:   []api.StepParameter{{Name: "ZONE", Pattern: "us-(east"}},
+		expected: []error{errors.New("test.env[0]: parameter ZONE: invalid pattern: error parsing regexp: missing closing ): `us-(east`")},
+	}, {
+		name:     "enum value not of the type",
+		params:   []api.StepParameter{{Name: "REPLICAS", Type: api.StepParameterTypeInteger, Enum: []string{"1", "three"}}},
+		expected: []error{errors.New(`test.env[0]: parameter REPLICAS: invalid enum value: value "three" is not an integer`)},
+	}, {
+		name: "default not accepted by the schema",
+		params: []api.StepParameter{
+			{Name: "UNTYPED"},
+			{Name: "FIPS_ENABLED", Type: api.StepParameterTypeBoolean, Default: &yes},
+		},
+		expected: []error{errors.New(`test.env[1]: parameter FIPS_ENABLED: invalid default: value "yes" is not a boolean, expected true or false`)},
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			actual := validateParameterSchemas(newContext("test.env", nil, nil, nil), tc.params)
+			if diff := cmp.Diff(tc.expected, actual, testhelper.EquateErrorMessage); diff != "" {
+				t.Errorf("errors differ from expected:\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestStepParameterValue(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		param    api.StepParameter
+		value    string
+		expected error
+	}{{
+		name:  "untyped parameter accepts anything",
+		param: api.StepParameter{Name: "P"},
+		value: "anything",
+	}, {
+		name:  "empty value is always accepted",
+		param: api.StepParameter{Name: "P", Type: api.StepParameterTypeInteger, Enum: []string{"1"}, Pattern: "1"},
+	}, {
+		name:  "negative integer",
+		param: api.StepParameter{Name: "P", Type: api.StepParameterTypeInteger},
+		value: "-1",
+	}, {
+		name:     "floating point is not an integer",
+		param:    api.StepParameter{Name: "P", Type: api.StepParameterTypeInteger},
+		value:    "1.5",
+		expected: errors.New(`value "1.5" is not an integer`),
+	}, {
+		name:  "boolean",
+		param: api.StepParameter{Name: "P", Type: api.StepParameterTypeBoolean},
+		value: "false",
+	}, {
+		name:     "boolean in another spelling",
+		param:    api.StepParameter{Name: "P", Type: api.StepParameterTypeBoolean},
+		value:    "True",
+		expected: errors.New(`value "True" is not a boolean, expected true or false`),
+	}, {
+		name:  "pattern matches the entire value",
+		param: api.StepParameter{Name: "P", Pattern: "[0-9]+|auto"},
+		value: "auto",
+	}, {
+		name:     "pattern matching part of the value",
+		param:    api.StepParameter{Name: "P", Pattern: "[0-9]+|auto"},
+		value:    "automatic",
+		expected: errors.New(`value "automatic" does not match pattern "[0-9]+|auto"`),
+	}, {
+		name:     "value not in the enum",
+		param:    api.StepParameter{Name: "P", Enum: []string{"a", "b"}},
+		value:    "c",
+		expected: errors.New(`value "c" is not one of ["a" "b"]`),
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			actual := StepParameterValue(tc.param, tc.value)
+			if diff := cmp.Diff(tc.expected, actual, testhelper.EquateErrorMessage); diff != "" {
+				t.Errorf("error differs from expected:\n%s", diff)
+			}
+		})
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"path/filepath"
 	"regexp"
+	"slices"
 	"strings"
 	"time"
 
@@ -193,6 +194,9 @@ func (v *Validator) validateTestStepConfiguration(
 		if test.RunIfChanged != "" && test.SkipIfOnlyChanged != "" {
 			validationErrors = append(validationErrors, fmt.Errorf("%s: `run_if_changed` and `skip_if_only_changed` are mutually exclusive", fieldRootN))
 		}
+		if err := validateExecutionPolicy(fieldRootN, test.ExecutionPolicy); err != nil {
+			validationErrors = append(validationErrors, err)
+		}
 
 		if test.Interval != nil {
 			if _, err := time.ParseDuration(*test.Interval); err != nil {
@@ -615,6 +619,9 @@ func (v *Validator) validateTestConfigurationType(
 		for i, s := range testConfig.Post {
 			validationErrors = append(validationErrors, v.validateLiteralTestStep(context.addField("post").addIndex(i), testStagePost, s, claimRelease)...)
 		}
+		validationErrors = append(validationErrors, validateParallelGroups(context.addField("pre"), testConfig.Pre, testConfig.ParallelGroups)...)
+		validationErrors = append(validationErrors, validateParallelGroups(context.addField("test"), testConfig.Test, testConfig.ParallelGroups)...)
+		validationErrors = append(validationErrors, validateParallelGroups(context.addField("post"), testConfig.Post, testConfig.ParallelGroups)...)
 	}
 	if typeCount == 0 {
 		validationErrors = append(validationErrors, fmt.Errorf("%s has no type, you may want to specify 'container' for a container based test", fieldRoot))
@@ -635,7 +642,33 @@ func (v *Validator) validateTestConfigurationType(
 func (v *Validator) validateTestSteps(context *context, stage testStage, steps []api.TestStep, claimRelease *api.ClaimRelease) (ret []error) {
 	for i, s := range steps {
 		contextI := context.addIndex(i)
-		ret = append(ret, validateTestStep(contextI, s)...)
+		errs := validateTestStep(contextI, s)
+		ret = append(ret, errs...)
+		if s.LiteralTestStep != nil {
+			ret = append(ret, v.validateLiteralTestStep(contextI, stage, *s.LiteralTestStep, claimRelease)...)
+		}
+		if s.Parallel != nil && len(errs) == 0 {
+			ret = append(ret, v.validateParallel(contextI.addField("parallel"), stage, s.Parallel, claimRelease)...)
+		}
+	}
+	return
+}
+
+func (v *Validator) validateParallel(context *context, stage testStage, members []api.ParallelStep, claimRelease *api.ClaimRelease) (ret []error) {
+	if len(members) < 2 {
+		return []error{context.errorf("at least two steps are required")}
+	}
+	for i, s := range members {
+		contextI := context.addIndex(i)
+		if s.LiteralTestStep != nil && s.Reference != nil {
+			ret = append(ret, contextI.errorf("only one of `ref` or a literal test step can be set"))
+			continue
+		}
+		if s.LiteralTestStep == nil && s.Reference == nil {
+			ret = append(ret, contextI.errorf("a reference or literal test step is required"))
+			continue
+		}
+		ret = append(ret, validateTestStep(contextI, api.TestStep{LiteralTestStep: s.LiteralTestStep, Reference: s.Reference})...)
 		if s.LiteralTestStep != nil {
 			ret = append(ret, v.validateLiteralTestStep(contextI, stage, *s.LiteralTestStep, claimRelease)...)
 		}
@@ -643,15 +676,37 @@ func (v *Validator) validateTestSteps(context *context, stage testStage, steps [
 	return
 }
 
+// validateParallelGroups checks that the steps of each resolved `parallel`
+// group are adjacent in the phase.
+func validateParallelGroups(context *context, steps []api.LiteralTestStep, groups map[string]string) (ret []error) {
+	done := sets.New[string]()
+	for i, s := range steps {
+		group := groups[s.As]
+		if i > 0 {
+			if previous := groups[steps[i-1].As]; previous != "" && previous != group {
+				done.Insert(previous)
+			}
+		}
+		if group != "" && done.Has(group) {
+			ret = append(ret, context.addIndex(i).errorf("steps of parallel group %q must be adjacent", group))
+		}
+	}
+	return
+}
+
 func validateTestStep(context *context, step api.TestStep) (ret []error) {
-	if (step.LiteralTestStep != nil && step.Reference != nil) ||
-		(step.LiteralTestStep != nil && step.Chain != nil) ||
-		(step.Reference != nil && step.Chain != nil) {
-		ret = append(ret, context.errorf("only one of `ref`, `chain`, or a literal test step can be set"))
+	set := 0
+	for _, isSet := range []bool{step.LiteralTestStep != nil, step.Reference != nil, step.Chain != nil, step.Parallel != nil} {
+		if isSet {
+			set++
+		}
+	}
+	if set > 1 {
+		ret = append(ret, context.errorf("only one of `ref`, `chain`, `parallel`, or a literal test step can be set"))
 		return
 	}
-	if step.LiteralTestStep == nil && step.Reference == nil && step.Chain == nil {
-		ret = append(ret, context.errorf("a reference, chain, or literal test step is required"))
+	if set == 0 {
+		ret = append(ret, context.errorf("a reference, chain, parallel group, or literal test step is required"))
 		return
 	}
 	if step.Reference != nil {
@@ -702,13 +757,20 @@ func (v *Validator) validateLiteralTestStep(context *context, stage testStage, s
 
 	ret = append(ret, validateResourceRequirements(string(context.field)+".resources", step.Resources)...)
 	ret = append(ret, validateCredentials(string(context.field), step.Credentials)...)
+	ret = append(ret, validateParameterSchemas(context.addField("env"), step.Environment)...)
 	if context.env != nil {
-		if err := validateParameters(context, step.Environment); err != nil {
-			ret = append(ret, err)
-		}
+		ret = append(ret, validateParameters(context, step.Environment)...)
 	}
 	ret = append(ret, validateDependencies(string(context.field), step.Dependencies)...)
 	ret = append(ret, validateLeases(context.addField("leases"), step.Leases)...)
+	if step.Retry != nil {
+		ret = append(ret, validateRetryPolicy(context.addField("retry"), *step.Retry)...)
+	}
+	ret = append(ret, validateOutputs(context.addField("outputs"), step.Outputs)...)
+	ret = append(ret, validateInputs(context.addField("inputs"), step.Inputs)...)
+	if step.When != "" {
+		ret = append(ret, validateWhen(context.addField("when"), step)...)
+	}
 	if step.NodeArchitecture != nil {
 		if err := validateNodeArchitecture(string(context.field), *step.NodeArchitecture); err != nil {
 			ret = append(ret, err)
@@ -840,20 +902,24 @@ func ValidateSecretInStep(ns, name string) error {
 	return nil
 }
 
-func validateParameters(context *context, params []api.StepParameter) error {
+func validateParameters(context *context, params []api.StepParameter) (ret []error) {
 	var missing []string
 	for _, param := range params {
-		if param.Default != nil {
+		value, ok := context.env[param.Name]
+		if !ok {
+			if param.Default == nil {
+				missing = append(missing, param.Name)
+			}
 			continue
 		}
-		if _, ok := context.env[param.Name]; !ok {
-			missing = append(missing, param.Name)
+		if err := StepParameterValue(param, value); err != nil {
+			ret = append(ret, context.errorf("parameter %s: %v", param.Name, err))
 		}
 	}
 	if missing != nil {
-		return context.errorf("unresolved parameter(s): %s", missing)
+		ret = append(ret, context.errorf("unresolved parameter(s): %s", missing))
 	}
-	return nil
+	return
 }
 
 func validateDependencies(fieldRoot string, dependencies []api.StepDependency) []error {
@@ -887,6 +953,15 @@ func validateDNSConfig(fieldRoot string, dnsConfig []api.StepDNSConfig) (ret []e
 	return errs
 }
 
+func validateExecutionPolicy(fieldRoot string, policy api.ExecutionPolicy) error {
+	switch policy {
+	case "", api.ExecutionPolicyKeepGoing, api.ExecutionPolicyFailFast:
+		return nil
+	default:
+		return fmt.Errorf("%s.execution_policy: expected one of %v or %v, got %q", fieldRoot, api.ExecutionPolicyKeepGoing, api.ExecutionPolicyFailFast, policy)
+	}
+}
+
 func validateNodeArchitecture(fieldRoot string, nodeArchitecture api.NodeArchitecture) error {
 	if nodeArchitecture != api.NodeArchitectureAMD64 && nodeArchitecture != api.NodeArchitectureARM64 {
 		return fmt.Errorf("%s.nodeArchitecture expected one of %v or %v", fieldRoot, api.NodeArchitectureAMD64, api.NodeArchitectureARM64)
@@ -894,6 +969,101 @@ func validateNodeArchitecture(fieldRoot string, nodeArchitecture api.NodeArchite
 	return nil
 }
 
+// maxStepAttempts limits retries so transient failures cannot keep a job
+// running indefinitely.
+const maxStepAttempts = 5
+
+func validateRetryPolicy(context *context, policy api.StepRetryPolicy) (ret []error) {
+	if policy.Attempts < 1 || policy.Attempts > maxStepAttempts {
+		ret = append(ret, context.addField("attempts").errorf("must be between 1 and %d, got %d", maxStepAttempts, policy.Attempts))
+	}
+	if policy.Backoff != nil && policy.Backoff.Duration < 0 {
+		ret = append(ret, context.addField("backoff").errorf("cannot be negative"))
+	}
+	for i, class := range policy.On {
+		if !slices.Contains(api.StepFailureClasses, class) {
+			ret = append(ret, context.addField("on").addIndex(i).errorf("expected one of %v, got %q", api.StepFailureClasses, class))
+		}
+	}
+	return
+}
+
+func validateOutputType(context *context, outputType api.StepOutputType) error {
+	switch outputType {
+	case api.StepOutputTypeString, api.StepOutputTypeFile:
+		return nil
+	default:
+		return context.errorf("'type' must be one of %s or %s, got %q", api.StepOutputTypeString, api.StepOutputTypeFile, outputType)
+	}
+}
+
+func validateOutputs(context *context, outputs []api.StepOutput) (ret []error) {
+	seen := sets.New[string]()
+	for i, o := range outputs {
+		contextI := context.addIndex(i)
+		if errs := validation.IsConfigMapKey(o.Name); len(errs) != 0 {
+			ret = append(ret, contextI.errorf("'name' is not a valid file name: %s", strings.Join(errs, ", ")))
+		} else if seen.Has(o.Name) {
+			ret = append(ret, contextI.errorf("duplicate output: %s", o.Name))
+		} else {
+			seen.Insert(o.Name)
+		}
+		if err := validateOutputType(contextI, o.Type); err != nil {
+			ret = append(ret, err)
+		}
+	}
+	return
+}
+
+func validateInputs(context *context, inputs []api.StepInput) (ret []error) {
+	envs := sets.New[string]()
+	for i, in := range inputs {
+		contextI := context.addIndex(i)
+		if in.Name == "" {
+			ret = append(ret, contextI.errorf("'name' cannot be empty"))
+		}
+		if in.Type != "" {
+			if err := validateOutputType(contextI, in.Type); err != nil {
+				ret = append(ret, err)
+			}
+		}
+		if in.Env == "" {
+			ret = append(ret, contextI.errorf("'env' cannot be empty"))
+		} else if envs.Has(in.Env) {
+			ret = append(ret, contextI.errorf("duplicate environment variable: %s", in.Env))
+		} else {
+			envs.Insert(in.Env)
+		}
+	}
+	return
+}
+
+// validateWhen checks that the `when` expression of a step parses and only
+// references parameters of the step and steps that run before it.
+func validateWhen(context *context, step api.LiteralTestStep) (ret []error) {
+	expression, err := api.ParseWhenExpression(step.When)
+	if err != nil {
+		return []error{context.errorf("invalid expression: %v", err)}
+	}
+	params := sets.New[string]()
+	for _, param := range step.Environment {
+		params.Insert(param.Name)
+	}
+	for _, name := range expression.Env() {
+		if !params.Has(name) {
+			ret = append(ret, context.errorf("references env.%s, which is not a parameter of the step", name))
+		}
+	}
+	for _, name := range expression.Steps() {
+		if name == step.As {
+			ret = append(ret, context.errorf("cannot reference the result of the step itself"))
+		} else if context.namesSeen != nil && !context.namesSeen.Has(name) {
+			ret = append(ret, context.errorf("references steps.%s, which does not run before the step", name))
+		}
+	}
+	return
+}
+
 func validateLeases(context *context, leases []api.StepLease) (ret []error) {
 	for i, l := range leases {
 		if l.ResourceType == "" {
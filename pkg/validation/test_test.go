@@ -1016,7 +1016,7 @@ func TestValidateTestSteps(t *testing.T) {
 			Reference: &myReference,
 		}},
 		errs: []error{
-			errors.New("test[0]: only one of `ref`, `chain`, or a literal test step can be set"),
+			errors.New("test[0]: only one of `ref`, `chain`, `parallel`, or a literal test step can be set"),
 		},
 	}, {
 		name: "Step with same name as reference",
@@ -1132,6 +1132,43 @@ func TestValidateTestSteps(t *testing.T) {
 				Resources: resources},
 		}},
 		clusterClaim: api.ClaimRelease{ReleaseName: "myclaim-as", OverrideName: "myclaim"},
+	}, {
+		name: "parallel group",
+		steps: []api.TestStep{{
+			Parallel: []api.ParallelStep{
+				{Reference: &myReference},
+				{LiteralTestStep: &api.LiteralTestStep{As: "as", From: "from", Commands: "commands", Resources: resources}},
+			},
+		}},
+	}, {
+		name: "parallel group with a single step",
+		steps: []api.TestStep{{
+			Parallel: []api.ParallelStep{{Reference: &myReference}},
+		}},
+		errs: []error{errors.New("test[0].parallel: at least two steps are required")},
+	}, {
+		name: "invalid parallel group members",
+		steps: []api.TestStep{{
+			Parallel: []api.ParallelStep{
+				{},
+				{Reference: &myReference, LiteralTestStep: &api.LiteralTestStep{As: "as"}},
+				{LiteralTestStep: &api.LiteralTestStep{As: "no_commands", From: "from", Resources: resources}},
+			},
+		}},
+		errs: []error{
+			errors.New("test[0].parallel[0]: a reference or literal test step is required"),
+			errors.New("test[0].parallel[1]: only one of `ref` or a literal test step can be set"),
+			errors.New("test[0].parallel[2]: `commands` is required"),
+		},
+	}, {
+		name: "parallel group and reference set",
+		steps: []api.TestStep{{
+			Reference: &myReference,
+			Parallel:  []api.ParallelStep{{Reference: &myReference}},
+		}},
+		errs: []error{
+			errors.New("test[0]: only one of `ref`, `chain`, `parallel`, or a literal test step can be set"),
+		},
 	}} {
 		t.Run(tc.name, func(t *testing.T) {
 			context := newContext("test", nil, tc.releases, make(testInputImages))
@@ -1210,6 +1247,20 @@ func TestValidateParameters(t *testing.T) {
 		params: []api.StepParameter{{Name: "TEST0"}, {Name: "TEST1"}},
 		env:    api.TestEnvironment{"TEST0": "test0"},
 		err:    []error{errors.New("test: unresolved parameter(s): [TEST1]")},
+	}, {
+		name:   "parameter provided with a value of the declared type",
+		params: []api.StepParameter{{Name: "REPLICAS", Type: api.StepParameterTypeInteger}},
+		env:    api.TestEnvironment{"REPLICAS": "3"},
+	}, {
+		name:   "parameter provided with a value not of the declared type",
+		params: []api.StepParameter{{Name: "REPLICAS", Type: api.StepParameterTypeInteger}},
+		env:    api.TestEnvironment{"REPLICAS": "three"},
+		err:    []error{errors.New(`test: parameter REPLICAS: value "three" is not an integer`)},
+	}, {
+		name:   "parameter provided with a value not in the enum",
+		params: []api.StepParameter{{Name: "NETWORK", Enum: []string{"OVNKubernetes", "OpenShiftSDN"}}},
+		env:    api.TestEnvironment{"NETWORK": "Calico"},
+		err:    []error{errors.New(`test: parameter NETWORK: value "Calico" is not one of ["OVNKubernetes" "OpenShiftSDN"]`)},
 	}} {
 		t.Run(tc.name, func(t *testing.T) {
 			v := NewValidator(nil, nil)
@@ -1441,6 +1492,175 @@ func TestValidateNodeArchitecture(t *testing.T) {
 	}
 }
 
+func TestValidateExecutionPolicy(t *testing.T) {
+	var testCases = []struct {
+		name   string
+		input  api.ExecutionPolicy
+		output error
+	}{
+		{
+			name: "empty execution policy",
+		},
+		{
+			name:  "keep-going",
+			input: api.ExecutionPolicyKeepGoing,
+		},
+		{
+			name:  "fail-fast",
+			input: api.ExecutionPolicyFailFast,
+		},
+		{
+			name:   "invalid execution policy",
+			input:  api.ExecutionPolicy("fail-slow"),
+			output: errors.New(`root.execution_policy: expected one of keep-going or fail-fast, got "fail-slow"`),
+		},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			err := validateExecutionPolicy("root", testCase.input)
+			if diff := cmp.Diff(err, testCase.output, testhelper.EquateErrorMessage); diff != "" {
+				t.Errorf("actualError does not match expectedError, diff: %s", diff)
+			}
+		})
+	}
+}
+
+func TestValidateRetryPolicy(t *testing.T) {
+	for _, tc := range []struct {
+		name   string
+		policy api.StepRetryPolicy
+		errs   []error
+	}{{
+		name:   "valid policy",
+		policy: api.StepRetryPolicy{Attempts: 3, Backoff: &prowv1.Duration{Duration: time.Minute}, On: []api.StepFailureClass{api.StepFailureEviction, api.StepFailureImagePull}},
+	}, {
+		name:   "too few attempts",
+		policy: api.StepRetryPolicy{},
+		errs:   []error{errors.New("test.retry.attempts: must be between 1 and 5, got 0")},
+	}, {
+		name:   "too many attempts",
+		policy: api.StepRetryPolicy{Attempts: 10},
+		errs:   []error{errors.New("test.retry.attempts: must be between 1 and 5, got 10")},
+	}, {
+		name:   "negative backoff",
+		policy: api.StepRetryPolicy{Attempts: 2, Backoff: &prowv1.Duration{Duration: -time.Second}},
+		errs:   []error{errors.New("test.retry.backoff: cannot be negative")},
+	}, {
+		name:   "unknown failure class",
+		policy: api.StepRetryPolicy{Attempts: 2, On: []api.StepFailureClass{api.StepFailureOOMKilled, "flake"}},
+		errs:   []error{errors.New(`test.retry.on[1]: expected one of [eviction image_pull oom_killed api_throttling], got "flake"`)},
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			errs := validateRetryPolicy(newContext("test", nil, nil, nil).addField("retry"), tc.policy)
+			if diff := cmp.Diff(tc.errs, errs, testhelper.EquateErrorMessage); diff != "" {
+				t.Errorf("unexpected errors: %s", diff)
+			}
+		})
+	}
+}
+
+func TestValidateOutputsAndInputs(t *testing.T) {
+	for _, tc := range []struct {
+		name    string
+		outputs []api.StepOutput
+		inputs  []api.StepInput
+		errs    []error
+	}{{
+		name:    "valid outputs and inputs",
+		outputs: []api.StepOutput{{Name: "cluster-name", Type: api.StepOutputTypeString}, {Name: "report.json", Type: api.StepOutputTypeFile}},
+		inputs:  []api.StepInput{{Name: "kubeconfig", Type: api.StepOutputTypeFile, Env: "KUBECONFIG_FILE"}, {Name: "region", Env: "REGION"}},
+	}, {
+		name:    "invalid outputs",
+		outputs: []api.StepOutput{{Name: "a/b", Type: api.StepOutputTypeString}, {Name: "name", Type: "int"}, {Name: "name", Type: api.StepOutputTypeFile}},
+		errs: []error{
+			errors.New("test.outputs[0]: 'name' is not a valid file name: a valid config key must consist of alphanumeric characters, '-', '_' or '.' (e.g. 'key.name',  or 'KEY_NAME',  or 'key-name', regex used for validation is '[-._a-zA-Z0-9]+')"),
+			errors.New(`test.outputs[1]: 'type' must be one of string or file, got "int"`),
+			errors.New("test.outputs[2]: duplicate output: name"),
+		},
+	}, {
+		name:   "invalid inputs",
+		inputs: []api.StepInput{{Type: "int", Env: "VALUE"}, {Name: "other", Env: "VALUE"}, {Name: "third"}},
+		errs: []error{
+			errors.New("test.inputs[0]: 'name' cannot be empty"),
+			errors.New(`test.inputs[0]: 'type' must be one of string or file, got "int"`),
+			errors.New("test.inputs[1]: duplicate environment variable: VALUE"),
+			errors.New("test.inputs[2]: 'env' cannot be empty"),
+		},
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			context := newContext("test", nil, nil, nil)
+			errs := append(validateOutputs(context.addField("outputs"), tc.outputs), validateInputs(context.addField("inputs"), tc.inputs)...)
+			if diff := cmp.Diff(tc.errs, errs, testhelper.EquateErrorMessage); diff != "" {
+				t.Errorf("unexpected errors: %s", diff)
+			}
+		})
+	}
+}
+
+func TestValidateParallelGroups(t *testing.T) {
+	for _, tc := range []struct {
+		name   string
+		steps  []api.LiteralTestStep
+		groups map[string]string
+		errs   []error
+	}{{
+		name:   "adjacent groups",
+		steps:  []api.LiteralTestStep{{As: "a"}, {As: "b"}, {As: "c"}, {As: "d"}, {As: "e"}},
+		groups: map[string]string{"a": "a", "b": "a", "c": "c", "d": "c"},
+	}, {
+		name:   "group interrupted by another step",
+		steps:  []api.LiteralTestStep{{As: "a"}, {As: "b"}, {As: "c"}},
+		groups: map[string]string{"a": "a", "c": "a"},
+		errs:   []error{errors.New(`test[2]: steps of parallel group "a" must be adjacent`)},
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			errs := validateParallelGroups(newContext("test", nil, nil, nil), tc.steps, tc.groups)
+			if diff := cmp.Diff(tc.errs, errs, testhelper.EquateErrorMessage); diff != "" {
+				t.Errorf("unexpected errors: %s", diff)
+			}
+		})
+	}
+}
+
+func TestValidateWhen(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		seen []string
+		step api.LiteralTestStep
+		errs []error
+	}{{
+		name: "valid expression",
+		seen: []string{"install", "step"},
+		step: api.LiteralTestStep{
+			As:          "step",
+			Environment: []api.StepParameter{{Name: "UPGRADE"}},
+			When:        `failure() || (env.UPGRADE == "true" && steps.install != "skipped")`,
+		},
+	}, {
+		name: "invalid expression",
+		step: api.LiteralTestStep{As: "step", When: "failure() &&"},
+		errs: []error{errors.New("test.when: invalid expression: expected an operand at the end of the expression")},
+	}, {
+		name: "unknown references",
+		seen: []string{"step"},
+		step: api.LiteralTestStep{As: "step", When: `env.UNKNOWN == "true" || steps.later == "failed" || steps.step == "failed"`},
+		errs: []error{
+			errors.New("test.when: references env.UNKNOWN, which is not a parameter of the step"),
+			errors.New("test.when: references steps.later, which does not run before the step"),
+			errors.New("test.when: cannot reference the result of the step itself"),
+		},
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			context := newContext("test", nil, nil, nil)
+			context.namesSeen.Insert(tc.seen...)
+			errs := validateWhen(context.addField("when"), tc.step)
+			if diff := cmp.Diff(tc.errs, errs, testhelper.EquateErrorMessage); diff != "" {
+				t.Errorf("unexpected errors: %s", diff)
+			}
+		})
+	}
+}
+
 func TestValidateLeases(t *testing.T) {
 	for _, tc := range []struct {
 		name string
@@ -21,13 +21,16 @@ type node struct {
 	linkable bool
 }
 
-// subgraph is a group of sequential steps and/or other subgraphs
+// subgraph is a group of steps and/or other subgraphs
 // Used to render both registry chains and the pre/test/post steps of a
 // workflow.
 type subgraph struct {
 	label     string
 	nodes     []int
 	subgraphs []int
+	// Whether this is a `parallel` group, whose nodes are not sequential and
+	// which is not a registry component that can be linked to.
+	parallel bool
 }
 
 // edge connects node objects in the final drawing
@@ -156,6 +159,8 @@ func (b *graphBuilder) addSubgraph(label string, steps []api.TestStep) int {
 		} else if step.Chain != nil {
 			i := b.addSubgraph(*step.Chain, b.chains[*step.Chain].Steps)
 			sg.subgraphs = append(sg.subgraphs, i)
+		} else if step.Parallel != nil {
+			sg.subgraphs = append(sg.subgraphs, b.addParallel(step.Parallel))
 		}
 	}
 	i := len(b.graph.subgraphs)
@@ -173,6 +178,31 @@ func (b *graphBuilder) addSubgraph(label string, steps []api.TestStep) int {
 	return i
 }
 
+// addParallel creates a sub-graph for a `parallel` group, with an edge from
+// the preceding step to each of its members
+func (b *graphBuilder) addParallel(members []api.ParallelStep) int {
+	sg := subgraph{label: "parallel", parallel: true}
+	in := b.edge
+	for i, member := range api.FlattenParallel([]api.TestStep{{Parallel: members}}) {
+		b.edge = in
+		if i != 0 {
+			// only the first edge is clipped against the enclosing chain,
+			// see `addSubgraph`
+			b.edge.dstType = nodeType
+		}
+		if member.LiteralTestStep != nil {
+			b.addNode(&sg, node{label: member.As})
+		} else if member.Reference != nil {
+			b.addNode(&sg, node{label: *member.Reference, linkable: true})
+		}
+	}
+	i := len(b.graph.subgraphs)
+	b.graph.subgraphs = append(b.graph.subgraphs, sg)
+	b.edge.srcType = subgraphType
+	b.edge.srcGraph = i
+	return i
+}
+
 // addNode creates a single leaf node and, if necessary, an edge
 func (b *graphBuilder) addNode(sg *subgraph, n node) {
 	i := len(b.graph.nodes)
@@ -250,7 +280,7 @@ func writeSubgraph(g graph, tmpl *template.Template, b *strings.Builder, i int,
 		panic(fmt.Errorf("subgraph template rendering failed: %w", err))
 	}
 	for _, i := range g.subgraphs[i].subgraphs {
-		writeSubgraph(g, tmpl, b, i, prefix+"\t", true)
+		writeSubgraph(g, tmpl, b, i, prefix+"\t", !g.subgraphs[i].parallel)
 	}
 	b.WriteString(prefix)
 	b.WriteString("}\n")
@@ -150,6 +150,18 @@ func TestWorkflowDotFile(t *testing.T) {
 				{Chain: &chainOfChains},
 			},
 		},
+	}, {
+		name: "parallel",
+		workflow: api.MultiStageTestConfiguration{
+			Post: []api.TestStep{
+				{LiteralTestStep: &api.LiteralTestStep{As: "gather"}},
+				{Parallel: []api.ParallelStep{
+					{Reference: &gather},
+					{LiteralTestStep: &api.LiteralTestStep{As: "gather-extra"}},
+				}},
+				{Reference: &deprovision},
+			},
+		},
 	}} {
 		t.Run(tc.name, func(t *testing.T) {
 			workflows := registry.WorkflowByName{tc.name: tc.workflow}
@@ -6,7 +6,6 @@ import (
 	"fmt"
 	"html/template"
 	"net/http"
-	"path"
 	"strings"
 	"time"
 
@@ -65,11 +64,18 @@ const bodyStart = `
           <a class="dropdown-item" href="/#workflows">Workflows</a>
           <a class="dropdown-item" href="/#chains">Chains</a>
           <a class="dropdown-item" href="/#steps">Steps</a>
+          <a class="dropdown-item" href="/deprecations">Deprecations</a>
         </div>
       </li>
       <li class="nav-item">
         <a class="nav-link" href="/search">Jobs</a>
       </li>
+      <li class="nav-item">
+        <a class="nav-link" href="/registry-search">Registry Search</a>
+      </li>
+      <li class="nav-item">
+        <a class="nav-link" href="/resolved-diff">Resolved Diff</a>
+      </li>
       <li class="nav-item">
         <a class="nav-link" href="http://docs.ci.openshift.org">Help</a>
       </li>
@@ -102,6 +108,7 @@ const mainPage = `
 
 const referencePage = `
 <h2 id="title"><a href="#title">Step:</a> <nobr style="font-family:monospace">{{ .Reference.As }}</nobr></h2>
+{{ template "deprecationBanner" deprecationNotice "reference" .Metadata.Deprecation }}
 <p id="documentation">{{ .Reference.Documentation }}</p>
 <h3 id="image"><a href="#image">Container image used for this step:</a> <span style="font-family:monospace">{{ fromImage .Reference.From .Reference.FromImage }}</span></h3>
 <p id="image">{{ fromImageDescription .Reference.From .Reference.FromImage }}<d/p>
@@ -111,12 +118,14 @@ const referencePage = `
 {{ syntaxedSource .Reference.Commands }}
 <h3 id="properties"><a href="#properties">Properties</a></h3>
 {{ template "referenceProperties" .Reference }}
+<h3 id="impact"><a href="#impact">Impact:</a></h3><p>See the <a href="/impact?step={{ .Reference.As }}">registry components and jobs using this step</a>.</p>
 <h3 id="github"><p><a href="#github">GitHub Link:</a></h3></p>{{ githubLink .Metadata.Path }}
 {{ ownersBlock .Metadata.Owners }}
 `
 
 const chainPage = `
 <h2 id="title"><a href="#title">Chain:</a> <nobr style="font-family:monospace">{{ .Chain.As }}</nobr></h2>
+{{ template "deprecationBanner" deprecationNotice "chain" .Metadata.Deprecation }}
 <p id="documentation">{{ .Chain.Documentation }}</p>
 <h3 id="steps" title="Step run by the chain, in runtime order"><a href="#steps">Steps</a></h3>
 {{ template "stepTable" .Chain.Steps}}
@@ -127,6 +136,7 @@ const chainPage = `
 {{ template "refEnvironment" .Chain.As }}
 <h3 id="graph" title="Visual representation of steps run by this chain"><a href="#graph">Step Graph</a></h3>
 {{ chainGraph .Chain.As }}
+<h3 id="impact"><a href="#impact">Impact:</a></h3><p>See the <a href="/impact?chain={{ .Chain.As }}">registry components and jobs using this chain</a>.</p>
 <h3 id="github"><a href="#github">GitHub Link:</a></h3>{{ githubLink .Metadata.Path }}
 {{ ownersBlock .Metadata.Owners }}
 `
@@ -135,6 +145,9 @@ const chainPage = `
 const workflowJobPage = `
 {{ $type := .Workflow.Type }}
 <h2 id="title"><a href="#title">{{ $type }}:</a> <nobr style="font-family:monospace">{{ .Workflow.As }}</nobr></h2>
+{{ if eq $type "Workflow" }}
+{{ template "deprecationBanner" deprecationNotice "workflow" .Metadata.Deprecation }}
+{{ end }}
 {{ if .Workflow.Documentation }}
 	<p id="documentation">{{ .Workflow.Documentation }}</p>
 {{ end }}
@@ -155,16 +168,274 @@ const workflowJobPage = `
 <h3 id="graph" title="Visual representation of steps run by this {{ toLower $type }}"><a href="#graph">Step Graph</a></h3>
 {{ workflowGraph .Workflow.As .Workflow.Type }}
 {{ if eq $type "Workflow" }}
+<h3 id="impact"><a href="#impact">Impact:</a></h3><p>See the <a href="/impact?workflow={{ .Workflow.As }}">jobs using this workflow</a>.</p>
 <h3 id="github"><a href="#github">GitHub Link:</a></h3>{{ githubLink .Metadata.Path }}
 {{ ownersBlock .Metadata.Owners }}
 {{ end }}
 `
 
+const impactPage = `
+<h2 id="title"><a href="#title">Impact of {{ .Component.Type }}:</a> {{ template "nameWithLink" .Component }}</h2>
+<p>Changing this {{ .Component.Type }} affects all registry components and jobs listed below.</p>
+<h3 id="components" title="Registry components that include this {{ .Component.Type }}, directly or through other components"><a href="#components">Registry Components</a></h3>
+{{ if .Users }}
+<table class="table">
+	<thead>
+		<tr>
+			<th title="The type of the registry component" class="info">Type</th>
+			<th title="The name of the registry component" class="info">Name</th>
+		</tr>
+	</thead>
+	<tbody>
+	{{ range $index, $user := .Users }}
+		<tr>
+			<td>{{ $user.Type }}</td>
+			<td>{{ template "nameWithLink" $user }}</td>
+		</tr>
+	{{ end }}
+	</tbody>
+</table>
+{{ else }}
+<p>No registry component includes this {{ .Component.Type }}.</p>
+{{ end }}
+<h3 id="jobs" title="Tests in ci-operator configurations that use this {{ .Component.Type }}, and the jobs running them"><a href="#jobs">Jobs</a></h3>
+{{ if .Tests }}
+<table class="table">
+	<thead>
+		<tr>
+			<th title="GitHub organization that the job is from" class="info">Org</th>
+			<th title="GitHub repo that the job is from" class="info">Repo</th>
+			<th title="GitHub branch that the job is from" class="info">Branch</th>
+			<th title="Variant of the ci-operator config" class="info">Variant</th>
+			<th title="The multistage test in the configuration" class="info">Test</th>
+			<th title="The type of the Prow job running the test" class="info">Type</th>
+			<th title="The registry component used directly by the test" class="info">Via</th>
+		</tr>
+	</thead>
+	<tbody>
+	{{ range $index, $test := .Tests }}
+		<tr>
+			<td>{{ $test.Metadata.Org }}</td>
+			<td>{{ $test.Metadata.Repo }}</td>
+			<td>{{ $test.Metadata.Branch }}</td>
+			<td>{{ $test.Metadata.Variant }}</td>
+			<td><nobr><a href="/job?org={{ $test.Metadata.Org }}&repo={{ $test.Metadata.Repo }}&branch={{ $test.Metadata.Branch }}{{ if $test.Metadata.Variant }}&variant={{ $test.Metadata.Variant }}{{ end }}&test={{ $test.Test }}" style="font-family:monospace" title="{{ $test.JobName }}">{{ $test.Test }}</a></nobr></td>
+			<td>{{ $test.JobType }}</td>
+			<td>{{ template "nameWithLink" $test.Via }}</td>
+		</tr>
+	{{ end }}
+	</tbody>
+</table>
+{{ else }}
+<p>No job uses this {{ .Component.Type }}.</p>
+{{ end }}
+`
+
+const deprecationsPage = `
+<h2 id="title"><a href="#title">Deprecated Registry Components</a></h2>
+<p>Deprecated components are listed by the approvers in their OWNERS, along with the jobs that still use them and need to be migrated before the components can be removed.</p>
+{{ range $index, $owner := . }}
+<h3 id="{{ $owner.Owner }}"><a href="#{{ $owner.Owner }}">{{ $owner.Owner }}</a></h3>
+<table class="table">
+	<thead>
+		<tr>
+			<th title="The deprecated registry component" class="info">Component</th>
+			<th title="Why the component is deprecated" class="info">Message</th>
+			<th title="The component to use instead" class="info">Replacement</th>
+			<th title="The date after which the component may be removed" class="info">Removal Date</th>
+			<th title="The number of jobs that still use the component" class="info">Remaining Jobs</th>
+		</tr>
+	</thead>
+	<tbody>
+	{{ range $index, $component := $owner.Components }}
+		<tr>
+			<td>{{ $component.Component.Type }} {{ template "nameWithLink" $component.Component }}</td>
+			<td>{{ $component.Deprecation.Message }}</td>
+			<td>{{ if $component.Deprecation.Replacement }}{{ template "nameWithLink" (replacementFor $component.Component $component.Deprecation) }}{{ end }}</td>
+			<td>{{ $component.Deprecation.RemovalDate }}</td>
+			<td><a href="/impact?{{ if eq $component.Component.Type "reference" }}step{{ else }}{{ $component.Component.Type }}{{ end }}={{ $component.Component.Name }}#jobs">{{ len $component.Tests }}</a></td>
+		</tr>
+	{{ end }}
+	</tbody>
+</table>
+{{ else }}
+<p>No registry component is deprecated.</p>
+{{ end }}
+`
+
+const registrySearchPage = `
+<h2 id="title"><a href="#title">Registry Search</a></h2>
+<p>Search steps, chains and workflows. Every field that is filled in must match.</p>
+<form action="/registry-search" method="get">
+	<div class="form-row">
+		<div class="form-group col-md-8">
+			<label for="q" title="Words in the names, documentation and commands of components; each word matches as a prefix">Text</label>
+			<input class="form-control" type="search" id="q" name="q" value="{{ .Query.Text }}" placeholder="install aws">
+		</div>
+		<div class="form-group col-md-4">
+			<label for="type">Type</label>
+			<select class="form-control" id="type" name="type">
+				<option value="" {{ if eq .Query.Type "" }}selected{{ end }}>Any</option>
+				<option value="reference" {{ if eq .Query.Type "reference" }}selected{{ end }}>Step</option>
+				<option value="chain" {{ if eq .Query.Type "chain" }}selected{{ end }}>Chain</option>
+				<option value="workflow" {{ if eq .Query.Type "workflow" }}selected{{ end }}>Workflow</option>
+			</select>
+		</div>
+	</div>
+	<div class="form-row">
+		<div class="form-group col-md-3">
+			<label for="credential" title="Steps that mount the credential, by name or namespace/name">Credential</label>
+			<input class="form-control" type="text" id="credential" name="credential" value="{{ .Query.Credential }}" placeholder="namespace/name">
+		</div>
+		<div class="form-group col-md-3">
+			<label for="env" title="Steps and chains that declare the parameter, and workflows that set it">Parameter</label>
+			<input class="form-control" type="text" id="env" name="env" value="{{ .Query.Env }}" placeholder="NAME">
+		</div>
+		<div class="form-group col-md-3">
+			<label for="command" title="Steps whose commands contain the text">Command</label>
+			<input class="form-control" type="text" id="command" name="command" value="{{ .Query.Command }}" placeholder="oc adm must-gather">
+		</div>
+		<div class="form-group col-md-3">
+			<label for="owner" title="Components with the user in their OWNERS">Owner</label>
+			<input class="form-control" type="text" id="owner" name="owner" value="{{ .Query.Owner }}" placeholder="GitHub user">
+		</div>
+	</div>
+	<button class="btn btn-primary" type="submit">Search</button>
+</form>
+{{ if .Searched }}
+<h3 id="results"><a href="#results">Results</a></h3>
+{{ if .Results }}
+<table class="table">
+	<thead>
+		<tr>
+			<th title="The type of the registry component" class="info">Type</th>
+			<th title="The name of the registry component" class="info">Name</th>
+			<th title="What the registry component does" class="info">Description</th>
+		</tr>
+	</thead>
+	<tbody>
+	{{ range $index, $result := .Results }}
+		<tr>
+			<td>{{ $result.Component.Type }}</td>
+			<td>{{ template "nameWithLink" $result.Component }}</td>
+			<td>{{ markdown $result.Documentation }}</td>
+		</tr>
+	{{ end }}
+	</tbody>
+</table>
+{{ else }}
+<p>No registry component matches the search.</p>
+{{ end }}
+{{ end }}
+`
+
+const resolvedDiffPage = `
+<h2 id="title"><a href="#title">Resolved Test Diff</a></h2>
+<p>Compare the fully resolved steps of a multi-stage test between two registry versions{{ if .AllowUploads }}, or between a registry version and a registry uploaded as a tarball{{ end }}.</p>
+<form action="/resolved-diff" method="{{ if .Upload }}post{{ else }}get{{ end }}" enctype="multipart/form-data">
+	<div class="form-row">
+		<div class="form-group col-md-2"><label for="org">Org</label><input class="form-control" type="text" id="org" name="org" value="{{ .Org }}" required></div>
+		<div class="form-group col-md-3"><label for="repo">Repo</label><input class="form-control" type="text" id="repo" name="repo" value="{{ .Repo }}" required></div>
+		<div class="form-group col-md-2"><label for="branch">Branch</label><input class="form-control" type="text" id="branch" name="branch" value="{{ .Branch }}" required></div>
+		<div class="form-group col-md-2"><label for="variant">Variant</label><input class="form-control" type="text" id="variant" name="variant" value="{{ .Variant }}"></div>
+		<div class="form-group col-md-3"><label for="test">Test</label><input class="form-control" type="text" id="test" name="test" value="{{ .Test }}" required></div>
+	</div>
+	<div class="form-row">
+		<div class="form-group col-md-6">
+			<label for="from" title="The registry version to compare from">From</label>
+			<select class="form-control" id="from" name="from" style="font-family:monospace">
+				<option value="">current</option>
+			{{ range $index, $version := .Versions }}
+				<option value="{{ $version }}" {{ if eq $version $.From }}selected{{ end }}>{{ $version }}</option>
+			{{ end }}
+			</select>
+		</div>
+		<div class="form-group col-md-6">
+		{{ if .Upload }}
+			<label for="registry" title="A tarball of the step registry directory, e.g. created with tar -czf registry.tar.gz -C ci-operator/step-registry .">To (registry tarball)</label>
+			<input class="form-control-file" type="file" id="registry" name="registry" required>
+		{{ else }}
+			<label for="to" title="The registry version to compare to">To</label>
+			<select class="form-control" id="to" name="to" style="font-family:monospace">
+				<option value="">current</option>
+			{{ range $index, $version := .Versions }}
+				<option value="{{ $version }}" {{ if eq $version $.To }}selected{{ end }}>{{ $version }}</option>
+			{{ end }}
+			</select>
+		{{ end }}
+		</div>
+	</div>
+	<button class="btn btn-primary" type="submit">Compare</button>
+	{{ if .Upload }}<a href="/resolved-diff">Compare registry versions instead</a>{{ else if .AllowUploads }}<a href="/resolved-diff?upload=true">Compare with an uploaded registry instead</a>{{ end }}
+</form>
+{{ with .Diff }}
+<h3 id="summary"><a href="#summary">Comparing</a> <span style="font-family:monospace">{{ .From }}</span> to <span style="font-family:monospace">{{ .To }}</span></h3>
+{{ if not .Changed }}<p>The resolved test is identical in both registries.</p>{{ end }}
+{{ if .Fields }}
+<h3 id="test-fields"><a href="#test-fields">Test</a></h3>
+{{ template "fieldDiffTable" .Fields }}
+{{ end }}
+<h3 id="steps"><a href="#steps">Steps</a></h3>
+<table class="table">
+	<thead>
+		<tr>
+			<th title="The phase that the step runs in" class="info">Phase</th>
+			<th title="The name of the step" class="info">Step</th>
+			<th title="How the step differs" class="info">Status</th>
+		</tr>
+	</thead>
+	<tbody>
+	{{ range $index, $step := .Steps }}
+		<tr>
+			<td>{{ $step.Phase }}</td>
+			<td style="font-family:monospace">{{ $step.Name }}</td>
+			<td><span class="badge {{ if eq $step.Status "added" }}badge-success{{ else if eq $step.Status "removed" }}badge-danger{{ else if eq $step.Status "changed" }}badge-warning{{ else }}badge-secondary{{ end }}">{{ $step.Status }}</span></td>
+		</tr>
+		{{ if $step.Fields }}
+		<tr><td></td><td colspan="2">{{ template "fieldDiffTable" $step.Fields }}</td></tr>
+		{{ end }}
+	{{ end }}
+	</tbody>
+</table>
+{{ end }}
+`
+
 const jobSearchPage = `
 {{ template "jobTable" . }}
 `
 
 const templateDefinitions = `
+{{ define "fieldDiffTable" }}
+<table class="table table-sm">
+	<thead>
+		<tr>
+			<th class="info">Field</th>
+			<th class="info">Before</th>
+			<th class="info">After</th>
+		</tr>
+	</thead>
+	<tbody>
+	{{ range $index, $field := . }}
+		<tr>
+			<td style="font-family:monospace">{{ $field.Field }}</td>
+			<td>{{ if $field.Before }}<pre>{{ $field.Before }}</pre>{{ else }}<i>unset</i>{{ end }}</td>
+			<td>{{ if $field.After }}<pre>{{ $field.After }}</pre>{{ else }}<i>unset</i>{{ end }}</td>
+		</tr>
+	{{ end }}
+	</tbody>
+</table>
+{{ end }}
+
+{{ define "deprecationBanner" }}
+	{{ if . }}
+	<div class="alert alert-warning" role="alert" id="deprecation">
+		<b>This {{ .Type }} is deprecated{{ if .Deprecation.RemovalDate }} and will be removed after {{ .Deprecation.RemovalDate }}{{ end }}.</b>
+		{{ .Deprecation.Message }}
+		{{ if .Deprecation.Replacement }}<p>Use {{ template "nameWithLink" .Replacement }} instead.</p>{{ end }}
+	</div>
+	{{ end }}
+{{ end }}
+
 {{ define "nameWithLink" }}
 	<nobr><a href="/{{ .Type }}/{{ .Name }}" style="font-family:monospace">{{ .Name }}</a></nobr>
 {{ end }}
@@ -359,6 +630,15 @@ const templateDefinitions = `
          (default: <span style="font-family:monospace">{{ $env.Default }}</span>)
        {{ end }}
        {{ end }}
+       {{ if $env.Type }}
+         <br/>Type: <span style="font-family:monospace">{{ $env.Type }}</span>
+       {{ end }}
+       {{ if $env.Enum }}
+         <br/>Allowed values:{{ range $value := $env.Enum }} <span style="font-family:monospace">{{ $value }}</span>{{ end }}
+       {{ end }}
+       {{ if $env.Pattern }}
+         <br/>Pattern: <span style="font-family:monospace">{{ $env.Pattern }}</span>
+       {{ end }}
      </td>
    </tr>
    {{ end }}
@@ -393,17 +673,19 @@ const templateDefinitions = `
 	</thead>
 	<tbody>
 		{{ range $index, $step := . }}
+		{{ range $member := parallelMembers $step }}
 			<tr>
-				{{ $nameAndType := testStepNameAndType $step }}
+				{{ $nameAndType := testStepNameAndType $member }}
 				{{ $doc := docsForName $nameAndType.Name }}
-				{{ if not $step.LiteralTestStep }}
-					<td>{{ template "nameWithLink" $nameAndType }}</td>
+				{{ if not $member.LiteralTestStep }}
+					<td>{{ template "nameWithLink" $nameAndType }}{{ if $step.Parallel }} <span class="badge badge-info" title="Runs concurrently with the other steps of its group">parallel</span>{{ end }}</td>
 				{{ else }}
-					<td>{{ $nameAndType.Name }}</td>
+					<td>{{ $nameAndType.Name }}{{ if $step.Parallel }} <span class="badge badge-info" title="Runs concurrently with the other steps of its group">parallel</span>{{ end }}</td>
 				{{ end }}
 				<td>{{ noescape $doc }}</td>
 			</tr>
 		{{ end }}
+		{{ end }}
 	</tbody>
 	</table>
 {{ end }}
@@ -412,8 +694,10 @@ const templateDefinitions = `
 {{ define "stepList" }}
 	<ul>
 	{{ range $index, $step := .}}
-		{{ $nameAndType := testStepNameAndType $step }}
-		<li>{{ template "nameWithLink" $nameAndType }}</li>
+	{{ range $member := parallelMembers $step }}
+		{{ $nameAndType := testStepNameAndType $member }}
+		<li>{{ template "nameWithLink" $nameAndType }}{{ if $step.Parallel }} (parallel){{ end }}</li>
+	{{ end }}
 	{{ end }}
 	</ul>
 {{ end }}
@@ -667,6 +951,13 @@ func getBaseTemplate() (*template.Template, error) {
 			},
 
 			"testStepNameAndType": getTestStepNameAndType,
+			"deprecationNotice":   getDeprecationNotice,
+			"replacementFor": func(component registryserver.Component, deprecation api.RegistryDeprecation) stepNameAndType {
+				return stepNameAndType{Name: deprecation.Replacement, Type: component.Type}
+			},
+			"parallelMembers": func(step api.TestStep) []api.TestStep {
+				return api.FlattenParallel([]api.TestStep{step})
+			},
 			"noescape": func(str string) template.HTML {
 				return template.HTML(str)
 			},
@@ -686,6 +977,28 @@ func getBaseTemplate() (*template.Template, error) {
 	return base.Funcs(template.FuncMap{"markdown": markDowner}).Parse(templateDefinitions)
 }
 
+// deprecationNotice is rendered as a banner on the pages of deprecated registry components
+type deprecationNotice struct {
+	Type        string
+	Deprecation api.RegistryDeprecation
+	Replacement stepNameAndType
+}
+
+func getDeprecationNotice(componentType string, deprecation *api.RegistryDeprecation) *deprecationNotice {
+	if deprecation == nil {
+		return nil
+	}
+	label := componentType
+	if componentType == "reference" {
+		label = "step"
+	}
+	return &deprecationNotice{
+		Type:        label,
+		Deprecation: *deprecation,
+		Replacement: stepNameAndType{Name: deprecation.Replacement, Type: componentType},
+	}
+}
+
 type stepNameAndType struct {
 	Name string
 	Type string
@@ -850,6 +1163,8 @@ func getEnvironmentDataItems(worklist []api.TestStep, registryRefs registry.Refe
 				}
 				worklist = append(worklist, chain.Steps...)
 			}
+		case step.Parallel != nil:
+			worklist = append(worklist, api.FlattenParallel([]api.TestStep{step})...)
 		case step.LiteralTestStep != nil:
 			for _, env := range step.Environment {
 				add(env.Name, env.Documentation, step.As, env.Default)
@@ -921,6 +1236,8 @@ func getDependencyDataItems(worklist []api.TestStep, registryRefs registry.Refer
 				}
 				worklist = append(worklist, chain.Steps...)
 			}
+		case step.Parallel != nil:
+			worklist = append(worklist, api.FlattenParallel([]api.TestStep{step})...)
 		case step.LiteralTestStep != nil:
 			for _, dep := range step.Dependencies {
 				add(dep.Name, dep.Env, step.As)
@@ -1064,7 +1381,7 @@ func mainPageHandler(agent agents.RegistryAgent, templateString string, w http.R
 	writePage(w, "Step Registry Help Page", page, comps)
 }
 
-func WebRegHandler(regAgent agents.RegistryAgent, confAgent agents.ConfigAgent) http.HandlerFunc {
+func WebRegHandler(regAgent agents.RegistryAgent, confAgent agents.ConfigAgent, searcher *registryserver.Searcher, allowRegistryUploads bool) http.HandlerFunc {
 	return func(w http.ResponseWriter, req *http.Request) {
 		trimmedPath := strings.TrimPrefix(req.URL.Path, req.URL.Host)
 		// remove leading slash
@@ -1080,13 +1397,22 @@ func WebRegHandler(regAgent agents.RegistryAgent, confAgent agents.ConfigAgent)
 				searchHandler(confAgent, w, req)
 			case "job":
 				jobHandler(regAgent, confAgent, w, req)
+			case "impact":
+				impactHandler(regAgent, confAgent, w, req)
+			case "deprecations":
+				deprecationsHandler(regAgent, confAgent, w, req)
+			case "registry-search":
+				registrySearchHandler(searcher, w, req)
+			case "resolved-diff":
+				resolvedDiffHandler(regAgent, confAgent, allowRegistryUploads, w, req)
 			case "ci-operator-reference":
 				ciOpConfigRefHandler(w)
 			default:
 				writeErrorPage(w, errors.New("Invalid path"), http.StatusNotImplemented)
 			}
 			return
-		} else if len(splitURI) == 2 {
+		} else if len(splitURI) == 2 || len(splitURI) == 3 {
+			// components of federated registries have qualified names, e.g. `reference/myteam/install-foo`
 			switch splitURI[0] {
 			case "reference":
 				referenceHandler(regAgent, w, req)
@@ -1258,11 +1584,18 @@ func fromImageDescription(name string, reference *api.ImageStreamTagReference) t
 	return template.HTML(fmt.Sprintf("%s image built or imported by the ci-operator configuration (<a href=\"%s\">documentation</a>).", prefix, fromDocumentation))
 }
 
+// componentName returns the name of the component in the path of a component
+// page, qualified for components of federated registries
+func componentName(req *http.Request) string {
+	_, name, _ := strings.Cut(strings.Trim(req.URL.Path, "/"), "/")
+	return name
+}
+
 func referenceHandler(agent agents.RegistryAgent, w http.ResponseWriter, req *http.Request) {
 	start := time.Now()
 	defer func() { logrus.Infof("rendered in %s", time.Since(start)) }()
 	w.Header().Set("Content-Type", "text/html;charset=UTF-8")
-	name := path.Base(req.URL.Path)
+	name := componentName(req)
 	page, err := baseTemplate.Clone()
 	if err != nil {
 		writeErrorPage(w, fmt.Errorf("Failed to render page: %w", err), http.StatusInternalServerError)
@@ -1329,7 +1662,7 @@ func chainHandler(agent agents.RegistryAgent, w http.ResponseWriter, req *http.R
 	start := time.Now()
 	defer func() { logrus.Infof("rendered in %s", time.Since(start)) }()
 	w.Header().Set("Content-Type", "text/html;charset=UTF-8")
-	name := path.Base(req.URL.Path)
+	name := componentName(req)
 
 	refs, chains, _, docs, metadata := agent.GetRegistryComponents()
 	page, err := baseTemplate.Clone()
@@ -1372,7 +1705,7 @@ func workflowHandler(agent agents.RegistryAgent, w http.ResponseWriter, req *htt
 	start := time.Now()
 	defer func() { logrus.Infof("rendered in %s", time.Since(start)) }()
 	w.Header().Set("Content-Type", "text/html;charset=UTF-8")
-	name := path.Base(req.URL.Path)
+	name := componentName(req)
 
 	refs, chains, workflows, docs, metadata := agent.GetRegistryComponents()
 	page, err := baseTemplate.Clone()
@@ -1621,6 +1954,163 @@ func searchHandler(confAgent agents.ConfigAgent, w http.ResponseWriter, req *htt
 	writePage(w, "Job Search Page", page, matches)
 }
 
+func impactHandler(regAgent agents.RegistryAgent, confAgent agents.ConfigAgent, w http.ResponseWriter, req *http.Request) {
+	start := time.Now()
+	defer func() { logrus.Infof("rendered in %s", time.Since(start)) }()
+	w.Header().Set("Content-Type", "text/html;charset=UTF-8")
+	nodeType, name, err := registryserver.ComponentFromQuery(req)
+	if err != nil {
+		writeErrorPage(w, err, http.StatusBadRequest)
+		return
+	}
+	impact, err := registryserver.GetImpact(regAgent.GetRegistryGraph(), confAgent.GetAll(), nodeType, name)
+	if err != nil {
+		writeErrorPage(w, err, http.StatusNotFound)
+		return
+	}
+	page, err := baseTemplate.Clone()
+	if err != nil {
+		writeErrorPage(w, fmt.Errorf("Failed to render page: %w", err), http.StatusInternalServerError)
+		return
+	}
+	if page, err = page.Parse(impactPage); err != nil {
+		writeErrorPage(w, fmt.Errorf("Failed to render page: %w", err), http.StatusInternalServerError)
+		return
+	}
+	writePage(w, "Registry Impact Page", page, impact)
+}
+
+func deprecationsHandler(regAgent agents.RegistryAgent, confAgent agents.ConfigAgent, w http.ResponseWriter, req *http.Request) {
+	start := time.Now()
+	defer func() { logrus.Infof("rendered in %s", time.Since(start)) }()
+	w.Header().Set("Content-Type", "text/html;charset=UTF-8")
+	_, _, _, _, metadata := regAgent.GetRegistryComponents()
+	inventory, err := registryserver.GetDeprecationInventory(regAgent.GetRegistryGraph(), confAgent.GetAll(), metadata, req.URL.Query().Get(registryserver.OwnerQuery))
+	if err != nil {
+		writeErrorPage(w, err, http.StatusInternalServerError)
+		return
+	}
+	page, err := baseTemplate.Clone()
+	if err != nil {
+		writeErrorPage(w, fmt.Errorf("Failed to render page: %w", err), http.StatusInternalServerError)
+		return
+	}
+	if page, err = page.Parse(deprecationsPage); err != nil {
+		writeErrorPage(w, fmt.Errorf("Failed to render page: %w", err), http.StatusInternalServerError)
+		return
+	}
+	writePage(w, "Deprecated Registry Components", page, inventory)
+}
+
+func registrySearchHandler(searcher *registryserver.Searcher, w http.ResponseWriter, req *http.Request) {
+	start := time.Now()
+	defer func() { logrus.Infof("rendered in %s", time.Since(start)) }()
+	w.Header().Set("Content-Type", "text/html;charset=UTF-8")
+	data := struct {
+		Query    registryserver.SearchQuery
+		Searched bool
+		Results  []registryserver.SearchResult
+	}{}
+	// an empty form only renders the search fields
+	var filled bool
+	for _, values := range req.URL.Query() {
+		for _, value := range values {
+			filled = filled || value != ""
+		}
+	}
+	if filled {
+		query, err := registryserver.SearchQueryFromRequest(req)
+		if err != nil {
+			writeErrorPage(w, err, http.StatusBadRequest)
+			return
+		}
+		data.Query, data.Searched, data.Results = query, true, searcher.Search(query)
+	}
+	page, err := baseTemplate.Clone()
+	if err != nil {
+		writeErrorPage(w, fmt.Errorf("Failed to render page: %w", err), http.StatusInternalServerError)
+		return
+	}
+	if page, err = page.Parse(registrySearchPage); err != nil {
+		writeErrorPage(w, fmt.Errorf("Failed to render page: %w", err), http.StatusInternalServerError)
+		return
+	}
+	writePage(w, "Registry Search Page", page, data)
+}
+
+// maxRegistryUploadMemory is how much of an uploaded registry tarball is
+// kept in memory while parsing the form
+const maxRegistryUploadMemory = 32 << 20
+
+func resolvedDiffHandler(regAgent agents.RegistryAgent, confAgent agents.ConfigAgent, allowUploads bool, w http.ResponseWriter, req *http.Request) {
+	start := time.Now()
+	defer func() { logrus.Infof("rendered in %s", time.Since(start)) }()
+	w.Header().Set("Content-Type", "text/html;charset=UTF-8")
+	if req.Method == http.MethodPost && !allowUploads {
+		writeErrorPage(w, errors.New("registry uploads are disabled, compare two registry versions instead"), http.StatusForbidden)
+		return
+	}
+	upload := allowUploads && (req.Method == http.MethodPost || req.URL.Query().Get("upload") == "true")
+	if req.Method == http.MethodPost {
+		if err := req.ParseMultipartForm(maxRegistryUploadMemory); err != nil {
+			writeErrorPage(w, fmt.Errorf("failed to parse form: %w", err), http.StatusBadRequest)
+			return
+		}
+	}
+	data := struct {
+		Org, Repo, Branch, Variant, Test string
+		From, To                         string
+		Upload, AllowUploads             bool
+		Versions                         []string
+		Diff                             *registryserver.ResolvedDiff
+	}{
+		Org:          req.FormValue(registryserver.OrgQuery),
+		Repo:         req.FormValue(registryserver.RepoQuery),
+		Branch:       req.FormValue(registryserver.BranchQuery),
+		Variant:      req.FormValue(registryserver.VariantQuery),
+		Test:         req.FormValue(registryserver.TestQuery),
+		From:         req.FormValue(registryserver.FromVersionQuery),
+		To:           req.FormValue(registryserver.ToVersionQuery),
+		Upload:       upload,
+		AllowUploads: allowUploads,
+		Versions:     regAgent.GetRegistryVersions(),
+	}
+	if data.Org != "" && data.Repo != "" && data.Branch != "" && data.Test != "" {
+		to := registryserver.VersionResolver(regAgent, data.To)
+		if req.Method == http.MethodPost {
+			file, _, err := req.FormFile("registry")
+			if err != nil {
+				writeErrorPage(w, fmt.Errorf("failed to read registry tarball: %w", err), http.StatusBadRequest)
+				return
+			}
+			defer file.Close()
+			if to, err = registryserver.ArchiveResolver(file); err != nil {
+				writeErrorPage(w, err, http.StatusBadRequest)
+				return
+			}
+		}
+		config, err := confAgent.GetMatchingConfig(api.Metadata{Org: data.Org, Repo: data.Repo, Branch: data.Branch, Variant: data.Variant})
+		if err != nil {
+			writeErrorPage(w, err, http.StatusNotFound)
+			return
+		}
+		if data.Diff, err = registryserver.DiffResolvedTest(config, data.Test, registryserver.VersionResolver(regAgent, data.From), to); err != nil {
+			writeErrorPage(w, err, http.StatusBadRequest)
+			return
+		}
+	}
+	page, err := baseTemplate.Clone()
+	if err != nil {
+		writeErrorPage(w, fmt.Errorf("Failed to render page: %w", err), http.StatusInternalServerError)
+		return
+	}
+	if page, err = page.Parse(resolvedDiffPage); err != nil {
+		writeErrorPage(w, fmt.Errorf("Failed to render page: %w", err), http.StatusInternalServerError)
+		return
+	}
+	writePage(w, "Resolved Test Diff", page, data)
+}
+
 func searchJobs(jobs *Jobs, search string) *Jobs {
 	search = strings.TrimPrefix(search, "pull-ci-")
 	search = strings.TrimPrefix(search, "branch-ci-")
@@ -160,6 +160,9 @@ const ciOperatorReferenceYaml = "# The list of base images describe\n" +
 	"canonical_go_repository_list:\n" +
 	"    - ref: ' '\n" +
 	"      repository: ' '\n" +
+	"# Concurrency limits how many steps are executed at the same\n" +
+	"# time, complementing the per-step Resources.\n" +
+	"concurrency: {}\n" +
 	"# ExternalImages are images that are imported into the pipeline from an external source.\n" +
 	"external_images:\n" +
 	"    \"\":\n" +
@@ -321,6 +324,40 @@ const ciOperatorReferenceYaml = "# The list of base images describe\n" +
 	"              pullspec: ' '\n" +
 	"              # With is the string that the PullSpec is being replaced by\n" +
 	"              with: ' '\n" +
+	"      external_step:\n" +
+	"        # As is the name of the step and of its Pod. It must be a valid\n" +
+	"        # Kubernetes object name. The step runs as the service account\n" +
+	"        # external-<as>.\n" +
+	"        as: ' '\n" +
+	"        # Config is passed verbatim to the implementation and is part of the\n" +
+	"        # inputs of the job.\n" +
+	"        config:\n" +
+	"            \"\": \"\"\n" +
+	"        # Creates lists the pipeline images that the step tags into the\n" +
+	"        # pipeline image stream. The step runs as a service account that is\n" +
+	"        # allowed to tag images into the pipeline image stream.\n" +
+	"        creates:\n" +
+	"            - \"\"\n" +
+	"        # Image is the pull spec of the image implementing the step.\n" +
+	"        image: ' '\n" +
+	"        # Parameters lists the parameters passed to the step. Parameters\n" +
+	"        # exposing images, like IMAGE_FORMAT or LOCAL_IMAGE_SRC, make the step\n" +
+	"        # depend on the steps producing the images.\n" +
+	"        parameters:\n" +
+	"            - \"\"\n" +
+	"        # Provides lists the parameters the step exposes to later steps.\n" +
+	"        provides:\n" +
+	"            - \"\"\n" +
+	"        # Resources are the resource requirements of the step Pod.\n" +
+	"        resources:\n" +
+	"            # Limits are resource limits applied to an individual step in the job.\n" +
+	"            # These are directly used in creating the Pods that execute the Job.\n" +
+	"            limits:\n" +
+	"                \"\": \"\"\n" +
+	"            # Requests are resource requests applied to an individual step in the job.\n" +
+	"            # These are directly used in creating the Pods that execute the Job.\n" +
+	"            requests:\n" +
+	"                \"\": \"\"\n" +
 	"      index_generator_step:\n" +
 	"        # BaseIndex is the index image to add the bundle(s) to. If unset, a new index is created\n" +
 	"        base_index: ' '\n" +
@@ -592,6 +629,9 @@ const ciOperatorReferenceYaml = "# The list of base images describe\n" +
 	"        # of pull request workflows. Setting this field will\n" +
 	"        # create a periodic job instead of a presubmit\n" +
 	"        cron: \"\"\n" +
+	"        # ExecutionPolicy determines how the execution continues after a step\n" +
+	"        # fails when this test is targeted. Defaults to keep-going.\n" +
+	"        execution_policy: ' '\n" +
 	"        # Interval is how frequently the test should be run based\n" +
 	"        # on the last time the test ran. Setting this field will\n" +
 	"        # create a periodic job instead of a presubmit\n" +
@@ -644,8 +684,19 @@ const ciOperatorReferenceYaml = "# The list of base images describe\n" +
 	"                      default: \"\"\n" +
 	"                      # Documentation is a textual description of the parameter.\n" +
 	"                      documentation: ' '\n" +
+	"                      # Enum, if set, lists all values accepted by the parameter.\n" +
+	"                      enum:\n" +
+	"                        - \"\"\n" +
 	"                      # Name of the environment variable.\n" +
 	"                      name: ' '\n" +
+	"                      # Pattern, if set, is a regular expression that the entire value of the\n" +
+	"                      # parameter must match.\n" +
+	"                      # An empty value is always accepted regardless of the type, enum and\n" +
+	"                      # pattern, as steps conventionally treat it as unset.\n" +
+	"                      pattern: ' '\n" +
+	"                      # Type of the value of the parameter, one of `string` (the default),\n" +
+	"                      # `integer` or `boolean`. Boolean parameters accept `true` or `false`.\n" +
+	"                      type: ' '\n" +
 	"                  # From is the container image that will be used for this observer.\n" +
 	"                  from: ' '\n" +
 	"                  # FromImage is a literal ImageStreamTag reference to use for this observer.\n" +
@@ -672,6 +723,11 @@ const ciOperatorReferenceYaml = "# The list of base images describe\n" +
 	"                        \"\": \"\"\n" +
 	"                  # Timeout is how long the we will wait before aborting a job with SIGINT.\n" +
 	"                  timeout: 0s\n" +
+	"            # ParallelGroups maps the steps resolved from a `parallel` group to the\n" +
+	"            # name of the group and is set by the resolver. Adjacent steps in a phase\n" +
+	"            # with the same group run concurrently.\n" +
+	"            parallel_groups:\n" +
+	"                \"\": \"\"\n" +
 	"            # Post is the array of test steps run after the tests finish and teardown/deprovision resources.\n" +
 	"            # Post steps always run, even if previous steps fail.\n" +
 	"            post:\n" +
@@ -716,8 +772,19 @@ const ciOperatorReferenceYaml = "# The list of base images describe\n" +
 	"                      default: \"\"\n" +
 	"                      # Documentation is a textual description of the parameter.\n" +
 	"                      documentation: ' '\n" +
+	"                      # Enum, if set, lists all values accepted by the parameter.\n" +
+	"                      enum:\n" +
+	"                        - \"\"\n" +
 	"                      # Name of the environment variable.\n" +
 	"                      name: ' '\n" +
+	"                      # Pattern, if set, is a regular expression that the entire value of the\n" +
+	"                      # parameter must match.\n" +
+	"                      # An empty value is always accepted regardless of the type, enum and\n" +
+	"                      # pattern, as steps conventionally treat it as unset.\n" +
+	"                      pattern: ' '\n" +
+	"                      # Type of the value of the parameter, one of `string` (the default),\n" +
+	"                      # `integer` or `boolean`. Boolean parameters accept `true` or `false`.\n" +
+	"                      type: ' '\n" +
 	"                  # From is the container image that will be used for this step.\n" +
 	"                  from: ' '\n" +
 	"                  # FromImage is a literal ImageStreamTag reference to use for this step.\n" +
@@ -730,6 +797,14 @@ const ciOperatorReferenceYaml = "# The list of base images describe\n" +
 	"                  # GracePeriod is how long the we will wait after sending SIGINT to send\n" +
 	"                  # SIGKILL when aborting a Step.\n" +
 	"                  grace_period: 0s\n" +
+	"                  # Inputs lists the outputs of earlier steps that this step requires.\n" +
+	"                  inputs:\n" +
+	"                    - # Env is the environment variable used to expose the output to the step.\n" +
+	"                      env: ' '\n" +
+	"                      # Name of the output.\n" +
+	"                      name: ' '\n" +
+	"                      # Type is the (optional) expected type of the output.\n" +
+	"                      type: ' '\n" +
 	"                  # Leases lists resources that should be acquired for the test.\n" +
 	"                  leases:\n" +
 	"                    - # Env is the environment variable that will contain the resource name.\n" +
@@ -751,6 +826,15 @@ const ciOperatorReferenceYaml = "# The list of base images describe\n" +
 	"                  # flag is set to true in MultiStageTestConfiguration. This option is\n" +
 	"                  # applicable to `post` steps.\n" +
 	"                  optional_on_success: false\n" +
+	"                  # Outputs lists the values this step publishes for later steps. Outputs\n" +
+	"                  # are stored in ${SHARED_DIR}, so they share its size limit of 1 MiB.\n" +
+	"                  outputs:\n" +
+	"                    - # Documentation is a textual description of the output.\n" +
+	"                      documentation: ' '\n" +
+	"                      # Name of the output, which is also the name of the file in ${SHARED_DIR}.\n" +
+	"                      name: ' '\n" +
+	"                      # Type of the output, `string` or `file`.\n" +
+	"                      type: ' '\n" +
 	"                  # Resources defines the resource requirements for the step.\n" +
 	"                  resources:\n" +
 	"                    # Limits are resource limits applied to an individual step in the job.\n" +
@@ -761,11 +845,30 @@ const ciOperatorReferenceYaml = "# The list of base images describe\n" +
 	"                    # These are directly used in creating the Pods that execute the Job.\n" +
 	"                    requests:\n" +
 	"                        \"\": \"\"\n" +
+	"                  # Retry is the (optional) policy used to run the step again when it fails\n" +
+	"                  # because of a transient infrastructure problem.\n" +
+	"                  retry:\n" +
+	"                    # Attempts is the maximum number of times the step is run, including the\n" +
+	"                    # first attempt.\n" +
+	"                    attempts: 0\n" +
+	"                    # Backoff is how long we wait before the first retry. The wait is doubled\n" +
+	"                    # for each subsequent retry. Defaults to 30 seconds.\n" +
+	"                    backoff: 0s\n" +
+	"                    # On lists the classes of failures that are retried. Defaults to all of\n" +
+	"                    # them.\n" +
+	"                    \"on\":\n" +
+	"                        - \"\"\n" +
 	"                  # RunAsScript defines if this step should be executed as a script mounted\n" +
 	"                  # in the test container instead of being executed directly via bash\n" +
 	"                  run_as_script: false\n" +
 	"                  # Timeout is how long the we will wait before aborting a job with SIGINT.\n" +
 	"                  timeout: 0s\n" +
+	"                  # When is an (optional) expression that determines whether the step runs,\n" +
+	"                  # evaluated right before the step would start. For example, `failure()`\n" +
+	"                  # runs the step only if an earlier step failed and `env.UPGRADE == \"true\"`\n" +
+	"                  # only if the UPGRADE parameter of the step is set to \"true\". See\n" +
+	"                  # WhenExpression for the syntax.\n" +
+	"                  when: ' '\n" +
 	"            # Pre is the array of test steps run to set up the environment for the test.\n" +
 	"            pre:\n" +
 	"                - # As is the name of the LiteralTestStep.\n" +
@@ -809,8 +912,19 @@ const ciOperatorReferenceYaml = "# The list of base images describe\n" +
 	"                      default: \"\"\n" +
 	"                      # Documentation is a textual description of the parameter.\n" +
 	"                      documentation: ' '\n" +
+	"                      # Enum, if set, lists all values accepted by the parameter.\n" +
+	"                      enum:\n" +
+	"                        - \"\"\n" +
 	"                      # Name of the environment variable.\n" +
 	"                      name: ' '\n" +
+	"                      # Pattern, if set, is a regular expression that the entire value of the\n" +
+	"                      # parameter must match.\n" +
+	"                      # An empty value is always accepted regardless of the type, enum and\n" +
+	"                      # pattern, as steps conventionally treat it as unset.\n" +
+	"                      pattern: ' '\n" +
+	"                      # Type of the value of the parameter, one of `string` (the default),\n" +
+	"                      # `integer` or `boolean`. Boolean parameters accept `true` or `false`.\n" +
+	"                      type: ' '\n" +
 	"                  # From is the container image that will be used for this step.\n" +
 	"                  from: ' '\n" +
 	"                  # FromImage is a literal ImageStreamTag reference to use for this step.\n" +
@@ -823,6 +937,14 @@ const ciOperatorReferenceYaml = "# The list of base images describe\n" +
 	"                  # GracePeriod is how long the we will wait after sending SIGINT to send\n" +
 	"                  # SIGKILL when aborting a Step.\n" +
 	"                  grace_period: 0s\n" +
+	"                  # Inputs lists the outputs of earlier steps that this step requires.\n" +
+	"                  inputs:\n" +
+	"                    - # Env is the environment variable used to expose the output to the step.\n" +
+	"                      env: ' '\n" +
+	"                      # Name of the output.\n" +
+	"                      name: ' '\n" +
+	"                      # Type is the (optional) expected type of the output.\n" +
+	"                      type: ' '\n" +
 	"                  # Leases lists resources that should be acquired for the test.\n" +
 	"                  leases:\n" +
 	"                    - # Env is the environment variable that will contain the resource name.\n" +
@@ -844,6 +966,15 @@ const ciOperatorReferenceYaml = "# The list of base images describe\n" +
 	"                  # flag is set to true in MultiStageTestConfiguration. This option is\n" +
 	"                  # applicable to `post` steps.\n" +
 	"                  optional_on_success: false\n" +
+	"                  # Outputs lists the values this step publishes for later steps. Outputs\n" +
+	"                  # are stored in ${SHARED_DIR}, so they share its size limit of 1 MiB.\n" +
+	"                  outputs:\n" +
+	"                    - # Documentation is a textual description of the output.\n" +
+	"                      documentation: ' '\n" +
+	"                      # Name of the output, which is also the name of the file in ${SHARED_DIR}.\n" +
+	"                      name: ' '\n" +
+	"                      # Type of the output, `string` or `file`.\n" +
+	"                      type: ' '\n" +
 	"                  # Resources defines the resource requirements for the step.\n" +
 	"                  resources:\n" +
 	"                    # Limits are resource limits applied to an individual step in the job.\n" +
@@ -854,11 +985,30 @@ const ciOperatorReferenceYaml = "# The list of base images describe\n" +
 	"                    # These are directly used in creating the Pods that execute the Job.\n" +
 	"                    requests:\n" +
 	"                        \"\": \"\"\n" +
+	"                  # Retry is the (optional) policy used to run the step again when it fails\n" +
+	"                  # because of a transient infrastructure problem.\n" +
+	"                  retry:\n" +
+	"                    # Attempts is the maximum number of times the step is run, including the\n" +
+	"                    # first attempt.\n" +
+	"                    attempts: 0\n" +
+	"                    # Backoff is how long we wait before the first retry. The wait is doubled\n" +
+	"                    # for each subsequent retry. Defaults to 30 seconds.\n" +
+	"                    backoff: 0s\n" +
+	"                    # On lists the classes of failures that are retried. Defaults to all of\n" +
+	"                    # them.\n" +
+	"                    \"on\":\n" +
+	"                        - \"\"\n" +
 	"                  # RunAsScript defines if this step should be executed as a script mounted\n" +
 	"                  # in the test container instead of being executed directly via bash\n" +
 	"                  run_as_script: false\n" +
 	"                  # Timeout is how long the we will wait before aborting a job with SIGINT.\n" +
 	"                  timeout: 0s\n" +
+	"                  # When is an (optional) expression that determines whether the step runs,\n" +
+	"                  # evaluated right before the step would start. For example, `failure()`\n" +
+	"                  # runs the step only if an earlier step failed and `env.UPGRADE == \"true\"`\n" +
+	"                  # only if the UPGRADE parameter of the step is set to \"true\". See\n" +
+	"                  # WhenExpression for the syntax.\n" +
+	"                  when: ' '\n" +
 	"            # Test is the array of test steps that define the actual test.\n" +
 	"            test:\n" +
 	"                - # As is the name of the LiteralTestStep.\n" +
@@ -902,8 +1052,19 @@ const ciOperatorReferenceYaml = "# The list of base images describe\n" +
 	"                      default: \"\"\n" +
 	"                      # Documentation is a textual description of the parameter.\n" +
 	"                      documentation: ' '\n" +
+	"                      # Enum, if set, lists all values accepted by the parameter.\n" +
+	"                      enum:\n" +
+	"                        - \"\"\n" +
 	"                      # Name of the environment variable.\n" +
 	"                      name: ' '\n" +
+	"                      # Pattern, if set, is a regular expression that the entire value of the\n" +
+	"                      # parameter must match.\n" +
+	"                      # An empty value is always accepted regardless of the type, enum and\n" +
+	"                      # pattern, as steps conventionally treat it as unset.\n" +
+	"                      pattern: ' '\n" +
+	"                      # Type of the value of the parameter, one of `string` (the default),\n" +
+	"                      # `integer` or `boolean`. Boolean parameters accept `true` or `false`.\n" +
+	"                      type: ' '\n" +
 	"                  # From is the container image that will be used for this step.\n" +
 	"                  from: ' '\n" +
 	"                  # FromImage is a literal ImageStreamTag reference to use for this step.\n" +
@@ -916,6 +1077,14 @@ const ciOperatorReferenceYaml = "# The list of base images describe\n" +
 	"                  # GracePeriod is how long the we will wait after sending SIGINT to send\n" +
 	"                  # SIGKILL when aborting a Step.\n" +
 	"                  grace_period: 0s\n" +
+	"                  # Inputs lists the outputs of earlier steps that this step requires.\n" +
+	"                  inputs:\n" +
+	"                    - # Env is the environment variable used to expose the output to the step.\n" +
+	"                      env: ' '\n" +
+	"                      # Name of the output.\n" +
+	"                      name: ' '\n" +
+	"                      # Type is the (optional) expected type of the output.\n" +
+	"                      type: ' '\n" +
 	"                  # Leases lists resources that should be acquired for the test.\n" +
 	"                  leases:\n" +
 	"                    - # Env is the environment variable that will contain the resource name.\n" +
@@ -937,6 +1106,15 @@ const ciOperatorReferenceYaml = "# The list of base images describe\n" +
 	"                  # flag is set to true in MultiStageTestConfiguration. This option is\n" +
 	"                  # applicable to `post` steps.\n" +
 	"                  optional_on_success: false\n" +
+	"                  # Outputs lists the values this step publishes for later steps. Outputs\n" +
+	"                  # are stored in ${SHARED_DIR}, so they share its size limit of 1 MiB.\n" +
+	"                  outputs:\n" +
+	"                    - # Documentation is a textual description of the output.\n" +
+	"                      documentation: ' '\n" +
+	"                      # Name of the output, which is also the name of the file in ${SHARED_DIR}.\n" +
+	"                      name: ' '\n" +
+	"                      # Type of the output, `string` or `file`.\n" +
+	"                      type: ' '\n" +
 	"                  # Resources defines the resource requirements for the step.\n" +
 	"                  resources:\n" +
 	"                    # Limits are resource limits applied to an individual step in the job.\n" +
@@ -947,11 +1125,30 @@ const ciOperatorReferenceYaml = "# The list of base images describe\n" +
 	"                    # These are directly used in creating the Pods that execute the Job.\n" +
 	"                    requests:\n" +
 	"                        \"\": \"\"\n" +
+	"                  # Retry is the (optional) policy used to run the step again when it fails\n" +
+	"                  # because of a transient infrastructure problem.\n" +
+	"                  retry:\n" +
+	"                    # Attempts is the maximum number of times the step is run, including the\n" +
+	"                    # first attempt.\n" +
+	"                    attempts: 0\n" +
+	"                    # Backoff is how long we wait before the first retry. The wait is doubled\n" +
+	"                    # for each subsequent retry. Defaults to 30 seconds.\n" +
+	"                    backoff: 0s\n" +
+	"                    # On lists the classes of failures that are retried. Defaults to all of\n" +
+	"                    # them.\n" +
+	"                    \"on\":\n" +
+	"                        - \"\"\n" +
 	"                  # RunAsScript defines if this step should be executed as a script mounted\n" +
 	"                  # in the test container instead of being executed directly via bash\n" +
 	"                  run_as_script: false\n" +
 	"                  # Timeout is how long the we will wait before aborting a job with SIGINT.\n" +
 	"                  timeout: 0s\n" +
+	"                  # When is an (optional) expression that determines whether the step runs,\n" +
+	"                  # evaluated right before the step would start. For example, `failure()`\n" +
+	"                  # runs the step only if an earlier step failed and `env.UPGRADE == \"true\"`\n" +
+	"                  # only if the UPGRADE parameter of the step is set to \"true\". See\n" +
+	"                  # WhenExpression for the syntax.\n" +
+	"                  when: ' '\n" +
 	"            # Override job timeout\n" +
 	"            timeout: 0s\n" +
 	"        # MinimumInterval to wait between two runs of the job. Consecutive\n" +
@@ -1111,7 +1308,12 @@ const ciOperatorReferenceYaml = "# The list of base images describe\n" +
 	"                    # LiteralTestStep is a full test step definition.\n" +
 	"                    - default: \"\"\n" +
 	"                      documentation: ' '\n" +
+	"                      enum:\n" +
+	"                        # LiteralTestStep is a full test step definition.\n" +
+	"                        - \"\"\n" +
 	"                      name: ' '\n" +
+	"                      pattern: ' '\n" +
+	"                      type: ' '\n" +
 	"                  from: ' '\n" +
 	"                  from_image:\n" +
 	"                    # LiteralTestStep is a full test step definition.\n" +
@@ -1120,6 +1322,11 @@ const ciOperatorReferenceYaml = "# The list of base images describe\n" +
 	"                    namespace: ' '\n" +
 	"                    tag: ' '\n" +
 	"                  grace_period: 0s\n" +
+	"                  inputs:\n" +
+	"                    # LiteralTestStep is a full test step definition.\n" +
+	"                    - env: ' '\n" +
+	"                      name: ' '\n" +
+	"                      type: ' '\n" +
 	"                  leases:\n" +
 	"                    # LiteralTestStep is a full test step definition.\n" +
 	"                    - env: ' '\n" +
@@ -1131,6 +1338,97 @@ const ciOperatorReferenceYaml = "# The list of base images describe\n" +
 	"                    # LiteralTestStep is a full test step definition.\n" +
 	"                    - \"\"\n" +
 	"                  optional_on_success: false\n" +
+	"                  outputs:\n" +
+	"                    # LiteralTestStep is a full test step definition.\n" +
+	"                    - documentation: ' '\n" +
+	"                      name: ' '\n" +
+	"                      type: ' '\n" +
+	"                  # Parallel is a group of steps that run concurrently. Changes the\n" +
+	"                  # members make to the shared directory are merged once all of them\n" +
+	"                  # finish and conflicting changes to the same file fail the group.\n" +
+	"                  parallel:\n" +
+	"                    # LiteralTestStep is a full test step definition.\n" +
+	"                    - as: ' '\n" +
+	"                      best_effort: false\n" +
+	"                      cli: ' '\n" +
+	"                      commands: ' '\n" +
+	"                      credentials:\n" +
+	"                        # LiteralTestStep is a full test step definition.\n" +
+	"                        - mount_path: ' '\n" +
+	"                          name: ' '\n" +
+	"                          namespace: ' '\n" +
+	"                      dependencies:\n" +
+	"                        # LiteralTestStep is a full test step definition.\n" +
+	"                        - env: ' '\n" +
+	"                          name: ' '\n" +
+	"                      dnsConfig:\n" +
+	"                        # LiteralTestStep is a full test step definition.\n" +
+	"                        nameservers:\n" +
+	"                            # LiteralTestStep is a full test step definition.\n" +
+	"                            - \"\"\n" +
+	"                        searches:\n" +
+	"                            # LiteralTestStep is a full test step definition.\n" +
+	"                            - \"\"\n" +
+	"                      env:\n" +
+	"                        # LiteralTestStep is a full test step definition.\n" +
+	"                        - default: \"\"\n" +
+	"                          documentation: ' '\n" +
+	"                          enum:\n" +
+	"                            # LiteralTestStep is a full test step definition.\n" +
+	"                            - \"\"\n" +
+	"                          name: ' '\n" +
+	"                          pattern: ' '\n" +
+	"                          type: ' '\n" +
+	"                      from: ' '\n" +
+	"                      from_image:\n" +
+	"                        # LiteralTestStep is a full test step definition.\n" +
+	"                        as: ' '\n" +
+	"                        name: ' '\n" +
+	"                        namespace: ' '\n" +
+	"                        tag: ' '\n" +
+	"                      grace_period: 0s\n" +
+	"                      inputs:\n" +
+	"                        # LiteralTestStep is a full test step definition.\n" +
+	"                        - env: ' '\n" +
+	"                          name: ' '\n" +
+	"                          type: ' '\n" +
+	"                      leases:\n" +
+	"                        # LiteralTestStep is a full test step definition.\n" +
+	"                        - env: ' '\n" +
+	"                          resource_type: ' '\n" +
+	"                      no_kubeconfig: false\n" +
+	"                      node_architecture: \"\"\n" +
+	"                      # Observers are the observers that should be running\n" +
+	"                      observers:\n" +
+	"                        # LiteralTestStep is a full test step definition.\n" +
+	"                        - \"\"\n" +
+	"                      optional_on_success: false\n" +
+	"                      outputs:\n" +
+	"                        # LiteralTestStep is a full test step definition.\n" +
+	"                        - documentation: ' '\n" +
+	"                          name: ' '\n" +
+	"                          type: ' '\n" +
+	"                      # Reference is the name of a step reference.\n" +
+	"                      ref: \"\"\n" +
+	"                      # Resources defines the resource requirements for the step.\n" +
+	"                      resources:\n" +
+	"                        # LiteralTestStep is a full test step definition.\n" +
+	"                        limits:\n" +
+	"                            # LiteralTestStep is a full test step definition.\n" +
+	"                            \"\": \"\"\n" +
+	"                        requests:\n" +
+	"                            # LiteralTestStep is a full test step definition.\n" +
+	"                            \"\": \"\"\n" +
+	"                      retry:\n" +
+	"                        # LiteralTestStep is a full test step definition.\n" +
+	"                        attempts: 0\n" +
+	"                        backoff: 0s\n" +
+	"                        \"on\":\n" +
+	"                            # LiteralTestStep is a full test step definition.\n" +
+	"                            - \"\"\n" +
+	"                      run_as_script: false\n" +
+	"                      timeout: 0s\n" +
+	"                      when: ' '\n" +
 	"                  # Reference is the name of a step reference.\n" +
 	"                  ref: \"\"\n" +
 	"                  # Resources defines the resource requirements for the step.\n" +
@@ -1142,8 +1440,16 @@ const ciOperatorReferenceYaml = "# The list of base images describe\n" +
 	"                    requests:\n" +
 	"                        # LiteralTestStep is a full test step definition.\n" +
 	"                        \"\": \"\"\n" +
+	"                  retry:\n" +
+	"                    # LiteralTestStep is a full test step definition.\n" +
+	"                    attempts: 0\n" +
+	"                    backoff: 0s\n" +
+	"                    \"on\":\n" +
+	"                        # LiteralTestStep is a full test step definition.\n" +
+	"                        - \"\"\n" +
 	"                  run_as_script: false\n" +
 	"                  timeout: 0s\n" +
+	"                  when: ' '\n" +
 	"            # Pre is the array of test steps run to set up the environment for the test.\n" +
 	"            pre:\n" +
 	"                # LiteralTestStep is a full test step definition.\n" +
@@ -1176,7 +1482,12 @@ const ciOperatorReferenceYaml = "# The list of base images describe\n" +
 	"                    # LiteralTestStep is a full test step definition.\n" +
 	"                    - default: \"\"\n" +
 	"                      documentation: ' '\n" +
+	"                      enum:\n" +
+	"                        # LiteralTestStep is a full test step definition.\n" +
+	"                        - \"\"\n" +
 	"                      name: ' '\n" +
+	"                      pattern: ' '\n" +
+	"                      type: ' '\n" +
 	"                  from: ' '\n" +
 	"                  from_image:\n" +
 	"                    # LiteralTestStep is a full test step definition.\n" +
@@ -1185,6 +1496,11 @@ const ciOperatorReferenceYaml = "# The list of base images describe\n" +
 	"                    namespace: ' '\n" +
 	"                    tag: ' '\n" +
 	"                  grace_period: 0s\n" +
+	"                  inputs:\n" +
+	"                    # LiteralTestStep is a full test step definition.\n" +
+	"                    - env: ' '\n" +
+	"                      name: ' '\n" +
+	"                      type: ' '\n" +
 	"                  leases:\n" +
 	"                    # LiteralTestStep is a full test step definition.\n" +
 	"                    - env: ' '\n" +
@@ -1196,6 +1512,97 @@ const ciOperatorReferenceYaml = "# The list of base images describe\n" +
 	"                    # LiteralTestStep is a full test step definition.\n" +
 	"                    - \"\"\n" +
 	"                  optional_on_success: false\n" +
+	"                  outputs:\n" +
+	"                    # LiteralTestStep is a full test step definition.\n" +
+	"                    - documentation: ' '\n" +
+	"                      name: ' '\n" +
+	"                      type: ' '\n" +
+	"                  # Parallel is a group of steps that run concurrently. Changes the\n" +
+	"                  # members make to the shared directory are merged once all of them\n" +
+	"                  # finish and conflicting changes to the same file fail the group.\n" +
+	"                  parallel:\n" +
+	"                    # LiteralTestStep is a full test step definition.\n" +
+	"                    - as: ' '\n" +
+	"                      best_effort: false\n" +
+	"                      cli: ' '\n" +
+	"                      commands: ' '\n" +
+	"                      credentials:\n" +
+	"                        # LiteralTestStep is a full test step definition.\n" +
+	"                        - mount_path: ' '\n" +
+	"                          name: ' '\n" +
+	"                          namespace: ' '\n" +
+	"                      dependencies:\n" +
+	"                        # LiteralTestStep is a full test step definition.\n" +
+	"                        - env: ' '\n" +
+	"                          name: ' '\n" +
+	"                      dnsConfig:\n" +
+	"                        # LiteralTestStep is a full test step definition.\n" +
+	"                        nameservers:\n" +
+	"                            # LiteralTestStep is a full test step definition.\n" +
+	"                            - \"\"\n" +
+	"                        searches:\n" +
+	"                            # LiteralTestStep is a full test step definition.\n" +
+	"                            - \"\"\n" +
+	"                      env:\n" +
+	"                        # LiteralTestStep is a full test step definition.\n" +
+	"                        - default: \"\"\n" +
+	"                          documentation: ' '\n" +
+	"                          enum:\n" +
+	"                            # LiteralTestStep is a full test step definition.\n" +
+	"                            - \"\"\n" +
+	"                          name: ' '\n" +
+	"                          pattern: ' '\n" +
+	"                          type: ' '\n" +
+	"                      from: ' '\n" +
+	"                      from_image:\n" +
+	"                        # LiteralTestStep is a full test step definition.\n" +
+	"                        as: ' '\n" +
+	"                        name: ' '\n" +
+	"                        namespace: ' '\n" +
+	"                        tag: ' '\n" +
+	"                      grace_period: 0s\n" +
+	"                      inputs:\n" +
+	"                        # LiteralTestStep is a full test step definition.\n" +
+	"                        - env: ' '\n" +
+	"                          name: ' '\n" +
+	"                          type: ' '\n" +
+	"                      leases:\n" +
+	"                        # LiteralTestStep is a full test step definition.\n" +
+	"                        - env: ' '\n" +
+	"                          resource_type: ' '\n" +
+	"                      no_kubeconfig: false\n" +
+	"                      node_architecture: \"\"\n" +
+	"                      # Observers are the observers that should be running\n" +
+	"                      observers:\n" +
+	"                        # LiteralTestStep is a full test step definition.\n" +
+	"                        - \"\"\n" +
+	"                      optional_on_success: false\n" +
+	"                      outputs:\n" +
+	"                        # LiteralTestStep is a full test step definition.\n" +
+	"                        - documentation: ' '\n" +
+	"                          name: ' '\n" +
+	"                          type: ' '\n" +
+	"                      # Reference is the name of a step reference.\n" +
+	"                      ref: \"\"\n" +
+	"                      # Resources defines the resource requirements for the step.\n" +
+	"                      resources:\n" +
+	"                        # LiteralTestStep is a full test step definition.\n" +
+	"                        limits:\n" +
+	"                            # LiteralTestStep is a full test step definition.\n" +
+	"                            \"\": \"\"\n" +
+	"                        requests:\n" +
+	"                            # LiteralTestStep is a full test step definition.\n" +
+	"                            \"\": \"\"\n" +
+	"                      retry:\n" +
+	"                        # LiteralTestStep is a full test step definition.\n" +
+	"                        attempts: 0\n" +
+	"                        backoff: 0s\n" +
+	"                        \"on\":\n" +
+	"                            # LiteralTestStep is a full test step definition.\n" +
+	"                            - \"\"\n" +
+	"                      run_as_script: false\n" +
+	"                      timeout: 0s\n" +
+	"                      when: ' '\n" +
 	"                  # Reference is the name of a step reference.\n" +
 	"                  ref: \"\"\n" +
 	"                  # Resources defines the resource requirements for the step.\n" +
@@ -1207,8 +1614,16 @@ const ciOperatorReferenceYaml = "# The list of base images describe\n" +
 	"                    requests:\n" +
 	"                        # LiteralTestStep is a full test step definition.\n" +
 	"                        \"\": \"\"\n" +
+	"                  retry:\n" +
+	"                    # LiteralTestStep is a full test step definition.\n" +
+	"                    attempts: 0\n" +
+	"                    backoff: 0s\n" +
+	"                    \"on\":\n" +
+	"                        # LiteralTestStep is a full test step definition.\n" +
+	"                        - \"\"\n" +
 	"                  run_as_script: false\n" +
 	"                  timeout: 0s\n" +
+	"                  when: ' '\n" +
 	"            # Test is the array of test steps that define the actual test.\n" +
 	"            test:\n" +
 	"                # LiteralTestStep is a full test step definition.\n" +
@@ -1241,7 +1656,12 @@ const ciOperatorReferenceYaml = "# The list of base images describe\n" +
 	"                    # LiteralTestStep is a full test step definition.\n" +
 	"                    - default: \"\"\n" +
 	"                      documentation: ' '\n" +
+	"                      enum:\n" +
+	"                        # LiteralTestStep is a full test step definition.\n" +
+	"                        - \"\"\n" +
 	"                      name: ' '\n" +
+	"                      pattern: ' '\n" +
+	"                      type: ' '\n" +
 	"                  from: ' '\n" +
 	"                  from_image:\n" +
 	"                    # LiteralTestStep is a full test step definition.\n" +
@@ -1250,6 +1670,11 @@ const ciOperatorReferenceYaml = "# The list of base images describe\n" +
 	"                    namespace: ' '\n" +
 	"                    tag: ' '\n" +
 	"                  grace_period: 0s\n" +
+	"                  inputs:\n" +
+	"                    # LiteralTestStep is a full test step definition.\n" +
+	"                    - env: ' '\n" +
+	"                      name: ' '\n" +
+	"                      type: ' '\n" +
 	"                  leases:\n" +
 	"                    # LiteralTestStep is a full test step definition.\n" +
 	"                    - env: ' '\n" +
@@ -1261,6 +1686,97 @@ const ciOperatorReferenceYaml = "# The list of base images describe\n" +
 	"                    # LiteralTestStep is a full test step definition.\n" +
 	"                    - \"\"\n" +
 	"                  optional_on_success: false\n" +
+	"                  outputs:\n" +
+	"                    # LiteralTestStep is a full test step definition.\n" +
+	"                    - documentation: ' '\n" +
+	"                      name: ' '\n" +
+	"                      type: ' '\n" +
+	"                  # Parallel is a group of steps that run concurrently. Changes the\n" +
+	"                  # members make to the shared directory are merged once all of them\n" +
+	"                  # finish and conflicting changes to the same file fail the group.\n" +
+	"                  parallel:\n" +
+	"                    # LiteralTestStep is a full test step definition.\n" +
+	"                    - as: ' '\n" +
+	"                      best_effort: false\n" +
+	"                      cli: ' '\n" +
+	"                      commands: ' '\n" +
+	"                      credentials:\n" +
+	"                        # LiteralTestStep is a full test step definition.\n" +
+	"                        - mount_path: ' '\n" +
+	"                          name: ' '\n" +
+	"                          namespace: ' '\n" +
+	"                      dependencies:\n" +
+	"                        # LiteralTestStep is a full test step definition.\n" +
+	"                        - env: ' '\n" +
+	"                          name: ' '\n" +
+	"                      dnsConfig:\n" +
+	"                        # LiteralTestStep is a full test step definition.\n" +
+	"                        nameservers:\n" +
+	"                            # LiteralTestStep is a full test step definition.\n" +
+	"                            - \"\"\n" +
+	"                        searches:\n" +
+	"                            # LiteralTestStep is a full test step definition.\n" +
+	"                            - \"\"\n" +
+	"                      env:\n" +
+	"                        # LiteralTestStep is a full test step definition.\n" +
+	"                        - default: \"\"\n" +
+	"                          documentation: ' '\n" +
+	"                          enum:\n" +
+	"                            # LiteralTestStep is a full test step definition.\n" +
+	"                            - \"\"\n" +
+	"                          name: ' '\n" +
+	"                          pattern: ' '\n" +
+	"                          type: ' '\n" +
+	"                      from: ' '\n" +
+	"                      from_image:\n" +
+	"                        # LiteralTestStep is a full test step definition.\n" +
+	"                        as: ' '\n" +
+	"                        name: ' '\n" +
+	"                        namespace: ' '\n" +
+	"                        tag: ' '\n" +
+	"                      grace_period: 0s\n" +
+	"                      inputs:\n" +
+	"                        # LiteralTestStep is a full test step definition.\n" +
+	"                        - env: ' '\n" +
+	"                          name: ' '\n" +
+	"                          type: ' '\n" +
+	"                      leases:\n" +
+	"                        # LiteralTestStep is a full test step definition.\n" +
+	"                        - env: ' '\n" +
+	"                          resource_type: ' '\n" +
+	"                      no_kubeconfig: false\n" +
+	"                      node_architecture: \"\"\n" +
+	"                      # Observers are the observers that should be running\n" +
+	"                      observers:\n" +
+	"                        # LiteralTestStep is a full test step definition.\n" +
+	"                        - \"\"\n" +
+	"                      optional_on_success: false\n" +
+	"                      outputs:\n" +
+	"                        # LiteralTestStep is a full test step definition.\n" +
+	"                        - documentation: ' '\n" +
+	"                          name: ' '\n" +
+	"                          type: ' '\n" +
+	"                      # Reference is the name of a step reference.\n" +
+	"                      ref: \"\"\n" +
+	"                      # Resources defines the resource requirements for the step.\n" +
+	"                      resources:\n" +
+	"                        # LiteralTestStep is a full test step definition.\n" +
+	"                        limits:\n" +
+	"                            # LiteralTestStep is a full test step definition.\n" +
+	"                            \"\": \"\"\n" +
+	"                        requests:\n" +
+	"                            # LiteralTestStep is a full test step definition.\n" +
+	"                            \"\": \"\"\n" +
+	"                      retry:\n" +
+	"                        # LiteralTestStep is a full test step definition.\n" +
+	"                        attempts: 0\n" +
+	"                        backoff: 0s\n" +
+	"                        \"on\":\n" +
+	"                            # LiteralTestStep is a full test step definition.\n" +
+	"                            - \"\"\n" +
+	"                      run_as_script: false\n" +
+	"                      timeout: 0s\n" +
+	"                      when: ' '\n" +
 	"                  # Reference is the name of a step reference.\n" +
 	"                  ref: \"\"\n" +
 	"                  # Resources defines the resource requirements for the step.\n" +
@@ -1272,13 +1788,27 @@ const ciOperatorReferenceYaml = "# The list of base images describe\n" +
 	"                    requests:\n" +
 	"                        # LiteralTestStep is a full test step definition.\n" +
 	"                        \"\": \"\"\n" +
+	"                  retry:\n" +
+	"                    # LiteralTestStep is a full test step definition.\n" +
+	"                    attempts: 0\n" +
+	"                    backoff: 0s\n" +
+	"                    \"on\":\n" +
+	"                        # LiteralTestStep is a full test step definition.\n" +
+	"                        - \"\"\n" +
 	"                  run_as_script: false\n" +
 	"                  timeout: 0s\n" +
+	"                  when: ' '\n" +
 	"            # Workflow is the name of the workflow to be used for this configuration. For fields defined in both\n" +
 	"            # the config and the workflow, the fields from the config will override what is set in Workflow.\n" +
 	"            workflow: \"\"\n" +
 	"        # Timeout overrides maximum prowjob duration\n" +
 	"        timeout: 0s\n" +
+	"# RegistryVersion pins the step registry content used to resolve the\n" +
+	"# multi-stage tests in this configuration. The configuration resolver\n" +
+	"# reports the version it resolved against, so a run can be reproduced\n" +
+	"# exactly by pinning it. An empty value resolves against the latest\n" +
+	"# registry.\n" +
+	"registry_version: ' '\n" +
 	"# Releases maps semantic release payload identifiers\n" +
 	"# to the names that they will be exposed under. For\n" +
 	"# instance, an 'initial' name will be exposed as\n" +
@@ -1442,6 +1972,9 @@ const ciOperatorReferenceYaml = "# The list of base images describe\n" +
 	"      # of pull request workflows. Setting this field will\n" +
 	"      # create a periodic job instead of a presubmit\n" +
 	"      cron: \"\"\n" +
+	"      # ExecutionPolicy determines how the execution continues after a step\n" +
+	"      # fails when this test is targeted. Defaults to keep-going.\n" +
+	"      execution_policy: ' '\n" +
 	"      # Interval is how frequently the test should be run based\n" +
 	"      # on the last time the test ran. Setting this field will\n" +
 	"      # create a periodic job instead of a presubmit\n" +
@@ -1494,8 +2027,19 @@ const ciOperatorReferenceYaml = "# The list of base images describe\n" +
 	"                  default: \"\"\n" +
 	"                  # Documentation is a textual description of the parameter.\n" +
 	"                  documentation: ' '\n" +
+	"                  # Enum, if set, lists all values accepted by the parameter.\n" +
+	"                  enum:\n" +
+	"                    - \"\"\n" +
 	"                  # Name of the environment variable.\n" +
 	"                  name: ' '\n" +
+	"                  # Pattern, if set, is a regular expression that the entire value of the\n" +
+	"                  # parameter must match.\n" +
+	"                  # An empty value is always accepted regardless of the type, enum and\n" +
+	"                  # pattern, as steps conventionally treat it as unset.\n" +
+	"                  pattern: ' '\n" +
+	"                  # Type of the value of the parameter, one of `string` (the default),\n" +
+	"                  # `integer` or `boolean`. Boolean parameters accept `true` or `false`.\n" +
+	"                  type: ' '\n" +
 	"              # From is the container image that will be used for this observer.\n" +
 	"              from: ' '\n" +
 	"              # FromImage is a literal ImageStreamTag reference to use for this observer.\n" +
@@ -1522,6 +2066,11 @@ const ciOperatorReferenceYaml = "# The list of base images describe\n" +
 	"                    \"\": \"\"\n" +
 	"              # Timeout is how long the we will wait before aborting a job with SIGINT.\n" +
 	"              timeout: 0s\n" +
+	"        # ParallelGroups maps the steps resolved from a `parallel` group to the\n" +
+	"        # name of the group and is set by the resolver. Adjacent steps in a phase\n" +
+	"        # with the same group run concurrently.\n" +
+	"        parallel_groups:\n" +
+	"            \"\": \"\"\n" +
 	"        # Post is the array of test steps run after the tests finish and teardown/deprovision resources.\n" +
 	"        # Post steps always run, even if previous steps fail.\n" +
 	"        post:\n" +
@@ -1566,8 +2115,19 @@ const ciOperatorReferenceYaml = "# The list of base images describe\n" +
 	"                  default: \"\"\n" +
 	"                  # Documentation is a textual description of the parameter.\n" +
 	"                  documentation: ' '\n" +
+	"                  # Enum, if set, lists all values accepted by the parameter.\n" +
+	"                  enum:\n" +
+	"                    - \"\"\n" +
 	"                  # Name of the environment variable.\n" +
 	"                  name: ' '\n" +
+	"                  # Pattern, if set, is a regular expression that the entire value of the\n" +
+	"                  # parameter must match.\n" +
+	"                  # An empty value is always accepted regardless of the type, enum and\n" +
+	"                  # pattern, as steps conventionally treat it as unset.\n" +
+	"                  pattern: ' '\n" +
+	"                  # Type of the value of the parameter, one of `string` (the default),\n" +
+	"                  # `integer` or `boolean`. Boolean parameters accept `true` or `false`.\n" +
+	"                  type: ' '\n" +
 	"              # From is the container image that will be used for this step.\n" +
 	"              from: ' '\n" +
 	"              # FromImage is a literal ImageStreamTag reference to use for this step.\n" +
@@ -1580,6 +2140,14 @@ const ciOperatorReferenceYaml = "# The list of base images describe\n" +
 	"              # GracePeriod is how long the we will wait after sending SIGINT to send\n" +
 	"              # SIGKILL when aborting a Step.\n" +
 	"              grace_period: 0s\n" +
+	"              # Inputs lists the outputs of earlier steps that this step requires.\n" +
+	"              inputs:\n" +
+	"                - # Env is the environment variable used to expose the output to the step.\n" +
+	"                  env: ' '\n" +
+	"                  # Name of the output.\n" +
+	"                  name: ' '\n" +
+	"                  # Type is the (optional) expected type of the output.\n" +
+	"                  type: ' '\n" +
 	"              # Leases lists resources that should be acquired for the test.\n" +
 	"              leases:\n" +
 	"                - # Env is the environment variable that will contain the resource name.\n" +
@@ -1601,6 +2169,15 @@ const ciOperatorReferenceYaml = "# The list of base images describe\n" +
 	"              # flag is set to true in MultiStageTestConfiguration. This option is\n" +
 	"              # applicable to `post` steps.\n" +
 	"              optional_on_success: false\n" +
+	"              # Outputs lists the values this step publishes for later steps. Outputs\n" +
+	"              # are stored in ${SHARED_DIR}, so they share its size limit of 1 MiB.\n" +
+	"              outputs:\n" +
+	"                - # Documentation is a textual description of the output.\n" +
+	"                  documentation: ' '\n" +
+	"                  # Name of the output, which is also the name of the file in ${SHARED_DIR}.\n" +
+	"                  name: ' '\n" +
+	"                  # Type of the output, `string` or `file`.\n" +
+	"                  type: ' '\n" +
 	"              # Resources defines the resource requirements for the step.\n" +
 	"              resources:\n" +
 	"                # Limits are resource limits applied to an individual step in the job.\n" +
@@ -1611,11 +2188,30 @@ const ciOperatorReferenceYaml = "# The list of base images describe\n" +
 	"                # These are directly used in creating the Pods that execute the Job.\n" +
 	"                requests:\n" +
 	"                    \"\": \"\"\n" +
+	"              # Retry is the (optional) policy used to run the step again when it fails\n" +
+	"              # because of a transient infrastructure problem.\n" +
+	"              retry:\n" +
+	"                # Attempts is the maximum number of times the step is run, including the\n" +
+	"                # first attempt.\n" +
+	"                attempts: 0\n" +
+	"                # Backoff is how long we wait before the first retry. The wait is doubled\n" +
+	"                # for each subsequent retry. Defaults to 30 seconds.\n" +
+	"                backoff: 0s\n" +
+	"                # On lists the classes of failures that are retried. Defaults to all of\n" +
+	"                # them.\n" +
+	"                \"on\":\n" +
+	"                    - \"\"\n" +
 	"              # RunAsScript defines if this step should be executed as a script mounted\n" +
 	"              # in the test container instead of being executed directly via bash\n" +
 	"              run_as_script: false\n" +
 	"              # Timeout is how long the we will wait before aborting a job with SIGINT.\n" +
 	"              timeout: 0s\n" +
+	"              # When is an (optional) expression that determines whether the step runs,\n" +
+	"              # evaluated right before the step would start. For example, `failure()`\n" +
+	"              # runs the step only if an earlier step failed and `env.UPGRADE == \"true\"`\n" +
+	"              # only if the UPGRADE parameter of the step is set to \"true\". See\n" +
+	"              # WhenExpression for the syntax.\n" +
+	"              when: ' '\n" +
 	"        # Pre is the array of test steps run to set up the environment for the test.\n" +
 	"        pre:\n" +
 	"            - # As is the name of the LiteralTestStep.\n" +
@@ -1659,8 +2255,19 @@ const ciOperatorReferenceYaml = "# The list of base images describe\n" +
 	"                  default: \"\"\n" +
 	"                  # Documentation is a textual description of the parameter.\n" +
 	"                  documentation: ' '\n" +
+	"                  # Enum, if set, lists all values accepted by the parameter.\n" +
+	"                  enum:\n" +
+	"                    - \"\"\n" +
 	"                  # Name of the environment variable.\n" +
 	"                  name: ' '\n" +
+	"                  # Pattern, if set, is a regular expression that the entire value of the\n" +
+	"                  # parameter must match.\n" +
+	"                  # An empty value is always accepted regardless of the type, enum and\n" +
+	"                  # pattern, as steps conventionally treat it as unset.\n" +
+	"                  pattern: ' '\n" +
+	"                  # Type of the value of the parameter, one of `string` (the default),\n" +
+	"                  # `integer` or `boolean`. Boolean parameters accept `true` or `false`.\n" +
+	"                  type: ' '\n" +
 	"              # From is the container image that will be used for this step.\n" +
 	"              from: ' '\n" +
 	"              # FromImage is a literal ImageStreamTag reference to use for this step.\n" +
@@ -1673,6 +2280,14 @@ const ciOperatorReferenceYaml = "# The list of base images describe\n" +
 	"              # GracePeriod is how long the we will wait after sending SIGINT to send\n" +
 	"              # SIGKILL when aborting a Step.\n" +
 	"              grace_period: 0s\n" +
+	"              # Inputs lists the outputs of earlier steps that this step requires.\n" +
+	"              inputs:\n" +
+	"                - # Env is the environment variable used to expose the output to the step.\n" +
+	"                  env: ' '\n" +
+	"                  # Name of the output.\n" +
+	"                  name: ' '\n" +
+	"                  # Type is the (optional) expected type of the output.\n" +
+	"                  type: ' '\n" +
 	"              # Leases lists resources that should be acquired for the test.\n" +
 	"              leases:\n" +
 	"                - # Env is the environment variable that will contain the resource name.\n" +
@@ -1694,6 +2309,15 @@ const ciOperatorReferenceYaml = "# The list of base images describe\n" +
 	"              # flag is set to true in MultiStageTestConfiguration. This option is\n" +
 	"              # applicable to `post` steps.\n" +
 	"              optional_on_success: false\n" +
+	"              # Outputs lists the values this step publishes for later steps. Outputs\n" +
+	"              # are stored in ${SHARED_DIR}, so they share its size limit of 1 MiB.\n" +
+	"              outputs:\n" +
+	"                - # Documentation is a textual description of the output.\n" +
+	"                  documentation: ' '\n" +
+	"                  # Name of the output, which is also the name of the file in ${SHARED_DIR}.\n" +
+	"                  name: ' '\n" +
+	"                  # Type of the output, `string` or `file`.\n" +
+	"                  type: ' '\n" +
 	"              # Resources defines the resource requirements for the step.\n" +
 	"              resources:\n" +
 	"                # Limits are resource limits applied to an individual step in the job.\n" +
@@ -1704,11 +2328,30 @@ const ciOperatorReferenceYaml = "# The list of base images describe\n" +
 	"                # These are directly used in creating the Pods that execute the Job.\n" +
 	"                requests:\n" +
 	"                    \"\": \"\"\n" +
+	"              # Retry is the (optional) policy used to run the step again when it fails\n" +
+	"              # because of a transient infrastructure problem.\n" +
+	"              retry:\n" +
+	"                # Attempts is the maximum number of times the step is run, including the\n" +
+	"                # first attempt.\n" +
+	"                attempts: 0\n" +
+	"                # Backoff is how long we wait before the first retry. The wait is doubled\n" +
+	"                # for each subsequent retry. Defaults to 30 seconds.\n" +
+	"                backoff: 0s\n" +
+	"                # On lists the classes of failures that are retried. Defaults to all of\n" +
+	"                # them.\n" +
+	"                \"on\":\n" +
+	"                    - \"\"\n" +
 	"              # RunAsScript defines if this step should be executed as a script mounted\n" +
 	"              # in the test container instead of being executed directly via bash\n" +
 	"              run_as_script: false\n" +
 	"              # Timeout is how long the we will wait before aborting a job with SIGINT.\n" +
 	"              timeout: 0s\n" +
+	"              # When is an (optional) expression that determines whether the step runs,\n" +
+	"              # evaluated right before the step would start. For example, `failure()`\n" +
+	"              # runs the step only if an earlier step failed and `env.UPGRADE == \"true\"`\n" +
+	"              # only if the UPGRADE parameter of the step is set to \"true\". See\n" +
+	"              # WhenExpression for the syntax.\n" +
+	"              when: ' '\n" +
 	"        # Test is the array of test steps that define the actual test.\n" +
 	"        test:\n" +
 	"            - # As is the name of the LiteralTestStep.\n" +
@@ -1752,8 +2395,19 @@ const ciOperatorReferenceYaml = "# The list of base images describe\n" +
 	"                  default: \"\"\n" +
 	"                  # Documentation is a textual description of the parameter.\n" +
 	"                  documentation: ' '\n" +
+	"                  # Enum, if set, lists all values accepted by the parameter.\n" +
+	"                  enum:\n" +
+	"                    - \"\"\n" +
 	"                  # Name of the environment variable.\n" +
 	"                  name: ' '\n" +
+	"                  # Pattern, if set, is a regular expression that the entire value of the\n" +
+	"                  # parameter must match.\n" +
+	"                  # An empty value is always accepted regardless of the type, enum and\n" +
+	"                  # pattern, as steps conventionally treat it as unset.\n" +
+	"                  pattern: ' '\n" +
+	"                  # Type of the value of the parameter, one of `string` (the default),\n" +
+	"                  # `integer` or `boolean`. Boolean parameters accept `true` or `false`.\n" +
+	"                  type: ' '\n" +
 	"              # From is the container image that will be used for this step.\n" +
 	"              from: ' '\n" +
 	"              # FromImage is a literal ImageStreamTag reference to use for this step.\n" +
@@ -1766,6 +2420,14 @@ const ciOperatorReferenceYaml = "# The list of base images describe\n" +
 	"              # GracePeriod is how long the we will wait after sending SIGINT to send\n" +
 	"              # SIGKILL when aborting a Step.\n" +
 	"              grace_period: 0s\n" +
+	"              # Inputs lists the outputs of earlier steps that this step requires.\n" +
+	"              inputs:\n" +
+	"                - # Env is the environment variable used to expose the output to the step.\n" +
+	"                  env: ' '\n" +
+	"                  # Name of the output.\n" +
+	"                  name: ' '\n" +
+	"                  # Type is the (optional) expected type of the output.\n" +
+	"                  type: ' '\n" +
 	"              # Leases lists resources that should be acquired for the test.\n" +
 	"              leases:\n" +
 	"                - # Env is the environment variable that will contain the resource name.\n" +
@@ -1787,6 +2449,15 @@ const ciOperatorReferenceYaml = "# The list of base images describe\n" +
 	"              # flag is set to true in MultiStageTestConfiguration. This option is\n" +
 	"              # applicable to `post` steps.\n" +
 	"              optional_on_success: false\n" +
+	"              # Outputs lists the values this step publishes for later steps. Outputs\n" +
+	"              # are stored in ${SHARED_DIR}, so they share its size limit of 1 MiB.\n" +
+	"              outputs:\n" +
+	"                - # Documentation is a textual description of the output.\n" +
+	"                  documentation: ' '\n" +
+	"                  # Name of the output, which is also the name of the file in ${SHARED_DIR}.\n" +
+	"                  name: ' '\n" +
+	"                  # Type of the output, `string` or `file`.\n" +
+	"                  type: ' '\n" +
 	"              # Resources defines the resource requirements for the step.\n" +
 	"              resources:\n" +
 	"                # Limits are resource limits applied to an individual step in the job.\n" +
@@ -1797,11 +2468,30 @@ const ciOperatorReferenceYaml = "# The list of base images describe\n" +
 	"                # These are directly used in creating the Pods that execute the Job.\n" +
 	"                requests:\n" +
 	"                    \"\": \"\"\n" +
+	"              # Retry is the (optional) policy used to run the step again when it fails\n" +
+	"              # because of a transient infrastructure problem.\n" +
+	"              retry:\n" +
+	"                # Attempts is the maximum number of times the step is run, including the\n" +
+	"                # first attempt.\n" +
+	"                attempts: 0\n" +
+	"                # Backoff is how long we wait before the first retry. The wait is doubled\n" +
+	"                # for each subsequent retry. Defaults to 30 seconds.\n" +
+	"                backoff: 0s\n" +
+	"                # On lists the classes of failures that are retried. Defaults to all of\n" +
+	"                # them.\n" +
+	"                \"on\":\n" +
+	"                    - \"\"\n" +
 	"              # RunAsScript defines if this step should be executed as a script mounted\n" +
 	"              # in the test container instead of being executed directly via bash\n" +
 	"              run_as_script: false\n" +
 	"              # Timeout is how long the we will wait before aborting a job with SIGINT.\n" +
 	"              timeout: 0s\n" +
+	"              # When is an (optional) expression that determines whether the step runs,\n" +
+	"              # evaluated right before the step would start. For example, `failure()`\n" +
+	"              # runs the step only if an earlier step failed and `env.UPGRADE == \"true\"`\n" +
+	"              # only if the UPGRADE parameter of the step is set to \"true\". See\n" +
+	"              # WhenExpression for the syntax.\n" +
+	"              when: ' '\n" +
 	"        # Override job timeout\n" +
 	"        timeout: 0s\n" +
 	"      # MinimumInterval to wait between two runs of the job. Consecutive\n" +
@@ -1961,7 +2651,12 @@ const ciOperatorReferenceYaml = "# The list of base images describe\n" +
 	"                # LiteralTestStep is a full test step definition.\n" +
 	"                - default: \"\"\n" +
 	"                  documentation: ' '\n" +
+	"                  enum:\n" +
+	"                    # LiteralTestStep is a full test step definition.\n" +
+	"                    - \"\"\n" +
 	"                  name: ' '\n" +
+	"                  pattern: ' '\n" +
+	"                  type: ' '\n" +
 	"              from: ' '\n" +
 	"              from_image:\n" +
 	"                # LiteralTestStep is a full test step definition.\n" +
@@ -1970,6 +2665,11 @@ const ciOperatorReferenceYaml = "# The list of base images describe\n" +
 	"                namespace: ' '\n" +
 	"                tag: ' '\n" +
 	"              grace_period: 0s\n" +
+	"              inputs:\n" +
+	"                # LiteralTestStep is a full test step definition.\n" +
+	"                - env: ' '\n" +
+	"                  name: ' '\n" +
+	"                  type: ' '\n" +
 	"              leases:\n" +
 	"                # LiteralTestStep is a full test step definition.\n" +
 	"                - env: ' '\n" +
@@ -1981,6 +2681,97 @@ const ciOperatorReferenceYaml = "# The list of base images describe\n" +
 	"                # LiteralTestStep is a full test step definition.\n" +
 	"                - \"\"\n" +
 	"              optional_on_success: false\n" +
+	"              outputs:\n" +
+	"                # LiteralTestStep is a full test step definition.\n" +
+	"                - documentation: ' '\n" +
+	"                  name: ' '\n" +
+	"                  type: ' '\n" +
+	"              # Parallel is a group of steps that run concurrently. Changes the\n" +
+	"              # members make to the shared directory are merged once all of them\n" +
+	"              # finish and conflicting changes to the same file fail the group.\n" +
+	"              parallel:\n" +
+	"                # LiteralTestStep is a full test step definition.\n" +
+	"                - as: ' '\n" +
+	"                  best_effort: false\n" +
+	"                  cli: ' '\n" +
+	"                  commands: ' '\n" +
+	"                  credentials:\n" +
+	"                    # LiteralTestStep is a full test step definition.\n" +
+	"                    - mount_path: ' '\n" +
+	"                      name: ' '\n" +
+	"                      namespace: ' '\n" +
+	"                  dependencies:\n" +
+	"                    # LiteralTestStep is a full test step definition.\n" +
+	"                    - env: ' '\n" +
+	"                      name: ' '\n" +
+	"                  dnsConfig:\n" +
+	"                    # LiteralTestStep is a full test step definition.\n" +
+	"                    nameservers:\n" +
+	"                        # LiteralTestStep is a full test step definition.\n" +
+	"                        - \"\"\n" +
+	"                    searches:\n" +
+	"                        # LiteralTestStep is a full test step definition.\n" +
+	"                        - \"\"\n" +
+	"                  env:\n" +
+	"                    # LiteralTestStep is a full test step definition.\n" +
+	"                    - default: \"\"\n" +
+	"                      documentation: ' '\n" +
+	"                      enum:\n" +
+	"                        # LiteralTestStep is a full test step definition.\n" +
+	"                        - \"\"\n" +
+	"                      name: ' '\n" +
+	"                      pattern: ' '\n" +
+	"                      type: ' '\n" +
+	"                  from: ' '\n" +
+	"                  from_image:\n" +
+	"                    # LiteralTestStep is a full test step definition.\n" +
+	"                    as: ' '\n" +
+	"                    name: ' '\n" +
+	"                    namespace: ' '\n" +
+	"                    tag: ' '\n" +
+	"                  grace_period: 0s\n" +
+	"                  inputs:\n" +
+	"                    # LiteralTestStep is a full test step definition.\n" +
+	"                    - env: ' '\n" +
+	"                      name: ' '\n" +
+	"                      type: ' '\n" +
+	"                  leases:\n" +
+	"                    # LiteralTestStep is a full test step definition.\n" +
+	"                    - env: ' '\n" +
+	"                      resource_type: ' '\n" +
+	"                  no_kubeconfig: false\n" +
+	"                  node_architecture: \"\"\n" +
+	"                  # Observers are the observers that should be running\n" +
+	"                  observers:\n" +
+	"                    # LiteralTestStep is a full test step definition.\n" +
+	"                    - \"\"\n" +
+	"                  optional_on_success: false\n" +
+	"                  outputs:\n" +
+	"                    # LiteralTestStep is a full test step definition.\n" +
+	"                    - documentation: ' '\n" +
+	"                      name: ' '\n" +
+	"                      type: ' '\n" +
+	"                  # Reference is the name of a step reference.\n" +
+	"                  ref: \"\"\n" +
+	"                  # Resources defines the resource requirements for the step.\n" +
+	"                  resources:\n" +
+	"                    # LiteralTestStep is a full test step definition.\n" +
+	"                    limits:\n" +
+	"                        # LiteralTestStep is a full test step definition.\n" +
+	"                        \"\": \"\"\n" +
+	"                    requests:\n" +
+	"                        # LiteralTestStep is a full test step definition.\n" +
+	"                        \"\": \"\"\n" +
+	"                  retry:\n" +
+	"                    # LiteralTestStep is a full test step definition.\n" +
+	"                    attempts: 0\n" +
+	"                    backoff: 0s\n" +
+	"                    \"on\":\n" +
+	"                        # LiteralTestStep is a full test step definition.\n" +
+	"                        - \"\"\n" +
+	"                  run_as_script: false\n" +
+	"                  timeout: 0s\n" +
+	"                  when: ' '\n" +
 	"              # Reference is the name of a step reference.\n" +
 	"              ref: \"\"\n" +
 	"              # Resources defines the resource requirements for the step.\n" +
@@ -1992,8 +2783,16 @@ const ciOperatorReferenceYaml = "# The list of base images describe\n" +
 	"                requests:\n" +
 	"                    # LiteralTestStep is a full test step definition.\n" +
 	"                    \"\": \"\"\n" +
+	"              retry:\n" +
+	"                # LiteralTestStep is a full test step definition.\n" +
+	"                attempts: 0\n" +
+	"                backoff: 0s\n" +
+	"                \"on\":\n" +
+	"                    # LiteralTestStep is a full test step definition.\n" +
+	"                    - \"\"\n" +
 	"              run_as_script: false\n" +
 	"              timeout: 0s\n" +
+	"              when: ' '\n" +
 	"        # Pre is the array of test steps run to set up the environment for the test.\n" +
 	"        pre:\n" +
 	"            # LiteralTestStep is a full test step definition.\n" +
@@ -2026,7 +2825,12 @@ const ciOperatorReferenceYaml = "# The list of base images describe\n" +
 	"                # LiteralTestStep is a full test step definition.\n" +
 	"                - default: \"\"\n" +
 	"                  documentation: ' '\n" +
+	"                  enum:\n" +
+	"                    # LiteralTestStep is a full test step definition.\n" +
+	"                    - \"\"\n" +
 	"                  name: ' '\n" +
+	"                  pattern: ' '\n" +
+	"                  type: ' '\n" +
 	"              from: ' '\n" +
 	"              from_image:\n" +
 	"                # LiteralTestStep is a full test step definition.\n" +
@@ -2035,6 +2839,11 @@ const ciOperatorReferenceYaml = "# The list of base images describe\n" +
 	"                namespace: ' '\n" +
 	"                tag: ' '\n" +
 	"              grace_period: 0s\n" +
+	"              inputs:\n" +
+	"                # LiteralTestStep is a full test step definition.\n" +
+	"                - env: ' '\n" +
+	"                  name: ' '\n" +
+	"                  type: ' '\n" +
 	"              leases:\n" +
 	"                # LiteralTestStep is a full test step definition.\n" +
 	"                - env: ' '\n" +
@@ -2046,6 +2855,97 @@ const ciOperatorReferenceYaml = "# The list of base images describe\n" +
 	"                # LiteralTestStep is a full test step definition.\n" +
 	"                - \"\"\n" +
 	"              optional_on_success: false\n" +
+	"              outputs:\n" +
+	"                # LiteralTestStep is a full test step definition.\n" +
+	"                - documentation: ' '\n" +
+	"                  name: ' '\n" +
+	"                  type: ' '\n" +
+	"              # Parallel is a group of steps that run concurrently. Changes the\n" +
+	"              # members make to the shared directory are merged once all of them\n" +
+	"              # finish and conflicting changes to the same file fail the group.\n" +
+	"              parallel:\n" +
+	"                # LiteralTestStep is a full test step definition.\n" +
+	"                - as: ' '\n" +
+	"                  best_effort: false\n" +
+	"                  cli: ' '\n" +
+	"                  commands: ' '\n" +
+	"                  credentials:\n" +
+	"                    # LiteralTestStep is a full test step definition.\n" +
+	"                    - mount_path: ' '\n" +
+	"                      name: ' '\n" +
+	"                      namespace: ' '\n" +
+	"                  dependencies:\n" +
+	"                    # LiteralTestStep is a full test step definition.\n" +
+	"                    - env: ' '\n" +
+	"                      name: ' '\n" +
+	"                  dnsConfig:\n" +
+	"                    # LiteralTestStep is a full test step definition.\n" +
+	"                    nameservers:\n" +
+	"                        # LiteralTestStep is a full test step definition.\n" +
+	"                        - \"\"\n" +
+	"                    searches:\n" +
+	"                        # LiteralTestStep is a full test step definition.\n" +
+	"                        - \"\"\n" +
+	"                  env:\n" +
+	"                    # LiteralTestStep is a full test step definition.\n" +
+	"                    - default: \"\"\n" +
+	"                      documentation: ' '\n" +
+	"                      enum:\n" +
+	"                        # LiteralTestStep is a full test step definition.\n" +
+	"                        - \"\"\n" +
+	"                      name: ' '\n" +
+	"                      pattern: ' '\n" +
+	"                      type: ' '\n" +
+	"                  from: ' '\n" +
+	"                  from_image:\n" +
+	"                    # LiteralTestStep is a full test step definition.\n" +
+	"                    as: ' '\n" +
+	"                    name: ' '\n" +
+	"                    namespace: ' '\n" +
+	"                    tag: ' '\n" +
+	"                  grace_period: 0s\n" +
+	"                  inputs:\n" +
+	"                    # LiteralTestStep is a full test step definition.\n" +
+	"                    - env: ' '\n" +
+	"                      name: ' '\n" +
+	"                      type: ' '\n" +
+	"                  leases:\n" +
+	"                    # LiteralTestStep is a full test step definition.\n" +
+	"                    - env: ' '\n" +
+	"                      resource_type: ' '\n" +
+	"                  no_kubeconfig: false\n" +
+	"                  node_architecture: \"\"\n" +
+	"                  # Observers are the observers that should be running\n" +
+	"                  observers:\n" +
+	"                    # LiteralTestStep is a full test step definition.\n" +
+	"                    - \"\"\n" +
+	"                  optional_on_success: false\n" +
+	"                  outputs:\n" +
+	"                    # LiteralTestStep is a full test step definition.\n" +
+	"                    - documentation: ' '\n" +
+	"                      name: ' '\n" +
+	"                      type: ' '\n" +
+	"                  # Reference is the name of a step reference.\n" +
+	"                  ref: \"\"\n" +
+	"                  # Resources defines the resource requirements for the step.\n" +
+	"                  resources:\n" +
+	"                    # LiteralTestStep is a full test step definition.\n" +
+	"                    limits:\n" +
+	"                        # LiteralTestStep is a full test step definition.\n" +
+	"                        \"\": \"\"\n" +
+	"                    requests:\n" +
+	"                        # LiteralTestStep is a full test step definition.\n" +
+	"                        \"\": \"\"\n" +
+	"                  retry:\n" +
+	"                    # LiteralTestStep is a full test step definition.\n" +
+	"                    attempts: 0\n" +
+	"                    backoff: 0s\n" +
+	"                    \"on\":\n" +
+	"                        # LiteralTestStep is a full test step definition.\n" +
+	"                        - \"\"\n" +
+	"                  run_as_script: false\n" +
+	"                  timeout: 0s\n" +
+	"                  when: ' '\n" +
 	"              # Reference is the name of a step reference.\n" +
 	"              ref: \"\"\n" +
 	"              # Resources defines the resource requirements for the step.\n" +
@@ -2057,8 +2957,16 @@ const ciOperatorReferenceYaml = "# The list of base images describe\n" +
 	"                requests:\n" +
 	"                    # LiteralTestStep is a full test step definition.\n" +
 	"                    \"\": \"\"\n" +
+	"              retry:\n" +
+	"                # LiteralTestStep is a full test step definition.\n" +
+	"                attempts: 0\n" +
+	"                backoff: 0s\n" +
+	"                \"on\":\n" +
+	"                    # LiteralTestStep is a full test step definition.\n" +
+	"                    - \"\"\n" +
 	"              run_as_script: false\n" +
 	"              timeout: 0s\n" +
+	"              when: ' '\n" +
 	"        # Test is the array of test steps that define the actual test.\n" +
 	"        test:\n" +
 	"            # LiteralTestStep is a full test step definition.\n" +
@@ -2091,7 +2999,12 @@ const ciOperatorReferenceYaml = "# The list of base images describe\n" +
 	"                # LiteralTestStep is a full test step definition.\n" +
 	"                - default: \"\"\n" +
 	"                  documentation: ' '\n" +
+	"                  enum:\n" +
+	"                    # LiteralTestStep is a full test step definition.\n" +
+	"                    - \"\"\n" +
 	"                  name: ' '\n" +
+	"                  pattern: ' '\n" +
+	"                  type: ' '\n" +
 	"              from: ' '\n" +
 	"              from_image:\n" +
 	"                # LiteralTestStep is a full test step definition.\n" +
@@ -2100,6 +3013,11 @@ const ciOperatorReferenceYaml = "# The list of base images describe\n" +
 	"                namespace: ' '\n" +
 	"                tag: ' '\n" +
 	"              grace_period: 0s\n" +
+	"              inputs:\n" +
+	"                # LiteralTestStep is a full test step definition.\n" +
+	"                - env: ' '\n" +
+	"                  name: ' '\n" +
+	"                  type: ' '\n" +
 	"              leases:\n" +
 	"                # LiteralTestStep is a full test step definition.\n" +
 	"                - env: ' '\n" +
@@ -2111,6 +3029,97 @@ const ciOperatorReferenceYaml = "# The list of base images describe\n" +
 	"                # LiteralTestStep is a full test step definition.\n" +
 	"                - \"\"\n" +
 	"              optional_on_success: false\n" +
+	"              outputs:\n" +
+	"                # LiteralTestStep is a full test step definition.\n" +
+	"                - documentation: ' '\n" +
+	"                  name: ' '\n" +
+	"                  type: ' '\n" +
+	"              # Parallel is a group of steps that run concurrently. Changes the\n" +
+	"              # members make to the shared directory are merged once all of them\n" +
+	"              # finish and conflicting changes to the same file fail the group.\n" +
+	"              parallel:\n" +
+	"                # LiteralTestStep is a full test step definition.\n" +
+	"                - as: ' '\n" +
+	"                  best_effort: false\n" +
+	"                  cli: ' '\n" +
+	"                  commands: ' '\n" +
+	"                  credentials:\n" +
+	"                    # LiteralTestStep is a full test step definition.\n" +
+	"                    - mount_path: ' '\n" +
+	"                      name: ' '\n" +
+	"                      namespace: ' '\n" +
+	"                  dependencies:\n" +
+	"                    # LiteralTestStep is a full test step definition.\n" +
+	"                    - env: ' '\n" +
+	"                      name: ' '\n" +
+	"                  dnsConfig:\n" +
+	"                    # LiteralTestStep is a full test step definition.\n" +
+	"                    nameservers:\n" +
+	"                        # LiteralTestStep is a full test step definition.\n" +
+	"                        - \"\"\n" +
+	"                    searches:\n" +
+	"                        # LiteralTestStep is a full test step definition.\n" +
+	"                        - \"\"\n" +
+	"                  env:\n" +
+	"                    # LiteralTestStep is a full test step definition.\n" +
+	"                    - default: \"\"\n" +
+	"                      documentation: ' '\n" +
+	"                      enum:\n" +
+	"                        # LiteralTestStep is a full test step definition.\n" +
+	"                        - \"\"\n" +
+	"                      name: ' '\n" +
+	"                      pattern: ' '\n" +
+	"                      type: ' '\n" +
+	"                  from: ' '\n" +
+	"                  from_image:\n" +
+	"                    # LiteralTestStep is a full test step definition.\n" +
+	"                    as: ' '\n" +
+	"                    name: ' '\n" +
+	"                    namespace: ' '\n" +
+	"                    tag: ' '\n" +
+	"                  grace_period: 0s\n" +
+	"                  inputs:\n" +
+	"                    # LiteralTestStep is a full test step definition.\n" +
+	"                    - env: ' '\n" +
+	"                      name: ' '\n" +
+	"                      type: ' '\n" +
+	"                  leases:\n" +
+	"                    # LiteralTestStep is a full test step definition.\n" +
+	"                    - env: ' '\n" +
+	"                      resource_type: ' '\n" +
+	"                  no_kubeconfig: false\n" +
+	"                  node_architecture: \"\"\n" +
+	"                  # Observers are the observers that should be running\n" +
+	"                  observers:\n" +
+	"                    # LiteralTestStep is a full test step definition.\n" +
+	"                    - \"\"\n" +
+	"                  optional_on_success: false\n" +
+	"                  outputs:\n" +
+	"                    # LiteralTestStep is a full test step definition.\n" +
+	"                    - documentation: ' '\n" +
+	"                      name: ' '\n" +
+	"                      type: ' '\n" +
+	"                  # Reference is the name of a step reference.\n" +
+	"                  ref: \"\"\n" +
+	"                  # Resources defines the resource requirements for the step.\n" +
+	"                  resources:\n" +
+	"                    # LiteralTestStep is a full test step definition.\n" +
+	"                    limits:\n" +
+	"                        # LiteralTestStep is a full test step definition.\n" +
+	"                        \"\": \"\"\n" +
+	"                    requests:\n" +
+	"                        # LiteralTestStep is a full test step definition.\n" +
+	"                        \"\": \"\"\n" +
+	"                  retry:\n" +
+	"                    # LiteralTestStep is a full test step definition.\n" +
+	"                    attempts: 0\n" +
+	"                    backoff: 0s\n" +
+	"                    \"on\":\n" +
+	"                        # LiteralTestStep is a full test step definition.\n" +
+	"                        - \"\"\n" +
+	"                  run_as_script: false\n" +
+	"                  timeout: 0s\n" +
+	"                  when: ' '\n" +
 	"              # Reference is the name of a step reference.\n" +
 	"              ref: \"\"\n" +
 	"              # Resources defines the resource requirements for the step.\n" +
@@ -2122,8 +3131,16 @@ const ciOperatorReferenceYaml = "# The list of base images describe\n" +
 	"                requests:\n" +
 	"                    # LiteralTestStep is a full test step definition.\n" +
 	"                    \"\": \"\"\n" +
+	"              retry:\n" +
+	"                # LiteralTestStep is a full test step definition.\n" +
+	"                attempts: 0\n" +
+	"                backoff: 0s\n" +
+	"                \"on\":\n" +
+	"                    # LiteralTestStep is a full test step definition.\n" +
+	"                    - \"\"\n" +
 	"              run_as_script: false\n" +
 	"              timeout: 0s\n" +
+	"              when: ' '\n" +
 	"        # Workflow is the name of the workflow to be used for this configuration. For fields defined in both\n" +
 	"        # the config and the workflow, the fields from the config will override what is set in Workflow.\n" +
 	"        workflow: \"\"\n" +